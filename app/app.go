@@ -6,6 +6,7 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/jmoiron/sqlx"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/private"
 	dataRedis "github.com/keratin/authn-server/app/data/redis"
 	"github.com/keratin/authn-server/lib/oauth"
 	"github.com/keratin/authn-server/ops"
@@ -16,18 +17,29 @@ import (
 type pinger func() bool
 
 type App struct {
-	DB                *sqlx.DB
-	DbCheck           pinger
-	RedisCheck        pinger
-	Config            *Config
-	AccountStore      data.AccountStore
-	RefreshTokenStore data.RefreshTokenStore
-	KeyStore          data.KeyStore
-	TOTPCache         data.TOTPCache
-	Actives           data.Actives
-	Reporter          ops.ErrorReporter
-	OauthProviders    map[string]oauth.Provider
-	Logger            logrus.FieldLogger
+	DB                   *sqlx.DB
+	DbCheck              pinger
+	RedisCheck           pinger
+	Config               *Config
+	AccountStore         data.AccountStore
+	RefreshTokenStore    data.RefreshTokenStore
+	TrustedDeviceStore   data.TrustedDeviceStore
+	ClientStore          data.ClientStore
+	OrganizationStore    data.OrganizationStore
+	InvitationStore      data.InvitationStore
+	KeyStore             data.KeyStore
+	KeyRotater           *data.KeyStoreRotater
+	BlobStore            *data.EncryptedBlobStore
+	TOTPCache            data.TOTPCache
+	OTPCodeCache         data.OTPCodeCache
+	DeviceCodeCache      data.DeviceCodeCache
+	ClientAssertions     data.ClientAssertionCache
+	MFAChallenges        data.MFAChallengeCache
+	Actives              data.Actives
+	Reporter             ops.ErrorReporter
+	OauthProviders       map[string]oauth.Provider
+	TenantOauthProviders map[string]map[string]oauth.Provider
+	Logger               logrus.FieldLogger
 }
 
 func NewApp(cfg *Config, logger logrus.FieldLogger) (*App, error) {
@@ -54,92 +66,188 @@ func NewApp(cfg *Config, logger logrus.FieldLogger) (*App, error) {
 		}
 	}
 
+	instrumentation := data.NewInstrumentation(cfg.DataOperationTimeout, cfg.DataSlowOperationThreshold, logger)
+
 	accountStore, err := data.NewAccountStore(db)
 	if err != nil {
 		return nil, errors.Wrap(err, "NewAccountStore")
 	}
+	replicaStores := []data.AccountStore{}
+	for _, replicaURL := range cfg.DatabaseReplicaURLs {
+		replica, err := data.NewDB(replicaURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "data.NewDB")
+		}
+		replicaStore, err := data.NewAccountStore(replica)
+		if err != nil {
+			return nil, errors.Wrap(err, "NewAccountStore")
+		}
+		replicaStores = append(replicaStores, replicaStore)
+	}
+	accountStore = data.NewReplicatedAccountStore(accountStore, replicaStores)
+	accountStore = instrumentation.AccountStore(accountStore)
 
 	tokenStore, err := data.NewRefreshTokenStore(db, redis, errorReporter, cfg.RefreshTokenTTL)
 	if err != nil {
 		return nil, errors.Wrap(err, "NewRefreshTokenStore")
 	}
+	tokenStore = instrumentation.RefreshTokenStore(tokenStore)
+
+	trustedDeviceStore, err := data.NewTrustedDeviceStore(db, redis, errorReporter, cfg.TrustedDeviceTTL)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewTrustedDeviceStore")
+	}
+	trustedDeviceStore = instrumentation.TrustedDeviceStore(trustedDeviceStore)
+
+	clientStore, err := data.NewClientStore(db)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewClientStore")
+	}
+	clientStore = instrumentation.ClientStore(clientStore)
+
+	organizationStore, err := data.NewOrganizationStore(db)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewOrganizationStore")
+	}
+	organizationStore = instrumentation.OrganizationStore(organizationStore)
+
+	invitationStore, err := data.NewInvitationStore(db)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewInvitationStore")
+	}
+	invitationStore = instrumentation.InvitationStore(invitationStore)
 
 	blobStore, err := data.NewBlobStore(cfg.AccessTokenTTL, redis, db, errorReporter)
 	if err != nil {
 		return nil, errors.Wrap(err, "NewBlobStore")
 	}
+	blobStore = instrumentation.BlobStore(blobStore)
 
-	encryptedBlobStore := data.NewEncryptedBlobStore(blobStore, cfg.DBEncryptionKey)
+	encryptedBlobStore := data.NewEncryptedBlobStore(blobStore, cfg.DBEncryptionKeyring())
 
 	keyStore := data.NewRotatingKeyStore()
-	if cfg.IdentitySigningKey == nil {
-		m := data.NewKeyStoreRotater(
+	var keyRotater *data.KeyStoreRotater
+	if cfg.IdentitySigningKeysDir != "" {
+		err := data.NewKeyStoreWatcher(cfg.IdentitySigningKeysDir, logger).Maintain(keyStore, errorReporter)
+		if err != nil {
+			return nil, errors.Wrap(err, "Maintain")
+		}
+	} else if cfg.IdentitySigningKey == nil {
+		keyRotater = data.NewKeyStoreRotater(
 			encryptedBlobStore,
 			cfg.AccessTokenTTL,
+			cfg.IdentitySigningAlgorithm,
 			logger,
 		)
-		err := m.Maintain(keyStore, errorReporter)
+		err := keyRotater.Maintain(keyStore, errorReporter)
 		if err != nil {
 			return nil, errors.Wrap(err, "Maintain")
 		}
 	} else {
-		keyStore.Rotate(cfg.IdentitySigningKey)
+		keys := append([]*private.Key{}, cfg.IdentityPublishedKeys...)
+		keyStore.Reset(append(keys, cfg.IdentitySigningKey), nil)
 	}
 
 	totpCache := data.NewTOTPCache(encryptedBlobStore)
+	otpCodeCache := data.NewOTPCodeCache(encryptedBlobStore)
+	deviceCodeCache := data.NewDeviceCodeCache(encryptedBlobStore)
+	clientAssertions := data.NewClientAssertionCache(encryptedBlobStore)
+	mfaChallenges := data.NewMFAChallengeCache(encryptedBlobStore)
 
 	var actives data.Actives
 	if redis != nil {
-		actives = dataRedis.NewActives(
+		actives = instrumentation.Actives(dataRedis.NewActives(
 			redis,
 			cfg.StatisticsTimeZone,
 			cfg.DailyActivesRetention,
 			cfg.WeeklyActivesRetention,
 			5*12,
-		)
+		))
 	}
 
 	oauthProviders, err := initializeOAuthProviders(cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "initializeOAuthProviders")
 	}
+	tenantOauthProviders, err := initializeTenantOAuthProviders(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "initializeTenantOAuthProviders")
+	}
 
 	return &App{
 		// Provide access to root DB - useful when extending AccountStore functionality
-		DB:                db,
-		DbCheck:           func() bool { return db.Ping() == nil },
-		RedisCheck:        func() bool { return redis != nil && redis.Ping(context.TODO()).Err() == nil },
-		Config:            cfg,
-		AccountStore:      accountStore,
-		RefreshTokenStore: tokenStore,
-		KeyStore:          keyStore,
-		TOTPCache:         totpCache,
-		Actives:           actives,
-		Reporter:          errorReporter,
-		OauthProviders:    oauthProviders,
-		Logger:            logger,
+		DB:                   db,
+		DbCheck:              func() bool { return db.Ping() == nil },
+		RedisCheck:           func() bool { return redis != nil && redis.Ping(context.TODO()).Err() == nil },
+		Config:               cfg,
+		AccountStore:         accountStore,
+		RefreshTokenStore:    tokenStore,
+		TrustedDeviceStore:   trustedDeviceStore,
+		ClientStore:          clientStore,
+		OrganizationStore:    organizationStore,
+		InvitationStore:      invitationStore,
+		KeyStore:             keyStore,
+		KeyRotater:           keyRotater,
+		BlobStore:            encryptedBlobStore,
+		TOTPCache:            totpCache,
+		OTPCodeCache:         otpCodeCache,
+		DeviceCodeCache:      deviceCodeCache,
+		ClientAssertions:     clientAssertions,
+		MFAChallenges:        mfaChallenges,
+		Actives:              actives,
+		Reporter:             errorReporter,
+		OauthProviders:       oauthProviders,
+		TenantOauthProviders: tenantOauthProviders,
+		Logger:               logger,
 	}, nil
 }
 
 func initializeOAuthProviders(cfg *Config) (map[string]oauth.Provider, error) {
+	return newOAuthProviders(map[string]*oauth.Credentials{
+		"google":    cfg.GoogleOauthCredentials,
+		"github":    cfg.GitHubOauthCredentials,
+		"facebook":  cfg.FacebookOauthCredentials,
+		"discord":   cfg.DiscordOauthCredentials,
+		"microsoft": cfg.MicrosoftOauthCredentials,
+		"apple":     cfg.AppleOAuthCredentials,
+	})
+}
+
+// initializeTenantOAuthProviders configures the providers of tenants with their own credentials.
+func initializeTenantOAuthProviders(cfg *Config) (map[string]map[string]oauth.Provider, error) {
+	tenantProviders := make(map[string]map[string]oauth.Provider)
+	for name, tenant := range cfg.Tenants {
+		if len(tenant.OAuthCredentials) == 0 {
+			continue
+		}
+		providers, err := newOAuthProviders(tenant.OAuthCredentials)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tenant %v", name)
+		}
+		tenantProviders[name] = providers
+	}
+	return tenantProviders, nil
+}
+
+func newOAuthProviders(credentials map[string]*oauth.Credentials) (map[string]oauth.Provider, error) {
 	oauthProviders := make(map[string]oauth.Provider)
-	if cfg.GoogleOauthCredentials != nil {
-		oauthProviders["google"] = *oauth.NewGoogleProvider(cfg.GoogleOauthCredentials)
+	if credentials["google"] != nil {
+		oauthProviders["google"] = *oauth.NewGoogleProvider(credentials["google"])
 	}
-	if cfg.GitHubOauthCredentials != nil {
-		oauthProviders["github"] = *oauth.NewGitHubProvider(cfg.GitHubOauthCredentials)
+	if credentials["github"] != nil {
+		oauthProviders["github"] = *oauth.NewGitHubProvider(credentials["github"])
 	}
-	if cfg.FacebookOauthCredentials != nil {
-		oauthProviders["facebook"] = *oauth.NewFacebookProvider(cfg.FacebookOauthCredentials)
+	if credentials["facebook"] != nil {
+		oauthProviders["facebook"] = *oauth.NewFacebookProvider(credentials["facebook"])
 	}
-	if cfg.DiscordOauthCredentials != nil {
-		oauthProviders["discord"] = *oauth.NewDiscordProvider(cfg.DiscordOauthCredentials)
+	if credentials["discord"] != nil {
+		oauthProviders["discord"] = *oauth.NewDiscordProvider(credentials["discord"])
 	}
-	if cfg.MicrosoftOauthCredentials != nil {
-		oauthProviders["microsoft"] = *oauth.NewMicrosoftProvider(cfg.MicrosoftOauthCredentials)
+	if credentials["microsoft"] != nil {
+		oauthProviders["microsoft"] = *oauth.NewMicrosoftProvider(credentials["microsoft"])
 	}
-	if cfg.AppleOAuthCredentials != nil {
-		appleProvider, err := oauth.NewAppleProvider(cfg.AppleOAuthCredentials)
+	if credentials["apple"] != nil {
+		appleProvider, err := oauth.NewAppleProvider(credentials["apple"])
 		if err != nil {
 			return nil, err
 		}
@@ -147,3 +255,13 @@ func initializeOAuthProviders(cfg *Config) (map[string]oauth.Provider, error) {
 	}
 	return oauthProviders, nil
 }
+
+// OauthProvider returns the named provider for a tenant. A tenant's own credentials take precedence
+// over those of the server.
+func (a *App) OauthProvider(tenant string, name string) (oauth.Provider, bool) {
+	if provider, ok := a.TenantOauthProviders[tenant][name]; ok {
+		return provider, true
+	}
+	provider, ok := a.OauthProviders[name]
+	return provider, ok
+}
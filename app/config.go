@@ -3,14 +3,13 @@ package app
 import (
 	"crypto/rand"
 	"crypto/sha256"
-	"crypto/x509"
 	"encoding/hex"
-	"encoding/pem"
 	"fmt"
 	"math/big"
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +17,7 @@ import (
 	// a .env file is extremely useful during development
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/lib/compat"
 	"github.com/keratin/authn-server/lib/oauth"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/ops"
@@ -30,8 +30,17 @@ type Config struct {
 	AppPasswordlessTokenURL     *url.URL
 	PasswordlessTokenTTL        time.Duration
 	PasswordlessTokenSigningKey []byte
+	AppInvitationURL            *url.URL
+	InvitationTokenTTL          time.Duration
+	InvitationTokenSigningKey   []byte
+	DeviceVerificationURL       *url.URL
+	DeviceCodeTTL               time.Duration
 	AppPasswordResetURL         *url.URL
 	AppPasswordChangedURL       *url.URL
+	AppPreRegistrationURL       *url.URL
+	AppPreLoginURL              *url.URL
+	HookTimeout                 time.Duration
+	BackchannelLogoutURIs       map[string]*url.URL
 	ApplicationDomains          []route.Domain
 	BcryptCost                  int
 	UsernameIsEmail             bool
@@ -46,15 +55,22 @@ type Config struct {
 	RedisSentinelNodes          string
 	RedisSentinelPassword       string
 	DatabaseURL                 *url.URL
+	DatabaseReplicaURLs         []*url.URL
+	DataOperationTimeout        time.Duration
+	DataSlowOperationThreshold  time.Duration
 	SessionCookieName           string
 	OAuthCookieName             string
 	SessionSigningKey           []byte
 	ResetSigningKey             []byte
 	DBEncryptionKey             []byte
+	DBEncryptionPreviousKeys    [][]byte
 	OAuthSigningKey             []byte
 	AppSigningKey               []byte
 	ResetTokenTTL               time.Duration
 	IdentitySigningKey          *private.Key
+	IdentityPublishedKeys       []*private.Key
+	IdentitySigningKeysDir      string
+	IdentitySigningAlgorithm    string
 	AuthNURL                    *url.URL
 	ForceSSL                    bool
 	SameSite                    http.SameSite
@@ -78,10 +94,119 @@ type Config struct {
 	MicrosoftOauthCredentials   *oauth.Credentials
 	AppleOAuthCredentials       *oauth.Credentials
 	RefreshTokenExplicitExpiry  bool
+	RefreshTokenRotation        bool
+	RefreshTokenReuseGrace      time.Duration
+	SessionMaxAge               DomainDurations
+	SessionIdleTimeout          DomainDurations
+	EnableMFAChallenge          bool
+	MFAChallengeTTL             time.Duration
+	MFAChallengeSigningKey      []byte
+	MFARequired                 bool
+	MFARequiredDomains          []string
+	EnableTrustedDevices        bool
+	TrustedDeviceTTL            time.Duration
+	TrustedDeviceSigningKey     []byte
+	TrustedDeviceCookieName     string
+	AppOTPDeliveryURL           *url.URL
+	OTPCodeTTL                  time.Duration
+	OTPCodeSigningKey           []byte
+	TOTPIssuer                  string
+	TOTPDigits                  int
+	TOTPPeriod                  time.Duration
+	TOTPAlgorithm               string
+	ReauthenticationMaxAge      time.Duration
+	IntrospectionCacheTTL       time.Duration
+	EnablePersonalAccessTokens  bool
+	PersonalAccessTokenScopes   []string
+	ImpersonationMaxAge         time.Duration
+	TenantDomains               map[string]string
+	Tenants                     map[string]*TenantConfig
+	EnableOrganizations         bool
+}
+
+// DomainDurations is a default duration with overrides for specific application domains. A zero
+// duration is unlimited.
+type DomainDurations struct {
+	Default time.Duration
+	Domains map[string]time.Duration
+}
+
+// For returns the duration that applies to the application domain.
+func (d DomainDurations) For(domain string) time.Duration {
+	if duration, ok := d.Domains[domain]; ok {
+		return duration
+	}
+	return d.Default
+}
+
+// TenantConfig overrides settings for the domains of one tenant. Settings that are not overridden
+// fall back to the server-wide configuration.
+type TenantConfig struct {
+	PasswordMinComplexity *int
+	MFARequired           *bool
+	MFARequiredDomains    []string
+	Issuer                *url.URL
+	OAuthCredentials      map[string]*oauth.Credentials
+}
+
+// TenantFor returns the tenant of an application domain. Domains that were not assigned to a tenant
+// belong to the default tenant, which has an empty name.
+func (c *Config) TenantFor(domain string) string {
+	return c.TenantDomains[domain]
+}
+
+// TenantExists returns true for the default tenant and for any tenant with application domains.
+func (c *Config) TenantExists(name string) bool {
+	if name == "" {
+		return true
+	}
+	for _, tenant := range c.TenantDomains {
+		if tenant == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PasswordMinComplexityFor returns the password policy score of a tenant.
+func (c *Config) PasswordMinComplexityFor(tenant string) int {
+	if t, ok := c.Tenants[tenant]; ok && t.PasswordMinComplexity != nil {
+		return *t.PasswordMinComplexity
+	}
+	return c.PasswordMinComplexity
+}
+
+// MFARequiredFor returns whether every account of a tenant must use MFA.
+func (c *Config) MFARequiredFor(tenant string) bool {
+	if t, ok := c.Tenants[tenant]; ok && t.MFARequired != nil {
+		return *t.MFARequired
+	}
+	return c.MFARequired
+}
+
+// MFARequiredDomainsFor returns the email domains of a tenant for which accounts must use MFA.
+func (c *Config) MFARequiredDomainsFor(tenant string) []string {
+	if t, ok := c.Tenants[tenant]; ok && t.MFARequiredDomains != nil {
+		return t.MFARequiredDomains
+	}
+	return c.MFARequiredDomains
+}
+
+// IssuerFor returns the issuer of identity tokens for a tenant.
+func (c *Config) IssuerFor(tenant string) string {
+	if t, ok := c.Tenants[tenant]; ok && t.Issuer != nil {
+		return t.Issuer.String()
+	}
+	return c.AuthNURL.String()
 }
 
 // OAuthEnabled returns true if any provider is configured.
 func (c *Config) OAuthEnabled() bool {
+	for _, t := range c.Tenants {
+		if len(t.OAuthCredentials) > 0 {
+			return true
+		}
+	}
 	return c.GoogleOauthCredentials != nil ||
 		c.GitHubOauthCredentials != nil ||
 		c.FacebookOauthCredentials != nil ||
@@ -101,6 +226,12 @@ func (c *Config) SameSiteComputed() http.SameSite {
 	return http.SameSiteLaxMode
 }
 
+// DBEncryptionKeyring returns a keyring that encrypts with DBEncryptionKey and can still decrypt
+// with any of the DBEncryptionPreviousKeys.
+func (c *Config) DBEncryptionKeyring() *compat.Keyring {
+	return compat.NewKeyring(c.DBEncryptionKey, c.DBEncryptionPreviousKeys...)
+}
+
 var configurers = []configurer{
 	// The APP_DOMAINS are a list of domains that may refer traffic and be valid JWT audiences. If
 	// the domain includes a port, it must match referred traffic. If the domain does not include a
@@ -116,6 +247,39 @@ var configurers = []configurer{
 		return err
 	},
 
+	// TENANT_DOMAINS assigns APP_DOMAINS to tenants. Each tenant has a separate pool of accounts, so
+	// a username may be registered once per tenant, and sessions may only be used on domains of the
+	// tenant where they were created. Unassigned domains belong to the default tenant.
+	//
+	// example: acme.example.com=acme,admin.acme.example.com=acme,globex.example.com=globex
+	func(c *Config) error {
+		val, ok := os.LookupEnv("TENANT_DOMAINS")
+		if !ok || val == "" {
+			return nil
+		}
+
+		c.TenantDomains = map[string]string{}
+		for _, str := range strings.Split(val, ",") {
+			pieces := strings.SplitN(strings.TrimSpace(str), "=", 2)
+			if len(pieces) != 2 || pieces[1] == "" {
+				return fmt.Errorf("TENANT_DOMAINS: invalid assignment %v", str)
+			}
+			c.TenantDomains[pieces[0]] = pieces[1]
+		}
+		for domain := range c.TenantDomains {
+			found := false
+			for _, d := range c.ApplicationDomains {
+				if d.String() == domain {
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("TENANT_DOMAINS: unknown application domain %v", domain)
+			}
+		}
+		return nil
+	},
+
 	// The AUTHN_URL is used as an issuer for ID tokens, and must be a URL that
 	// the application can resolve in order to fetch our public key for JWT
 	// verification.
@@ -156,12 +320,40 @@ var configurers = []configurer{
 			c.SessionSigningKey = derive([]byte(val), "session-key-salt")
 			c.ResetSigningKey = derive([]byte(val), "password-reset-token-key-salt")
 			c.PasswordlessTokenSigningKey = derive([]byte(val), "passwordless-token-key-salt")
+			c.InvitationTokenSigningKey = derive([]byte(val), "invitation-token-key-salt")
 			c.DBEncryptionKey = derive([]byte(val), "db-encryption-key-salt")[:32]
 			c.OAuthSigningKey = derive([]byte(val), "oauth-key-salt")
+			c.MFAChallengeSigningKey = derive([]byte(val), "mfa-challenge-key-salt")
+			c.TrustedDeviceSigningKey = derive([]byte(val), "trusted-device-key-salt")
+			c.OTPCodeSigningKey = derive([]byte(val), "otp-code-key-salt")
 		}
 		return err
 	},
 
+	// DB_ENCRYPTION_KEY is an optional secret used to derive the key that encrypts data at rest,
+	// instead of deriving it from SECRET_KEY_BASE. This allows the encryption key to be rotated
+	// independently.
+	func(c *Config) error {
+		val, err := requireEnv("DB_ENCRYPTION_KEY")
+		if err == nil {
+			c.DBEncryptionKey = derive([]byte(val), "db-encryption-key-salt")[:32]
+		}
+		return nil
+	},
+
+	// DB_ENCRYPTION_PREVIOUS_KEYS is a comma-delimited list of secrets that were previously used as
+	// DB_ENCRYPTION_KEY (or SECRET_KEY_BASE). Data encrypted with them can still be read until it has
+	// been re-encrypted.
+	func(c *Config) error {
+		val, err := requireEnv("DB_ENCRYPTION_PREVIOUS_KEYS")
+		if err == nil && val != "" {
+			for _, secret := range strings.Split(val, ",") {
+				c.DBEncryptionPreviousKeys = append(c.DBEncryptionPreviousKeys, derive([]byte(secret), "db-encryption-key-salt")[:32])
+			}
+		}
+		return nil
+	},
+
 	// BCRYPT_COST describes how many times a password should be hashed. Costs are
 	// exponential, and may be increased later without waiting for a user to return
 	// and log in.
@@ -225,6 +417,19 @@ var configurers = []configurer{
 		return err
 	},
 
+	// DATABASE_REPLICA_URL is a comma-delimited list of read replicas for DATABASE_URL. Account
+	// lookups will be spread across the replicas, except after a request has written to the
+	// primary.
+	//
+	// Example: mysql://replica1:3306/authn,mysql://replica2:3306/authn
+	func(c *Config) error {
+		val, err := lookupURLs("DATABASE_REPLICA_URL")
+		if err == nil {
+			c.DatabaseReplicaURLs = val
+		}
+		return err
+	},
+
 	// REDIS_URL is a string format that can specify any option for connecting to
 	// a Redis server.
 	//
@@ -280,6 +485,27 @@ var configurers = []configurer{
 		return nil
 	},
 
+	// DATA_OPERATION_TIMEOUT is the number of milliseconds that a single database or Redis
+	// operation may take before it is abandoned. Operations are also abandoned when the client
+	// request that triggered them goes away. Set to 0 to disable.
+	func(c *Config) error {
+		ms, err := lookupInt("DATA_OPERATION_TIMEOUT", 5000)
+		if err == nil {
+			c.DataOperationTimeout = time.Duration(ms) * time.Millisecond
+		}
+		return err
+	},
+
+	// DATA_SLOW_OPERATION_THRESHOLD is the number of milliseconds after which a database or Redis
+	// operation will be logged as slow. Set to 0 to disable.
+	func(c *Config) error {
+		ms, err := lookupInt("DATA_SLOW_OPERATION_THRESHOLD", 500)
+		if err == nil {
+			c.DataSlowOperationThreshold = time.Duration(ms) * time.Millisecond
+		}
+		return err
+	},
+
 	// USERNAME_IS_EMAIL is a truthy string ("t", "true", "yes") that enables the
 	// email validations for username fields. By default, usernames are just
 	// strings.
@@ -335,6 +561,64 @@ var configurers = []configurer{
 		return err
 	},
 
+	// REFRESH_TOKEN_ROTATION determines whether a refresh token is replaced each time it is used
+	// to refresh a session. A replaced token that is presented again indicates that it was stolen,
+	// and the entire session will be revoked.
+	func(c *Config) error {
+		use, err := lookupBool("REFRESH_TOKEN_ROTATION", false)
+		if err == nil {
+			c.RefreshTokenRotation = use
+		}
+		return err
+	},
+
+	// REFRESH_TOKEN_REUSE_GRACE is how many seconds a replaced refresh token may still be presented
+	// without revoking the session. This tolerates clients that race with their own refreshes.
+	func(c *Config) error {
+		grace, err := lookupInt("REFRESH_TOKEN_REUSE_GRACE", 5)
+		if err == nil {
+			c.RefreshTokenReuseGrace = time.Duration(grace) * time.Second
+		}
+		return err
+	},
+
+	// SESSION_MAX_AGE determines how long a session may live after login, no matter how often it is
+	// refreshed. It may be followed by overrides for specific APP_DOMAINS.
+	//
+	// example: 86400,admin.example.com=3600
+	func(c *Config) error {
+		durations, err := LookupDomainDurations("SESSION_MAX_AGE")
+		if err == nil {
+			err = validateDomainDurations("SESSION_MAX_AGE", durations, c.ApplicationDomains)
+			c.SessionMaxAge = durations
+		}
+		return err
+	},
+
+	// SESSION_IDLE_TIMEOUT determines how long a session may go without being refreshed. Unlike
+	// REFRESH_TOKEN_TTL, it may be followed by overrides for specific APP_DOMAINS.
+	//
+	// example: 1800,admin.example.com=600
+	func(c *Config) error {
+		durations, err := LookupDomainDurations("SESSION_IDLE_TIMEOUT")
+		if err == nil {
+			err = validateDomainDurations("SESSION_IDLE_TIMEOUT", durations, c.ApplicationDomains)
+			c.SessionIdleTimeout = durations
+		}
+		return err
+	},
+
+	// IMPERSONATION_MAX_AGE determines how long a session created by POST
+	// /accounts/:id/impersonate may live, no matter how often it is refreshed. It should be short,
+	// since support staff only need enough time to see what the user sees.
+	func(c *Config) error {
+		maxAge, err := lookupInt("IMPERSONATION_MAX_AGE", 3600)
+		if err == nil {
+			c.ImpersonationMaxAge = time.Duration(maxAge) * time.Second
+		}
+		return err
+	},
+
 	// PASSWORD_RESET_TOKEN_TTL determines how long a password reset token (as JWT)
 	// will be valid from when it is generated. These tokens should not live much
 	// longer than it takes for an attentive user to act in a reasonably expedient
@@ -361,6 +645,169 @@ var configurers = []configurer{
 		return err
 	},
 
+	// INVITATION_TOKEN_TTL determines how long an invitation (as JWT) will be valid from when it is
+	// generated. An invitation may only be accepted once, but anyone holding it may accept it until
+	// then.
+	func(c *Config) error {
+		ttl, err := lookupInt("INVITATION_TOKEN_TTL", 604800)
+		if err == nil {
+			c.InvitationTokenTTL = time.Duration(ttl) * time.Second
+		}
+		return err
+	},
+
+	// DEVICE_VERIFICATION_URL enables the device authorization grant (RFC 8628) for devices that
+	// can't easily use a browser, like CLIs and TVs. It is the page of your application where a user
+	// who is logged in may enter the code shown by the device to approve it.
+	func(c *Config) error {
+		val, err := LookupURL("DEVICE_VERIFICATION_URL")
+		if err == nil && val != nil {
+			c.DeviceVerificationURL = val
+		}
+		return err
+	},
+
+	// DEVICE_CODE_TTL determines how long a device has to be approved after it requests a code.
+	func(c *Config) error {
+		ttl, err := lookupInt("DEVICE_CODE_TTL", 600)
+		if err == nil {
+			c.DeviceCodeTTL = time.Duration(ttl) * time.Second
+		}
+		return err
+	},
+
+	// REAUTHENTICATION_MAX_AGE limits sensitive actions, like removing a second factor, to sessions
+	// where the user provided credentials within this many seconds. Older sessions may be refreshed
+	// with POST /session/reauthenticate. A value of 0 (default) disables the requirement.
+	func(c *Config) error {
+		maxAge, err := lookupInt("REAUTHENTICATION_MAX_AGE", 0)
+		if err == nil {
+			c.ReauthenticationMaxAge = time.Duration(maxAge) * time.Second
+		}
+		return err
+	},
+
+	// ENABLE_MFA_CHALLENGE changes how logins for accounts with MFA are completed. When the second
+	// factor is missing, a short-lived challenge is returned so that it may be submitted separately,
+	// rather than requiring the first factor to be submitted again.
+	func(c *Config) error {
+		enabled, err := lookupBool("ENABLE_MFA_CHALLENGE", false)
+		if err == nil {
+			c.EnableMFAChallenge = enabled
+		}
+		return err
+	},
+
+	// MFA_REQUIRED makes MFA mandatory for every account. Accounts that have not set up MFA may only
+	// log in to set it up.
+	func(c *Config) error {
+		required, err := lookupBool("MFA_REQUIRED", false)
+		if err == nil {
+			c.MFARequired = required
+		}
+		return err
+	},
+
+	// MFA_REQUIRED_DOMAINS is a comma-delimited list of domains for which an email username must
+	// use MFA. This allows MFA to be mandatory for some accounts, like those of admins.
+	func(c *Config) error {
+		if val, ok := os.LookupEnv("MFA_REQUIRED_DOMAINS"); ok && val != "" {
+			c.MFARequiredDomains = strings.Split(val, ",")
+		}
+		return nil
+	},
+
+	// MFA_CHALLENGE_TTL determines how long a user has to submit their second factor after their
+	// first factor has been verified.
+	func(c *Config) error {
+		ttl, err := lookupInt("MFA_CHALLENGE_TTL", 300)
+		if err == nil {
+			c.MFAChallengeTTL = time.Duration(ttl) * time.Second
+		}
+		return err
+	},
+
+	// ENABLE_TRUSTED_DEVICES allows a user to skip their second factor on later logins from a
+	// browser where they have already completed it, if they choose to trust that browser.
+	func(c *Config) error {
+		enabled, err := lookupBool("ENABLE_TRUSTED_DEVICES", false)
+		if err == nil {
+			c.EnableTrustedDevices = enabled
+		}
+		return err
+	},
+
+	// TRUSTED_DEVICE_TTL determines how long a browser may skip the second factor before it must be
+	// trusted again.
+	func(c *Config) error {
+		ttl, err := lookupInt("TRUSTED_DEVICE_TTL", 2592000)
+		if err == nil {
+			c.TrustedDeviceTTL = time.Duration(ttl) * time.Second
+		}
+		return err
+	},
+
+	// OTP_CODE_TTL determines how long a one-time code that was delivered by email or SMS may be
+	// used to complete a login.
+	func(c *Config) error {
+		ttl, err := lookupInt("OTP_CODE_TTL", 300)
+		if err == nil {
+			c.OTPCodeTTL = time.Duration(ttl) * time.Second
+		}
+		return err
+	},
+
+	// TOTP_ISSUER is the name that authenticator apps will display for new TOTP secrets. It
+	// defaults to the application domain that requested the secret.
+	func(c *Config) error {
+		c.TOTPIssuer = os.Getenv("TOTP_ISSUER")
+		return nil
+	},
+
+	// TOTP_DIGITS is the length of TOTP codes. It may be 6 (default) or 8.
+	func(c *Config) error {
+		digits, err := lookupInt("TOTP_DIGITS", 6)
+		if err != nil {
+			return err
+		}
+		if digits != 6 && digits != 8 {
+			return fmt.Errorf("unsupported TOTP_DIGITS: %v", digits)
+		}
+		c.TOTPDigits = digits
+		return nil
+	},
+
+	// TOTP_PERIOD is how many seconds each TOTP code is valid for.
+	func(c *Config) error {
+		period, err := lookupInt("TOTP_PERIOD", 30)
+		if err != nil {
+			return err
+		}
+		if period <= 0 {
+			return fmt.Errorf("invalid TOTP_PERIOD: %v", period)
+		}
+		c.TOTPPeriod = time.Duration(period) * time.Second
+		return nil
+	},
+
+	// TOTP_ALGORITHM is the hash algorithm for TOTP codes. It may be SHA1 (default), SHA256, or
+	// SHA512. Many authenticator apps only support SHA1.
+	//
+	// TOTP_DIGITS, TOTP_PERIOD, and TOTP_ALGORITHM apply to every authenticator, so changing them
+	// will break authenticators that have already been set up.
+	func(c *Config) error {
+		c.TOTPAlgorithm = "SHA1"
+		if val, ok := os.LookupEnv("TOTP_ALGORITHM"); ok {
+			switch val {
+			case "SHA1", "SHA256", "SHA512":
+				c.TOTPAlgorithm = val
+			default:
+				return fmt.Errorf("unsupported TOTP_ALGORITHM: %v", val)
+			}
+		}
+		return nil
+	},
+
 	// ACCESS_TOKEN_TTL determines how long an access token (as JWT) will remain
 	// valid. This is a hard limit, to limit the potential damage of an exposed
 	// access token.
@@ -380,6 +827,51 @@ var configurers = []configurer{
 		return err
 	},
 
+	// INTROSPECTION_CACHE_TTL allows responses from POST /introspect to be cached by the client for
+	// this many seconds. A revoked token may be reported as active until the cache expires, so
+	// shorter is better. A value of 0 (default) disables caching.
+	func(c *Config) error {
+		ttl, err := lookupInt("INTROSPECTION_CACHE_TTL", 0)
+		if err == nil {
+			c.IntrospectionCacheTTL = time.Duration(ttl) * time.Second
+		}
+		return err
+	},
+
+	// ENABLE_PERSONAL_ACCESS_TOKENS allows a logged-in user to create long-lived tokens for
+	// non-interactive use, like from a CLI. These tokens may be exchanged for identity tokens through
+	// a private endpoint.
+	func(c *Config) error {
+		enabled, err := lookupBool("ENABLE_PERSONAL_ACCESS_TOKENS", false)
+		if err == nil {
+			c.EnablePersonalAccessTokens = enabled
+		}
+		return err
+	},
+
+	// PERSONAL_ACCESS_TOKEN_SCOPES is a comma-delimited list of scopes that a user may grant to their
+	// personal access tokens. When unset, tokens may not be given any scopes.
+	func(c *Config) error {
+		if val, ok := os.LookupEnv("PERSONAL_ACCESS_TOKEN_SCOPES"); ok && val != "" {
+			for _, scope := range strings.Split(val, ",") {
+				if scope = strings.TrimSpace(scope); scope != "" {
+					c.PersonalAccessTokenScopes = append(c.PersonalAccessTokenScopes, scope)
+				}
+			}
+		}
+		return nil
+	},
+
+	// ENABLE_ORGANIZATIONS allows a logged-in user to list their organizations and choose one to be
+	// described in their identity tokens. Organizations are managed through private endpoints.
+	func(c *Config) error {
+		enabled, err := lookupBool("ENABLE_ORGANIZATIONS", false)
+		if err == nil {
+			c.EnableOrganizations = enabled
+		}
+		return err
+	},
+
 	// HTTP_AUTH_USERNAME and HTTP_AUTH_PASSWORD specify the basic auth credentials
 	// that must be provided to access private endpoints.
 	//
@@ -448,6 +940,111 @@ var configurers = []configurer{
 		return err
 	},
 
+	// APP_INVITATION_URL is an endpoint that will be notified when an invitation has been created.
+	// The endpoint is expected to deliver the given invitation token to the invited username, then
+	// respond with a 2xx HTTP status. When it is not set, invitations are returned to the caller.
+	//
+	// For security, this URL should specify https and include a basic auth username
+	// and password.
+	func(c *Config) error {
+		val, err := LookupURL("APP_INVITATION_URL")
+		if err == nil && val != nil {
+			c.AppInvitationURL = val
+		}
+		return err
+	},
+
+	// APP_PRE_REGISTRATION_URL is an endpoint that will be asked to allow or deny each new account
+	// before it is created. The endpoint is expected to respond within HOOK_TIMEOUT with a 2xx HTTP
+	// status and a JSON decision. Accounts are not created when the endpoint fails.
+	//
+	// For security, this URL should specify https and include a basic auth username
+	// and password.
+	func(c *Config) error {
+		val, err := LookupURL("APP_PRE_REGISTRATION_URL")
+		if err == nil && val != nil {
+			c.AppPreRegistrationURL = val
+		}
+		return err
+	},
+
+	// APP_PRE_LOGIN_URL is an endpoint that will be asked to allow or deny each new session before
+	// it is issued, and may add claims to its identity tokens. The endpoint is expected to respond
+	// within HOOK_TIMEOUT with a 2xx HTTP status and a JSON decision. Sessions are not issued when
+	// the endpoint fails.
+	//
+	// For security, this URL should specify https and include a basic auth username
+	// and password.
+	func(c *Config) error {
+		val, err := LookupURL("APP_PRE_LOGIN_URL")
+		if err == nil && val != nil {
+			c.AppPreLoginURL = val
+		}
+		return err
+	},
+
+	// HOOK_TIMEOUT is the number of milliseconds that AuthN will wait for a decision from
+	// APP_PRE_REGISTRATION_URL or APP_PRE_LOGIN_URL. Users are waiting on the decision, so this
+	// should be short.
+	func(c *Config) error {
+		ms, err := lookupInt("HOOK_TIMEOUT", 2000)
+		if err == nil {
+			c.HookTimeout = time.Duration(ms) * time.Millisecond
+		}
+		return err
+	},
+
+	// BACKCHANNEL_LOGOUT_URIS assigns OpenID Connect back-channel logout endpoints to APP_DOMAINS.
+	// When sessions are ended, each endpoint will be sent a signed logout token so that the app may
+	// forget the sessions immediately rather than waiting for its identity tokens to expire.
+	//
+	// example: app.example.com=https://app.example.com/logout,admin.example.com=https://admin.example.com/logout
+	func(c *Config) error {
+		val, ok := os.LookupEnv("BACKCHANNEL_LOGOUT_URIS")
+		if !ok || val == "" {
+			return nil
+		}
+
+		c.BackchannelLogoutURIs = map[string]*url.URL{}
+		for _, str := range strings.Split(val, ",") {
+			pieces := strings.SplitN(strings.TrimSpace(str), "=", 2)
+			if len(pieces) != 2 {
+				return fmt.Errorf("BACKCHANNEL_LOGOUT_URIS: invalid assignment %v", str)
+			}
+			uri, err := parseURL(pieces[1])
+			if err != nil {
+				return fmt.Errorf("BACKCHANNEL_LOGOUT_URIS: %v", err)
+			}
+			c.BackchannelLogoutURIs[pieces[0]] = uri
+		}
+		for domain := range c.BackchannelLogoutURIs {
+			found := false
+			for _, d := range c.ApplicationDomains {
+				if d.String() == domain {
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("BACKCHANNEL_LOGOUT_URIS: unknown application domain %v", domain)
+			}
+		}
+		return nil
+	},
+
+	// APP_OTP_DELIVERY_URL is an endpoint that will be notified when a one-time code must be
+	// delivered to a user as a second factor. The endpoint is expected to deliver the given code
+	// by email or SMS, according to the channel, then respond with a 2xx HTTP status.
+	//
+	// For security, this URL should specify https and include a basic auth username
+	// and password.
+	func(c *Config) error {
+		val, err := LookupURL("APP_OTP_DELIVERY_URL")
+		if err == nil && val != nil {
+			c.AppOTPDeliveryURL = val
+		}
+		return err
+	},
+
 	// RSA_PRIVATE_KEY is a RSA private key in PEM format. If provided as a single
 	// line string, any literal \n sequences will be converted to real linebreaks.
 	// When provided, it will be used for signing identity tokens, and the public
@@ -457,15 +1054,68 @@ var configurers = []configurer{
 	func(c *Config) error {
 		if str, ok := os.LookupEnv("RSA_PRIVATE_KEY"); ok {
 			str = strings.Replace(str, `\n`, "\n", -1)
-			block, _ := pem.Decode([]byte(str))
-			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			key, err := private.ParseKey([]byte(str))
 			if err != nil {
 				return err
 			}
-			c.IdentitySigningKey, err = private.NewKey(key)
+			c.IdentitySigningKey = key
+		}
+		return nil
+	},
+
+	// IDENTITY_SIGNING_KEY is a RSA, ECDSA (P-256), or Ed25519 private key in PEM format. It works
+	// like RSA_PRIVATE_KEY, but the signing algorithm will match the type of key.
+	//
+	// It may also be a list of PEM keys. The first key will be used for signing, and the others will
+	// only be published.
+	func(c *Config) error {
+		if str, ok := os.LookupEnv("IDENTITY_SIGNING_KEY"); ok {
+			str = strings.Replace(str, `\n`, "\n", -1)
+			keys, err := private.ParseKeys([]byte(str))
 			if err != nil {
 				return err
 			}
+			if len(keys) == 0 {
+				return fmt.Errorf("no keys found in IDENTITY_SIGNING_KEY")
+			}
+			c.IdentitySigningKey = keys[0]
+			c.IdentityPublishedKeys = keys[1:]
+		}
+		return nil
+	},
+
+	// IDENTITY_SIGNING_KEYS_DIR is a directory of PEM keys that are managed externally. All of them
+	// will be published, and the one named in the directory's "active" file will be used for
+	// signing. The directory is watched for changes.
+	func(c *Config) error {
+		if val, ok := os.LookupEnv("IDENTITY_SIGNING_KEYS_DIR"); ok {
+			info, err := os.Stat(val)
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return fmt.Errorf("IDENTITY_SIGNING_KEYS_DIR is not a directory: %v", val)
+			}
+			c.IdentitySigningKeysDir = val
+		}
+		return nil
+	},
+
+	// IDENTITY_SIGNING_ALGORITHM determines the type of keys that AuthN will generate to sign
+	// identity tokens. It may be RS256 (default), ES256, or EdDSA. When a static key is provided,
+	// the algorithm is determined by that key instead.
+	func(c *Config) error {
+		c.IdentitySigningAlgorithm = private.RS256
+		if val, ok := os.LookupEnv("IDENTITY_SIGNING_ALGORITHM"); ok {
+			switch val {
+			case private.RS256, private.ES256, private.EdDSA:
+				c.IdentitySigningAlgorithm = val
+			default:
+				return fmt.Errorf("unsupported IDENTITY_SIGNING_ALGORITHM: %v", val)
+			}
+		}
+		if c.IdentitySigningKey != nil {
+			c.IdentitySigningAlgorithm = string(c.IdentitySigningKey.Algorithm())
 		}
 		return nil
 	},
@@ -673,6 +1323,30 @@ var configurers = []configurer{
 		return nil
 	},
 
+	// Each tenant of TENANT_DOMAINS may override the password policy, the MFA requirement, the issuer
+	// of its identity tokens, and OAuth credentials with settings that are prefixed by the tenant's
+	// upper-cased name. The issuer must be a URL for the same AuthN server, with the same path.
+	//
+	// example: TENANT_ACME_PASSWORD_POLICY_SCORE=3
+	// example: TENANT_ACME_AUTHN_URL=https://auth.acme.example.com
+	// example: TENANT_ACME_GOOGLE_OAUTH_CREDENTIALS=id:secret
+	func(c *Config) error {
+		for _, name := range c.TenantDomains {
+			if _, ok := c.Tenants[name]; ok {
+				continue
+			}
+			tenant, err := lookupTenantConfig(c, name)
+			if err != nil {
+				return err
+			}
+			if c.Tenants == nil {
+				c.Tenants = map[string]*TenantConfig{}
+			}
+			c.Tenants[name] = tenant
+		}
+		return nil
+	},
+
 	// APP_SIGNING_KEY is a hex encoded key used to sign notifications sent to client app using sha256-HMAC
 	func(c *Config) error {
 		if val, ok := os.LookupEnv("APP_SIGNING_KEY"); ok {
@@ -692,7 +1366,78 @@ func ReadEnv() (*Config, error) {
 	return configure(configurers)
 }
 
+// oauthProviderNames lists the providers that may be configured with OAuth credentials.
+var oauthProviderNames = []string{"google", "github", "facebook", "discord", "microsoft", "apple"}
+
+var nonAlphanumeric = regexp.MustCompile("[^A-Za-z0-9]+")
+
+// lookupTenantConfig reads the settings that a tenant overrides, from variables prefixed by its name.
+func lookupTenantConfig(c *Config, name string) (*TenantConfig, error) {
+	prefix := "TENANT_" + strings.ToUpper(nonAlphanumeric.ReplaceAllString(name, "_")) + "_"
+	tenant := &TenantConfig{OAuthCredentials: map[string]*oauth.Credentials{}}
+
+	if _, ok := os.LookupEnv(prefix + "PASSWORD_POLICY_SCORE"); ok {
+		score, err := lookupInt(prefix+"PASSWORD_POLICY_SCORE", 0)
+		if err != nil {
+			return nil, err
+		}
+		tenant.PasswordMinComplexity = &score
+	}
+
+	if _, ok := os.LookupEnv(prefix + "MFA_REQUIRED"); ok {
+		required, err := lookupBool(prefix+"MFA_REQUIRED", false)
+		if err != nil {
+			return nil, err
+		}
+		tenant.MFARequired = &required
+	}
+
+	if val, ok := os.LookupEnv(prefix + "MFA_REQUIRED_DOMAINS"); ok {
+		tenant.MFARequiredDomains = []string{}
+		if val != "" {
+			tenant.MFARequiredDomains = strings.Split(val, ",")
+		}
+	}
+
+	issuer, err := LookupURL(prefix + "AUTHN_URL")
+	if err != nil {
+		return nil, err
+	}
+	if issuer != nil && strings.TrimSuffix(issuer.Path, "/") != strings.TrimSuffix(c.AuthNURL.Path, "/") {
+		return nil, fmt.Errorf("%vAUTHN_URL: path must match AUTHN_URL", prefix)
+	}
+	tenant.Issuer = issuer
+
+	for _, provider := range oauthProviderNames {
+		if val, ok := os.LookupEnv(prefix + strings.ToUpper(provider) + "_OAUTH_CREDENTIALS"); ok {
+			credentials, err := oauth.NewCredentials(val)
+			if err != nil {
+				return nil, err
+			}
+			tenant.OAuthCredentials[provider] = credentials
+		}
+	}
+
+	return tenant, nil
+}
+
 // 20k iterations of PBKDF2 HMAC SHA-256
 func derive(base []byte, salt string) []byte {
 	return pbkdf2.Key(base, []byte(salt), 2e4, 128, sha256.New)
 }
+
+// validateDomainDurations ensures that overrides refer to configured application domains.
+func validateDomainDurations(name string, durations DomainDurations, domains []route.Domain) error {
+	for domain := range durations.Domains {
+		found := false
+		for _, d := range domains {
+			if d.String() == domain {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%v: unknown application domain %v", name, domain)
+		}
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package data
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/keratin/authn-server/app/data/postgres"
 
@@ -12,33 +14,48 @@ import (
 )
 
 type AccountStore interface {
-	Create(u string, p []byte) (*models.Account, error)
-	Find(id int) (*models.Account, error)
-	FindByUsername(u string) (*models.Account, error)
-	FindByOauthAccount(p string, pid string) (*models.Account, error)
-	AddOauthAccount(id int, p string, pid string, email string, tok string) error
-	UpdateOauthAccount(id int, p string, email string) (bool, error)
-	DeleteOauthAccount(id int, p string) (bool, error)
-	GetOauthAccounts(id int) ([]*models.OauthAccount, error)
-	Archive(id int) (bool, error)
-	Lock(id int) (bool, error)
-	Unlock(id int) (bool, error)
-	RequireNewPassword(id int) (bool, error)
-	SetPassword(id int, p []byte) (bool, error)
-	UpdateUsername(id int, u string) (bool, error)
-	SetLastLogin(id int) (bool, error)
-	SetTOTPSecret(id int, secret []byte) (bool, error)
-	DeleteTOTPSecret(id int) (bool, error)
+	Create(ctx context.Context, u string, p []byte) (*models.Account, error)
+	Find(ctx context.Context, id int) (*models.Account, error)
+	FindByUsername(ctx context.Context, u string) (*models.Account, error)
+	FindByOauthAccount(ctx context.Context, p string, pid string) (*models.Account, error)
+	AddOauthAccount(ctx context.Context, id int, p string, pid string, email string, tok string) error
+	UpdateOauthAccount(ctx context.Context, id int, p string, email string) (bool, error)
+	DeleteOauthAccount(ctx context.Context, id int, p string) (bool, error)
+	GetOauthAccounts(ctx context.Context, id int) ([]*models.OauthAccount, error)
+	Archive(ctx context.Context, id int) (bool, error)
+	Lock(ctx context.Context, id int) (bool, error)
+	Unlock(ctx context.Context, id int) (bool, error)
+	RequireNewPassword(ctx context.Context, id int) (bool, error)
+	SetPassword(ctx context.Context, id int, p []byte) (bool, error)
+	UpdateUsername(ctx context.Context, id int, u string) (bool, error)
+	SetLastLogin(ctx context.Context, id int) (bool, error)
+	// AddTOTPAuthenticator adds a named authenticator. The step of the code that confirmed it is
+	// recorded as already used.
+	AddTOTPAuthenticator(ctx context.Context, id int, name string, secret []byte, step int64) (*models.TOTPAuthenticator, error)
+	GetTOTPAuthenticators(ctx context.Context, id int) ([]*models.TOTPAuthenticator, error)
+	// SetTOTPAuthenticatorLastUsed records a code for the given time step. It returns false when a
+	// code for the same or a later time step has already been accepted, so that codes are not replayed.
+	SetTOTPAuthenticatorLastUsed(ctx context.Context, id int, authenticatorID int, step int64) (bool, error)
+	DeleteTOTPAuthenticator(ctx context.Context, id int, authenticatorID int) (bool, error)
+	DeleteTOTPAuthenticators(ctx context.Context, id int) (bool, error)
+	SetRequireMFA(ctx context.Context, id int, required bool) (bool, error)
+	SetOTPChannel(ctx context.Context, id int, channel string) (bool, error)
+	AddPersonalAccessToken(ctx context.Context, id int, name string, tokenHash string, scopes models.NameList, audience string, expiresAt *time.Time) (*models.PersonalAccessToken, error)
+	GetPersonalAccessTokens(ctx context.Context, id int) ([]*models.PersonalAccessToken, error)
+	// FindPersonalAccessToken returns nil when no token has the hash. Expired tokens are returned.
+	FindPersonalAccessToken(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+	SetPersonalAccessTokenLastUsed(ctx context.Context, tokenID int) (bool, error)
+	DeletePersonalAccessToken(ctx context.Context, id int, tokenID int) (bool, error)
 }
 
-func NewAccountStore(db sqlx.Ext) (AccountStore, error) {
+func NewAccountStore(db *sqlx.DB) (AccountStore, error) {
 	switch db.DriverName() {
 	case "sqlite3":
-		return &sqlite3.AccountStore{Ext: db}, nil
+		return &sqlite3.AccountStore{ExtContext: db}, nil
 	case "mysql":
-		return &mysql.AccountStore{Ext: db}, nil
+		return &mysql.AccountStore{ExtContext: db}, nil
 	case "postgres":
-		return &postgres.AccountStore{Ext: db}, nil
+		return &postgres.AccountStore{ExtContext: db}, nil
 	default:
 		return nil, fmt.Errorf("unsupported driver: %v", db.DriverName())
 	}
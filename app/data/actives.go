@@ -1,8 +1,10 @@
 package data
 
+import "context"
+
 type Actives interface {
-	Track(int) error
-	ActivesByDay() (map[string]int, error)
-	ActivesByWeek() (map[string]int, error)
-	ActivesByMonth() (map[string]int, error)
+	Track(ctx context.Context, accountID int) error
+	ActivesByDay(ctx context.Context) (map[string]int, error)
+	ActivesByWeek(ctx context.Context) (map[string]int, error)
+	ActivesByMonth(ctx context.Context) (map[string]int, error)
 }
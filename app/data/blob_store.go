@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -13,21 +14,33 @@ import (
 
 type BlobStore interface {
 	// Read fetches a blob from the store.
-	Read(name string) ([]byte, error)
+	Read(ctx context.Context, name string) ([]byte, error)
 
 	// WriteNX will write the blob into the store only if the name does not exist.
-	WriteNX(name string, blob []byte) (bool, error)
+	WriteNX(ctx context.Context, name string, blob []byte) (bool, error)
 
 	// Write will write the blob into the store
-	Write(name string, blob []byte) (bool, error)
+	Write(ctx context.Context, name string, blob []byte) (bool, error)
+
+	// Rewrite will replace an existing blob without changing when it expires
+	Rewrite(ctx context.Context, name string, blob []byte) (bool, error)
 
 	// Delete will remove the blob from the store
-	Delete(name string) error
+	Delete(ctx context.Context, name string) error
+
+	// Names lists the blobs whose names begin with the prefix
+	Names(ctx context.Context, prefix string) ([]string, error)
+}
+
+// blobTTL is the lifetime of a key. The next key is generated up to one interval before it is used
+// for signing, and then remains published for verification for one interval after, so a key must
+// last slightly more than three intervals.
+func blobTTL(interval time.Duration) time.Duration {
+	return interval*3 + 10*time.Second
 }
 
 func NewBlobStore(interval time.Duration, redis *redis.Client, db *sqlx.DB, reporter ops.ErrorReporter) (BlobStore, error) {
-	// the lifetime of a key should be slightly more than two intervals
-	ttl := interval*2 + 10*time.Second
+	ttl := blobTTL(interval)
 
 	// the write lock should be greater than the peak time necessary to generate and encrypt a key,
 	// plus send it back over the wire to redis. after this time has elapsed, any other authn server
@@ -0,0 +1,37 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+)
+
+// ClientAssertionCache remembers the IDs (jti) of client assertions that have been used, so that
+// an assertion can not be replayed. An ID is remembered for longer than an assertion may live.
+type ClientAssertionCache interface {
+	// UseClientAssertion records the assertion ID for the client, and returns false if it was
+	// already used.
+	UseClientAssertion(ctx context.Context, clientID string, jti string) (bool, error)
+}
+
+type clientAssertionCache struct {
+	ebs *EncryptedBlobStore
+}
+
+func NewClientAssertionCache(ebs *EncryptedBlobStore) ClientAssertionCache {
+	return &clientAssertionCache{
+		ebs: ebs,
+	}
+}
+
+func (c *clientAssertionCache) UseClientAssertion(ctx context.Context, clientID string, jti string) (bool, error) {
+	// the jti is chosen by the client, so it is hashed to keep blob names predictable in size
+	hash := sha256.Sum256([]byte(clientID + ":" + jti))
+	ok, err := c.ebs.WriteNX(ctx, "client_jti:"+hex.EncodeToString(hash[:]), []byte{})
+	if err != nil {
+		return false, errors.Wrap(err, "UseClientAssertion")
+	}
+	return ok, nil
+}
@@ -0,0 +1,42 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/data/mysql"
+	"github.com/keratin/authn-server/app/data/postgres"
+	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/keratin/authn-server/app/models"
+)
+
+type ClientStore interface {
+	// Persists a new client. The ID must already be generated.
+	Create(ctx context.Context, client *models.Client) error
+
+	// Finds the client. A nil value indicates that no client was found.
+	Find(ctx context.Context, id string) (*models.Client, error)
+
+	// Returns every client, oldest first.
+	FindAll(ctx context.Context) ([]*models.Client, error)
+
+	// Replaces the name, scopes, and audiences of the client.
+	Update(ctx context.Context, id string, name string, scopes models.NameList, audiences models.NameList) (bool, error)
+
+	// Deletes the client, so that it may no longer authenticate.
+	Delete(ctx context.Context, id string) (bool, error)
+}
+
+func NewClientStore(db *sqlx.DB) (ClientStore, error) {
+	switch db.DriverName() {
+	case "sqlite3":
+		return &sqlite3.ClientStore{ExtContext: db}, nil
+	case "mysql":
+		return &mysql.ClientStore{ExtContext: db}, nil
+	case "postgres":
+		return &postgres.ClientStore{ExtContext: db}, nil
+	default:
+		return nil, fmt.Errorf("unsupported driver: %v", db.DriverName())
+	}
+}
@@ -0,0 +1,181 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registerEncryptedBlobPrefix("device:")
+	registerEncryptedBlobPrefix("device_user:")
+	registerEncryptedBlobPrefix("device_approval:")
+}
+
+// DeviceCodeCache keeps device authorizations until they are exchanged or expire. They are
+// found by device code when the device polls, and by user code when a user approves the device.
+type DeviceCodeCache interface {
+	CacheDeviceAuthorization(ctx context.Context, deviceCode string, authorization *models.DeviceAuthorization) error
+	LoadDeviceAuthorization(ctx context.Context, deviceCode string) (*models.DeviceAuthorization, error)
+	FindDeviceCode(ctx context.Context, userCode string) (string, error)
+	RemoveDeviceAuthorization(ctx context.Context, deviceCode string) error
+
+	// ApproveDeviceAuthorization records the account, audience, and authentication methods of the
+	// authorization, and returns false if it was already approved. The approval is kept apart from
+	// the authorization, so that a device that is polling can not overwrite it.
+	ApproveDeviceAuthorization(ctx context.Context, deviceCode string, authorization *models.DeviceAuthorization) (bool, error)
+
+	// ClaimDeviceAuthorization removes the authorization, and returns false if it was already
+	// claimed.
+	ClaimDeviceAuthorization(ctx context.Context, deviceCode string) (bool, error)
+}
+
+// deviceApproval is the part of a device authorization that is written once, by the approver.
+type deviceApproval struct {
+	AccountID           int      `json:"account_id"`
+	Audience            string   `json:"audience"`
+	AuthMethodReference []string `json:"amr"`
+}
+
+type deviceCodeCache struct {
+	ebs *EncryptedBlobStore
+}
+
+func NewDeviceCodeCache(ebs *EncryptedBlobStore) DeviceCodeCache {
+	return &deviceCodeCache{
+		ebs: ebs,
+	}
+}
+
+// device codes are bearer secrets, so they are hashed before being used as blob names.
+func deviceCodeName(deviceCode string) string {
+	return "device:" + hashDeviceCode(deviceCode)
+}
+
+func deviceApprovalName(deviceCode string) string {
+	return "device_approval:" + hashDeviceCode(deviceCode)
+}
+
+func deviceClaimName(deviceCode string) string {
+	return "device_claim:" + hashDeviceCode(deviceCode)
+}
+
+func hashDeviceCode(deviceCode string) string {
+	hash := sha256.Sum256([]byte(deviceCode))
+	return hex.EncodeToString(hash[:])
+}
+
+func userCodeName(userCode string) string {
+	return "device_user:" + userCode
+}
+
+func (c *deviceCodeCache) CacheDeviceAuthorization(ctx context.Context, deviceCode string, authorization *models.DeviceAuthorization) error {
+	blob, err := json.Marshal(authorization)
+	if err != nil {
+		return errors.Wrap(err, "Marshal")
+	}
+	_, err = c.ebs.Write(ctx, deviceCodeName(deviceCode), blob)
+	if err != nil {
+		return errors.Wrap(err, "CacheDeviceAuthorization")
+	}
+	_, err = c.ebs.Write(ctx, userCodeName(authorization.UserCode), []byte(deviceCode))
+	if err != nil {
+		return errors.Wrap(err, "CacheDeviceAuthorization")
+	}
+	return nil
+}
+
+func (c *deviceCodeCache) LoadDeviceAuthorization(ctx context.Context, deviceCode string) (*models.DeviceAuthorization, error) {
+	blob, err := c.ebs.Read(ctx, deviceCodeName(deviceCode))
+	if err != nil {
+		return nil, errors.Wrap(err, "LoadDeviceAuthorization")
+	}
+	if blob == nil {
+		return nil, nil
+	}
+
+	authorization := models.DeviceAuthorization{}
+	err = json.Unmarshal(blob, &authorization)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unmarshal")
+	}
+
+	blob, err = c.ebs.Read(ctx, deviceApprovalName(deviceCode))
+	if err != nil {
+		return nil, errors.Wrap(err, "LoadDeviceAuthorization")
+	}
+	if blob != nil {
+		approval := deviceApproval{}
+		err = json.Unmarshal(blob, &approval)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unmarshal")
+		}
+		authorization.AccountID = approval.AccountID
+		authorization.Audience = approval.Audience
+		authorization.AuthMethodReference = approval.AuthMethodReference
+	}
+	return &authorization, nil
+}
+
+func (c *deviceCodeCache) FindDeviceCode(ctx context.Context, userCode string) (string, error) {
+	blob, err := c.ebs.Read(ctx, userCodeName(userCode))
+	if err != nil {
+		return "", errors.Wrap(err, "FindDeviceCode")
+	}
+	return string(blob), nil
+}
+
+func (c *deviceCodeCache) RemoveDeviceAuthorization(ctx context.Context, deviceCode string) error {
+	authorization, err := c.LoadDeviceAuthorization(ctx, deviceCode)
+	if err != nil {
+		return err
+	}
+	if authorization == nil {
+		return nil
+	}
+
+	err = c.ebs.Delete(ctx, userCodeName(authorization.UserCode))
+	if err != nil {
+		return errors.Wrap(err, "RemoveDeviceAuthorization")
+	}
+	err = c.ebs.Delete(ctx, deviceApprovalName(deviceCode))
+	if err != nil {
+		return errors.Wrap(err, "RemoveDeviceAuthorization")
+	}
+	return c.ebs.Delete(ctx, deviceCodeName(deviceCode))
+}
+
+func (c *deviceCodeCache) ApproveDeviceAuthorization(ctx context.Context, deviceCode string, authorization *models.DeviceAuthorization) (bool, error) {
+	blob, err := json.Marshal(deviceApproval{
+		AccountID:           authorization.AccountID,
+		Audience:            authorization.Audience,
+		AuthMethodReference: authorization.AuthMethodReference,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "Marshal")
+	}
+	ok, err := c.ebs.WriteNX(ctx, deviceApprovalName(deviceCode), blob)
+	if err != nil {
+		return false, errors.Wrap(err, "ApproveDeviceAuthorization")
+	}
+	return ok, nil
+}
+
+func (c *deviceCodeCache) ClaimDeviceAuthorization(ctx context.Context, deviceCode string) (bool, error) {
+	ok, err := c.ebs.WriteNX(ctx, deviceClaimName(deviceCode), []byte{})
+	if err != nil {
+		return false, errors.Wrap(err, "ClaimDeviceAuthorization")
+	}
+	if !ok {
+		return false, nil
+	}
+	err = c.RemoveDeviceAuthorization(ctx, deviceCode)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
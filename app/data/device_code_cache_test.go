@@ -0,0 +1,83 @@
+package data_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceCodeCache(t *testing.T) {
+	ebs := data.NewEncryptedBlobStore(mock.NewBlobStore(time.Minute, time.Second), compat.NewKeyring([]byte("secretsecretsecretsecretsecret12")))
+	codes := data.NewDeviceCodeCache(ebs)
+	authorization := &models.DeviceAuthorization{UserCode: "AAAA-AAAA", ExpiresAt: time.Now().Add(time.Minute)}
+	require.NoError(t, codes.CacheDeviceAuthorization(context.Background(), "device-code", authorization))
+
+	// counts how many of concurrent calls succeed
+	concurrently := func(fn func(i int) bool) int {
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		succeeded := 0
+		for i := 1; i <= 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if fn(i) {
+					mutex.Lock()
+					succeeded++
+					mutex.Unlock()
+				}
+			}(i)
+		}
+		wg.Wait()
+		return succeeded
+	}
+
+	t.Run("concurrent approvals", func(t *testing.T) {
+		approved := concurrently(func(i int) bool {
+			ok, err := codes.ApproveDeviceAuthorization(context.Background(), "device-code", &models.DeviceAuthorization{
+				AccountID: i, Audience: "example.com", AuthMethodReference: []string{"pwd"},
+			})
+			assert.NoError(t, err)
+			return ok
+		})
+		assert.Equal(t, 1, approved)
+
+		found, err := codes.LoadDeviceAuthorization(context.Background(), "device-code")
+		require.NoError(t, err)
+		assert.True(t, found.Approved())
+		assert.Equal(t, "example.com", found.Audience)
+	})
+
+	t.Run("polling after approval", func(t *testing.T) {
+		authorization.PolledAt = time.Now()
+		require.NoError(t, codes.CacheDeviceAuthorization(context.Background(), "device-code", authorization))
+
+		found, err := codes.LoadDeviceAuthorization(context.Background(), "device-code")
+		require.NoError(t, err)
+		assert.True(t, found.Approved())
+	})
+
+	t.Run("concurrent claims", func(t *testing.T) {
+		claimed := concurrently(func(i int) bool {
+			ok, err := codes.ClaimDeviceAuthorization(context.Background(), "device-code")
+			assert.NoError(t, err)
+			return ok
+		})
+		assert.Equal(t, 1, claimed)
+
+		found, err := codes.LoadDeviceAuthorization(context.Background(), "device-code")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+		deviceCode, err := codes.FindDeviceCode(context.Background(), "AAAA-AAAA")
+		require.NoError(t, err)
+		assert.Empty(t, deviceCode)
+	})
+}
@@ -1,44 +1,96 @@
 package data
 
-import "github.com/keratin/authn-server/lib/compat"
+import (
+	"context"
+
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/pkg/errors"
+)
+
+// encryptedBlobPrefixes are the namespaces of blobs written through an EncryptedBlobStore. Other
+// keys may share the underlying store, so re-encryption is limited to these.
+var encryptedBlobPrefixes []string
+
+// registerEncryptedBlobPrefix should be called next to any code that writes a new namespace of
+// blobs through an EncryptedBlobStore, so that Reencrypt will find them.
+func registerEncryptedBlobPrefix(prefix string) {
+	encryptedBlobPrefixes = append(encryptedBlobPrefixes, prefix)
+}
 
 type EncryptedBlobStore struct {
-	store         BlobStore
-	encryptionKey []byte
+	store   BlobStore
+	keyring *compat.Keyring
 }
 
-func NewEncryptedBlobStore(store BlobStore, encryptionKey []byte) *EncryptedBlobStore {
+func NewEncryptedBlobStore(store BlobStore, keyring *compat.Keyring) *EncryptedBlobStore {
 	return &EncryptedBlobStore{
-		store:         store,
-		encryptionKey: encryptionKey,
+		store:   store,
+		keyring: keyring,
 	}
 }
 
-func (bs *EncryptedBlobStore) Read(name string) ([]byte, error) {
-	encryptedBlob, err := bs.store.Read(name)
+func (bs *EncryptedBlobStore) Read(ctx context.Context, name string) ([]byte, error) {
+	encryptedBlob, err := bs.store.Read(ctx, name)
 	if err != nil || encryptedBlob == nil {
 		return encryptedBlob, err
 	}
-	val, err := compat.Decrypt(encryptedBlob, bs.encryptionKey)
+	val, err := bs.keyring.Decrypt(encryptedBlob)
 	return []byte(val), err
 }
 
-func (bs *EncryptedBlobStore) WriteNX(name string, blob []byte) (bool, error) {
-	encryptedBlob, err := compat.Encrypt(blob, bs.encryptionKey)
+func (bs *EncryptedBlobStore) WriteNX(ctx context.Context, name string, blob []byte) (bool, error) {
+	encryptedBlob, err := bs.keyring.Encrypt(blob)
 	if err != nil {
 		return false, err
 	}
-	return bs.store.WriteNX(name, encryptedBlob)
+	return bs.store.WriteNX(ctx, name, encryptedBlob)
 }
 
-func (bs *EncryptedBlobStore) Write(name string, blob []byte) (bool, error) {
-	encryptedBlob, err := compat.Encrypt(blob, bs.encryptionKey)
+func (bs *EncryptedBlobStore) Write(ctx context.Context, name string, blob []byte) (bool, error) {
+	encryptedBlob, err := bs.keyring.Encrypt(blob)
 	if err != nil {
 		return false, err
 	}
-	return bs.store.Write(name, encryptedBlob)
+	return bs.store.Write(ctx, name, encryptedBlob)
 }
 
-func (bs *EncryptedBlobStore) Delete(name string) error {
-	return bs.store.Delete(name)
+func (bs *EncryptedBlobStore) Delete(ctx context.Context, name string) error {
+	return bs.store.Delete(ctx, name)
+}
+
+// Reencrypt rewrites every blob that is not yet encrypted with the current key, and returns the
+// number of blobs that were rewritten. Rewritten blobs keep their remaining lifetime.
+func (bs *EncryptedBlobStore) Reencrypt(ctx context.Context) (int, error) {
+	count := 0
+	for _, prefix := range encryptedBlobPrefixes {
+		names, err := bs.store.Names(ctx, prefix)
+		if err != nil {
+			return count, errors.Wrap(err, "Names")
+		}
+		for _, name := range names {
+			encryptedBlob, err := bs.store.Read(ctx, name)
+			if err != nil {
+				return count, errors.Wrap(err, "Read")
+			}
+			if encryptedBlob == nil || bs.keyring.IsCurrent(encryptedBlob) {
+				continue
+			}
+			val, err := bs.keyring.Decrypt(encryptedBlob)
+			if err != nil {
+				return count, errors.Wrapf(err, "Decrypt %v", name)
+			}
+			reencryptedBlob, err := bs.keyring.Encrypt([]byte(val))
+			if err != nil {
+				return count, errors.Wrapf(err, "Encrypt %v", name)
+			}
+			ok, err := bs.store.Rewrite(ctx, name, reencryptedBlob)
+			if err != nil {
+				return count, errors.Wrap(err, "Rewrite")
+			}
+			if ok {
+				count++
+			}
+		}
+	}
+	return count, nil
 }
@@ -1,29 +1,65 @@
 package data_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/lib/compat"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEncryptedBlobStore(t *testing.T) {
 	bs := mock.NewBlobStore(time.Second, time.Second)
-	ebs := data.NewEncryptedBlobStore(bs, []byte("secretsecretsecretsecretsecret12"))
+	ebs := data.NewEncryptedBlobStore(bs, compat.NewKeyring([]byte("secretsecretsecretsecretsecret12")))
 	val := []byte("val")
 
-	ok, err := ebs.WriteNX("key", val)
+	ok, err := ebs.WriteNX(context.Background(), "key", val)
 	assert.NoError(t, err)
 	assert.True(t, ok)
 
-	blob, err := bs.Read("key")
+	blob, err := bs.Read(context.Background(), "key")
 	assert.NoError(t, err)
 	assert.NotEmpty(t, blob)
 	assert.NotEqual(t, val, blob)
 
-	blob, err = ebs.Read("key")
+	blob, err = ebs.Read(context.Background(), "key")
 	assert.NoError(t, err)
 	assert.Equal(t, val, blob)
 }
+
+func TestEncryptedBlobStoreReencrypt(t *testing.T) {
+	oldKey := []byte("secretsecretsecretsecretsecret12")
+	newKey := []byte("secretsecretsecretsecretsecret34")
+	bs := mock.NewBlobStore(time.Second, time.Second)
+
+	_, err := data.NewEncryptedBlobStore(bs, compat.NewKeyring(oldKey)).Write(context.Background(), "totp:1", []byte("val"))
+	require.NoError(t, err)
+	_, err = data.NewEncryptedBlobStore(bs, compat.NewKeyring(oldKey)).Write(context.Background(), "otp:1", []byte("val"))
+	require.NoError(t, err)
+	_, err = data.NewEncryptedBlobStore(bs, compat.NewKeyring(oldKey)).Write(context.Background(), "device_user:BCDF-GHJK", []byte("val"))
+	require.NoError(t, err)
+	_, err = bs.Write(context.Background(), "other", []byte("plaintext"))
+	require.NoError(t, err)
+
+	keyring := compat.NewKeyring(newKey, oldKey)
+	ebs := data.NewEncryptedBlobStore(bs, keyring)
+	count, err := ebs.Reencrypt(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	blob, err := bs.Read(context.Background(), "totp:1")
+	require.NoError(t, err)
+	assert.True(t, keyring.IsCurrent(blob))
+
+	blob, err = data.NewEncryptedBlobStore(bs, compat.NewKeyring(newKey)).Read(context.Background(), "totp:1")
+	require.NoError(t, err)
+	assert.Equal(t, "val", string(blob))
+
+	count, err = ebs.Reencrypt(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,552 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var dataTimings = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "data_operation_times",
+		Help:    "The duration of data store operations, partitioned by operation",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 10, 6),
+	},
+	[]string{"operation"},
+)
+
+func init() {
+	prometheus.MustRegister(dataTimings)
+}
+
+// Instrumentation bounds and measures every call into a data store. Each operation runs with a
+// deadline derived from the caller's context, so that a cancelled request or a database brownout
+// releases its goroutine instead of waiting on the driver. Operations slower than SlowThreshold are
+// logged.
+type Instrumentation struct {
+	Timeout       time.Duration
+	SlowThreshold time.Duration
+	Logger        logrus.FieldLogger
+}
+
+// NewInstrumentation creates an Instrumentation. A zero timeout or threshold disables that feature.
+func NewInstrumentation(timeout time.Duration, slowThreshold time.Duration, logger logrus.FieldLogger) *Instrumentation {
+	return &Instrumentation{
+		Timeout:       timeout,
+		SlowThreshold: slowThreshold,
+		Logger:        logger.WithField("scope", "data"),
+	}
+}
+
+// start returns a context bounded by the configured timeout and a function that must be deferred
+// to release the context and record the operation.
+func (i *Instrumentation) start(ctx context.Context, operation string) (context.Context, func()) {
+	began := time.Now()
+	cancel := func() {}
+	if i.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, i.Timeout)
+	}
+
+	return ctx, func() {
+		cancel()
+		elapsed := time.Since(began)
+		dataTimings.WithLabelValues(operation).Observe(elapsed.Seconds())
+		if i.SlowThreshold > 0 && elapsed > i.SlowThreshold {
+			i.Logger.WithFields(logrus.Fields{
+				"operation": operation,
+				"duration":  elapsed.String(),
+			}).Warn("slow data operation")
+		}
+	}
+}
+
+// AccountStore wraps an AccountStore with instrumentation.
+func (i *Instrumentation) AccountStore(store AccountStore) AccountStore {
+	return &instrumentedAccountStore{store, i}
+}
+
+// RefreshTokenStore wraps a RefreshTokenStore with instrumentation.
+func (i *Instrumentation) RefreshTokenStore(store RefreshTokenStore) RefreshTokenStore {
+	return &instrumentedRefreshTokenStore{store, i}
+}
+
+// TrustedDeviceStore wraps a TrustedDeviceStore with instrumentation.
+func (i *Instrumentation) TrustedDeviceStore(store TrustedDeviceStore) TrustedDeviceStore {
+	return &instrumentedTrustedDeviceStore{store, i}
+}
+
+// ClientStore wraps a ClientStore with instrumentation.
+func (i *Instrumentation) ClientStore(store ClientStore) ClientStore {
+	return &instrumentedClientStore{store, i}
+}
+
+// OrganizationStore wraps an OrganizationStore with instrumentation.
+func (i *Instrumentation) OrganizationStore(store OrganizationStore) OrganizationStore {
+	return &instrumentedOrganizationStore{store, i}
+}
+
+// InvitationStore wraps an InvitationStore with instrumentation.
+func (i *Instrumentation) InvitationStore(store InvitationStore) InvitationStore {
+	return &instrumentedInvitationStore{store, i}
+}
+
+// BlobStore wraps a BlobStore with instrumentation.
+func (i *Instrumentation) BlobStore(store BlobStore) BlobStore {
+	return &instrumentedBlobStore{store, i}
+}
+
+// Actives wraps an Actives with instrumentation.
+func (i *Instrumentation) Actives(actives Actives) Actives {
+	return &instrumentedActives{actives, i}
+}
+
+type instrumentedAccountStore struct {
+	store AccountStore
+	i     *Instrumentation
+}
+
+func (s *instrumentedAccountStore) Create(ctx context.Context, u string, p []byte) (*models.Account, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.Create")
+	defer done()
+	return s.store.Create(ctx, u, p)
+}
+
+func (s *instrumentedAccountStore) Find(ctx context.Context, id int) (*models.Account, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.Find")
+	defer done()
+	return s.store.Find(ctx, id)
+}
+
+func (s *instrumentedAccountStore) FindByUsername(ctx context.Context, u string) (*models.Account, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.FindByUsername")
+	defer done()
+	return s.store.FindByUsername(ctx, u)
+}
+
+func (s *instrumentedAccountStore) FindByOauthAccount(ctx context.Context, p string, pid string) (*models.Account, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.FindByOauthAccount")
+	defer done()
+	return s.store.FindByOauthAccount(ctx, p, pid)
+}
+
+func (s *instrumentedAccountStore) AddOauthAccount(ctx context.Context, id int, p string, pid string, email string, tok string) error {
+	ctx, done := s.i.start(ctx, "AccountStore.AddOauthAccount")
+	defer done()
+	return s.store.AddOauthAccount(ctx, id, p, pid, email, tok)
+}
+
+func (s *instrumentedAccountStore) UpdateOauthAccount(ctx context.Context, id int, p string, email string) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.UpdateOauthAccount")
+	defer done()
+	return s.store.UpdateOauthAccount(ctx, id, p, email)
+}
+
+func (s *instrumentedAccountStore) DeleteOauthAccount(ctx context.Context, id int, p string) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.DeleteOauthAccount")
+	defer done()
+	return s.store.DeleteOauthAccount(ctx, id, p)
+}
+
+func (s *instrumentedAccountStore) GetOauthAccounts(ctx context.Context, id int) ([]*models.OauthAccount, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.GetOauthAccounts")
+	defer done()
+	return s.store.GetOauthAccounts(ctx, id)
+}
+
+func (s *instrumentedAccountStore) Archive(ctx context.Context, id int) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.Archive")
+	defer done()
+	return s.store.Archive(ctx, id)
+}
+
+func (s *instrumentedAccountStore) Lock(ctx context.Context, id int) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.Lock")
+	defer done()
+	return s.store.Lock(ctx, id)
+}
+
+func (s *instrumentedAccountStore) Unlock(ctx context.Context, id int) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.Unlock")
+	defer done()
+	return s.store.Unlock(ctx, id)
+}
+
+func (s *instrumentedAccountStore) RequireNewPassword(ctx context.Context, id int) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.RequireNewPassword")
+	defer done()
+	return s.store.RequireNewPassword(ctx, id)
+}
+
+func (s *instrumentedAccountStore) SetPassword(ctx context.Context, id int, p []byte) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.SetPassword")
+	defer done()
+	return s.store.SetPassword(ctx, id, p)
+}
+
+func (s *instrumentedAccountStore) UpdateUsername(ctx context.Context, id int, u string) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.UpdateUsername")
+	defer done()
+	return s.store.UpdateUsername(ctx, id, u)
+}
+
+func (s *instrumentedAccountStore) SetLastLogin(ctx context.Context, id int) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.SetLastLogin")
+	defer done()
+	return s.store.SetLastLogin(ctx, id)
+}
+
+func (s *instrumentedAccountStore) AddTOTPAuthenticator(ctx context.Context, id int, name string, secret []byte, step int64) (*models.TOTPAuthenticator, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.AddTOTPAuthenticator")
+	defer done()
+	return s.store.AddTOTPAuthenticator(ctx, id, name, secret, step)
+}
+
+func (s *instrumentedAccountStore) GetTOTPAuthenticators(ctx context.Context, id int) ([]*models.TOTPAuthenticator, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.GetTOTPAuthenticators")
+	defer done()
+	return s.store.GetTOTPAuthenticators(ctx, id)
+}
+
+func (s *instrumentedAccountStore) SetTOTPAuthenticatorLastUsed(ctx context.Context, id int, authenticatorID int, step int64) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.SetTOTPAuthenticatorLastUsed")
+	defer done()
+	return s.store.SetTOTPAuthenticatorLastUsed(ctx, id, authenticatorID, step)
+}
+
+func (s *instrumentedAccountStore) DeleteTOTPAuthenticator(ctx context.Context, id int, authenticatorID int) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.DeleteTOTPAuthenticator")
+	defer done()
+	return s.store.DeleteTOTPAuthenticator(ctx, id, authenticatorID)
+}
+
+func (s *instrumentedAccountStore) DeleteTOTPAuthenticators(ctx context.Context, id int) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.DeleteTOTPAuthenticators")
+	defer done()
+	return s.store.DeleteTOTPAuthenticators(ctx, id)
+}
+
+func (s *instrumentedAccountStore) SetRequireMFA(ctx context.Context, id int, required bool) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.SetRequireMFA")
+	defer done()
+	return s.store.SetRequireMFA(ctx, id, required)
+}
+
+func (s *instrumentedAccountStore) SetOTPChannel(ctx context.Context, id int, channel string) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.SetOTPChannel")
+	defer done()
+	return s.store.SetOTPChannel(ctx, id, channel)
+}
+
+func (s *instrumentedAccountStore) AddPersonalAccessToken(ctx context.Context, id int, name string, tokenHash string, scopes models.NameList, audience string, expiresAt *time.Time) (*models.PersonalAccessToken, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.AddPersonalAccessToken")
+	defer done()
+	return s.store.AddPersonalAccessToken(ctx, id, name, tokenHash, scopes, audience, expiresAt)
+}
+
+func (s *instrumentedAccountStore) GetPersonalAccessTokens(ctx context.Context, id int) ([]*models.PersonalAccessToken, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.GetPersonalAccessTokens")
+	defer done()
+	return s.store.GetPersonalAccessTokens(ctx, id)
+}
+
+func (s *instrumentedAccountStore) FindPersonalAccessToken(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.FindPersonalAccessToken")
+	defer done()
+	return s.store.FindPersonalAccessToken(ctx, tokenHash)
+}
+
+func (s *instrumentedAccountStore) SetPersonalAccessTokenLastUsed(ctx context.Context, tokenID int) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.SetPersonalAccessTokenLastUsed")
+	defer done()
+	return s.store.SetPersonalAccessTokenLastUsed(ctx, tokenID)
+}
+
+func (s *instrumentedAccountStore) DeletePersonalAccessToken(ctx context.Context, id int, tokenID int) (bool, error) {
+	ctx, done := s.i.start(ctx, "AccountStore.DeletePersonalAccessToken")
+	defer done()
+	return s.store.DeletePersonalAccessToken(ctx, id, tokenID)
+}
+
+type instrumentedRefreshTokenStore struct {
+	store RefreshTokenStore
+	i     *Instrumentation
+}
+
+func (s *instrumentedRefreshTokenStore) Create(ctx context.Context, accountID int) (models.RefreshToken, error) {
+	ctx, done := s.i.start(ctx, "RefreshTokenStore.Create")
+	defer done()
+	return s.store.Create(ctx, accountID)
+}
+
+func (s *instrumentedRefreshTokenStore) Find(ctx context.Context, t models.RefreshToken) (int, error) {
+	ctx, done := s.i.start(ctx, "RefreshTokenStore.Find")
+	defer done()
+	return s.store.Find(ctx, t)
+}
+
+func (s *instrumentedRefreshTokenStore) Touch(ctx context.Context, t models.RefreshToken, accountID int) error {
+	ctx, done := s.i.start(ctx, "RefreshTokenStore.Touch")
+	defer done()
+	return s.store.Touch(ctx, t, accountID)
+}
+
+func (s *instrumentedRefreshTokenStore) FindAll(ctx context.Context, accountID int) ([]models.RefreshToken, error) {
+	ctx, done := s.i.start(ctx, "RefreshTokenStore.FindAll")
+	defer done()
+	return s.store.FindAll(ctx, accountID)
+}
+
+func (s *instrumentedRefreshTokenStore) Revoke(ctx context.Context, t models.RefreshToken) error {
+	ctx, done := s.i.start(ctx, "RefreshTokenStore.Revoke")
+	defer done()
+	return s.store.Revoke(ctx, t)
+}
+
+func (s *instrumentedRefreshTokenStore) Rotate(ctx context.Context, t models.RefreshToken, accountID int) (models.RefreshToken, error) {
+	ctx, done := s.i.start(ctx, "RefreshTokenStore.Rotate")
+	defer done()
+	return s.store.Rotate(ctx, t, accountID)
+}
+
+func (s *instrumentedRefreshTokenStore) RevokeFamily(ctx context.Context, t models.RefreshToken, grace time.Duration) (int, error) {
+	ctx, done := s.i.start(ctx, "RefreshTokenStore.RevokeFamily")
+	defer done()
+	return s.store.RevokeFamily(ctx, t, grace)
+}
+
+func (s *instrumentedRefreshTokenStore) SetSessionID(ctx context.Context, t models.RefreshToken, sessionID string) error {
+	ctx, done := s.i.start(ctx, "RefreshTokenStore.SetSessionID")
+	defer done()
+	return s.store.SetSessionID(ctx, t, sessionID)
+}
+
+func (s *instrumentedRefreshTokenStore) FindBySessionID(ctx context.Context, sessionID string) (models.RefreshToken, error) {
+	ctx, done := s.i.start(ctx, "RefreshTokenStore.FindBySessionID")
+	defer done()
+	return s.store.FindBySessionID(ctx, sessionID)
+}
+
+func (s *instrumentedRefreshTokenStore) FindSessionID(ctx context.Context, t models.RefreshToken) (string, error) {
+	ctx, done := s.i.start(ctx, "RefreshTokenStore.FindSessionID")
+	defer done()
+	return s.store.FindSessionID(ctx, t)
+}
+
+type instrumentedTrustedDeviceStore struct {
+	store TrustedDeviceStore
+	i     *Instrumentation
+}
+
+func (s *instrumentedTrustedDeviceStore) Create(ctx context.Context, accountID int, name string) (*models.TrustedDevice, error) {
+	ctx, done := s.i.start(ctx, "TrustedDeviceStore.Create")
+	defer done()
+	return s.store.Create(ctx, accountID, name)
+}
+
+func (s *instrumentedTrustedDeviceStore) Find(ctx context.Context, id string) (*models.TrustedDevice, error) {
+	ctx, done := s.i.start(ctx, "TrustedDeviceStore.Find")
+	defer done()
+	return s.store.Find(ctx, id)
+}
+
+func (s *instrumentedTrustedDeviceStore) FindAll(ctx context.Context, accountID int) ([]*models.TrustedDevice, error) {
+	ctx, done := s.i.start(ctx, "TrustedDeviceStore.FindAll")
+	defer done()
+	return s.store.FindAll(ctx, accountID)
+}
+
+func (s *instrumentedTrustedDeviceStore) Revoke(ctx context.Context, accountID int, id string) (bool, error) {
+	ctx, done := s.i.start(ctx, "TrustedDeviceStore.Revoke")
+	defer done()
+	return s.store.Revoke(ctx, accountID, id)
+}
+
+func (s *instrumentedTrustedDeviceStore) RevokeAll(ctx context.Context, accountID int) error {
+	ctx, done := s.i.start(ctx, "TrustedDeviceStore.RevokeAll")
+	defer done()
+	return s.store.RevokeAll(ctx, accountID)
+}
+
+type instrumentedClientStore struct {
+	store ClientStore
+	i     *Instrumentation
+}
+
+func (s *instrumentedClientStore) Create(ctx context.Context, client *models.Client) error {
+	ctx, done := s.i.start(ctx, "ClientStore.Create")
+	defer done()
+	return s.store.Create(ctx, client)
+}
+
+func (s *instrumentedClientStore) Find(ctx context.Context, id string) (*models.Client, error) {
+	ctx, done := s.i.start(ctx, "ClientStore.Find")
+	defer done()
+	return s.store.Find(ctx, id)
+}
+
+func (s *instrumentedClientStore) FindAll(ctx context.Context) ([]*models.Client, error) {
+	ctx, done := s.i.start(ctx, "ClientStore.FindAll")
+	defer done()
+	return s.store.FindAll(ctx)
+}
+
+func (s *instrumentedClientStore) Update(ctx context.Context, id string, name string, scopes models.NameList, audiences models.NameList) (bool, error) {
+	ctx, done := s.i.start(ctx, "ClientStore.Update")
+	defer done()
+	return s.store.Update(ctx, id, name, scopes, audiences)
+}
+
+func (s *instrumentedClientStore) Delete(ctx context.Context, id string) (bool, error) {
+	ctx, done := s.i.start(ctx, "ClientStore.Delete")
+	defer done()
+	return s.store.Delete(ctx, id)
+}
+
+type instrumentedOrganizationStore struct {
+	store OrganizationStore
+	i     *Instrumentation
+}
+
+func (s *instrumentedOrganizationStore) Create(ctx context.Context, name string) (*models.Organization, error) {
+	ctx, done := s.i.start(ctx, "OrganizationStore.Create")
+	defer done()
+	return s.store.Create(ctx, name)
+}
+
+func (s *instrumentedOrganizationStore) Find(ctx context.Context, id int) (*models.Organization, error) {
+	ctx, done := s.i.start(ctx, "OrganizationStore.Find")
+	defer done()
+	return s.store.Find(ctx, id)
+}
+
+func (s *instrumentedOrganizationStore) Update(ctx context.Context, id int, name string) (bool, error) {
+	ctx, done := s.i.start(ctx, "OrganizationStore.Update")
+	defer done()
+	return s.store.Update(ctx, id, name)
+}
+
+func (s *instrumentedOrganizationStore) Delete(ctx context.Context, id int) (bool, error) {
+	ctx, done := s.i.start(ctx, "OrganizationStore.Delete")
+	defer done()
+	return s.store.Delete(ctx, id)
+}
+
+func (s *instrumentedOrganizationStore) SetMembership(ctx context.Context, organizationID int, accountID int, roles models.NameList) error {
+	ctx, done := s.i.start(ctx, "OrganizationStore.SetMembership")
+	defer done()
+	return s.store.SetMembership(ctx, organizationID, accountID, roles)
+}
+
+func (s *instrumentedOrganizationStore) FindMembership(ctx context.Context, organizationID int, accountID int) (*models.Membership, error) {
+	ctx, done := s.i.start(ctx, "OrganizationStore.FindMembership")
+	defer done()
+	return s.store.FindMembership(ctx, organizationID, accountID)
+}
+
+func (s *instrumentedOrganizationStore) GetMembers(ctx context.Context, organizationID int) ([]*models.Membership, error) {
+	ctx, done := s.i.start(ctx, "OrganizationStore.GetMembers")
+	defer done()
+	return s.store.GetMembers(ctx, organizationID)
+}
+
+func (s *instrumentedOrganizationStore) GetMemberships(ctx context.Context, accountID int) ([]*models.Membership, error) {
+	ctx, done := s.i.start(ctx, "OrganizationStore.GetMemberships")
+	defer done()
+	return s.store.GetMemberships(ctx, accountID)
+}
+
+func (s *instrumentedOrganizationStore) DeleteMembership(ctx context.Context, organizationID int, accountID int) (bool, error) {
+	ctx, done := s.i.start(ctx, "OrganizationStore.DeleteMembership")
+	defer done()
+	return s.store.DeleteMembership(ctx, organizationID, accountID)
+}
+
+type instrumentedInvitationStore struct {
+	store InvitationStore
+	i     *Instrumentation
+}
+
+func (s *instrumentedInvitationStore) Accept(ctx context.Context, id string, accountID int) error {
+	ctx, done := s.i.start(ctx, "InvitationStore.Accept")
+	defer done()
+	return s.store.Accept(ctx, id, accountID)
+}
+
+func (s *instrumentedInvitationStore) FindAccepted(ctx context.Context, id string) (int, error) {
+	ctx, done := s.i.start(ctx, "InvitationStore.FindAccepted")
+	defer done()
+	return s.store.FindAccepted(ctx, id)
+}
+
+type instrumentedBlobStore struct {
+	store BlobStore
+	i     *Instrumentation
+}
+
+func (s *instrumentedBlobStore) Read(ctx context.Context, name string) ([]byte, error) {
+	ctx, done := s.i.start(ctx, "BlobStore.Read")
+	defer done()
+	return s.store.Read(ctx, name)
+}
+
+func (s *instrumentedBlobStore) WriteNX(ctx context.Context, name string, blob []byte) (bool, error) {
+	ctx, done := s.i.start(ctx, "BlobStore.WriteNX")
+	defer done()
+	return s.store.WriteNX(ctx, name, blob)
+}
+
+func (s *instrumentedBlobStore) Write(ctx context.Context, name string, blob []byte) (bool, error) {
+	ctx, done := s.i.start(ctx, "BlobStore.Write")
+	defer done()
+	return s.store.Write(ctx, name, blob)
+}
+
+func (s *instrumentedBlobStore) Rewrite(ctx context.Context, name string, blob []byte) (bool, error) {
+	ctx, done := s.i.start(ctx, "BlobStore.Rewrite")
+	defer done()
+	return s.store.Rewrite(ctx, name, blob)
+}
+
+func (s *instrumentedBlobStore) Delete(ctx context.Context, name string) error {
+	ctx, done := s.i.start(ctx, "BlobStore.Delete")
+	defer done()
+	return s.store.Delete(ctx, name)
+}
+
+func (s *instrumentedBlobStore) Names(ctx context.Context, prefix string) ([]string, error) {
+	ctx, done := s.i.start(ctx, "BlobStore.Names")
+	defer done()
+	return s.store.Names(ctx, prefix)
+}
+
+type instrumentedActives struct {
+	actives Actives
+	i       *Instrumentation
+}
+
+func (a *instrumentedActives) Track(ctx context.Context, accountID int) error {
+	ctx, done := a.i.start(ctx, "Actives.Track")
+	defer done()
+	return a.actives.Track(ctx, accountID)
+}
+
+func (a *instrumentedActives) ActivesByDay(ctx context.Context) (map[string]int, error) {
+	ctx, done := a.i.start(ctx, "Actives.ActivesByDay")
+	defer done()
+	return a.actives.ActivesByDay(ctx)
+}
+
+func (a *instrumentedActives) ActivesByWeek(ctx context.Context) (map[string]int, error) {
+	ctx, done := a.i.start(ctx, "Actives.ActivesByWeek")
+	defer done()
+	return a.actives.ActivesByWeek(ctx)
+}
+
+func (a *instrumentedActives) ActivesByMonth(ctx context.Context) (map[string]int, error) {
+	ctx, done := a.i.start(ctx, "Actives.ActivesByMonth")
+	defer done()
+	return a.actives.ActivesByMonth(ctx)
+}
@@ -0,0 +1,71 @@
+package data_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowTokenStore blocks until its context is done
+type slowTokenStore struct {
+	data.RefreshTokenStore
+}
+
+func (s *slowTokenStore) Find(ctx context.Context, t models.RefreshToken) (int, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func TestInstrumentation(t *testing.T) {
+	t.Run("passes through", func(t *testing.T) {
+		inst := data.NewInstrumentation(time.Second, time.Second, logrus.New())
+		store := inst.AccountStore(mock.NewAccountStore())
+
+		account, err := store.Create(context.Background(), "instrumented", []byte("password"))
+		require.NoError(t, err)
+
+		found, err := store.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "instrumented", found.Username)
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		inst := data.NewInstrumentation(10*time.Millisecond, 0, logrus.New())
+		store := inst.RefreshTokenStore(&slowTokenStore{mock.NewRefreshTokenStore()})
+
+		_, err := store.Find(context.Background(), models.RefreshToken("token"))
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("cancels with caller", func(t *testing.T) {
+		inst := data.NewInstrumentation(time.Minute, 0, logrus.New())
+		store := inst.RefreshTokenStore(&slowTokenStore{mock.NewRefreshTokenStore()})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := store.Find(ctx, models.RefreshToken("token"))
+		assert.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("logs slow operations", func(t *testing.T) {
+		logger, hook := test.NewNullLogger()
+		inst := data.NewInstrumentation(time.Minute, time.Millisecond, logger)
+		store := inst.RefreshTokenStore(&slowTokenStore{mock.NewRefreshTokenStore()})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+		_, _ = store.Find(ctx, models.RefreshToken("token"))
+
+		require.Len(t, hook.Entries, 1)
+		assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+		assert.Equal(t, "RefreshTokenStore.Find", hook.LastEntry().Data["operation"])
+	})
+}
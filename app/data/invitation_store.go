@@ -0,0 +1,35 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/data/mysql"
+	"github.com/keratin/authn-server/app/data/postgres"
+	"github.com/keratin/authn-server/app/data/sqlite3"
+)
+
+// InvitationStore remembers which invitations have been accepted, so that each may only be used
+// once. The invitations themselves are signed tokens and are not stored.
+type InvitationStore interface {
+	// Records that the invitation was accepted by the account.
+	Accept(ctx context.Context, id string, accountID int) error
+
+	// Finds the account that accepted the invitation. An empty value indicates that the invitation
+	// has not been accepted.
+	FindAccepted(ctx context.Context, id string) (int, error)
+}
+
+func NewInvitationStore(db *sqlx.DB) (InvitationStore, error) {
+	switch db.DriverName() {
+	case "sqlite3":
+		return &sqlite3.InvitationStore{ExtContext: db}, nil
+	case "mysql":
+		return &mysql.InvitationStore{ExtContext: db}, nil
+	case "postgres":
+		return &postgres.InvitationStore{ExtContext: db}, nil
+	default:
+		return nil, fmt.Errorf("unsupported driver: %v", db.DriverName())
+	}
+}
@@ -1,9 +1,10 @@
 package data
 
 import (
-	"crypto/x509"
-	"encoding/pem"
+	"bytes"
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/keratin/authn-server/app/data/private"
@@ -13,62 +14,77 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// rotationMarker is rewritten whenever a rotation is forced, so that other processes know to
+// reload their keys.
+const rotationMarker = "rsa:rotation"
+
+func init() {
+	registerEncryptedBlobPrefix("rsa:")
+}
+
 // NewKeyStoreRotater creates a KeyStoreRotater.
 //
 // The rotation interval should match the lifetime of an access token. This means a key can be used
 // to sign tokens for one time period, remain available to verify tokens for another time period,
 // and be discarded during the third.
-func NewKeyStoreRotater(blobStore *EncryptedBlobStore, interval time.Duration, logger logrus.FieldLogger) *KeyStoreRotater {
+//
+// The algorithm determines the type of key that will be generated. A change of algorithm takes
+// effect with the next generated key.
+func NewKeyStoreRotater(blobStore *EncryptedBlobStore, interval time.Duration, algorithm string, logger logrus.FieldLogger) *KeyStoreRotater {
 	return &KeyStoreRotater{
-		store:       blobStore,
-		interval:    interval,
-		keyStrength: 2048,
-		logger:      logger.WithField("scope", "NewKeyStoreRotater"),
+		store:        blobStore,
+		interval:     interval,
+		pollInterval: 10 * time.Second,
+		algorithm:    algorithm,
+		keyStrength:  2048,
+		logger:       logger.WithField("scope", "NewKeyStoreRotater"),
+		now:          time.Now,
 	}
 }
 
 // KeyStoreRotater will rotate a RotatingKeyStore by periodically generating new keys. The keys will be
 // persisted into an EncryptedBlobStore, shared with other processes, and read back on startup.
+//
+// Each time period has one key for signing, and the key for the following time period is generated
+// in advance so that it may be published before it is used. A rotation may also be forced, which
+// immediately promotes the next key and lets other processes know to reload.
 type KeyStoreRotater struct {
-	interval    time.Duration
-	keyStrength int
-	store       *EncryptedBlobStore
-	logger      logrus.FieldLogger
+	interval     time.Duration
+	pollInterval time.Duration
+	algorithm    string
+	keyStrength  int
+	store        *EncryptedBlobStore
+	logger       logrus.FieldLogger
+	now          func() time.Time
+
+	keyStore *RotatingKeyStore
+	marker   []byte
+	mutex    sync.Mutex
 }
 
 // Maintain will restore and rotate a keyStore at periodic intervals. It will return an error only
 // for issues during startup. Any issues that arise later during background work will be reported.
 func (m *KeyStoreRotater) Maintain(ks *RotatingKeyStore, r ops.ErrorReporter) error {
-	// fetch current keys
-	keys, err := m.restore()
-	if err != nil {
-		return errors.Wrap(err, "restore")
-	}
-
-	// rotate in the previous key
-	if keys[0] != nil {
-		ks.Rotate(keys[0])
-
-		m.logger.WithField("keyID", keys[0].JWK.KeyID).Info("previous key restored")
-	}
-
-	// ensure and rotate in the current key
-	if keys[1] != nil {
-		ks.Rotate(keys[1])
+	m.keyStore = ks
 
-		m.logger.WithField("keyID", keys[1].JWK.KeyID).Info("current key restored")
-	} else {
-		newKey, err := m.generate()
-		if err != nil {
-			return errors.Wrap(err, "generate")
-		}
-		ks.Rotate(newKey)
+	err := m.rotate(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "rotate")
 	}
 
 	go func() {
 		intervals := lib.EpochIntervalTick(m.interval)
 		for range intervals {
-			err = m.rotate(ks)
+			err := m.rotate(context.Background())
+			if err != nil {
+				r.ReportError(err)
+			}
+		}
+	}()
+
+	go func() {
+		for range time.Tick(m.pollInterval) {
+			err := m.Refresh(context.Background())
 			if err != nil {
 				r.ReportError(err)
 			}
@@ -78,71 +94,176 @@ func (m *KeyStoreRotater) Maintain(ks *RotatingKeyStore, r ops.ErrorReporter) er
 	return nil
 }
 
-func (m *KeyStoreRotater) rotate(ks *RotatingKeyStore) error {
-	newKey, err := m.generate()
-	if err != nil {
+// rotate ensures that keys exist for the current and next time periods, then loads them.
+func (m *KeyStoreRotater) rotate(ctx context.Context) error {
+	bucket := m.currentBucket()
+	if _, err := m.generate(ctx, bucket); err != nil {
+		return errors.Wrap(err, "generate")
+	}
+	if _, err := m.generate(ctx, bucket+1); err != nil {
 		return errors.Wrap(err, "generate")
 	}
-	ks.Rotate(newKey)
 
-	return nil
+	return m.restore(ctx)
+}
+
+// Refresh reloads the keys if another process has forced a rotation.
+func (m *KeyStoreRotater) Refresh(ctx context.Context) error {
+	marker, err := m.store.Read(ctx, rotationMarker)
+	if err != nil {
+		return errors.Wrap(err, "Read")
+	}
+
+	m.mutex.Lock()
+	changed := !bytes.Equal(marker, m.marker)
+	m.mutex.Unlock()
+	if !changed {
+		return nil
+	}
+
+	m.logger.Info("forced rotation detected")
+	return m.restore(ctx)
 }
 
-// restore will query the blob store for the previous and current keys. It returns keys in the
-// proper sorting order, with the newest (current) key in last position. missing keys will leave a
-// blank slot, so that the caller may choose what to do.
-func (m *KeyStoreRotater) restore() ([]*private.Key, error) {
+// ForceRotation immediately replaces the current key with the next key, which should have already
+// been published. The replaced key continues to be published so that existing tokens can still be
+// verified, unless it is revoked. A new next key is then generated and published.
+//
+// It returns the new current key and the replaced key.
+func (m *KeyStoreRotater) ForceRotation(ctx context.Context, revoke bool) (*private.Key, *private.Key, error) {
 	bucket := m.currentBucket()
-	keys := make([]*private.Key, 2)
+	current, err := m.generate(ctx, bucket)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "generate")
+	}
+	next, err := m.generate(ctx, bucket+1)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "generate")
+	}
 
-	previous, err := m.find(bucket - 1)
+	promoted := []*private.Key{next[0]}
+	if revoke {
+		promoted = append(promoted, current[1:]...)
+	} else {
+		promoted = append(promoted, current...)
+	}
+	err = m.write(ctx, bucket, promoted)
 	if err != nil {
-		return nil, err
+		return nil, nil, errors.Wrap(err, "write")
 	}
-	keys[0] = previous
 
-	current, err := m.find(bucket)
+	key, err := private.GenerateKeyFor(m.algorithm, m.keyStrength)
 	if err != nil {
-		return nil, err
+		return nil, nil, errors.Wrap(err, "GenerateKeyFor")
+	}
+	err = m.write(ctx, bucket+1, []*private.Key{key})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "write")
 	}
-	keys[1] = current
 
-	return keys, nil
+	marker, err := lib.GenerateToken()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "GenerateToken")
+	}
+	_, err = m.store.Write(ctx, rotationMarker, marker)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Write")
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"keyID":         next[0].JWK.KeyID,
+		"replacedKeyID": current[0].JWK.KeyID,
+		"revoked":       revoke,
+	}).Warn("forced key rotation")
+
+	return next[0], current[0], m.restore(ctx)
 }
 
-// generate will create a new key and store it as an encrypted blob. It relies on a write lock to
-// coordinate with other AuthN servers.
-func (m *KeyStoreRotater) generate() (*private.Key, error) {
-	keyName := fmt.Sprintf("rsa:%d", m.currentBucket())
-	key, err := private.GenerateKey(m.keyStrength)
+// restore will query the blob store for the previous, current, and next keys and reset the key
+// store with them. Keys are kept in the proper sorting order, with the newest (current) key in last
+// position.
+func (m *KeyStoreRotater) restore(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	marker, err := m.store.Read(ctx, rotationMarker)
+	if err != nil {
+		return errors.Wrap(err, "Read")
+	}
+
+	bucket := m.currentBucket()
+	previous, err := m.find(ctx, bucket-1)
+	if err != nil {
+		return err
+	}
+	current, err := m.find(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if len(current) == 0 {
+		return fmt.Errorf("missing current key")
+	}
+	next, err := m.find(ctx, bucket+1)
+	if err != nil {
+		return err
+	}
+
+	keys := append(oldestFirst(previous), oldestFirst(current)...)
+	var nextKey *private.Key
+	if len(next) > 0 {
+		nextKey = next[0]
+	}
+	m.keyStore.Reset(keys, nextKey)
+	m.marker = marker
+
+	return nil
+}
+
+// generate will ensure a key exists for the bucket, creating one and storing it as an encrypted
+// blob if necessary. It relies on a write lock to coordinate with other AuthN servers.
+func (m *KeyStoreRotater) generate(ctx context.Context, bucket int64) ([]*private.Key, error) {
+	keys, err := m.find(ctx, bucket)
+	if err != nil || len(keys) > 0 {
+		return keys, err
+	}
+
+	// keys of every algorithm are stored under the original rsa: names so that servers agree on them
+	keyName := fmt.Sprintf("rsa:%d", bucket)
+	key, err := private.GenerateKeyFor(m.algorithm, m.keyStrength)
 	if err != nil {
 		return nil, err
 	}
 
-	blob := keyToBytes(key)
-	ok, err := m.store.WriteNX(keyName, blob)
+	blob, err := key.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	ok, err := m.store.WriteNX(ctx, keyName, blob)
 	if err != nil {
 		return nil, err
 	}
 
 	if ok {
 		m.logger.WithFields(logrus.Fields{"keyID": key.JWK.KeyID, "keyName": keyName}).Info("new key generated")
-	} else {
-		keyBlob, err := m.store.Read(keyName)
-		if err != nil {
-			return nil, err
-		}
-		key = bytesToKey(keyBlob)
+		return []*private.Key{key}, nil
+	}
 
-		m.logger.WithField("keyID", key.JWK.KeyID).Info("key synchronized")
+	keys, err = m.find(ctx, bucket)
+	if err != nil {
+		return nil, err
 	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("missing key: %v", keyName)
+	}
+	m.logger.WithField("keyID", keys[0].JWK.KeyID).Info("key synchronized")
 
-	return key, nil
+	return keys, nil
 }
 
-// find will retrieve and deserialize/decrypt from the blob store
-func (m *KeyStoreRotater) find(bucket int64) (*private.Key, error) {
-	blob, err := m.store.Read(fmt.Sprintf("rsa:%d", bucket))
+// find will retrieve and deserialize/decrypt from the blob store. The first key is the one used
+// for signing, and any others were replaced by a forced rotation.
+func (m *KeyStoreRotater) find(ctx context.Context, bucket int64) ([]*private.Key, error) {
+	blob, err := m.store.Read(ctx, fmt.Sprintf("rsa:%d", bucket))
 	if err != nil {
 		return nil, errors.Wrap(err, "Get")
 	}
@@ -150,26 +271,36 @@ func (m *KeyStoreRotater) find(bucket int64) (*private.Key, error) {
 		return nil, nil
 	}
 
-	return bytesToKey(blob), nil
+	keys, err := private.ParseKeys(blob)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseKeys")
+	}
+	return keys, nil
 }
 
-func (m *KeyStoreRotater) currentBucket() int64 {
-	return time.Now().Unix() / int64(m.interval/time.Second)
+// write will serialize/encrypt the keys into the blob store, replacing any existing keys.
+func (m *KeyStoreRotater) write(ctx context.Context, bucket int64, keys []*private.Key) error {
+	var blob []byte
+	for _, key := range keys {
+		pem, err := key.Marshal()
+		if err != nil {
+			return err
+		}
+		blob = append(blob, pem...)
+	}
+	_, err := m.store.Write(ctx, fmt.Sprintf("rsa:%d", bucket), blob)
+	return err
 }
 
-func keyToBytes(key *private.Key) []byte {
-	return pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(key.PrivateKey),
-	})
+func (m *KeyStoreRotater) currentBucket() int64 {
+	return m.now().Unix() / int64(m.interval/time.Second)
 }
 
-func bytesToKey(b []byte) *private.Key {
-	block, _ := pem.Decode(b)
-	if block == nil {
-		return nil
+// oldestFirst reverses the order of a bucket's keys, so that the signing key is last.
+func oldestFirst(keys []*private.Key) []*private.Key {
+	sorted := make([]*private.Key, len(keys))
+	for i, key := range keys {
+		sorted[len(keys)-1-i] = key
 	}
-	key, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
-	privateKey, _ := private.NewKey(key)
-	return privateKey
+	return sorted
 }
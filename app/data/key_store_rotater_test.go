@@ -1,33 +1,43 @@
 package data_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/lib/compat"
 	"github.com/keratin/authn-server/ops"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func keyIDs(keys []*private.Key) []string {
+	ids := []string{}
+	for _, key := range keys {
+		ids = append(ids, key.JWK.KeyID)
+	}
+	return ids
+}
+
 func TestKeyStoreRotater(t *testing.T) {
 	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
-	secret := []byte("32bigbytesofsuperultimatesecrecy")
+	secret := compat.NewKeyring([]byte("32bigbytesofsuperultimatesecrecy"))
 	interval := time.Hour
 	logger := logrus.New()
 
 	t.Run("empty remote storage", func(t *testing.T) {
 		blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
 		store := data.NewRotatingKeyStore()
-		rotater := data.NewKeyStoreRotater(blobStore, interval, logger)
+		rotater := data.NewKeyStoreRotater(blobStore, interval, private.RS256, logger)
 		err := rotater.Maintain(store, reporter)
 		require.NoError(t, err)
 
 		assert.NotEmpty(t, store.Keys())
-		assert.Len(t, store.Keys(), 1)
+		assert.Len(t, store.Keys(), 2, "current and next keys")
 		assert.Equal(t, store.Key(), store.Keys()[0])
 	})
 
@@ -35,36 +45,99 @@ func TestKeyStoreRotater(t *testing.T) {
 		blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
 
 		store1 := data.NewRotatingKeyStore()
-		err := data.NewKeyStoreRotater(blobStore, interval, logger).Maintain(store1, reporter)
+		err := data.NewKeyStoreRotater(blobStore, interval, private.RS256, logger).Maintain(store1, reporter)
 		require.NoError(t, err)
 		key1 := store1.Key()
 		assert.NotEmpty(t, key1)
 
 		store2 := data.NewRotatingKeyStore()
-		err = data.NewKeyStoreRotater(blobStore, interval, logger).Maintain(store2, reporter)
+		err = data.NewKeyStoreRotater(blobStore, interval, private.RS256, logger).Maintain(store2, reporter)
 		require.NoError(t, err)
-		assert.Len(t, store2.Keys(), 1)
-		assert.Equal(t, key1, store2.Key())
-		assert.Equal(t, key1, store2.Keys()[0])
+		assert.Equal(t, key1.JWK.KeyID, store2.Key().JWK.KeyID)
+		assert.Equal(t, keyIDs(store1.Keys()), keyIDs(store2.Keys()))
 	})
 
+	for _, alg := range []string{private.ES256, private.EdDSA} {
+		t.Run("multiple servers with "+alg, func(t *testing.T) {
+			blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
+
+			store1 := data.NewRotatingKeyStore()
+			err := data.NewKeyStoreRotater(blobStore, interval, alg, logger).Maintain(store1, reporter)
+			require.NoError(t, err)
+			assert.Equal(t, alg, store1.Key().JWK.Algorithm)
+
+			store2 := data.NewRotatingKeyStore()
+			err = data.NewKeyStoreRotater(blobStore, interval, alg, logger).Maintain(store2, reporter)
+			require.NoError(t, err)
+			assert.Equal(t, store1.Key().JWK.KeyID, store2.Key().JWK.KeyID)
+		})
+	}
+
 	t.Run("rotation", func(t *testing.T) {
 		blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
 		store := data.NewRotatingKeyStore()
-		rotater := data.NewKeyStoreRotater(blobStore, interval, logger)
+		rotater := data.NewKeyStoreRotater(blobStore, interval, private.RS256, logger)
 		err := rotater.Maintain(store, reporter)
 		require.NoError(t, err)
 
-		firstKey := store.Keys()[0]
+		firstKey := store.Key()
 
 		secondKey, err := private.GenerateKey(256)
 		require.NoError(t, err)
 		store.Rotate(secondKey)
-		assert.Equal(t, []*private.Key{firstKey, secondKey}, store.Keys())
+		assert.Equal(t, firstKey, store.Keys()[0])
+		assert.Equal(t, secondKey, store.Key())
 
 		thirdKey, err := private.GenerateKey(256)
 		require.NoError(t, err)
 		store.Rotate(thirdKey)
-		assert.Equal(t, []*private.Key{secondKey, thirdKey}, store.Keys())
+		assert.Equal(t, secondKey, store.Keys()[0])
+		assert.Equal(t, thirdKey, store.Key())
+	})
+
+	t.Run("forced rotation", func(t *testing.T) {
+		blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
+
+		store1 := data.NewRotatingKeyStore()
+		rotater1 := data.NewKeyStoreRotater(blobStore, interval, private.ES256, logger)
+		require.NoError(t, rotater1.Maintain(store1, reporter))
+		store2 := data.NewRotatingKeyStore()
+		rotater2 := data.NewKeyStoreRotater(blobStore, interval, private.ES256, logger)
+		require.NoError(t, rotater2.Maintain(store2, reporter))
+
+		current := store1.Key()
+		next := store1.Keys()[len(store1.Keys())-1]
+
+		key, replaced, err := rotater1.ForceRotation(context.Background(), false)
+		require.NoError(t, err)
+		assert.Equal(t, next.JWK.KeyID, key.JWK.KeyID)
+		assert.Equal(t, current.JWK.KeyID, replaced.JWK.KeyID)
+		assert.Equal(t, next.JWK.KeyID, store1.Key().JWK.KeyID)
+		assert.Contains(t, keyIDs(store1.Keys()), current.JWK.KeyID)
+		assert.Len(t, store1.Keys(), 3, "replaced, current, and next keys")
+
+		// the other server catches up
+		assert.Equal(t, current.JWK.KeyID, store2.Key().JWK.KeyID)
+		require.NoError(t, rotater2.Refresh(context.Background()))
+		assert.Equal(t, keyIDs(store1.Keys()), keyIDs(store2.Keys()))
+	})
+
+	t.Run("forced rotation with revocation", func(t *testing.T) {
+		blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
+		store := data.NewRotatingKeyStore()
+		rotater := data.NewKeyStoreRotater(blobStore, interval, private.ES256, logger)
+		require.NoError(t, rotater.Maintain(store, reporter))
+
+		current := store.Key()
+		_, replaced, err := rotater.ForceRotation(context.Background(), true)
+		require.NoError(t, err)
+		assert.Equal(t, current.JWK.KeyID, replaced.JWK.KeyID)
+		assert.NotContains(t, keyIDs(store.Keys()), current.JWK.KeyID)
+		assert.Len(t, store.Keys(), 2, "current and next keys")
+
+		// a new server restores the same keys
+		restored := data.NewRotatingKeyStore()
+		require.NoError(t, data.NewKeyStoreRotater(blobStore, interval, private.ES256, logger).Maintain(restored, reporter))
+		assert.Equal(t, keyIDs(store.Keys()), keyIDs(restored.Keys()))
 	})
 }
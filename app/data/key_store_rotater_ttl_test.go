@@ -0,0 +1,90 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expiringBlobStore expires blobs according to a controlled clock.
+type expiringBlobStore struct {
+	*mock.BlobStore
+	ttl       time.Duration
+	now       func() time.Time
+	expiresAt map[string]time.Time
+}
+
+func (bs *expiringBlobStore) expire(name string) {
+	if exp, ok := bs.expiresAt[name]; ok && !bs.now().Before(exp) {
+		delete(bs.expiresAt, name)
+		bs.BlobStore.Delete(context.Background(), name)
+	}
+}
+
+func (bs *expiringBlobStore) Read(ctx context.Context, name string) ([]byte, error) {
+	bs.expire(name)
+	return bs.BlobStore.Read(ctx, name)
+}
+
+func (bs *expiringBlobStore) WriteNX(ctx context.Context, name string, blob []byte) (bool, error) {
+	bs.expire(name)
+	ok, err := bs.BlobStore.WriteNX(ctx, name, blob)
+	if ok {
+		bs.expiresAt[name] = bs.now().Add(bs.ttl)
+	}
+	return ok, err
+}
+
+func (bs *expiringBlobStore) Write(ctx context.Context, name string, blob []byte) (bool, error) {
+	bs.expiresAt[name] = bs.now().Add(bs.ttl)
+	return bs.BlobStore.Write(ctx, name, blob)
+}
+
+func TestKeyStoreRotaterKeyLifetime(t *testing.T) {
+	ctx := context.Background()
+	interval := time.Hour
+	clock := time.Unix(1000*int64(interval/time.Second), 0)
+	now := func() time.Time { return clock }
+
+	blobStore := &expiringBlobStore{
+		BlobStore: mock.NewBlobStore(blobTTL(interval), time.Second),
+		ttl:       blobTTL(interval),
+		now:       now,
+		expiresAt: map[string]time.Time{},
+	}
+	rotater := NewKeyStoreRotater(
+		NewEncryptedBlobStore(blobStore, compat.NewKeyring([]byte("32bigbytesofsuperultimatesecrecy"))),
+		interval,
+		private.ES256,
+		logrus.New(),
+	)
+	rotater.now = now
+	rotater.keyStore = NewRotatingKeyStore()
+
+	// the next key is generated at the start of the first interval
+	require.NoError(t, rotater.rotate(ctx))
+	next, err := rotater.find(ctx, rotater.currentBucket()+1)
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+
+	// it signs during the second interval
+	clock = clock.Add(interval)
+	require.NoError(t, rotater.rotate(ctx))
+	assert.Equal(t, next[0].JWK.KeyID, rotater.keyStore.Key().JWK.KeyID)
+
+	// and must still be published midway through the third interval
+	clock = clock.Add(interval + interval/2)
+	require.NoError(t, rotater.restore(ctx))
+	ids := []string{}
+	for _, key := range rotater.keyStore.Keys() {
+		ids = append(ids, key.JWK.KeyID)
+	}
+	assert.Contains(t, ids, next[0].JWK.KeyID)
+}
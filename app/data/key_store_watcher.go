@@ -0,0 +1,134 @@
+package data
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// activeMarker is the name of the file that holds the name of the active key file.
+const activeMarker = "active"
+
+// NewKeyStoreWatcher creates a KeyStoreWatcher for a directory of PEM files.
+func NewKeyStoreWatcher(dir string, logger logrus.FieldLogger) *KeyStoreWatcher {
+	return &KeyStoreWatcher{
+		dir:          dir,
+		pollInterval: 10 * time.Second,
+		logger:       logger.WithField("scope", "NewKeyStoreWatcher"),
+	}
+}
+
+// KeyStoreWatcher will keep a RotatingKeyStore in sync with a directory of externally managed keys.
+// Every *.pem file in the directory is published, and a file named "active" contains the name of
+// the one used for signing. This allows a successor key to be staged (published) before it is
+// activated, and a retired key to remain published until tokens it signed have expired.
+type KeyStoreWatcher struct {
+	dir          string
+	pollInterval time.Duration
+	logger       logrus.FieldLogger
+	keyIDs       []string
+}
+
+// Maintain will load keys into the keyStore and reload them when the directory changes. It will
+// return an error only for issues during startup. Any issues that arise later are reported, and the
+// previous keys are kept.
+func (w *KeyStoreWatcher) Maintain(ks *RotatingKeyStore, r ops.ErrorReporter) error {
+	err := w.Reload(ks)
+	if err != nil {
+		return errors.Wrap(err, "Reload")
+	}
+
+	go func() {
+		for range time.Tick(w.pollInterval) {
+			err := w.Reload(ks)
+			if err != nil {
+				r.ReportError(err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Reload reads the directory and resets the keyStore if the keys have changed.
+func (w *KeyStoreWatcher) Reload(ks *RotatingKeyStore) error {
+	keys, err := w.load()
+	if err != nil {
+		return err
+	}
+
+	keyIDs := []string{}
+	for _, key := range keys {
+		keyIDs = append(keyIDs, key.JWK.KeyID)
+	}
+	if reflect.DeepEqual(keyIDs, w.keyIDs) {
+		return nil
+	}
+
+	ks.Reset(keys, nil)
+	w.keyIDs = keyIDs
+	w.logger.WithFields(logrus.Fields{
+		"keyID":  keys[len(keys)-1].JWK.KeyID,
+		"keyIDs": keyIDs,
+	}).Info("keys loaded")
+
+	return nil
+}
+
+// load returns every key in the directory, sorted with the active key last.
+func (w *KeyStoreWatcher) load() ([]*private.Key, error) {
+	paths, err := filepath.Glob(filepath.Join(w.dir, "*.pem"))
+	if err != nil {
+		return nil, errors.Wrap(err, "Glob")
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no keys found in %v", w.dir)
+	}
+	sort.Strings(paths)
+
+	active, err := ioutil.ReadFile(filepath.Join(w.dir, activeMarker))
+	if os.IsNotExist(err) && len(paths) == 1 {
+		active = []byte(filepath.Base(paths[0]))
+	} else if err != nil {
+		return nil, errors.Wrap(err, "ReadFile")
+	}
+	activeName := strings.TrimSpace(string(active))
+
+	var keys []*private.Key
+	var activeKey *private.Key
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "ReadFile")
+		}
+		fileKeys, err := private.ParseKeys(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "ParseKeys %v", path)
+		}
+		if len(fileKeys) == 0 {
+			return nil, fmt.Errorf("no keys found in %v", path)
+		}
+
+		if filepath.Base(path) == activeName {
+			activeKey = fileKeys[0]
+			keys = append(keys, fileKeys[1:]...)
+		} else {
+			keys = append(keys, fileKeys...)
+		}
+	}
+	if activeKey == nil {
+		return nil, fmt.Errorf("active key not found: %v", activeName)
+	}
+
+	return append(keys, activeKey), nil
+}
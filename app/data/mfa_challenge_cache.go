@@ -0,0 +1,34 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// MFAChallengeCache remembers the IDs (jti) of MFA challenges that have been completed, so that a
+// challenge can not be replayed. An ID is remembered for longer than a challenge may live.
+type MFAChallengeCache interface {
+	// UseMFAChallenge records the challenge ID for the account, and returns false if it was
+	// already used.
+	UseMFAChallenge(ctx context.Context, accountID int, jti string) (bool, error)
+}
+
+type mfaChallengeCache struct {
+	ebs *EncryptedBlobStore
+}
+
+func NewMFAChallengeCache(ebs *EncryptedBlobStore) MFAChallengeCache {
+	return &mfaChallengeCache{
+		ebs: ebs,
+	}
+}
+
+func (c *mfaChallengeCache) UseMFAChallenge(ctx context.Context, accountID int, jti string) (bool, error) {
+	ok, err := c.ebs.WriteNX(ctx, fmt.Sprintf("mfa_jti:%d:%s", accountID, jti), []byte{})
+	if err != nil {
+		return false, errors.Wrap(err, "UseMFAChallenge")
+	}
+	return ok, nil
+}
@@ -1,12 +1,14 @@
 package mock
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tenants"
 )
 
 type Error struct {
@@ -24,6 +26,10 @@ type accountStore struct {
 	idByUsername      map[string]int
 	oauthAccountsByID map[int][]*models.OauthAccount
 	idByOauthID       map[string]int
+	totpByID          map[int][]*models.TOTPAuthenticator
+	lastTOTPID        int
+	patsByID          map[int][]*models.PersonalAccessToken
+	lastPATID         int
 	errorOnID         int
 }
 
@@ -39,6 +45,8 @@ func NewAccountStore(opts ...func(*accountStore)) *accountStore {
 		oauthAccountsByID: make(map[int][]*models.OauthAccount),
 		idByUsername:      make(map[string]int),
 		idByOauthID:       make(map[string]int),
+		totpByID:          make(map[int][]*models.TOTPAuthenticator),
+		patsByID:          make(map[int][]*models.PersonalAccessToken),
 		errorOnID:         -1,
 	}
 
@@ -49,16 +57,12 @@ func NewAccountStore(opts ...func(*accountStore)) *accountStore {
 	return s
 }
 
-func (s *accountStore) Find(id int) (*models.Account, error) {
-	if s.accountsByID[id] != nil {
-		return dupAccount(*s.accountsByID[id]), nil
-	}
-
-	return nil, nil
+func (s *accountStore) Find(ctx context.Context, id int) (*models.Account, error) {
+	return s.findInTenant(ctx, id), nil
 }
 
-func (s *accountStore) FindByUsername(u string) (*models.Account, error) {
-	id := s.idByUsername[strings.ToLower(u)]
+func (s *accountStore) FindByUsername(ctx context.Context, u string) (*models.Account, error) {
+	id := s.idByUsername[usernameKey(tenants.Name(ctx), u)]
 	if id == 0 {
 		return nil, nil
 	}
@@ -66,23 +70,33 @@ func (s *accountStore) FindByUsername(u string) (*models.Account, error) {
 	return dupAccount(*s.accountsByID[id]), nil
 }
 
-func (s *accountStore) FindByOauthAccount(provider string, providerID string) (*models.Account, error) {
-	id := s.idByOauthID[provider+"|"+providerID]
-	if id == 0 {
-		return nil, nil
+func (s *accountStore) FindByOauthAccount(ctx context.Context, provider string, providerID string) (*models.Account, error) {
+	return s.findInTenant(ctx, s.idByOauthID[provider+"|"+providerID]), nil
+}
+
+// findInTenant returns a copy of the account unless the context is scoped to a different tenant.
+func (s *accountStore) findInTenant(ctx context.Context, id int) *models.Account {
+	account := s.accountsByID[id]
+	if account == nil {
+		return nil
+	}
+	if tenant, ok := tenants.FromContext(ctx); ok && account.Tenant != tenant {
+		return nil
 	}
 
-	return dupAccount(*s.accountsByID[id]), nil
+	return dupAccount(*account)
 }
 
-func (s *accountStore) Create(u string, p []byte) (*models.Account, error) {
-	if s.idByUsername[strings.ToLower(u)] != 0 {
+func (s *accountStore) Create(ctx context.Context, u string, p []byte) (*models.Account, error) {
+	tenant := tenants.Name(ctx)
+	if s.idByUsername[usernameKey(tenant, u)] != 0 {
 		return nil, Error{ErrNotUnique}
 	}
 
 	now := time.Now()
 	acc := models.Account{
 		ID:                len(s.accountsByID) + 1,
+		Tenant:            tenant,
 		Username:          u,
 		Password:          p,
 		PasswordChangedAt: now,
@@ -90,11 +104,11 @@ func (s *accountStore) Create(u string, p []byte) (*models.Account, error) {
 		UpdatedAt:         now,
 	}
 	s.accountsByID[acc.ID] = &acc
-	s.idByUsername[strings.ToLower(acc.Username)] = acc.ID
+	s.idByUsername[usernameKey(acc.Tenant, acc.Username)] = acc.ID
 	return dupAccount(acc), nil
 }
 
-func (s *accountStore) AddOauthAccount(accountID int, provider, providerID, email, tok string) error {
+func (s *accountStore) AddOauthAccount(ctx context.Context, accountID int, provider, providerID, email, tok string) error {
 	p := provider + "|" + providerID
 	if s.idByOauthID[p] != 0 {
 		return Error{ErrNotUnique}
@@ -121,11 +135,11 @@ func (s *accountStore) AddOauthAccount(accountID int, provider, providerID, emai
 	return nil
 }
 
-func (s *accountStore) GetOauthAccounts(accountID int) ([]*models.OauthAccount, error) {
+func (s *accountStore) GetOauthAccounts(ctx context.Context, accountID int) ([]*models.OauthAccount, error) {
 	return s.oauthAccountsByID[accountID], nil
 }
 
-func (s *accountStore) UpdateOauthAccount(accountID int, provider, email string) (bool, error) {
+func (s *accountStore) UpdateOauthAccount(ctx context.Context, accountID int, provider, email string) (bool, error) {
 	oauthAccounts := s.oauthAccountsByID[accountID]
 
 	for i, oauthAccount := range oauthAccounts {
@@ -138,7 +152,7 @@ func (s *accountStore) UpdateOauthAccount(accountID int, provider, email string)
 	return false, nil
 }
 
-func (s *accountStore) DeleteOauthAccount(accountID int, provider string) (bool, error) {
+func (s *accountStore) DeleteOauthAccount(ctx context.Context, accountID int, provider string) (bool, error) {
 	oauthAccounts := s.oauthAccountsByID[accountID]
 
 	for i, oauthAccount := range oauthAccounts {
@@ -151,13 +165,13 @@ func (s *accountStore) DeleteOauthAccount(accountID int, provider string) (bool,
 	return false, nil
 }
 
-func (s *accountStore) Archive(id int) (bool, error) {
+func (s *accountStore) Archive(ctx context.Context, id int) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
 		return false, nil
 	}
 
-	delete(s.idByUsername, strings.ToLower(account.Username))
+	delete(s.idByUsername, usernameKey(account.Tenant, account.Username))
 	now := time.Now()
 	account.Username = ""
 	account.Password = []byte("")
@@ -167,11 +181,14 @@ func (s *accountStore) Archive(id int) (bool, error) {
 		delete(s.idByOauthID, oauthAccount.Provider+"|"+oauthAccount.ProviderID)
 	}
 	delete(s.oauthAccountsByID, account.ID)
+	delete(s.totpByID, account.ID)
+	account.TOTPAuthenticators = 0
+	delete(s.patsByID, account.ID)
 
 	return true, nil
 }
 
-func (s *accountStore) Lock(id int) (bool, error) {
+func (s *accountStore) Lock(ctx context.Context, id int) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
 		return false, nil
@@ -182,7 +199,7 @@ func (s *accountStore) Lock(id int) (bool, error) {
 	return true, nil
 }
 
-func (s *accountStore) Unlock(id int) (bool, error) {
+func (s *accountStore) Unlock(ctx context.Context, id int) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
 		return false, nil
@@ -193,7 +210,7 @@ func (s *accountStore) Unlock(id int) (bool, error) {
 	return true, nil
 }
 
-func (s *accountStore) RequireNewPassword(id int) (bool, error) {
+func (s *accountStore) RequireNewPassword(ctx context.Context, id int) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
 		return false, nil
@@ -201,12 +218,13 @@ func (s *accountStore) RequireNewPassword(id int) (bool, error) {
 
 	account.RequireNewPassword = true
 	account.UpdatedAt = time.Now()
-	account.TOTPSecret = sql.NullString{}
+	delete(s.totpByID, account.ID)
+	account.TOTPAuthenticators = 0
 
 	return true, nil
 }
 
-func (s *accountStore) SetPassword(id int, p []byte) (bool, error) {
+func (s *accountStore) SetPassword(ctx context.Context, id int, p []byte) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
 		return false, nil
@@ -220,12 +238,12 @@ func (s *accountStore) SetPassword(id int, p []byte) (bool, error) {
 	return true, nil
 }
 
-func (s *accountStore) UpdateUsername(id int, u string) (bool, error) {
-	uNormalized := strings.ToLower(u)
+func (s *accountStore) UpdateUsername(ctx context.Context, id int, u string) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
 		return false, nil
 	}
+	uNormalized := usernameKey(account.Tenant, u)
 
 	if s.idByUsername[uNormalized] != 0 && s.idByUsername[uNormalized] != id {
 		return false, Error{ErrNotUnique}
@@ -237,7 +255,7 @@ func (s *accountStore) UpdateUsername(id int, u string) (bool, error) {
 	return true, nil
 }
 
-func (s *accountStore) SetLastLogin(id int) (bool, error) {
+func (s *accountStore) SetLastLogin(ctx context.Context, id int) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
 		return false, nil
@@ -248,37 +266,169 @@ func (s *accountStore) SetLastLogin(id int) (bool, error) {
 	return true, nil
 }
 
-func (s *accountStore) SetTOTPSecret(id int, secret []byte) (bool, error) {
+func (s *accountStore) AddTOTPAuthenticator(ctx context.Context, id int, name string, secret []byte, step int64) (*models.TOTPAuthenticator, error) {
 	account := s.accountsByID[id]
 	if account == nil {
-		return false, nil
+		return nil, fmt.Errorf("unknown account: %d", id)
+	}
+	if account.ID == s.errorOnID {
+		return nil, fmt.Errorf("rejecting for bad ID: %d", account.ID)
 	}
 
-	// this is weird, but we can return "unaffected" if the secret already exists
-	// to approximate the failure mode for testing.
-	if account.TOTPSecret.Valid {
-		return false, nil
+	s.lastTOTPID++
+	authenticator := &models.TOTPAuthenticator{
+		ID:           s.lastTOTPID,
+		AccountID:    id,
+		Name:         name,
+		Secret:       string(secret),
+		CreatedAt:    time.Now(),
+		LastUsedStep: step,
 	}
+	s.totpByID[id] = append(s.totpByID[id], authenticator)
+	account.TOTPAuthenticators = len(s.totpByID[id])
 
-	if account.ID == s.errorOnID {
-		return false, fmt.Errorf("rejecting for bad ID: %d", account.ID)
+	dup := *authenticator
+	return &dup, nil
+}
+
+func (s *accountStore) GetTOTPAuthenticators(ctx context.Context, id int) ([]*models.TOTPAuthenticator, error) {
+	authenticators := []*models.TOTPAuthenticator{}
+	for _, authenticator := range s.totpByID[id] {
+		dup := *authenticator
+		authenticators = append(authenticators, &dup)
 	}
+	return authenticators, nil
+}
+
+func (s *accountStore) SetTOTPAuthenticatorLastUsed(ctx context.Context, id int, authenticatorID int, step int64) (bool, error) {
+	for _, authenticator := range s.totpByID[id] {
+		if authenticator.ID == authenticatorID {
+			if authenticator.LastUsedStep >= step {
+				return false, nil
+			}
+			now := time.Now()
+			authenticator.LastUsedAt = &now
+			authenticator.LastUsedStep = step
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *accountStore) DeleteTOTPAuthenticator(ctx context.Context, id int, authenticatorID int) (bool, error) {
+	authenticators := s.totpByID[id]
+	for i, authenticator := range authenticators {
+		if authenticator.ID == authenticatorID {
+			s.totpByID[id] = append(authenticators[:i], authenticators[i+1:]...)
+			s.accountsByID[id].TOTPAuthenticators = len(s.totpByID[id])
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *accountStore) DeleteTOTPAuthenticators(ctx context.Context, id int) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil || len(s.totpByID[id]) == 0 {
+		return false, nil
+	}
+	delete(s.totpByID, id)
+	account.TOTPAuthenticators = 0
+	return true, nil
+}
 
-	account.TOTPSecret = sql.NullString{String: string(secret), Valid: true}
+func (s *accountStore) SetRequireMFA(ctx context.Context, id int, required bool) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil {
+		return false, nil
+	}
+	account.RequireMFA = required
+	account.UpdatedAt = time.Now()
 	return true, nil
 }
 
-func (s *accountStore) DeleteTOTPSecret(id int) (bool, error) {
+func (s *accountStore) SetOTPChannel(ctx context.Context, id int, channel string) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
 		return false, nil
 	}
-	deleted := false
-	if account.TOTPSecret.Valid {
-		account.TOTPSecret = sql.NullString{}
-		deleted = true
+	account.OTPChannel = sql.NullString{String: channel, Valid: channel != ""}
+	account.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (s *accountStore) AddPersonalAccessToken(ctx context.Context, id int, name string, tokenHash string, scopes models.NameList, audience string, expiresAt *time.Time) (*models.PersonalAccessToken, error) {
+	if s.accountsByID[id] == nil {
+		return nil, fmt.Errorf("unknown account: %d", id)
+	}
+	for _, tokens := range s.patsByID {
+		for _, token := range tokens {
+			if token.TokenHash == tokenHash {
+				return nil, Error{ErrNotUnique}
+			}
+		}
+	}
+
+	s.lastPATID++
+	token := &models.PersonalAccessToken{
+		ID:        s.lastPATID,
+		AccountID: id,
+		Name:      name,
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		Audience:  audience,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+	s.patsByID[id] = append(s.patsByID[id], token)
+
+	dup := *token
+	return &dup, nil
+}
+
+func (s *accountStore) GetPersonalAccessTokens(ctx context.Context, id int) ([]*models.PersonalAccessToken, error) {
+	tokens := []*models.PersonalAccessToken{}
+	for _, token := range s.patsByID[id] {
+		dup := *token
+		tokens = append(tokens, &dup)
+	}
+	return tokens, nil
+}
+
+func (s *accountStore) FindPersonalAccessToken(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	for _, tokens := range s.patsByID {
+		for _, token := range tokens {
+			if token.TokenHash == tokenHash {
+				dup := *token
+				return &dup, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *accountStore) SetPersonalAccessTokenLastUsed(ctx context.Context, tokenID int) (bool, error) {
+	for _, tokens := range s.patsByID {
+		for _, token := range tokens {
+			if token.ID == tokenID {
+				now := time.Now()
+				token.LastUsedAt = &now
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (s *accountStore) DeletePersonalAccessToken(ctx context.Context, id int, tokenID int) (bool, error) {
+	tokens := s.patsByID[id]
+	for i, token := range tokens {
+		if token.ID == tokenID {
+			s.patsByID[id] = append(tokens[:i], tokens[i+1:]...)
+			return true, nil
+		}
 	}
-	return deleted, nil
+	return false, nil
 }
 
 // i think this works? i want to avoid accidentally giving callers the ability
@@ -287,3 +437,8 @@ func (s *accountStore) DeleteTOTPSecret(id int) (bool, error) {
 func dupAccount(acct models.Account) *models.Account {
 	return &acct
 }
+
+// usernameKey indexes usernames case-insensitively within a tenant.
+func usernameKey(tenant string, u string) string {
+	return tenant + "|" + strings.ToLower(u)
+}
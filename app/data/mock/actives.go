@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"context"
 	"strconv"
 	"time"
 )
@@ -19,7 +20,7 @@ func NewActives() *actives {
 	}
 }
 
-func (a *actives) Track(accountID int) error {
+func (a *actives) Track(ctx context.Context, accountID int) error {
 	t := time.Now().In(time.UTC)
 	a.byDay = appendUniq(a.byDay, dayKey(t), accountID)
 	a.byWeek = appendUniq(a.byWeek, weekKey(t), accountID)
@@ -28,15 +29,15 @@ func (a *actives) Track(accountID int) error {
 	return nil
 }
 
-func (a *actives) ActivesByDay() (map[string]int, error) {
+func (a *actives) ActivesByDay(ctx context.Context) (map[string]int, error) {
 	return countUniqs(a.byDay), nil
 }
 
-func (a *actives) ActivesByWeek() (map[string]int, error) {
+func (a *actives) ActivesByWeek(ctx context.Context) (map[string]int, error) {
 	return countUniqs(a.byWeek), nil
 }
 
-func (a *actives) ActivesByMonth() (map[string]int, error) {
+func (a *actives) ActivesByMonth(ctx context.Context) (map[string]int, error) {
 	return countUniqs(a.byMonth), nil
 }
 
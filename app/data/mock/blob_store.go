@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"context"
+	"strings"
 	"sync"
 	"time"
 )
@@ -12,7 +14,10 @@ type BlobStore struct {
 	LockTime time.Duration
 }
 
-func (bs *BlobStore) Delete(name string) error {
+func (bs *BlobStore) Delete(ctx context.Context, name string) error {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
 	delete(bs.blobs, name)
 	return nil
 }
@@ -28,7 +33,10 @@ func NewBlobStore(ttl time.Duration, lockTime time.Duration) *BlobStore {
 	}
 }
 
-func (bs *BlobStore) Read(name string) ([]byte, error) {
+func (bs *BlobStore) Read(ctx context.Context, name string) ([]byte, error) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
 	val := bs.blobs[name]
 	if string(val) == placeholder {
 		return nil, nil
@@ -36,7 +44,7 @@ func (bs *BlobStore) Read(name string) ([]byte, error) {
 	return val, nil
 }
 
-func (bs *BlobStore) WriteNX(name string, blob []byte) (bool, error) {
+func (bs *BlobStore) WriteNX(ctx context.Context, name string, blob []byte) (bool, error) {
 	bs.mutex.Lock()
 	defer bs.mutex.Unlock()
 
@@ -47,10 +55,34 @@ func (bs *BlobStore) WriteNX(name string, blob []byte) (bool, error) {
 	return true, nil
 }
 
-func (bs *BlobStore) Write(name string, blob []byte) (bool, error) {
+func (bs *BlobStore) Write(ctx context.Context, name string, blob []byte) (bool, error) {
 	bs.mutex.Lock()
 	defer bs.mutex.Unlock()
 
 	bs.blobs[name] = blob
 	return true, nil
 }
+
+func (bs *BlobStore) Rewrite(ctx context.Context, name string, blob []byte) (bool, error) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	if bs.blobs[name] == nil {
+		return false, nil
+	}
+	bs.blobs[name] = blob
+	return true, nil
+}
+
+func (bs *BlobStore) Names(ctx context.Context, prefix string) ([]string, error) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+
+	names := []string{}
+	for name := range bs.blobs {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
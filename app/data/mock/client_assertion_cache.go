@@ -0,0 +1,24 @@
+package mock
+
+import (
+	"context"
+)
+
+type ClientAssertions struct {
+	used map[string]bool
+}
+
+func NewClientAssertionCache() *ClientAssertions {
+	return &ClientAssertions{
+		used: make(map[string]bool),
+	}
+}
+
+func (m ClientAssertions) UseClientAssertion(ctx context.Context, clientID string, jti string) (bool, error) {
+	key := clientID + ":" + jti
+	if m.used[key] {
+		return false, nil
+	}
+	m.used[key] = true
+	return true, nil
+}
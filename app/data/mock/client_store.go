@@ -0,0 +1,74 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+type clientStore struct {
+	clients map[string]*models.Client
+}
+
+func NewClientStore() *clientStore {
+	return &clientStore{
+		clients: make(map[string]*models.Client),
+	}
+}
+
+func (s *clientStore) Create(ctx context.Context, client *models.Client) error {
+	if s.clients[client.ID] != nil {
+		return Error{ErrNotUnique}
+	}
+
+	now := time.Now()
+	client.CreatedAt = now
+	client.UpdatedAt = now
+	s.clients[client.ID] = dupClient(*client)
+	return nil
+}
+
+func (s *clientStore) Find(ctx context.Context, id string) (*models.Client, error) {
+	client := s.clients[id]
+	if client == nil {
+		return nil, nil
+	}
+	return dupClient(*client), nil
+}
+
+func (s *clientStore) FindAll(ctx context.Context) ([]*models.Client, error) {
+	clients := []*models.Client{}
+	for _, client := range s.clients {
+		clients = append(clients, dupClient(*client))
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].CreatedAt.Before(clients[j].CreatedAt)
+	})
+	return clients, nil
+}
+
+func (s *clientStore) Update(ctx context.Context, id string, name string, scopes models.NameList, audiences models.NameList) (bool, error) {
+	client := s.clients[id]
+	if client == nil {
+		return false, nil
+	}
+	client.Name = name
+	client.Scopes = scopes
+	client.Audiences = audiences
+	client.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (s *clientStore) Delete(ctx context.Context, id string) (bool, error) {
+	if s.clients[id] == nil {
+		return false, nil
+	}
+	delete(s.clients, id)
+	return true, nil
+}
+
+func dupClient(client models.Client) *models.Client {
+	return &client
+}
@@ -0,0 +1,71 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+type DeviceCodes struct {
+	byDeviceCode map[string]models.DeviceAuthorization
+	byUserCode   map[string]string
+	approvals    map[string]models.DeviceAuthorization
+	claims       map[string]bool
+}
+
+func NewDeviceCodeCache() *DeviceCodes {
+	return &DeviceCodes{
+		byDeviceCode: make(map[string]models.DeviceAuthorization),
+		byUserCode:   make(map[string]string),
+		approvals:    make(map[string]models.DeviceAuthorization),
+		claims:       make(map[string]bool),
+	}
+}
+
+func (m DeviceCodes) CacheDeviceAuthorization(ctx context.Context, deviceCode string, authorization *models.DeviceAuthorization) error {
+	m.byDeviceCode[deviceCode] = *authorization
+	m.byUserCode[authorization.UserCode] = deviceCode
+	return nil
+}
+
+func (m DeviceCodes) LoadDeviceAuthorization(ctx context.Context, deviceCode string) (*models.DeviceAuthorization, error) {
+	authorization, ok := m.byDeviceCode[deviceCode]
+	if !ok {
+		return nil, nil
+	}
+	if approval, ok := m.approvals[deviceCode]; ok {
+		authorization.AccountID = approval.AccountID
+		authorization.Audience = approval.Audience
+		authorization.AuthMethodReference = approval.AuthMethodReference
+	}
+	return &authorization, nil
+}
+
+func (m DeviceCodes) FindDeviceCode(ctx context.Context, userCode string) (string, error) {
+	return m.byUserCode[userCode], nil
+}
+
+func (m DeviceCodes) RemoveDeviceAuthorization(ctx context.Context, deviceCode string) error {
+	if authorization, ok := m.byDeviceCode[deviceCode]; ok {
+		delete(m.byUserCode, authorization.UserCode)
+		delete(m.byDeviceCode, deviceCode)
+		delete(m.approvals, deviceCode)
+	}
+	return nil
+}
+
+func (m DeviceCodes) ApproveDeviceAuthorization(ctx context.Context, deviceCode string, authorization *models.DeviceAuthorization) (bool, error) {
+	if _, ok := m.approvals[deviceCode]; ok {
+		return false, nil
+	}
+	m.approvals[deviceCode] = *authorization
+	return true, nil
+}
+
+func (m DeviceCodes) ClaimDeviceAuthorization(ctx context.Context, deviceCode string) (bool, error) {
+	if m.claims[deviceCode] {
+		return false, nil
+	}
+	m.claims[deviceCode] = true
+	return true, m.RemoveDeviceAuthorization(ctx, deviceCode)
+}
@@ -0,0 +1,27 @@
+package mock
+
+import (
+	"context"
+)
+
+type invitationStore struct {
+	acceptedByID map[string]int
+}
+
+func NewInvitationStore() *invitationStore {
+	return &invitationStore{
+		acceptedByID: make(map[string]int),
+	}
+}
+
+func (s *invitationStore) Accept(ctx context.Context, id string, accountID int) error {
+	if s.acceptedByID[id] != 0 {
+		return Error{ErrNotUnique}
+	}
+	s.acceptedByID[id] = accountID
+	return nil
+}
+
+func (s *invitationStore) FindAccepted(ctx context.Context, id string) (int, error) {
+	return s.acceptedByID[id], nil
+}
@@ -0,0 +1,15 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/testers"
+)
+
+func TestInvitationStore(t *testing.T) {
+	for _, tester := range testers.InvitationStoreTesters {
+		store := mock.NewInvitationStore()
+		tester(t, store)
+	}
+}
@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+)
+
+type MFAChallenges struct {
+	used map[string]bool
+}
+
+func NewMFAChallengeCache() *MFAChallenges {
+	return &MFAChallenges{
+		used: make(map[string]bool),
+	}
+}
+
+func (m MFAChallenges) UseMFAChallenge(ctx context.Context, accountID int, jti string) (bool, error) {
+	key := fmt.Sprintf("%d:%s", accountID, jti)
+	if m.used[key] {
+		return false, nil
+	}
+	m.used[key] = true
+	return true, nil
+}
@@ -0,0 +1,137 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+type organizationStore struct {
+	organizationsByID map[int]*models.Organization
+	memberships       []*models.Membership
+}
+
+func NewOrganizationStore() *organizationStore {
+	return &organizationStore{
+		organizationsByID: make(map[int]*models.Organization),
+	}
+}
+
+func (s *organizationStore) Create(ctx context.Context, name string) (*models.Organization, error) {
+	now := time.Now()
+	organization := models.Organization{
+		ID:        len(s.organizationsByID) + 1,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.organizationsByID[organization.ID] = &organization
+	return dupOrganization(organization), nil
+}
+
+func (s *organizationStore) Find(ctx context.Context, id int) (*models.Organization, error) {
+	organization := s.organizationsByID[id]
+	if organization == nil {
+		return nil, nil
+	}
+	return dupOrganization(*organization), nil
+}
+
+func (s *organizationStore) Update(ctx context.Context, id int, name string) (bool, error) {
+	organization := s.organizationsByID[id]
+	if organization == nil {
+		return false, nil
+	}
+	organization.Name = name
+	organization.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (s *organizationStore) Delete(ctx context.Context, id int) (bool, error) {
+	organization := s.organizationsByID[id]
+	if organization == nil {
+		return false, nil
+	}
+	delete(s.organizationsByID, id)
+
+	memberships := []*models.Membership{}
+	for _, membership := range s.memberships {
+		if membership.OrganizationID != id {
+			memberships = append(memberships, membership)
+		}
+	}
+	s.memberships = memberships
+	return true, nil
+}
+
+func (s *organizationStore) SetMembership(ctx context.Context, organizationID int, accountID int, roles models.NameList) error {
+	now := time.Now()
+	for _, membership := range s.memberships {
+		if membership.OrganizationID == organizationID && membership.AccountID == accountID {
+			membership.Roles = roles
+			membership.UpdatedAt = now
+			return nil
+		}
+	}
+
+	s.memberships = append(s.memberships, &models.Membership{
+		OrganizationID: organizationID,
+		AccountID:      accountID,
+		Roles:          roles,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+	return nil
+}
+
+func (s *organizationStore) FindMembership(ctx context.Context, organizationID int, accountID int) (*models.Membership, error) {
+	for _, membership := range s.memberships {
+		if membership.OrganizationID == organizationID && membership.AccountID == accountID {
+			return s.dupMembership(*membership), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *organizationStore) GetMembers(ctx context.Context, organizationID int) ([]*models.Membership, error) {
+	memberships := []*models.Membership{}
+	for _, membership := range s.memberships {
+		if membership.OrganizationID == organizationID {
+			memberships = append(memberships, s.dupMembership(*membership))
+		}
+	}
+	return memberships, nil
+}
+
+func (s *organizationStore) GetMemberships(ctx context.Context, accountID int) ([]*models.Membership, error) {
+	memberships := []*models.Membership{}
+	for _, membership := range s.memberships {
+		if membership.AccountID == accountID {
+			memberships = append(memberships, s.dupMembership(*membership))
+		}
+	}
+	return memberships, nil
+}
+
+func (s *organizationStore) DeleteMembership(ctx context.Context, organizationID int, accountID int) (bool, error) {
+	for i, membership := range s.memberships {
+		if membership.OrganizationID == organizationID && membership.AccountID == accountID {
+			s.memberships = append(s.memberships[:i], s.memberships[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func dupOrganization(organization models.Organization) *models.Organization {
+	return &organization
+}
+
+// dupMembership copies the membership with the current name of its organization.
+func (s *organizationStore) dupMembership(membership models.Membership) *models.Membership {
+	if organization := s.organizationsByID[membership.OrganizationID]; organization != nil {
+		membership.OrganizationName = organization.Name
+	}
+	return &membership
+}
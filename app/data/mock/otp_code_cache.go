@@ -0,0 +1,54 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+type OTPCodes struct {
+	store    map[int]models.OTPCode
+	attempts map[string]int
+	claims   map[string]bool
+}
+
+func NewOTPCodeCache() *OTPCodes {
+	return &OTPCodes{
+		store:    make(map[int]models.OTPCode),
+		attempts: make(map[string]int),
+		claims:   make(map[string]bool),
+	}
+}
+
+func (m OTPCodes) CacheOTPCode(ctx context.Context, accountID int, code *models.OTPCode) error {
+	m.store[accountID] = *code
+	return nil
+}
+
+func (m OTPCodes) LoadOTPCode(ctx context.Context, accountID int) (*models.OTPCode, error) {
+	code, ok := m.store[accountID]
+	if !ok {
+		return nil, nil
+	}
+	return &code, nil
+}
+
+func (m OTPCodes) RecordOTPAttempt(ctx context.Context, accountID int, code *models.OTPCode, max int) (bool, error) {
+	key := fmt.Sprintf("%d:%s", accountID, code.ID)
+	if m.attempts[key] >= max {
+		return false, nil
+	}
+	m.attempts[key]++
+	return true, nil
+}
+
+func (m OTPCodes) ClaimOTPCode(ctx context.Context, accountID int, code *models.OTPCode) (bool, error) {
+	key := fmt.Sprintf("%d:%s", accountID, code.ID)
+	if m.claims[key] {
+		return false, nil
+	}
+	m.claims[key] = true
+	delete(m.store, accountID)
+	return true, nil
+}
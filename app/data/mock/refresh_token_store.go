@@ -1,25 +1,37 @@
 package mock
 
 import (
+	"context"
 	"encoding/hex"
+	"time"
 
 	"github.com/keratin/authn-server/lib"
 	"github.com/keratin/authn-server/app/models"
 )
 
 type refreshTokenStore struct {
-	tokensByAccount map[int][]models.RefreshToken
-	accountByToken  map[models.RefreshToken]int
+	tokensByAccount     map[int][]models.RefreshToken
+	accountByToken      map[models.RefreshToken]int
+	familyByToken       map[models.RefreshToken]models.RefreshToken
+	accountBySuperseded map[models.RefreshToken]int
+	supersededAt        map[models.RefreshToken]time.Time
+	sessionByToken      map[models.RefreshToken]string
+	tokenBySession      map[string]models.RefreshToken
 }
 
 func NewRefreshTokenStore() *refreshTokenStore {
 	return &refreshTokenStore{
-		tokensByAccount: make(map[int][]models.RefreshToken),
-		accountByToken:  make(map[models.RefreshToken]int),
+		tokensByAccount:     make(map[int][]models.RefreshToken),
+		accountByToken:      make(map[models.RefreshToken]int),
+		familyByToken:       make(map[models.RefreshToken]models.RefreshToken),
+		accountBySuperseded: make(map[models.RefreshToken]int),
+		supersededAt:        make(map[models.RefreshToken]time.Time),
+		sessionByToken:      make(map[models.RefreshToken]string),
+		tokenBySession:      make(map[string]models.RefreshToken),
 	}
 }
 
-func (s *refreshTokenStore) Create(accountID int) (models.RefreshToken, error) {
+func (s *refreshTokenStore) Create(ctx context.Context, accountID int) (models.RefreshToken, error) {
 	binToken, err := lib.GenerateToken()
 	if err != nil {
 		return "", err
@@ -30,19 +42,19 @@ func (s *refreshTokenStore) Create(accountID int) (models.RefreshToken, error) {
 	return token, nil
 }
 
-func (s *refreshTokenStore) Find(t models.RefreshToken) (int, error) {
+func (s *refreshTokenStore) Find(ctx context.Context, t models.RefreshToken) (int, error) {
 	return s.accountByToken[t], nil
 }
 
-func (s *refreshTokenStore) Touch(t models.RefreshToken, accountID int) error {
+func (s *refreshTokenStore) Touch(ctx context.Context, t models.RefreshToken, accountID int) error {
 	return nil
 }
 
-func (s *refreshTokenStore) FindAll(accountID int) ([]models.RefreshToken, error) {
+func (s *refreshTokenStore) FindAll(ctx context.Context, accountID int) ([]models.RefreshToken, error) {
 	return s.tokensByAccount[accountID], nil
 }
 
-func (s *refreshTokenStore) Revoke(t models.RefreshToken) error {
+func (s *refreshTokenStore) Revoke(ctx context.Context, t models.RefreshToken) error {
 	accountID := s.accountByToken[t]
 	if accountID != 0 {
 		delete(s.accountByToken, t)
@@ -51,6 +63,71 @@ func (s *refreshTokenStore) Revoke(t models.RefreshToken) error {
 	return nil
 }
 
+func (s *refreshTokenStore) Rotate(ctx context.Context, t models.RefreshToken, accountID int) (models.RefreshToken, error) {
+	if s.accountByToken[t] == 0 {
+		return "", nil
+	}
+	family, ok := s.familyByToken[t]
+	if !ok {
+		family = t
+	}
+
+	token, err := s.Create(ctx, accountID)
+	if err != nil {
+		return "", err
+	}
+	err = s.Revoke(ctx, t)
+	if err != nil {
+		return "", err
+	}
+	s.familyByToken[t] = family
+	s.familyByToken[token] = family
+	s.accountBySuperseded[t] = accountID
+	s.supersededAt[t] = time.Now()
+	if sessionID, ok := s.sessionByToken[t]; ok {
+		err = s.SetSessionID(ctx, token, sessionID)
+		if err != nil {
+			return "", err
+		}
+	}
+	return token, nil
+}
+
+func (s *refreshTokenStore) RevokeFamily(ctx context.Context, t models.RefreshToken, grace time.Duration) (int, error) {
+	accountID := s.accountBySuperseded[t]
+	if accountID == 0 || time.Since(s.supersededAt[t]) < grace {
+		return 0, nil
+	}
+	family := s.familyByToken[t]
+	for token, f := range s.familyByToken {
+		if f == family {
+			err := s.Revoke(ctx, token)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	return accountID, nil
+}
+
+func (s *refreshTokenStore) SetSessionID(ctx context.Context, t models.RefreshToken, sessionID string) error {
+	s.sessionByToken[t] = sessionID
+	s.tokenBySession[sessionID] = t
+	return nil
+}
+
+func (s *refreshTokenStore) FindBySessionID(ctx context.Context, sessionID string) (models.RefreshToken, error) {
+	token := s.tokenBySession[sessionID]
+	if s.accountByToken[token] == 0 {
+		return "", nil
+	}
+	return token, nil
+}
+
+func (s *refreshTokenStore) FindSessionID(ctx context.Context, t models.RefreshToken) (string, error) {
+	return s.sessionByToken[t], nil
+}
+
 func without(needle models.RefreshToken, haystack []models.RefreshToken) []models.RefreshToken {
 	for idx, elem := range haystack {
 		if elem == needle {
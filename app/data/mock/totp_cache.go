@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -16,7 +17,7 @@ func NewTOTPCache(errorOnID int) *TOTP {
 	}
 }
 
-func (m TOTP) CacheTOTPSecret(accountID int, secret []byte) error {
+func (m TOTP) CacheTOTPSecret(ctx context.Context, accountID int, secret []byte) error {
 	if accountID == m.errorOnID {
 		return fmt.Errorf("error forced by ID: %d", accountID)
 	}
@@ -24,7 +25,7 @@ func (m TOTP) CacheTOTPSecret(accountID int, secret []byte) error {
 	return nil
 }
 
-func (m TOTP) LoadTOTPSecret(accountID int) ([]byte, error) {
+func (m TOTP) LoadTOTPSecret(ctx context.Context, accountID int) ([]byte, error) {
 	if accountID == m.errorOnID {
 		return nil, fmt.Errorf("error forced by ID: %d", accountID)
 	}
@@ -35,7 +36,7 @@ func (m TOTP) LoadTOTPSecret(accountID int) ([]byte, error) {
 	return r, nil
 }
 
-func (m TOTP) RemoveTOTPSecret(accountID int) error {
+func (m TOTP) RemoveTOTPSecret(ctx context.Context, accountID int) error {
 	if accountID == m.errorOnID {
 		return fmt.Errorf("error forced by ID: %d", accountID)
 	}
@@ -0,0 +1,80 @@
+package mock
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib"
+)
+
+type trustedDeviceStore struct {
+	ttl     time.Duration
+	devices map[string]*models.TrustedDevice
+}
+
+func NewTrustedDeviceStore(ttl time.Duration) *trustedDeviceStore {
+	return &trustedDeviceStore{
+		ttl:     ttl,
+		devices: make(map[string]*models.TrustedDevice),
+	}
+}
+
+func (s *trustedDeviceStore) Create(ctx context.Context, accountID int, name string) (*models.TrustedDevice, error) {
+	binID, err := lib.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	device := &models.TrustedDevice{
+		ID:        hex.EncodeToString(binID),
+		AccountID: accountID,
+		Name:      name,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	s.devices[device.ID] = device
+	return dupDevice(*device), nil
+}
+
+func (s *trustedDeviceStore) Find(ctx context.Context, id string) (*models.TrustedDevice, error) {
+	device := s.devices[id]
+	if device == nil || device.ExpiresAt.Before(time.Now()) {
+		return nil, nil
+	}
+	return dupDevice(*device), nil
+}
+
+func (s *trustedDeviceStore) FindAll(ctx context.Context, accountID int) ([]*models.TrustedDevice, error) {
+	devices := []*models.TrustedDevice{}
+	for _, device := range s.devices {
+		if device.AccountID == accountID && device.ExpiresAt.After(time.Now()) {
+			devices = append(devices, dupDevice(*device))
+		}
+	}
+	return devices, nil
+}
+
+func (s *trustedDeviceStore) Revoke(ctx context.Context, accountID int, id string) (bool, error) {
+	device := s.devices[id]
+	if device == nil || device.AccountID != accountID {
+		return false, nil
+	}
+	delete(s.devices, id)
+	return true, nil
+}
+
+func (s *trustedDeviceStore) RevokeAll(ctx context.Context, accountID int) error {
+	for id, device := range s.devices {
+		if device.AccountID == accountID {
+			delete(s.devices, id)
+		}
+	}
+	return nil
+}
+
+func dupDevice(device models.TrustedDevice) *models.TrustedDevice {
+	return &device
+}
@@ -0,0 +1,16 @@
+package mock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/testers"
+)
+
+func TestTrustedDeviceStore(t *testing.T) {
+	for _, tester := range testers.TrustedDeviceStoreTesters {
+		store := mock.NewTrustedDeviceStore(time.Minute)
+		tester(t, store)
+	}
+}
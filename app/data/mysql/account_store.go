@@ -1,20 +1,30 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tenants"
 )
 
 type AccountStore struct {
-	sqlx.Ext
+	sqlx.ExtContext
 }
 
-func (db *AccountStore) Find(id int) (*models.Account, error) {
+// selectAccounts loads accounts along with the number of TOTP authenticators for each.
+const selectAccounts = "SELECT a.*, (SELECT COUNT(*) FROM totp_authenticators t WHERE t.account_id = a.id) AS totp_authenticators FROM accounts a"
+
+func (db *AccountStore) Find(ctx context.Context, id int) (*models.Account, error) {
+	query, args := selectAccounts+" WHERE a.id = ?", []interface{}{id}
+	if tenant, ok := tenants.FromContext(ctx); ok {
+		query, args = query+" AND a.tenant = ?", append(args, tenant)
+	}
+
 	account := models.Account{}
-	err := sqlx.Get(db, &account, "SELECT * FROM accounts WHERE id = ?", id)
+	err := sqlx.GetContext(ctx, db, &account, query, args...)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
@@ -26,9 +36,9 @@ func (db *AccountStore) Find(id int) (*models.Account, error) {
 	return &account, nil
 }
 
-func (db *AccountStore) FindByUsername(u string) (*models.Account, error) {
+func (db *AccountStore) FindByUsername(ctx context.Context, u string) (*models.Account, error) {
 	account := models.Account{}
-	err := sqlx.Get(db, &account, "SELECT * FROM accounts WHERE username = ? AND deleted_at IS NULL", u)
+	err := sqlx.GetContext(ctx, db, &account, selectAccounts+" WHERE a.username = ? AND a.tenant = ? AND a.deleted_at IS NULL", u, tenants.Name(ctx))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
@@ -37,9 +47,14 @@ func (db *AccountStore) FindByUsername(u string) (*models.Account, error) {
 	return &account, nil
 }
 
-func (db *AccountStore) FindByOauthAccount(provider string, providerID string) (*models.Account, error) {
+func (db *AccountStore) FindByOauthAccount(ctx context.Context, provider string, providerID string) (*models.Account, error) {
+	query, args := selectAccounts+" INNER JOIN oauth_accounts oa ON a.id = oa.account_id WHERE oa.provider = ? AND oa.provider_id = ?", []interface{}{provider, providerID}
+	if tenant, ok := tenants.FromContext(ctx); ok {
+		query, args = query+" AND a.tenant = ?", append(args, tenant)
+	}
+
 	account := models.Account{}
-	err := sqlx.Get(db, &account, "SELECT a.* FROM accounts a INNER JOIN oauth_accounts oa ON a.id = oa.account_id WHERE oa.provider = ? AND oa.provider_id = ?", provider, providerID)
+	err := sqlx.GetContext(ctx, db, &account, query, args...)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
@@ -48,10 +63,11 @@ func (db *AccountStore) FindByOauthAccount(provider string, providerID string) (
 	return &account, nil
 }
 
-func (db *AccountStore) Create(u string, p []byte) (*models.Account, error) {
+func (db *AccountStore) Create(ctx context.Context, u string, p []byte) (*models.Account, error) {
 	now := time.Now()
 
 	account := &models.Account{
+		Tenant:            tenants.Name(ctx),
 		Username:          u,
 		Password:          p,
 		PasswordChangedAt: now,
@@ -59,8 +75,8 @@ func (db *AccountStore) Create(u string, p []byte) (*models.Account, error) {
 		UpdatedAt:         now,
 	}
 
-	result, err := sqlx.NamedExec(db,
-		"INSERT INTO accounts (username, password, locked, require_new_password, password_changed_at, created_at, updated_at) VALUES (:username, :password, :locked, :require_new_password, :password_changed_at, :created_at, :updated_at)",
+	result, err := sqlx.NamedExecContext(ctx, db,
+		"INSERT INTO accounts (tenant, username, password, locked, require_new_password, password_changed_at, created_at, updated_at) VALUES (:tenant, :username, :password, :locked, :require_new_password, :password_changed_at, :created_at, :updated_at)",
 		account,
 	)
 	if err != nil {
@@ -76,10 +92,10 @@ func (db *AccountStore) Create(u string, p []byte) (*models.Account, error) {
 	return account, nil
 }
 
-func (db *AccountStore) AddOauthAccount(accountID int, provider, providerID, email, accessToken string) error {
+func (db *AccountStore) AddOauthAccount(ctx context.Context, accountID int, provider, providerID, email, accessToken string) error {
 	now := time.Now()
 
-	_, err := sqlx.NamedExec(db, `
+	_, err := sqlx.NamedExecContext(ctx, db, `
         INSERT INTO oauth_accounts (account_id, provider, provider_id, email, access_token, created_at, updated_at)
         VALUES (:account_id, :provider, :provider_id, :email, :access_token, :created_at, :updated_at)
     `, map[string]interface{}{
@@ -94,14 +110,14 @@ func (db *AccountStore) AddOauthAccount(accountID int, provider, providerID, ema
 	return err
 }
 
-func (db *AccountStore) GetOauthAccounts(accountID int) ([]*models.OauthAccount, error) {
+func (db *AccountStore) GetOauthAccounts(ctx context.Context, accountID int) ([]*models.OauthAccount, error) {
 	accounts := []*models.OauthAccount{}
-	err := sqlx.Select(db, &accounts, `SELECT * FROM oauth_accounts WHERE account_id = ?`, accountID)
+	err := sqlx.SelectContext(ctx, db, &accounts, `SELECT * FROM oauth_accounts WHERE account_id = ?`, accountID)
 	return accounts, err
 }
 
-func (db *AccountStore) UpdateOauthAccount(accountId int, provider, email string) (bool, error) {
-	result, err := db.Exec("UDPATE oauth_accounts SET email = ? WHERE account_id = ? AND provider = ?", email, accountId, provider)
+func (db *AccountStore) UpdateOauthAccount(ctx context.Context, accountId int, provider, email string) (bool, error) {
+	result, err := db.ExecContext(ctx, "UDPATE oauth_accounts SET email = ? WHERE account_id = ? AND provider = ?", email, accountId, provider)
 	if err != nil {
 		return false, err
 	}
@@ -109,8 +125,8 @@ func (db *AccountStore) UpdateOauthAccount(accountId int, provider, email string
 	return ok(result, err)
 }
 
-func (db *AccountStore) DeleteOauthAccount(accountId int, provider string) (bool, error) {
-	result, err := db.Exec("DELETE FROM oauth_accounts WHERE account_id = ? AND provider = ?", accountId, provider)
+func (db *AccountStore) DeleteOauthAccount(ctx context.Context, accountId int, provider string) (bool, error) {
+	result, err := db.ExecContext(ctx, "DELETE FROM oauth_accounts WHERE account_id = ? AND provider = ?", accountId, provider)
 	if err != nil {
 		return false, err
 	}
@@ -118,52 +134,170 @@ func (db *AccountStore) DeleteOauthAccount(accountId int, provider string) (bool
 	return ok(result, err)
 }
 
-func (db *AccountStore) Archive(id int) (bool, error) {
-	_, err := db.Exec("DELETE FROM oauth_accounts WHERE account_id = ?", id)
+func (db *AccountStore) Archive(ctx context.Context, id int) (bool, error) {
+	_, err := db.ExecContext(ctx, "DELETE FROM oauth_accounts WHERE account_id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	_, err = db.ExecContext(ctx, "DELETE FROM totp_authenticators WHERE account_id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	_, err = db.ExecContext(ctx, "DELETE FROM personal_access_tokens WHERE account_id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	_, err = db.ExecContext(ctx, "DELETE FROM organization_memberships WHERE account_id = ?", id)
 	if err != nil {
 		return false, err
 	}
-	result, err := db.Exec("UPDATE accounts SET username = CONCAT('@', MD5(RAND())), password = ?, deleted_at = ? WHERE id = ?", "", time.Now(), id)
+	result, err := db.ExecContext(ctx, "UPDATE accounts SET username = CONCAT('@', MD5(RAND())), password = ?, deleted_at = ? WHERE id = ?", "", time.Now(), id)
 	return ok(result, err)
 }
 
-func (db *AccountStore) Lock(id int) (bool, error) {
-	result, err := db.Exec("UPDATE accounts SET locked = ?, updated_at = ? WHERE id = ?", true, time.Now(), id)
+func (db *AccountStore) Lock(ctx context.Context, id int) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE accounts SET locked = ?, updated_at = ? WHERE id = ?", true, time.Now(), id)
 	return ok(result, err)
 }
 
-func (db *AccountStore) Unlock(id int) (bool, error) {
-	result, err := db.Exec("UPDATE accounts SET locked = ?, updated_at = ? WHERE id = ?", false, time.Now(), id)
+func (db *AccountStore) Unlock(ctx context.Context, id int) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE accounts SET locked = ?, updated_at = ? WHERE id = ?", false, time.Now(), id)
+	return ok(result, err)
+}
+
+func (db *AccountStore) RequireNewPassword(ctx context.Context, id int) (bool, error) {
+	_, err := db.ExecContext(ctx, "DELETE FROM totp_authenticators WHERE account_id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	result, err := db.ExecContext(ctx, "UPDATE accounts SET require_new_password = ?, updated_at = ? WHERE id = ?", true, time.Now(), id)
 	return ok(result, err)
 }
 
-func (db *AccountStore) RequireNewPassword(id int) (bool, error) {
-	result, err := db.Exec("UPDATE accounts SET require_new_password = ?, updated_at = ?, totp_secret = null WHERE id = ?", true, time.Now(), id)
+func (db *AccountStore) SetPassword(ctx context.Context, id int, p []byte) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE accounts SET password = ?, require_new_password = ?, password_changed_at = ?, updated_at = ? WHERE id = ?", p, false, time.Now(), time.Now(), id)
 	return ok(result, err)
 }
 
-func (db *AccountStore) SetPassword(id int, p []byte) (bool, error) {
-	result, err := db.Exec("UPDATE accounts SET password = ?, require_new_password = ?, password_changed_at = ?, updated_at = ? WHERE id = ?", p, false, time.Now(), time.Now(), id)
+func (db *AccountStore) UpdateUsername(ctx context.Context, id int, u string) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE accounts SET username = ?, updated_at = ? WHERE id = ?", u, time.Now(), id)
 	return ok(result, err)
 }
 
-func (db *AccountStore) UpdateUsername(id int, u string) (bool, error) {
-	result, err := db.Exec("UPDATE accounts SET username = ?, updated_at = ? WHERE id = ?", u, time.Now(), id)
+func (db *AccountStore) SetLastLogin(ctx context.Context, id int) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE accounts SET last_login_at = ? WHERE id = ?", time.Now(), id)
 	return ok(result, err)
 }
 
-func (db *AccountStore) SetLastLogin(id int) (bool, error) {
-	result, err := db.Exec("UPDATE accounts SET last_login_at = ? WHERE id = ?", time.Now(), id)
+func (db *AccountStore) AddTOTPAuthenticator(ctx context.Context, accountID int, name string, secret []byte, step int64) (*models.TOTPAuthenticator, error) {
+	authenticator := &models.TOTPAuthenticator{
+		AccountID:    accountID,
+		Name:         name,
+		Secret:       string(secret),
+		CreatedAt:    time.Now(),
+		LastUsedStep: step,
+	}
+
+	result, err := sqlx.NamedExecContext(ctx, db,
+		"INSERT INTO totp_authenticators (account_id, name, secret, created_at, last_used_step) VALUES (:account_id, :name, :secret, :created_at, :last_used_step)",
+		authenticator,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	authenticator.ID = int(id)
+
+	return authenticator, nil
+}
+
+func (db *AccountStore) GetTOTPAuthenticators(ctx context.Context, accountID int) ([]*models.TOTPAuthenticator, error) {
+	authenticators := []*models.TOTPAuthenticator{}
+	err := sqlx.SelectContext(ctx, db, &authenticators, "SELECT * FROM totp_authenticators WHERE account_id = ? ORDER BY id", accountID)
+	return authenticators, err
+}
+
+func (db *AccountStore) SetTOTPAuthenticatorLastUsed(ctx context.Context, accountID int, authenticatorID int, step int64) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE totp_authenticators SET last_used_at = ?, last_used_step = ? WHERE account_id = ? AND id = ? AND last_used_step < ?", time.Now(), step, accountID, authenticatorID, step)
 	return ok(result, err)
 }
 
-func (db *AccountStore) SetTOTPSecret(id int, secret []byte) (bool, error) {
-	result, err := db.Exec("UPDATE accounts SET totp_secret = ? WHERE id = ?", secret, id)
+func (db *AccountStore) DeleteTOTPAuthenticator(ctx context.Context, accountID int, authenticatorID int) (bool, error) {
+	result, err := db.ExecContext(ctx, "DELETE FROM totp_authenticators WHERE account_id = ? AND id = ?", accountID, authenticatorID)
+	return ok(result, err)
+}
+
+func (db *AccountStore) DeleteTOTPAuthenticators(ctx context.Context, accountID int) (bool, error) {
+	result, err := db.ExecContext(ctx, "DELETE FROM totp_authenticators WHERE account_id = ?", accountID)
+	return ok(result, err)
+}
+
+func (db *AccountStore) SetRequireMFA(ctx context.Context, id int, required bool) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE accounts SET require_mfa = ?, updated_at = ? WHERE id = ?", required, time.Now(), id)
+	return ok(result, err)
+}
+
+func (db *AccountStore) SetOTPChannel(ctx context.Context, id int, channel string) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE accounts SET otp_channel = ?, updated_at = ? WHERE id = ?", sql.NullString{String: channel, Valid: channel != ""}, time.Now(), id)
+	return ok(result, err)
+}
+
+func (db *AccountStore) AddPersonalAccessToken(ctx context.Context, accountID int, name string, tokenHash string, scopes models.NameList, audience string, expiresAt *time.Time) (*models.PersonalAccessToken, error) {
+	token := &models.PersonalAccessToken{
+		AccountID: accountID,
+		Name:      name,
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		Audience:  audience,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+
+	result, err := sqlx.NamedExecContext(ctx, db,
+		"INSERT INTO personal_access_tokens (account_id, name, token_hash, scopes, audience, created_at, expires_at) VALUES (:account_id, :name, :token_hash, :scopes, :audience, :created_at, :expires_at)",
+		token,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	token.ID = int(id)
+
+	return token, nil
+}
+
+func (db *AccountStore) GetPersonalAccessTokens(ctx context.Context, accountID int) ([]*models.PersonalAccessToken, error) {
+	tokens := []*models.PersonalAccessToken{}
+	err := sqlx.SelectContext(ctx, db, &tokens, "SELECT * FROM personal_access_tokens WHERE account_id = ? ORDER BY id", accountID)
+	return tokens, err
+}
+
+func (db *AccountStore) FindPersonalAccessToken(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	token := models.PersonalAccessToken{}
+	err := sqlx.GetContext(ctx, db, &token, "SELECT * FROM personal_access_tokens WHERE token_hash = ?", tokenHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (db *AccountStore) SetPersonalAccessTokenLastUsed(ctx context.Context, tokenID int) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE personal_access_tokens SET last_used_at = ? WHERE id = ?", time.Now(), tokenID)
 	return ok(result, err)
 }
 
-func (db *AccountStore) DeleteTOTPSecret(id int) (bool, error) {
-	result, err := db.Exec("UPDATE accounts SET totp_secret = NULL WHERE id = ?", id)
+func (db *AccountStore) DeletePersonalAccessToken(ctx context.Context, accountID int, tokenID int) (bool, error) {
+	result, err := db.ExecContext(ctx, "DELETE FROM personal_access_tokens WHERE account_id = ? AND id = ?", accountID, tokenID)
 	return ok(result, err)
 }
 
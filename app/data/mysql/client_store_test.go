@@ -0,0 +1,19 @@
+package mysql_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mysql"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientStore(t *testing.T) {
+	db, err := mysql.TestDB()
+	require.NoError(t, err)
+	store := &mysql.ClientStore{db}
+	for _, tester := range testers.ClientStoreTesters {
+		db.MustExec("TRUNCATE clients")
+		tester(t, store)
+	}
+}
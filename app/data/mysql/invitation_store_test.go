@@ -0,0 +1,19 @@
+package mysql_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mysql"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationStore(t *testing.T) {
+	db, err := mysql.TestDB()
+	require.NoError(t, err)
+	store := &mysql.InvitationStore{db}
+	for _, tester := range testers.InvitationStoreTesters {
+		db.MustExec("TRUNCATE accepted_invitations")
+		tester(t, store)
+	}
+}
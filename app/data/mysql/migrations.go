@@ -16,6 +16,16 @@ func MigrateDB(db *sqlx.DB) error {
 		createAccountLastLoginAtField,
 		createAccountTOTPFields,
 		addOauthAccountEmail,
+		createAccountRequireMFAField,
+		createAccountOTPChannelField,
+		createTOTPAuthenticators,
+		moveTOTPSecrets,
+		createTOTPAuthenticatorLastUsedStepField,
+		createClients,
+		createPersonalAccessTokens,
+		createAccountTenantField,
+		createOrganizations,
+		createAcceptedInvitations,
 	}
 	for _, m := range migrations {
 		if err := m(db); err != nil {
@@ -97,3 +107,171 @@ func createAccountTOTPFields(db *sqlx.DB) error {
 	}
 	return err
 }
+
+func createAccountRequireMFAField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD require_mfa BOOLEAN NOT NULL DEFAULT FALSE
+    `)
+	if mysqlError, ok := err.(*mysql.MySQLError); ok {
+		if mysqlError.Number == 1060 { // 1060 = Duplicate column name
+			err = nil
+		}
+	}
+	return err
+}
+
+func createAccountOTPChannelField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD otp_channel VARCHAR(255) DEFAULT NULL
+    `)
+	if mysqlError, ok := err.(*mysql.MySQLError); ok {
+		if mysqlError.Number == 1060 { // 1060 = Duplicate column name
+			err = nil
+		}
+	}
+	return err
+}
+
+func createTOTPAuthenticators(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS totp_authenticators (
+            id INT(11) NOT NULL AUTO_INCREMENT,
+            account_id INT(11) NOT NULL,
+            name VARCHAR(255) NOT NULL,
+            secret VARCHAR(255) NOT NULL,
+            created_at DATETIME NOT NULL,
+            last_used_at DATETIME DEFAULT NULL,
+            PRIMARY KEY (id),
+            KEY index_totp_authenticators_by_account_id (account_id)
+        )
+    `)
+	return err
+}
+
+// moveTOTPSecrets converts the single TOTP secret that was stored with each account into an
+// authenticator. It runs in a transaction so that secrets are not copied twice.
+func moveTOTPSecrets(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+        INSERT INTO totp_authenticators (account_id, name, secret, created_at)
+        SELECT id, 'Authenticator', totp_secret, updated_at FROM accounts WHERE totp_secret IS NOT NULL AND totp_secret != ''
+    `)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec(`
+        UPDATE accounts SET totp_secret = NULL WHERE totp_secret IS NOT NULL
+    `)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func createTOTPAuthenticatorLastUsedStepField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE totp_authenticators ADD last_used_step BIGINT NOT NULL DEFAULT 0
+    `)
+	if mysqlError, ok := err.(*mysql.MySQLError); ok {
+		if mysqlError.Number == 1060 { // 1060 = Duplicate column name
+			err = nil
+		}
+	}
+	return err
+}
+
+func createClients(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS clients (
+            id VARCHAR(64) NOT NULL,
+            name VARCHAR(255) NOT NULL,
+            secret VARCHAR(255) DEFAULT NULL,
+            public_key TEXT NOT NULL,
+            scopes TEXT NOT NULL,
+            audiences TEXT NOT NULL,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL,
+            PRIMARY KEY (id)
+        ) ENGINE=InnoDB DEFAULT CHARSET=utf8
+    `)
+	return err
+}
+
+func createPersonalAccessTokens(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS personal_access_tokens (
+            id INT(11) NOT NULL AUTO_INCREMENT,
+            account_id INT(11) NOT NULL,
+            name VARCHAR(255) NOT NULL,
+            token_hash VARCHAR(64) NOT NULL,
+            scopes TEXT NOT NULL,
+            audience VARCHAR(255) NOT NULL,
+            created_at DATETIME NOT NULL,
+            expires_at DATETIME DEFAULT NULL,
+            last_used_at DATETIME DEFAULT NULL,
+            PRIMARY KEY (id),
+            UNIQUE KEY index_personal_access_tokens_by_token_hash (token_hash),
+            KEY index_personal_access_tokens_by_account_id (account_id)
+        ) ENGINE=InnoDB DEFAULT CHARSET=utf8
+    `)
+	return err
+}
+
+func createAccountTenantField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts
+            ADD tenant VARCHAR(255) NOT NULL DEFAULT '',
+            DROP INDEX index_accounts_on_username,
+            ADD UNIQUE KEY index_accounts_on_tenant_and_username (tenant, username)
+    `)
+	if mysqlError, ok := err.(*mysql.MySQLError); ok {
+		if mysqlError.Number == 1060 { // 1060 = Duplicate column name
+			err = nil
+		}
+	}
+	return err
+}
+
+func createOrganizations(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS organizations (
+            id INT(11) NOT NULL AUTO_INCREMENT,
+            name VARCHAR(255) NOT NULL,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL,
+            PRIMARY KEY (id)
+        ) ENGINE=InnoDB DEFAULT CHARSET=utf8
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS organization_memberships (
+            organization_id INT(11) NOT NULL,
+            account_id INT(11) NOT NULL,
+            roles TEXT NOT NULL,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL,
+            PRIMARY KEY (organization_id, account_id),
+            KEY index_organization_memberships_by_account_id (account_id)
+        ) ENGINE=InnoDB DEFAULT CHARSET=utf8
+    `)
+	return err
+}
+
+func createAcceptedInvitations(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS accepted_invitations (
+            id VARCHAR(255) NOT NULL,
+            account_id INT(11) NOT NULL,
+            created_at DATETIME NOT NULL,
+            PRIMARY KEY (id)
+        ) ENGINE=InnoDB DEFAULT CHARSET=utf8
+    `)
+	return err
+}
@@ -0,0 +1,55 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/data/mysql"
+	"github.com/keratin/authn-server/app/data/postgres"
+	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/keratin/authn-server/app/models"
+)
+
+type OrganizationStore interface {
+	// Persists a new organization.
+	Create(ctx context.Context, name string) (*models.Organization, error)
+
+	// Finds the organization. A nil value indicates that no organization was found.
+	Find(ctx context.Context, id int) (*models.Organization, error)
+
+	// Replaces the name of the organization.
+	Update(ctx context.Context, id int, name string) (bool, error)
+
+	// Deletes the organization and all of its memberships.
+	Delete(ctx context.Context, id int) (bool, error)
+
+	// Adds the account to the organization, or replaces the roles of an existing member.
+	SetMembership(ctx context.Context, organizationID int, accountID int, roles models.NameList) error
+
+	// Finds the account's membership in the organization. A nil value indicates that the account is
+	// not a member.
+	FindMembership(ctx context.Context, organizationID int, accountID int) (*models.Membership, error)
+
+	// Returns the memberships of the organization, oldest first.
+	GetMembers(ctx context.Context, organizationID int) ([]*models.Membership, error)
+
+	// Returns the memberships of the account, oldest first.
+	GetMemberships(ctx context.Context, accountID int) ([]*models.Membership, error)
+
+	// Removes the account from the organization.
+	DeleteMembership(ctx context.Context, organizationID int, accountID int) (bool, error)
+}
+
+func NewOrganizationStore(db *sqlx.DB) (OrganizationStore, error) {
+	switch db.DriverName() {
+	case "sqlite3":
+		return &sqlite3.OrganizationStore{ExtContext: db}, nil
+	case "mysql":
+		return &mysql.OrganizationStore{ExtContext: db}, nil
+	case "postgres":
+		return &postgres.OrganizationStore{ExtContext: db}, nil
+	default:
+		return nil, fmt.Errorf("unsupported driver: %v", db.DriverName())
+	}
+}
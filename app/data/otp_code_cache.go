@@ -0,0 +1,96 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registerEncryptedBlobPrefix("otp:")
+}
+
+// OTPCodeCache keeps the last one-time code that was delivered to each account. There is at most
+// one code per account, so a new code replaces any previous code.
+//
+// Attempts and claims are recorded with WriteNX so that concurrent requests can not share them.
+type OTPCodeCache interface {
+	CacheOTPCode(ctx context.Context, accountID int, code *models.OTPCode) error
+	LoadOTPCode(ctx context.Context, accountID int) (*models.OTPCode, error)
+	// RecordOTPAttempt counts an attempt against the code, and returns false if the code has
+	// already reached the maximum number of attempts.
+	RecordOTPAttempt(ctx context.Context, accountID int, code *models.OTPCode, max int) (bool, error)
+	// ClaimOTPCode removes the code, and returns false if it was already claimed.
+	ClaimOTPCode(ctx context.Context, accountID int, code *models.OTPCode) (bool, error)
+}
+
+type otpCodeCache struct {
+	ebs *EncryptedBlobStore
+}
+
+func NewOTPCodeCache(ebs *EncryptedBlobStore) OTPCodeCache {
+	return &otpCodeCache{
+		ebs: ebs,
+	}
+}
+
+func (c *otpCodeCache) CacheOTPCode(ctx context.Context, accountID int, code *models.OTPCode) error {
+	blob, err := json.Marshal(code)
+	if err != nil {
+		return errors.Wrap(err, "Marshal")
+	}
+	_, err = c.ebs.Write(ctx, fmt.Sprintf("otp:%d", accountID), blob)
+	if err != nil {
+		return errors.Wrap(err, "CacheOTPCode")
+	}
+	return nil
+}
+
+func (c *otpCodeCache) LoadOTPCode(ctx context.Context, accountID int) (*models.OTPCode, error) {
+	blob, err := c.ebs.Read(ctx, fmt.Sprintf("otp:%d", accountID))
+	if err != nil {
+		return nil, errors.Wrap(err, "LoadOTPCode")
+	}
+	if blob == nil {
+		return nil, nil
+	}
+
+	code := models.OTPCode{}
+	err = json.Unmarshal(blob, &code)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unmarshal")
+	}
+	return &code, nil
+}
+
+func (c *otpCodeCache) RecordOTPAttempt(ctx context.Context, accountID int, code *models.OTPCode, max int) (bool, error) {
+	// each attempt takes the next free slot
+	for n := 0; n < max; n++ {
+		ok, err := c.ebs.WriteNX(ctx, fmt.Sprintf("otp_attempt:%d:%s:%d", accountID, code.ID, n), []byte{})
+		if err != nil {
+			return false, errors.Wrap(err, "RecordOTPAttempt")
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *otpCodeCache) ClaimOTPCode(ctx context.Context, accountID int, code *models.OTPCode) (bool, error) {
+	ok, err := c.ebs.WriteNX(ctx, fmt.Sprintf("otp_claim:%d:%s", accountID, code.ID), []byte{})
+	if err != nil {
+		return false, errors.Wrap(err, "ClaimOTPCode")
+	}
+	if !ok {
+		return false, nil
+	}
+	err = c.ebs.Delete(ctx, fmt.Sprintf("otp:%d", accountID))
+	if err != nil {
+		return false, errors.Wrap(err, "Delete")
+	}
+	return true, nil
+}
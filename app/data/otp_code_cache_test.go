@@ -0,0 +1,57 @@
+package data_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTPCodeCache(t *testing.T) {
+	ebs := data.NewEncryptedBlobStore(mock.NewBlobStore(time.Minute, time.Second), compat.NewKeyring([]byte("secretsecretsecretsecretsecret12")))
+	codes := data.NewOTPCodeCache(ebs)
+	code := &models.OTPCode{ID: "abc", Channel: "email", Hash: []byte("hash"), ExpiresAt: time.Now().Add(time.Minute)}
+	require.NoError(t, codes.CacheOTPCode(context.Background(), 1, code))
+
+	t.Run("concurrent attempts", func(t *testing.T) {
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		counted := 0
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ok, err := codes.RecordOTPAttempt(context.Background(), 1, code, 5)
+				assert.NoError(t, err)
+				if ok {
+					mutex.Lock()
+					counted++
+					mutex.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 5, counted)
+	})
+
+	t.Run("claiming twice", func(t *testing.T) {
+		ok, err := codes.ClaimOTPCode(context.Background(), 1, code)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = codes.ClaimOTPCode(context.Background(), 1, code)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		found, err := codes.LoadOTPCode(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
@@ -1,6 +1,7 @@
 package postgres_test
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
@@ -46,10 +47,10 @@ func TestAccountStore(t *testing.T) {
 	}
 
 	t.Run("handle oauth email with null value", func(t *testing.T) {
-		account, err := store.Create("migrated-user", []byte("old"))
+		account, err := store.Create(context.Background(), "migrated-user", []byte("old"))
 		require.NoError(t, err)
 
-		err = store.AddOauthAccount(account.ID, "provider", "provider_id", "", "token")
+		err = store.AddOauthAccount(context.Background(), account.ID, "provider", "provider_id", "", "token")
 		require.NoError(t, err)
 
 		result, err := db.Exec("UPDATE oauth_accounts SET email = NULL WHERE account_id = $1", account.ID)
@@ -60,7 +61,7 @@ func TestAccountStore(t *testing.T) {
 
 		require.Equal(t, int64(1), rowsAffected)
 
-		oAccounts, err := store.GetOauthAccounts(account.ID)
+		oAccounts, err := store.GetOauthAccounts(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		require.Len(t, oAccounts, 1)
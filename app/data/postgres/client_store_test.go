@@ -0,0 +1,19 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/postgres"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientStore(t *testing.T) {
+	db, err := newTestDB()
+	require.NoError(t, err)
+	store := &postgres.ClientStore{db}
+	for _, tester := range testers.ClientStoreTesters {
+		db.MustExec("TRUNCATE clients")
+		tester(t, store)
+	}
+}
@@ -0,0 +1,19 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/postgres"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationStore(t *testing.T) {
+	db, err := newTestDB()
+	require.NoError(t, err)
+	store := &postgres.InvitationStore{db}
+	for _, tester := range testers.InvitationStoreTesters {
+		db.MustExec("TRUNCATE accepted_invitations")
+		tester(t, store)
+	}
+}
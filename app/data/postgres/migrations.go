@@ -14,6 +14,16 @@ func MigrateDB(db *sqlx.DB) error {
 		caseInsensitiveUsername,
 		createAccountTOTPFields,
 		addOauthAccountEmail,
+		createAccountRequireMFAField,
+		createAccountOTPChannelField,
+		createTOTPAuthenticators,
+		moveTOTPSecrets,
+		createTOTPAuthenticatorLastUsedStepField,
+		createClients,
+		createPersonalAccessTokens,
+		createAccountTenantField,
+		createOrganizations,
+		createAcceptedInvitations,
 	}
 	for _, m := range migrations {
 		if err := m(db); err != nil {
@@ -85,3 +95,159 @@ func createAccountTOTPFields(db *sqlx.DB) error {
     `)
 	return err
 }
+
+func createAccountRequireMFAField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD COLUMN IF NOT EXISTS require_mfa BOOLEAN NOT NULL DEFAULT FALSE
+    `)
+	return err
+}
+
+func createAccountOTPChannelField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD COLUMN IF NOT EXISTS otp_channel VARCHAR(255) DEFAULT NULL
+    `)
+	return err
+}
+
+func createTOTPAuthenticators(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS totp_authenticators (
+            id SERIAL PRIMARY KEY,
+            account_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            secret TEXT NOT NULL,
+            created_at timestamptz NOT NULL,
+            last_used_at timestamptz DEFAULT NULL
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE INDEX IF NOT EXISTS totp_authenticators_by_account_id ON totp_authenticators (account_id)
+    `)
+	return err
+}
+
+// moveTOTPSecrets converts the single TOTP secret that was stored with each account into an
+// authenticator. It runs in a transaction so that secrets are not copied twice.
+func moveTOTPSecrets(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+        INSERT INTO totp_authenticators (account_id, name, secret, created_at)
+        SELECT id, 'Authenticator', totp_secret, updated_at FROM accounts WHERE totp_secret IS NOT NULL AND totp_secret != ''
+    `)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec(`
+        UPDATE accounts SET totp_secret = NULL WHERE totp_secret IS NOT NULL
+    `)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func createTOTPAuthenticatorLastUsedStepField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE totp_authenticators ADD COLUMN IF NOT EXISTS last_used_step BIGINT NOT NULL DEFAULT 0
+    `)
+	return err
+}
+
+func createClients(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS clients (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL,
+            secret TEXT DEFAULT NULL,
+            public_key TEXT NOT NULL DEFAULT '',
+            scopes TEXT NOT NULL DEFAULT '',
+            audiences TEXT NOT NULL DEFAULT '',
+            created_at timestamptz NOT NULL,
+            updated_at timestamptz NOT NULL
+        )
+    `)
+	return err
+}
+
+func createPersonalAccessTokens(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS personal_access_tokens (
+            id SERIAL PRIMARY KEY,
+            account_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            token_hash TEXT NOT NULL UNIQUE,
+            scopes TEXT NOT NULL DEFAULT '',
+            audience TEXT NOT NULL DEFAULT '',
+            created_at timestamptz NOT NULL,
+            expires_at timestamptz DEFAULT NULL,
+            last_used_at timestamptz DEFAULT NULL
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE INDEX IF NOT EXISTS personal_access_tokens_by_account_id ON personal_access_tokens (account_id)
+    `)
+	return err
+}
+
+func createAccountTenantField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD COLUMN IF NOT EXISTS tenant TEXT NOT NULL DEFAULT '';
+        ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_username_key;
+        CREATE UNIQUE INDEX IF NOT EXISTS accounts_by_tenant_and_username ON accounts (tenant, username);
+    `)
+	return err
+}
+
+func createOrganizations(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS organizations (
+            id SERIAL PRIMARY KEY,
+            name TEXT NOT NULL,
+            created_at timestamptz NOT NULL,
+            updated_at timestamptz NOT NULL
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS organization_memberships (
+            organization_id INTEGER NOT NULL,
+            account_id INTEGER NOT NULL,
+            roles TEXT NOT NULL DEFAULT '',
+            created_at timestamptz NOT NULL,
+            updated_at timestamptz NOT NULL,
+            PRIMARY KEY (organization_id, account_id)
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE INDEX IF NOT EXISTS organization_memberships_by_account_id ON organization_memberships (account_id)
+    `)
+	return err
+}
+
+func createAcceptedInvitations(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS accepted_invitations (
+            id TEXT PRIMARY KEY,
+            account_id INTEGER NOT NULL,
+            created_at timestamptz NOT NULL
+        )
+    `)
+	return err
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/models"
+)
+
+type OrganizationStore struct {
+	sqlx.ExtContext
+}
+
+const selectMemberships = "SELECT m.*, o.name AS organization_name FROM organization_memberships m INNER JOIN organizations o ON o.id = m.organization_id"
+
+func (db *OrganizationStore) Create(ctx context.Context, name string) (*models.Organization, error) {
+	now := time.Now()
+	organization := &models.Organization{
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	result, err := sqlx.NamedQueryContext(ctx, db,
+		"INSERT INTO organizations (name, created_at, updated_at) VALUES (:name, :created_at, :updated_at) RETURNING id",
+		organization,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+	result.Next()
+	var id int64
+	err = result.Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	organization.ID = int(id)
+
+	return organization, nil
+}
+
+func (db *OrganizationStore) Find(ctx context.Context, id int) (*models.Organization, error) {
+	organization := models.Organization{}
+	err := sqlx.GetContext(ctx, db, &organization, "SELECT * FROM organizations WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+func (db *OrganizationStore) Update(ctx context.Context, id int, name string) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE organizations SET name = $1, updated_at = $2 WHERE id = $3", name, time.Now(), id)
+	return ok(result, err)
+}
+
+func (db *OrganizationStore) Delete(ctx context.Context, id int) (bool, error) {
+	_, err := db.ExecContext(ctx, "DELETE FROM organization_memberships WHERE organization_id = $1", id)
+	if err != nil {
+		return false, err
+	}
+	result, err := db.ExecContext(ctx, "DELETE FROM organizations WHERE id = $1", id)
+	return ok(result, err)
+}
+
+func (db *OrganizationStore) SetMembership(ctx context.Context, organizationID int, accountID int, roles models.NameList) error {
+	now := time.Now()
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO organization_memberships (organization_id, account_id, roles, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (organization_id, account_id) DO UPDATE SET roles = EXCLUDED.roles, updated_at = EXCLUDED.updated_at",
+		organizationID, accountID, roles, now, now,
+	)
+	return err
+}
+
+func (db *OrganizationStore) FindMembership(ctx context.Context, organizationID int, accountID int) (*models.Membership, error) {
+	membership := models.Membership{}
+	err := sqlx.GetContext(ctx, db, &membership, selectMemberships+" WHERE m.organization_id = $1 AND m.account_id = $2", organizationID, accountID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (db *OrganizationStore) GetMembers(ctx context.Context, organizationID int) ([]*models.Membership, error) {
+	memberships := []*models.Membership{}
+	err := sqlx.SelectContext(ctx, db, &memberships, selectMemberships+" WHERE m.organization_id = $1 ORDER BY m.created_at, m.account_id", organizationID)
+	return memberships, err
+}
+
+func (db *OrganizationStore) GetMemberships(ctx context.Context, accountID int) ([]*models.Membership, error) {
+	memberships := []*models.Membership{}
+	err := sqlx.SelectContext(ctx, db, &memberships, selectMemberships+" WHERE m.account_id = $1 ORDER BY m.created_at, m.organization_id", accountID)
+	return memberships, err
+}
+
+func (db *OrganizationStore) DeleteMembership(ctx context.Context, organizationID int, accountID int) (bool, error) {
+	result, err := db.ExecContext(ctx, "DELETE FROM organization_memberships WHERE organization_id = $1 AND account_id = $2", organizationID, accountID)
+	return ok(result, err)
+}
@@ -0,0 +1,20 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/postgres"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationStore(t *testing.T) {
+	db, err := newTestDB()
+	require.NoError(t, err)
+	store := &postgres.OrganizationStore{db}
+	for _, tester := range testers.OrganizationStoreTesters {
+		db.MustExec("TRUNCATE organizations")
+		db.MustExec("TRUNCATE organization_memberships")
+		tester(t, store)
+	}
+}
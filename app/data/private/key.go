@@ -2,22 +2,50 @@ package private
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
+	"fmt"
 
 	"github.com/go-jose/go-jose/v3"
 	"github.com/pkg/errors"
 )
 
+// Supported signing algorithms
+const (
+	RS256 = string(jose.RS256)
+	ES256 = string(jose.ES256)
+	EdDSA = string(jose.EdDSA)
+)
+
 type Key struct {
-	JWK jose.JSONWebKey
-	*rsa.PrivateKey
+	JWK        jose.JSONWebKey
+	PrivateKey crypto.Signer
 }
 
-// Wrap the provided RSA private key as our internal key with canonical ID
-func NewKey(key *rsa.PrivateKey) (*Key, error) {
-	id, err := keyID(&key.PublicKey)
+// Public returns the public key.
+func (k *Key) Public() crypto.PublicKey {
+	return k.PrivateKey.Public()
+}
+
+// Algorithm is the JWS algorithm that should be used when signing with this key.
+func (k *Key) Algorithm() jose.SignatureAlgorithm {
+	return jose.SignatureAlgorithm(k.JWK.Algorithm)
+}
+
+// Wrap the provided RSA, ECDSA (P-256), or Ed25519 private key as our internal key with canonical
+// ID
+func NewKey(key crypto.Signer) (*Key, error) {
+	alg, err := algorithmFor(key)
+	if err != nil {
+		return nil, err
+	}
+	id, err := keyID(key.Public())
 	if err != nil {
 		return nil, errors.Wrap(err, "private.keyID")
 	}
@@ -26,7 +54,7 @@ func NewKey(key *rsa.PrivateKey) (*Key, error) {
 		JWK: jose.JSONWebKey{
 			Key:       key.Public(),
 			Use:       "sig",
-			Algorithm: "RS256",
+			Algorithm: alg,
 			KeyID:     id,
 		},
 	}, nil
@@ -41,8 +69,114 @@ func GenerateKey(bits int) (*Key, error) {
 	return NewKey(key)
 }
 
-// KeyID uses square/go-jose to extract the JWK thumbprint for a RSA public key.
-func keyID(key *rsa.PublicKey) (string, error) {
+// GenerateKeyFor generates a private key for the given algorithm. The bit width only applies to RSA.
+func GenerateKeyFor(alg string, bits int) (*Key, error) {
+	switch alg {
+	case RS256:
+		return GenerateKey(bits)
+	case ES256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return NewKey(key)
+	case EdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return NewKey(key)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %v", alg)
+	}
+}
+
+// Marshal serializes the private key in PEM format. RSA keys use PKCS #1 for compatibility with
+// RSA_PRIVATE_KEY, and other keys use PKCS #8.
+func (k *Key) Marshal() ([]byte, error) {
+	if key, ok := k.PrivateKey.(*rsa.PrivateKey); ok {
+		return pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		}), nil
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(k.PrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "MarshalPKCS8PrivateKey")
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: der,
+	}), nil
+}
+
+// ParseKey reads a PEM private key in PKCS #1 (RSA), SEC 1 (EC), or PKCS #8 format.
+func ParseKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	return parseBlock(block)
+}
+
+// ParseKeys reads every PEM private key from the data, in order.
+func ParseKeys(data []byte) ([]*Key, error) {
+	keys := []*Key{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return keys, nil
+		}
+		key, err := parseBlock(block)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+}
+
+func parseBlock(block *pem.Block) (*Key, error) {
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type: %T", key)
+	}
+	return NewKey(signer)
+}
+
+func algorithmFor(key crypto.Signer) (string, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return RS256, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return "", fmt.Errorf("unsupported curve: %v", k.Curve.Params().Name)
+		}
+		return ES256, nil
+	case ed25519.PrivateKey:
+		return EdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported private key type: %T", key)
+	}
+}
+
+// KeyID uses square/go-jose to extract the JWK thumbprint for a public key.
+func keyID(key crypto.PublicKey) (string, error) {
 	jwk := jose.JSONWebKey{Key: key}
 	kid, err := jwk.Thumbprint(crypto.SHA256)
 	if err != nil {
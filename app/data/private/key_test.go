@@ -28,7 +28,26 @@ func TestGenerate(t *testing.T) {
 	bitLen := 512
 	key, err := private.GenerateKey(bitLen)
 	require.NoError(t, err)
-	assert.Equal(t, key.PrivateKey.N.BitLen(), bitLen, "generated key should have requested bit length")
+	assert.Equal(t, key.PrivateKey.(*rsa.PrivateKey).N.BitLen(), bitLen, "generated key should have requested bit length")
+}
+
+func TestGenerateKeyFor(t *testing.T) {
+	for _, alg := range []string{private.RS256, private.ES256, private.EdDSA} {
+		key, err := private.GenerateKeyFor(alg, 512)
+		require.NoError(t, err)
+		assert.Equal(t, alg, key.JWK.Algorithm)
+		assert.Len(t, key.JWK.KeyID, 43)
+
+		pem, err := key.Marshal()
+		require.NoError(t, err)
+		parsed, err := private.ParseKey(pem)
+		require.NoError(t, err)
+		assert.Equal(t, key.JWK.KeyID, parsed.JWK.KeyID)
+		assert.Equal(t, alg, parsed.JWK.Algorithm)
+	}
+
+	_, err := private.GenerateKeyFor("HS256", 512)
+	assert.Error(t, err)
 }
 
 func TestKeyID(t *testing.T) {
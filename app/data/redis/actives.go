@@ -32,8 +32,7 @@ func NewActives(client *redis.Client, tz *time.Location, days int, weeks int, mo
 	}
 }
 
-func (a *actives) Track(accountID int) error {
-	ctx := context.TODO()
+func (a *actives) Track(ctx context.Context, accountID int) error {
 	t := time.Now().In(a.tz)
 	pipe := a.client.Pipeline()
 
@@ -55,7 +54,7 @@ func (a *actives) Track(accountID int) error {
 	return err
 }
 
-func (a *actives) ActivesByDay() (map[string]int, error) {
+func (a *actives) ActivesByDay(ctx context.Context) (map[string]int, error) {
 	now := time.Now().In(a.tz)
 
 	days := make([]string, a.days)
@@ -63,10 +62,10 @@ func (a *actives) ActivesByDay() (map[string]int, error) {
 		days[i] = dayKey(now.Add(time.Duration(i*-24) * time.Hour))
 	}
 
-	return a.report(days)
+	return a.report(ctx, days)
 }
 
-func (a *actives) ActivesByWeek() (map[string]int, error) {
+func (a *actives) ActivesByWeek(ctx context.Context) (map[string]int, error) {
 	now := time.Now().In(a.tz)
 
 	weeks := make([]string, a.weeks)
@@ -74,10 +73,10 @@ func (a *actives) ActivesByWeek() (map[string]int, error) {
 		weeks[i] = weekKey(now.AddDate(0, 0, -7*i))
 	}
 
-	return a.report(weeks)
+	return a.report(ctx, weeks)
 }
 
-func (a *actives) ActivesByMonth() (map[string]int, error) {
+func (a *actives) ActivesByMonth(ctx context.Context) (map[string]int, error) {
 	now := time.Now().In(a.tz)
 
 	months := make([]string, a.months)
@@ -85,20 +84,20 @@ func (a *actives) ActivesByMonth() (map[string]int, error) {
 		months[i] = monthKey(now.AddDate(0, -1*i, 1-now.Day()))
 	}
 
-	return a.report(months)
+	return a.report(ctx, months)
 }
 
-func (a *actives) report(keys []string) (map[string]int, error) {
+func (a *actives) report(ctx context.Context, keys []string) (map[string]int, error) {
 	pipe := a.client.Pipeline()
 
 	// construct requests
 	metrics := make([]metric, len(keys))
 	for i := range metrics {
-		metrics[i] = newMetric(pipe, keys[i])
+		metrics[i] = newMetric(ctx, pipe, keys[i])
 	}
 
 	// to redis
-	_, err := pipe.Exec(context.TODO())
+	_, err := pipe.Exec(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -119,8 +118,8 @@ type metric struct {
 	future *redis.IntCmd
 }
 
-func newMetric(pipe redis.Pipeliner, key string) metric {
-	return metric{key, pipe.PFCount(context.TODO(), redisPrefix+key)}
+func newMetric(ctx context.Context, pipe redis.Pipeliner, key string) metric {
+	return metric{key, pipe.PFCount(ctx, redisPrefix+key)}
 }
 
 func (m metric) val() int {
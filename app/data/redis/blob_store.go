@@ -16,8 +16,8 @@ type BlobStore struct {
 	Client   *redis.Client
 }
 
-func (s *BlobStore) Read(name string) ([]byte, error) {
-	blob, err := s.Client.Get(context.TODO(), name).Result()
+func (s *BlobStore) Read(ctx context.Context, name string) ([]byte, error) {
+	blob, err := s.Client.Get(ctx, name).Result()
 	if err == redis.Nil {
 		return nil, nil
 	} else if err != nil {
@@ -28,18 +28,44 @@ func (s *BlobStore) Read(name string) ([]byte, error) {
 	return []byte(blob), nil
 }
 
-func (s *BlobStore) WriteNX(name string, blob []byte) (bool, error) {
-	return s.Client.SetNX(context.TODO(), name, blob, s.TTL).Result()
+func (s *BlobStore) WriteNX(ctx context.Context, name string, blob []byte) (bool, error) {
+	return s.Client.SetNX(ctx, name, blob, s.TTL).Result()
 }
 
-func (s *BlobStore) Write(name string, blob []byte) (bool, error) {
-	res, err := s.Client.Set(context.TODO(), name, blob, s.TTL).Result()
+func (s *BlobStore) Write(ctx context.Context, name string, blob []byte) (bool, error) {
+	res, err := s.Client.Set(ctx, name, blob, s.TTL).Result()
 	if res != "OK" {
 		return false, err
 	}
 	return true, nil
 }
 
-func (s *BlobStore) Delete(name string) error {
-	return s.Client.Del(context.TODO(), name).Err()
+func (s *BlobStore) Rewrite(ctx context.Context, name string, blob []byte) (bool, error) {
+	ttl, err := s.Client.PTTL(ctx, name).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "PTTL")
+	}
+	// -2 means the key no longer exists, and -1 means it has no expiry.
+	if ttl == -2 {
+		return false, nil
+	} else if ttl < 0 {
+		ttl = 0
+	}
+	return s.Client.SetXX(ctx, name, blob, ttl).Result()
+}
+
+func (s *BlobStore) Delete(ctx context.Context, name string) error {
+	return s.Client.Del(ctx, name).Err()
+}
+
+func (s *BlobStore) Names(ctx context.Context, prefix string) ([]string, error) {
+	names := []string{}
+	iter := s.Client.Scan(ctx, 0, prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		names = append(names, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.Wrap(err, "Scan")
+	}
+	return names, nil
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -29,12 +30,42 @@ func keyForAccount(id int) string {
 	return str
 }
 
-func (s *RefreshTokenStore) Find(hexToken models.RefreshToken) (int, error) {
+// Redis key for token => family lookup, where a family is named after its original token
+func keyForFamily(t []byte) string {
+	str := fmt.Sprintf("s:f.%s", t)
+	return str
+}
+
+// Redis key for family => tokens lookup
+func keyForFamilyMembers(family []byte) string {
+	str := fmt.Sprintf("s:m.%s", family)
+	return str
+}
+
+// Redis key for token => session ID lookup
+func keyForTokenSession(t []byte) string {
+	str := fmt.Sprintf("s:i.%s", t)
+	return str
+}
+
+// Redis key for session ID => token lookup
+func keyForSession(sessionID string) string {
+	str := fmt.Sprintf("s:s.%s", sessionID)
+	return str
+}
+
+// Redis key for superseded token => accountID lookup
+func keyForSuperseded(t []byte) string {
+	str := fmt.Sprintf("s:r.%s", t)
+	return str
+}
+
+func (s *RefreshTokenStore) Find(ctx context.Context, hexToken models.RefreshToken) (int, error) {
 	binToken, err := hex.DecodeString(string(hexToken))
 	if err != nil {
 		return 0, err
 	}
-	str, err := s.Client.Get(context.TODO(), keyForToken(binToken)).Result()
+	str, err := s.Client.Get(ctx, keyForToken(binToken)).Result()
 	if err == redis.Nil {
 		return 0, nil
 	} else if err != nil {
@@ -43,9 +74,7 @@ func (s *RefreshTokenStore) Find(hexToken models.RefreshToken) (int, error) {
 	return strconv.Atoi(str)
 }
 
-func (s *RefreshTokenStore) Touch(hexToken models.RefreshToken, accountID int) error {
-	ctx := context.TODO()
-
+func (s *RefreshTokenStore) Touch(ctx context.Context, hexToken models.RefreshToken, accountID int) error {
 	binToken, err := hex.DecodeString(string(hexToken))
 	if err != nil {
 		return err
@@ -59,8 +88,8 @@ func (s *RefreshTokenStore) Touch(hexToken models.RefreshToken, accountID int) e
 	return err
 }
 
-func (s *RefreshTokenStore) FindAll(accountID int) ([]models.RefreshToken, error) {
-	bins, err := s.Client.SMembers(context.TODO(), keyForAccount(accountID)).Result()
+func (s *RefreshTokenStore) FindAll(ctx context.Context, accountID int) ([]models.RefreshToken, error) {
+	bins, err := s.Client.SMembers(ctx, keyForAccount(accountID)).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -73,9 +102,7 @@ func (s *RefreshTokenStore) FindAll(accountID int) ([]models.RefreshToken, error
 	return tokens, nil
 }
 
-func (s *RefreshTokenStore) Create(accountID int) (models.RefreshToken, error) {
-	ctx := context.TODO()
-
+func (s *RefreshTokenStore) Create(ctx context.Context, accountID int) (models.RefreshToken, error) {
 	binToken, err := lib.GenerateToken()
 	if err != nil {
 		return "", err
@@ -98,10 +125,8 @@ func (s *RefreshTokenStore) Create(accountID int) (models.RefreshToken, error) {
 	return models.RefreshToken(hex.EncodeToString(binToken)), nil
 }
 
-func (s *RefreshTokenStore) Revoke(hexToken models.RefreshToken) error {
-	ctx := context.TODO()
-
-	accountID, err := s.Find(hexToken)
+func (s *RefreshTokenStore) Revoke(ctx context.Context, hexToken models.RefreshToken) error {
+	accountID, err := s.Find(ctx, hexToken)
 	if err != nil {
 		return err
 	}
@@ -122,3 +147,162 @@ func (s *RefreshTokenStore) Revoke(hexToken models.RefreshToken) error {
 	})
 	return err
 }
+
+func (s *RefreshTokenStore) Rotate(ctx context.Context, hexToken models.RefreshToken, accountID int) (models.RefreshToken, error) {
+	binToken, err := hex.DecodeString(string(hexToken))
+	if err != nil {
+		return "", err
+	}
+
+	// deleting the token first ensures that it may only be rotated once
+	deleted, err := s.Client.Del(ctx, keyForToken(binToken)).Result()
+	if err != nil {
+		return "", err
+	}
+	if deleted == 0 {
+		return "", nil
+	}
+
+	family, err := s.Client.Get(ctx, keyForFamily(binToken)).Bytes()
+	if err == redis.Nil {
+		family = binToken
+	} else if err != nil {
+		return "", err
+	}
+
+	sessionID, err := s.Client.Get(ctx, keyForTokenSession(binToken)).Result()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+
+	newToken, err := lib.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		// remember the superseded token
+		pipe.SRem(ctx, keyForAccount(accountID), binToken)
+		pipe.Set(ctx, keyForSuperseded(binToken), fmt.Sprintf("%d:%d", accountID, time.Now().UnixNano()), s.TTL)
+		pipe.Set(ctx, keyForFamily(binToken), family, s.TTL)
+
+		// persist the new token in the same family
+		pipe.Set(ctx, keyForToken(newToken), accountID, s.TTL)
+		pipe.Set(ctx, keyForFamily(newToken), family, s.TTL)
+		pipe.SAdd(ctx, keyForAccount(accountID), newToken)
+		pipe.Expire(ctx, keyForAccount(accountID), s.TTL)
+
+		// maintain a list of tokens per family
+		pipe.SAdd(ctx, keyForFamilyMembers(family), binToken, newToken)
+		pipe.Expire(ctx, keyForFamilyMembers(family), s.TTL)
+
+		// carry the session over to the new token
+		if sessionID != "" {
+			pipe.Set(ctx, keyForTokenSession(newToken), sessionID, s.TTL)
+			pipe.Set(ctx, keyForSession(sessionID), newToken, s.TTL)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return models.RefreshToken(hex.EncodeToString(newToken)), nil
+}
+
+func (s *RefreshTokenStore) RevokeFamily(ctx context.Context, hexToken models.RefreshToken, grace time.Duration) (int, error) {
+	binToken, err := hex.DecodeString(string(hexToken))
+	if err != nil {
+		return 0, err
+	}
+	str, err := s.Client.Get(ctx, keyForSuperseded(binToken)).Result()
+	if err == redis.Nil {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	// the value is accountID:supersededAt
+	parts := strings.SplitN(str, ":", 2)
+	accountID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	if len(parts) == 2 {
+		at, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if time.Since(time.Unix(0, at)) < grace {
+			return 0, nil
+		}
+	}
+
+	family, err := s.Client.Get(ctx, keyForFamily(binToken)).Bytes()
+	if err == redis.Nil {
+		family = binToken
+	} else if err != nil {
+		return 0, err
+	}
+	members, err := s.Client.SMembers(ctx, keyForFamilyMembers(family)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = s.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, t := range members {
+			pipe.Del(ctx, keyForToken([]byte(t)), keyForFamily([]byte(t)), keyForSuperseded([]byte(t)))
+			pipe.SRem(ctx, keyForAccount(accountID), t)
+		}
+		pipe.Del(ctx, keyForFamilyMembers(family))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return accountID, nil
+}
+
+func (s *RefreshTokenStore) SetSessionID(ctx context.Context, hexToken models.RefreshToken, sessionID string) error {
+	binToken, err := hex.DecodeString(string(hexToken))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, keyForTokenSession(binToken), sessionID, s.TTL)
+		pipe.Set(ctx, keyForSession(sessionID), binToken, s.TTL)
+		return nil
+	})
+	return err
+}
+
+func (s *RefreshTokenStore) FindBySessionID(ctx context.Context, sessionID string) (models.RefreshToken, error) {
+	binToken, err := s.Client.Get(ctx, keyForSession(sessionID)).Bytes()
+	if err == redis.Nil {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	hexToken := models.RefreshToken(hex.EncodeToString(binToken))
+	accountID, err := s.Find(ctx, hexToken)
+	if err != nil || accountID == 0 {
+		return "", err
+	}
+	return hexToken, nil
+}
+
+func (s *RefreshTokenStore) FindSessionID(ctx context.Context, hexToken models.RefreshToken) (string, error) {
+	binToken, err := hex.DecodeString(string(hexToken))
+	if err != nil {
+		return "", err
+	}
+
+	sessionID, err := s.Client.Get(ctx, keyForTokenSession(binToken)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return sessionID, err
+}
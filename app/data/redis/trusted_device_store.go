@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib"
+)
+
+type TrustedDeviceStore struct {
+	*redis.Client
+	TTL time.Duration
+}
+
+// Redis key for device => details lookup
+func keyForDevice(id string) string {
+	str := fmt.Sprintf("d:t.%s", id)
+	return str
+}
+
+// Redis key for accountID => devices lookup
+func keyForAccountDevices(accountID int) string {
+	str := fmt.Sprintf("d:a.%d", accountID)
+	return str
+}
+
+func (s *TrustedDeviceStore) Create(ctx context.Context, accountID int, name string) (*models.TrustedDevice, error) {
+	binID, err := lib.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	device := &models.TrustedDevice{
+		ID:        hex.EncodeToString(binID),
+		AccountID: accountID,
+		Name:      name,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.TTL),
+	}
+
+	_, err = s.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		// persist the device
+		pipe.HSet(ctx, keyForDevice(device.ID), map[string]interface{}{
+			"account_id": device.AccountID,
+			"name":       device.Name,
+			"created_at": device.CreatedAt.Unix(),
+			"expires_at": device.ExpiresAt.Unix(),
+		})
+		pipe.Expire(ctx, keyForDevice(device.ID), s.TTL)
+
+		// maintain a list of devices per accountID
+		pipe.SAdd(ctx, keyForAccountDevices(accountID), device.ID)
+		pipe.Expire(ctx, keyForAccountDevices(accountID), s.TTL)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func (s *TrustedDeviceStore) Find(ctx context.Context, id string) (*models.TrustedDevice, error) {
+	fields, err := s.Client.HGetAll(ctx, keyForDevice(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	accountID, err := strconv.Atoi(fields["account_id"])
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := strconv.ParseInt(fields["created_at"], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := strconv.ParseInt(fields["expires_at"], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TrustedDevice{
+		ID:        id,
+		AccountID: accountID,
+		Name:      fields["name"],
+		CreatedAt: time.Unix(createdAt, 0),
+		ExpiresAt: time.Unix(expiresAt, 0),
+	}, nil
+}
+
+func (s *TrustedDeviceStore) FindAll(ctx context.Context, accountID int) ([]*models.TrustedDevice, error) {
+	ids, err := s.Client.SMembers(ctx, keyForAccountDevices(accountID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	devices := []*models.TrustedDevice{}
+	for _, id := range ids {
+		device, err := s.Find(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		// the device may have expired
+		if device != nil {
+			devices = append(devices, device)
+		}
+	}
+
+	return devices, nil
+}
+
+func (s *TrustedDeviceStore) Revoke(ctx context.Context, accountID int, id string) (bool, error) {
+	device, err := s.Find(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if device == nil || device.AccountID != accountID {
+		return false, nil
+	}
+
+	_, err = s.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keyForDevice(id))
+		pipe.SRem(ctx, keyForAccountDevices(accountID), id)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *TrustedDeviceStore) RevokeAll(ctx context.Context, accountID int) error {
+	ids, err := s.Client.SMembers(ctx, keyForAccountDevices(accountID)).Result()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range ids {
+			pipe.Del(ctx, keyForDevice(id))
+		}
+		pipe.Del(ctx, keyForAccountDevices(accountID))
+		return nil
+	})
+	return err
+}
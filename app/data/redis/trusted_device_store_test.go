@@ -0,0 +1,21 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/redis"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedDeviceStore(t *testing.T) {
+	client, err := redis.TestDB()
+	require.NoError(t, err)
+	store := &redis.TrustedDeviceStore{Client: client, TTL: time.Minute}
+	for _, tester := range testers.TrustedDeviceStoreTesters {
+		tester(t, store)
+		store.FlushDB(context.TODO())
+	}
+}
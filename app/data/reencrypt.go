@@ -0,0 +1,45 @@
+package data
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/pkg/errors"
+)
+
+// ReencryptTOTPSecrets rewrites every TOTP authenticator's secret that is not yet encrypted with the
+// current key, and returns the number of authenticators that were updated.
+//
+// This works directly with the database because the AccountStore has no way to list accounts.
+func ReencryptTOTPSecrets(ctx context.Context, db *sqlx.DB, keyring *compat.Keyring) (int, error) {
+	rows := []struct {
+		ID     int    `db:"id"`
+		Secret string `db:"secret"`
+	}{}
+	err := sqlx.SelectContext(ctx, db, &rows, "SELECT id, secret FROM totp_authenticators")
+	if err != nil {
+		return 0, errors.Wrap(err, "Select")
+	}
+
+	count := 0
+	for _, row := range rows {
+		if keyring.IsCurrent([]byte(row.Secret)) {
+			continue
+		}
+		secret, err := keyring.Decrypt([]byte(row.Secret))
+		if err != nil {
+			return count, errors.Wrapf(err, "Decrypt authenticator %v", row.ID)
+		}
+		encrypted, err := keyring.Encrypt([]byte(secret))
+		if err != nil {
+			return count, errors.Wrap(err, "Encrypt")
+		}
+		_, err = db.ExecContext(ctx, db.Rebind("UPDATE totp_authenticators SET secret = ? WHERE id = ?"), string(encrypted), row.ID)
+		if err != nil {
+			return count, errors.Wrap(err, "Update")
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,47 @@
+package data_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReencryptTOTPSecrets(t *testing.T) {
+	oldKey := []byte("secretsecretsecretsecretsecret12")
+	newKey := []byte("secretsecretsecretsecretsecret34")
+	ctx := context.Background()
+
+	db, err := sqlite3.TestDB()
+	require.NoError(t, err)
+	defer db.Close()
+	store := &sqlite3.AccountStore{ExtContext: db}
+
+	account, err := store.Create(ctx, "reencrypted", []byte("password"))
+	require.NoError(t, err)
+	secret, err := compat.NewKeyring(oldKey).Encrypt([]byte("JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+	authenticator, err := store.AddTOTPAuthenticator(ctx, account.ID, "phone", secret, 0)
+	require.NoError(t, err)
+
+	keyring := compat.NewKeyring(newKey, oldKey)
+	count, err := data.ReencryptTOTPSecrets(ctx, db, keyring)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	authenticators, err := store.GetTOTPAuthenticators(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, authenticators, 1)
+	assert.Equal(t, authenticator.ID, authenticators[0].ID)
+	val, err := compat.NewKeyring(newKey).Decrypt([]byte(authenticators[0].Secret))
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", val)
+
+	count, err = data.ReencryptTOTPSecrets(ctx, db, keyring)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
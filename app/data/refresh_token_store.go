@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -15,25 +16,47 @@ import (
 
 type RefreshTokenStore interface {
 	// Generates and persists a token for the given accountID.
-	Create(accountID int) (models.RefreshToken, error)
+	Create(ctx context.Context, accountID int) (models.RefreshToken, error)
 
 	// Finds the accountID that owns the token, if the token is registered and unexpired. An empty
 	// value indicates that no active token was found.
-	Find(t models.RefreshToken) (int, error)
+	Find(ctx context.Context, t models.RefreshToken) (int, error)
 
 	// Refreshes the lifetime of the token.
 	//
 	// Technically could operate without accountID, but in the expected contexts the caller should
 	// already know the accountID and can save this operation one query by providing it. This seems
 	// important since touching can be a high traffic activity.
-	Touch(t models.RefreshToken, accountID int) error
+	Touch(ctx context.Context, t models.RefreshToken, accountID int) error
 
 	// Returns all tokens that are active for the specified account.
-	FindAll(accountID int) ([]models.RefreshToken, error)
+	FindAll(ctx context.Context, accountID int) ([]models.RefreshToken, error)
 
 	// Revokes the token and removes it from the set of active tokens for the account. Doesn't error
 	// if the token is unknown or already revoked.
-	Revoke(t models.RefreshToken) error
+	Revoke(ctx context.Context, t models.RefreshToken) error
+
+	// Replaces the token with a new token for the same account and in the same family. The replaced
+	// token is no longer active, but is remembered as superseded until it would have expired. An
+	// empty value indicates that the token was not active, e.g. because it was already replaced.
+	Rotate(ctx context.Context, t models.RefreshToken, accountID int) (models.RefreshToken, error)
+
+	// Revokes every token in the family of a superseded token, and returns the accountID that owned
+	// it. An empty value indicates that the token is not known to be superseded, or that it was
+	// superseded within the grace period and may simply have raced with its own rotation.
+	RevokeFamily(ctx context.Context, t models.RefreshToken, grace time.Duration) (int, error)
+
+	// Associates the token with a session ID. The association is carried over when the token is
+	// rotated, so that the session's current token may be found from an identity token.
+	SetSessionID(ctx context.Context, t models.RefreshToken, sessionID string) error
+
+	// Finds the active token for the session ID. An empty value indicates that the session is no
+	// longer active.
+	FindBySessionID(ctx context.Context, sessionID string) (models.RefreshToken, error)
+
+	// Finds the session ID associated with the token. An empty value indicates that the token has
+	// no session ID.
+	FindSessionID(ctx context.Context, t models.RefreshToken) (string, error)
 }
 
 func NewRefreshTokenStore(db *sqlx.DB, redis *redis.Client, reporter ops.ErrorReporter, ttl time.Duration) (RefreshTokenStore, error) {
@@ -47,8 +70,8 @@ func NewRefreshTokenStore(db *sqlx.DB, redis *redis.Client, reporter ops.ErrorRe
 	switch db.DriverName() {
 	case "sqlite3":
 		store := &sqlite3.RefreshTokenStore{
-			Ext: db,
-			TTL: ttl,
+			ExtContext: db,
+			TTL:        ttl,
 		}
 		store.Clean(reporter)
 		return store, nil
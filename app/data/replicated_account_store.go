@@ -0,0 +1,211 @@
+package data
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+type primaryPinKey struct{}
+
+// WithReadYourWrites prepares a context to remember when a write has been sent to the primary
+// database. Once that happens, any further reads with the context will also be sent to the primary
+// so that they can't observe a replica that hasn't caught up yet.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryPinKey{}, new(int32))
+}
+
+func pinPrimary(ctx context.Context) {
+	if pin, ok := ctx.Value(primaryPinKey{}).(*int32); ok {
+		atomic.StoreInt32(pin, 1)
+	}
+}
+
+func pinnedToPrimary(ctx context.Context) bool {
+	pin, ok := ctx.Value(primaryPinKey{}).(*int32)
+	return ok && atomic.LoadInt32(pin) == 1
+}
+
+// NewReplicatedAccountStore routes read-only account lookups to the replicas in rotation, and
+// everything else to the primary. A read will fall back to the primary when the replica fails or
+// when the context has already been used for a write.
+func NewReplicatedAccountStore(primary AccountStore, replicas []AccountStore) AccountStore {
+	if len(replicas) == 0 {
+		return primary
+	}
+	return &replicatedAccountStore{
+		primary:  primary,
+		replicas: replicas,
+	}
+}
+
+type replicatedAccountStore struct {
+	primary  AccountStore
+	replicas []AccountStore
+	next     uint32
+}
+
+// reader picks the store that should serve a read.
+func (s *replicatedAccountStore) reader(ctx context.Context) AccountStore {
+	if pinnedToPrimary(ctx) {
+		return s.primary
+	}
+	n := atomic.AddUint32(&s.next, 1)
+	return s.replicas[int(n)%len(s.replicas)]
+}
+
+// writer pins the context and returns the primary.
+func (s *replicatedAccountStore) writer(ctx context.Context) AccountStore {
+	pinPrimary(ctx)
+	return s.primary
+}
+
+// fallback decides whether a failed replica read should be retried against the primary.
+func (s *replicatedAccountStore) fallback(ctx context.Context, store AccountStore, err error) bool {
+	return err != nil && store != s.primary && ctx.Err() == nil
+}
+
+func (s *replicatedAccountStore) Find(ctx context.Context, id int) (*models.Account, error) {
+	store := s.reader(ctx)
+	account, err := store.Find(ctx, id)
+	if s.fallback(ctx, store, err) {
+		return s.primary.Find(ctx, id)
+	}
+	return account, err
+}
+
+func (s *replicatedAccountStore) FindByUsername(ctx context.Context, u string) (*models.Account, error) {
+	store := s.reader(ctx)
+	account, err := store.FindByUsername(ctx, u)
+	if s.fallback(ctx, store, err) {
+		return s.primary.FindByUsername(ctx, u)
+	}
+	return account, err
+}
+
+func (s *replicatedAccountStore) FindByOauthAccount(ctx context.Context, p string, pid string) (*models.Account, error) {
+	store := s.reader(ctx)
+	account, err := store.FindByOauthAccount(ctx, p, pid)
+	if s.fallback(ctx, store, err) {
+		return s.primary.FindByOauthAccount(ctx, p, pid)
+	}
+	return account, err
+}
+
+func (s *replicatedAccountStore) GetOauthAccounts(ctx context.Context, id int) ([]*models.OauthAccount, error) {
+	store := s.reader(ctx)
+	accounts, err := store.GetOauthAccounts(ctx, id)
+	if s.fallback(ctx, store, err) {
+		return s.primary.GetOauthAccounts(ctx, id)
+	}
+	return accounts, err
+}
+
+func (s *replicatedAccountStore) Create(ctx context.Context, u string, p []byte) (*models.Account, error) {
+	return s.writer(ctx).Create(ctx, u, p)
+}
+
+func (s *replicatedAccountStore) AddOauthAccount(ctx context.Context, id int, p string, pid string, email string, tok string) error {
+	return s.writer(ctx).AddOauthAccount(ctx, id, p, pid, email, tok)
+}
+
+func (s *replicatedAccountStore) UpdateOauthAccount(ctx context.Context, id int, p string, email string) (bool, error) {
+	return s.writer(ctx).UpdateOauthAccount(ctx, id, p, email)
+}
+
+func (s *replicatedAccountStore) DeleteOauthAccount(ctx context.Context, id int, p string) (bool, error) {
+	return s.writer(ctx).DeleteOauthAccount(ctx, id, p)
+}
+
+func (s *replicatedAccountStore) Archive(ctx context.Context, id int) (bool, error) {
+	return s.writer(ctx).Archive(ctx, id)
+}
+
+func (s *replicatedAccountStore) Lock(ctx context.Context, id int) (bool, error) {
+	return s.writer(ctx).Lock(ctx, id)
+}
+
+func (s *replicatedAccountStore) Unlock(ctx context.Context, id int) (bool, error) {
+	return s.writer(ctx).Unlock(ctx, id)
+}
+
+func (s *replicatedAccountStore) RequireNewPassword(ctx context.Context, id int) (bool, error) {
+	return s.writer(ctx).RequireNewPassword(ctx, id)
+}
+
+func (s *replicatedAccountStore) SetPassword(ctx context.Context, id int, p []byte) (bool, error) {
+	return s.writer(ctx).SetPassword(ctx, id, p)
+}
+
+func (s *replicatedAccountStore) UpdateUsername(ctx context.Context, id int, u string) (bool, error) {
+	return s.writer(ctx).UpdateUsername(ctx, id, u)
+}
+
+func (s *replicatedAccountStore) SetLastLogin(ctx context.Context, id int) (bool, error) {
+	return s.writer(ctx).SetLastLogin(ctx, id)
+}
+
+func (s *replicatedAccountStore) AddTOTPAuthenticator(ctx context.Context, id int, name string, secret []byte, step int64) (*models.TOTPAuthenticator, error) {
+	return s.writer(ctx).AddTOTPAuthenticator(ctx, id, name, secret, step)
+}
+
+func (s *replicatedAccountStore) GetTOTPAuthenticators(ctx context.Context, id int) ([]*models.TOTPAuthenticator, error) {
+	store := s.reader(ctx)
+	authenticators, err := store.GetTOTPAuthenticators(ctx, id)
+	if s.fallback(ctx, store, err) {
+		return s.primary.GetTOTPAuthenticators(ctx, id)
+	}
+	return authenticators, err
+}
+
+func (s *replicatedAccountStore) SetTOTPAuthenticatorLastUsed(ctx context.Context, id int, authenticatorID int, step int64) (bool, error) {
+	return s.writer(ctx).SetTOTPAuthenticatorLastUsed(ctx, id, authenticatorID, step)
+}
+
+func (s *replicatedAccountStore) DeleteTOTPAuthenticator(ctx context.Context, id int, authenticatorID int) (bool, error) {
+	return s.writer(ctx).DeleteTOTPAuthenticator(ctx, id, authenticatorID)
+}
+
+func (s *replicatedAccountStore) DeleteTOTPAuthenticators(ctx context.Context, id int) (bool, error) {
+	return s.writer(ctx).DeleteTOTPAuthenticators(ctx, id)
+}
+
+func (s *replicatedAccountStore) SetRequireMFA(ctx context.Context, id int, required bool) (bool, error) {
+	return s.writer(ctx).SetRequireMFA(ctx, id, required)
+}
+
+func (s *replicatedAccountStore) SetOTPChannel(ctx context.Context, id int, channel string) (bool, error) {
+	return s.writer(ctx).SetOTPChannel(ctx, id, channel)
+}
+
+func (s *replicatedAccountStore) AddPersonalAccessToken(ctx context.Context, id int, name string, tokenHash string, scopes models.NameList, audience string, expiresAt *time.Time) (*models.PersonalAccessToken, error) {
+	return s.writer(ctx).AddPersonalAccessToken(ctx, id, name, tokenHash, scopes, audience, expiresAt)
+}
+
+func (s *replicatedAccountStore) GetPersonalAccessTokens(ctx context.Context, id int) ([]*models.PersonalAccessToken, error) {
+	store := s.reader(ctx)
+	tokens, err := store.GetPersonalAccessTokens(ctx, id)
+	if s.fallback(ctx, store, err) {
+		return s.primary.GetPersonalAccessTokens(ctx, id)
+	}
+	return tokens, err
+}
+
+func (s *replicatedAccountStore) FindPersonalAccessToken(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	store := s.reader(ctx)
+	token, err := store.FindPersonalAccessToken(ctx, tokenHash)
+	if s.fallback(ctx, store, err) {
+		return s.primary.FindPersonalAccessToken(ctx, tokenHash)
+	}
+	return token, err
+}
+
+func (s *replicatedAccountStore) SetPersonalAccessTokenLastUsed(ctx context.Context, tokenID int) (bool, error) {
+	return s.writer(ctx).SetPersonalAccessTokenLastUsed(ctx, tokenID)
+}
+
+func (s *replicatedAccountStore) DeletePersonalAccessToken(ctx context.Context, id int, tokenID int) (bool, error) {
+	return s.writer(ctx).DeletePersonalAccessToken(ctx, id, tokenID)
+}
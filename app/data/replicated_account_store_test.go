@@ -0,0 +1,82 @@
+package data_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenAccountStore fails every lookup
+type brokenAccountStore struct {
+	data.AccountStore
+}
+
+func (s *brokenAccountStore) Find(ctx context.Context, id int) (*models.Account, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestReplicatedAccountStore(t *testing.T) {
+	t.Run("without replicas", func(t *testing.T) {
+		primary := mock.NewAccountStore()
+		store := data.NewReplicatedAccountStore(primary, nil)
+		assert.Equal(t, primary, store)
+	})
+
+	t.Run("reads from replicas", func(t *testing.T) {
+		primary := mock.NewAccountStore()
+		replica := mock.NewAccountStore()
+		store := data.NewReplicatedAccountStore(primary, []data.AccountStore{replica})
+
+		ctx := context.Background()
+		account, err := replica.Create(ctx, "replicated", []byte("secret"))
+		require.NoError(t, err)
+
+		found, err := store.Find(ctx, account.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "replicated", found.Username)
+
+		found, err = store.FindByUsername(ctx, "replicated")
+		require.NoError(t, err)
+		assert.NotNil(t, found)
+	})
+
+	t.Run("reads from primary after a write", func(t *testing.T) {
+		primary := mock.NewAccountStore()
+		replica := mock.NewAccountStore()
+		store := data.NewReplicatedAccountStore(primary, []data.AccountStore{replica})
+
+		ctx := data.WithReadYourWrites(context.Background())
+		account, err := store.Create(ctx, "written", []byte("secret"))
+		require.NoError(t, err)
+
+		found, err := store.Find(ctx, account.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "written", found.Username)
+
+		found, err = store.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("falls back to primary on replica failure", func(t *testing.T) {
+		primary := mock.NewAccountStore()
+		store := data.NewReplicatedAccountStore(primary, []data.AccountStore{&brokenAccountStore{}})
+
+		ctx := context.Background()
+		account, err := primary.Create(ctx, "fallback", []byte("secret"))
+		require.NoError(t, err)
+
+		found, err := store.Find(ctx, account.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "fallback", found.Username)
+	})
+}
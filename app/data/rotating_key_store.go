@@ -9,6 +9,7 @@ import (
 // RotatingKeyStore is a KeyStore that may be rotated by a maintainer.
 type RotatingKeyStore struct {
 	keys   []*private.Key
+	next   *private.Key
 	rwLock *sync.RWMutex
 }
 
@@ -33,12 +34,16 @@ func (ks *RotatingKeyStore) Key() *private.Key {
 	}
 }
 
-// Keys will return the previous and current keys, in that order.
+// Keys will return the previous and current keys, in that order, followed by the next key when it
+// has been published in advance.
 func (ks *RotatingKeyStore) Keys() []*private.Key {
 	ks.rwLock.RLock()
 	defer ks.rwLock.RUnlock()
 
-	return ks.keys
+	if ks.next == nil {
+		return ks.keys
+	}
+	return append(append([]*private.Key{}, ks.keys...), ks.next)
 }
 
 // Rotate is responsible for adding a new key to the list. It maintains key order from oldest to
@@ -53,4 +58,16 @@ func (ks *RotatingKeyStore) Rotate(k *private.Key) {
 	ks.rwLock.Lock()
 	defer ks.rwLock.Unlock()
 	ks.keys = keys
+	if ks.next != nil && ks.next.JWK.KeyID == k.JWK.KeyID {
+		ks.next = nil
+	}
+}
+
+// Reset replaces all of the keys at once. The keys must be sorted from oldest to newest, and the
+// next key (if any) will be published without being used.
+func (ks *RotatingKeyStore) Reset(keys []*private.Key, next *private.Key) {
+	ks.rwLock.Lock()
+	defer ks.rwLock.Unlock()
+	ks.keys = keys
+	ks.next = next
 }
@@ -36,3 +36,22 @@ func TestRotatingKeyStore(t *testing.T) {
 	assert.Equal(t, []*private.Key{k2, k3}, ks.Keys())
 	assert.Equal(t, k3, ks.Key())
 }
+
+func TestRotatingKeyStoreReset(t *testing.T) {
+	ks := data.NewRotatingKeyStore()
+
+	k1, err := private.GenerateKey(256)
+	require.NoError(t, err)
+	k2, err := private.GenerateKey(256)
+	require.NoError(t, err)
+	k3, err := private.GenerateKey(256)
+	require.NoError(t, err)
+
+	ks.Reset([]*private.Key{k1, k2}, k3)
+	assert.Equal(t, []*private.Key{k1, k2, k3}, ks.Keys())
+	assert.Equal(t, k2, ks.Key())
+
+	ks.Rotate(k3)
+	assert.Equal(t, []*private.Key{k2, k3}, ks.Keys())
+	assert.Equal(t, k3, ks.Key())
+}
@@ -1,6 +1,7 @@
 package sqlite3
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -15,13 +16,13 @@ var placeholder = "generating"
 type BlobStore struct {
 	TTL      time.Duration
 	LockTime time.Duration
-	DB       sqlx.Ext
+	DB       sqlx.ExtContext
 }
 
 func (s *BlobStore) Clean(reporter ops.ErrorReporter) {
 	go func() {
 		for range time.Tick(time.Minute + jitter()) {
-			_, err := s.DB.Exec("DELETE FROM blobs WHERE expires_at < ?", time.Now())
+			_, err := s.DB.ExecContext(context.Background(), "DELETE FROM blobs WHERE expires_at < ?", time.Now())
 			if err != nil {
 				reporter.ReportError(errors.Wrap(err, "BlobStore Clean"))
 			}
@@ -30,9 +31,9 @@ func (s *BlobStore) Clean(reporter ops.ErrorReporter) {
 	}()
 }
 
-func (s *BlobStore) Read(name string) ([]byte, error) {
+func (s *BlobStore) Read(ctx context.Context, name string) ([]byte, error) {
 	var blob []byte
-	err := s.DB.QueryRowx("SELECT blob FROM blobs WHERE name = ? AND blob != ? AND expires_at > ?", name, placeholder, time.Now()).Scan(&blob)
+	err := s.DB.QueryRowxContext(ctx, "SELECT blob FROM blobs WHERE name = ? AND blob != ? AND expires_at > ?", name, placeholder, time.Now()).Scan(&blob)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
@@ -41,8 +42,8 @@ func (s *BlobStore) Read(name string) ([]byte, error) {
 	return blob, nil
 }
 
-func (s *BlobStore) WriteNX(name string, blob []byte) (bool, error) {
-	_, err := s.DB.Exec("INSERT INTO blobs (name, blob, expires_at) VALUES (?, ?, ?)", name, blob, time.Now().Add(s.TTL))
+func (s *BlobStore) WriteNX(ctx context.Context, name string, blob []byte) (bool, error) {
+	_, err := s.DB.ExecContext(ctx, "INSERT INTO blobs (name, blob, expires_at) VALUES (?, ?, ?)", name, blob, time.Now().Add(s.TTL))
 	if i, ok := err.(sq3.Error); ok && i.ExtendedCode == sq3.ErrConstraintUnique {
 		return false, nil
 	}
@@ -52,16 +53,37 @@ func (s *BlobStore) WriteNX(name string, blob []byte) (bool, error) {
 	return true, nil
 }
 
-func (s *BlobStore) Write(name string, blob []byte) (bool, error) {
+func (s *BlobStore) Write(ctx context.Context, name string, blob []byte) (bool, error) {
 	expiresAt := time.Now().Add(s.TTL)
-	_, err := s.DB.Exec("INSERT or REPLACE INTO blobs (name, blob, expires_at) VALUES (?, ?, ?)", name, blob, expiresAt, blob, expiresAt, name)
+	_, err := s.DB.ExecContext(ctx, "INSERT or REPLACE INTO blobs (name, blob, expires_at) VALUES (?, ?, ?)", name, blob, expiresAt, blob, expiresAt, name)
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-func (s *BlobStore) Delete(name string) error {
-	_, err := s.DB.Exec("DELETE FROM blobs WHERE name = ?", name)
+func (s *BlobStore) Rewrite(ctx context.Context, name string, blob []byte) (bool, error) {
+	result, err := s.DB.ExecContext(ctx, "UPDATE blobs SET blob = ? WHERE name = ? AND expires_at > ?", blob, name, time.Now())
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *BlobStore) Delete(ctx context.Context, name string) error {
+	_, err := s.DB.ExecContext(ctx, "DELETE FROM blobs WHERE name = ?", name)
 	return err
 }
+
+func (s *BlobStore) Names(ctx context.Context, prefix string) ([]string, error) {
+	names := []string{}
+	err := sqlx.SelectContext(ctx, s.DB, &names, "SELECT name FROM blobs WHERE substr(name, 1, ?) = ? AND expires_at > ?", len(prefix), prefix, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "Select")
+	}
+	return names, nil
+}
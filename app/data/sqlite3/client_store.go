@@ -0,0 +1,53 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/models"
+)
+
+type ClientStore struct {
+	sqlx.ExtContext
+}
+
+func (db *ClientStore) Create(ctx context.Context, client *models.Client) error {
+	now := time.Now()
+	client.CreatedAt = now
+	client.UpdatedAt = now
+
+	_, err := sqlx.NamedExecContext(ctx, db,
+		"INSERT INTO clients (id, name, secret, public_key, scopes, audiences, created_at, updated_at) VALUES (:id, :name, :secret, :public_key, :scopes, :audiences, :created_at, :updated_at)",
+		client,
+	)
+	return err
+}
+
+func (db *ClientStore) Find(ctx context.Context, id string) (*models.Client, error) {
+	client := models.Client{}
+	err := sqlx.GetContext(ctx, db, &client, "SELECT * FROM clients WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (db *ClientStore) FindAll(ctx context.Context) ([]*models.Client, error) {
+	clients := []*models.Client{}
+	err := sqlx.SelectContext(ctx, db, &clients, "SELECT * FROM clients ORDER BY created_at, id")
+	return clients, err
+}
+
+func (db *ClientStore) Update(ctx context.Context, id string, name string, scopes models.NameList, audiences models.NameList) (bool, error) {
+	result, err := db.ExecContext(ctx, "UPDATE clients SET name = ?, scopes = ?, audiences = ?, updated_at = ? WHERE id = ?", name, scopes, audiences, time.Now(), id)
+	return ok(result, err)
+}
+
+func (db *ClientStore) Delete(ctx context.Context, id string) (bool, error) {
+	result, err := db.ExecContext(ctx, "DELETE FROM clients WHERE id = ?", id)
+	return ok(result, err)
+}
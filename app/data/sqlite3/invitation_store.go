@@ -0,0 +1,27 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type InvitationStore struct {
+	sqlx.ExtContext
+}
+
+func (db *InvitationStore) Accept(ctx context.Context, id string, accountID int) error {
+	_, err := db.ExecContext(ctx, "INSERT INTO accepted_invitations (id, account_id, created_at) VALUES (?, ?, ?)", id, accountID, time.Now())
+	return err
+}
+
+func (db *InvitationStore) FindAccepted(ctx context.Context, id string) (int, error) {
+	var accountID int
+	err := sqlx.GetContext(ctx, db, &accountID, "SELECT account_id FROM accepted_invitations WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return accountID, err
+}
@@ -21,6 +21,20 @@ func MigrateDB(db *sqlx.DB) error {
 		caseInsensitiveUsername,
 		createAccountTOTPFields,
 		addOauthAccountEmail,
+		addRefreshTokenFamily,
+		createAccountRequireMFAField,
+		createTrustedDevices,
+		createAccountOTPChannelField,
+		createTOTPAuthenticators,
+		moveTOTPSecrets,
+		createTOTPAuthenticatorLastUsedStepField,
+		createRefreshTokenSessionIDField,
+		createClients,
+		createPersonalAccessTokens,
+		createAccountTenantField,
+		createOrganizations,
+		createAcceptedInvitations,
+		createRefreshTokenSupersededAtField,
 	}
 	for _, m := range migrations {
 		if err := m(db); err != nil {
@@ -121,7 +135,12 @@ func createAccountLastLoginAtField(db *sqlx.DB) error {
 // this will fail if the current accounts table has existing usernames that are equal after
 // the operation.
 func caseInsensitiveUsername(db *sqlx.DB) error {
-	_, err := db.Exec(`
+	schema, err := accountsSchema(db)
+	if err != nil || strings.Contains(schema, "COLLATE NOCASE") {
+		return err
+	}
+
+	_, err = db.Exec(`
         BEGIN TRANSACTION;
 
         ALTER TABLE accounts RENAME TO accounts_old;
@@ -159,3 +178,261 @@ func createAccountTOTPFields(db *sqlx.DB) error {
 	}
 	return err
 }
+
+func addRefreshTokenFamily(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE refresh_tokens ADD family TEXT DEFAULT NULL
+    `)
+	if err != nil && !isDuplicateError(err) {
+		return err
+	}
+	_, err = db.Exec(`
+        ALTER TABLE refresh_tokens ADD superseded BOOLEAN NOT NULL DEFAULT 0
+    `)
+	if isDuplicateError(err) {
+		return nil
+	}
+	return err
+}
+
+func createAccountRequireMFAField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD require_mfa BOOLEAN NOT NULL DEFAULT 0
+    `)
+	if isDuplicateError(err) {
+		return nil
+	}
+	return err
+}
+
+func createTrustedDevices(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS trusted_devices (
+            id TEXT NOT NULL CONSTRAINT uniq UNIQUE,
+            account_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            created_at DATETIME NOT NULL,
+            expires_at DATETIME NOT NULL
+        )
+    `)
+	return err
+}
+
+func createAccountOTPChannelField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD otp_channel TEXT DEFAULT NULL
+    `)
+	if isDuplicateError(err) {
+		return nil
+	}
+	return err
+}
+
+func createTOTPAuthenticators(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS totp_authenticators (
+            id INTEGER PRIMARY KEY,
+            account_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            secret TEXT NOT NULL,
+            created_at DATETIME NOT NULL,
+            last_used_at DATETIME
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE INDEX IF NOT EXISTS totp_authenticators_by_account_id ON totp_authenticators (account_id)
+    `)
+	return err
+}
+
+// moveTOTPSecrets converts the single TOTP secret that was stored with each account into an
+// authenticator. It runs in a transaction so that secrets are not copied twice.
+func moveTOTPSecrets(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+        INSERT INTO totp_authenticators (account_id, name, secret, created_at)
+        SELECT id, 'Authenticator', totp_secret, updated_at FROM accounts WHERE totp_secret IS NOT NULL AND totp_secret != ''
+    `)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec(`
+        UPDATE accounts SET totp_secret = NULL WHERE totp_secret IS NOT NULL
+    `)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func createTOTPAuthenticatorLastUsedStepField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE totp_authenticators ADD last_used_step BIGINT NOT NULL DEFAULT 0
+    `)
+	if isDuplicateError(err) {
+		return nil
+	}
+	return err
+}
+
+func createRefreshTokenSessionIDField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE refresh_tokens ADD session_id TEXT DEFAULT NULL
+    `)
+	if err != nil && !isDuplicateError(err) {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE INDEX IF NOT EXISTS refresh_tokens_by_session_id ON refresh_tokens (session_id)
+    `)
+	return err
+}
+
+func createClients(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS clients (
+            id TEXT NOT NULL CONSTRAINT uniq UNIQUE,
+            name TEXT NOT NULL,
+            secret BLOB DEFAULT NULL,
+            public_key TEXT NOT NULL DEFAULT '',
+            scopes TEXT NOT NULL DEFAULT '',
+            audiences TEXT NOT NULL DEFAULT '',
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL
+        )
+    `)
+	return err
+}
+
+func createPersonalAccessTokens(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS personal_access_tokens (
+            id INTEGER PRIMARY KEY,
+            account_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            token_hash TEXT NOT NULL CONSTRAINT uniq UNIQUE,
+            scopes TEXT NOT NULL DEFAULT '',
+            audience TEXT NOT NULL DEFAULT '',
+            created_at DATETIME NOT NULL,
+            expires_at DATETIME DEFAULT NULL,
+            last_used_at DATETIME DEFAULT NULL
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE INDEX IF NOT EXISTS personal_access_tokens_by_account_id ON personal_access_tokens (account_id)
+    `)
+	return err
+}
+
+// createAccountTenantField will migrate the accounts table so that usernames are unique per tenant.
+// SQLite can not drop a constraint, so the table is rebuilt.
+func createAccountTenantField(db *sqlx.DB) error {
+	schema, err := accountsSchema(db)
+	if err != nil || strings.Contains(schema, "tenant") {
+		return err
+	}
+
+	_, err = db.Exec(`
+        BEGIN TRANSACTION;
+
+        ALTER TABLE accounts RENAME TO accounts_old;
+
+        CREATE TABLE accounts (
+            id INTEGER PRIMARY KEY,
+            tenant TEXT NOT NULL DEFAULT '',
+            username TEXT NOT NULL COLLATE NOCASE,
+            password TEXT NOT NULL,
+            locked BOOLEAN NOT NULL,
+            require_new_password BOOLEAN NOT NULL,
+            password_changed_at DATETIME NOT NULL,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL,
+            deleted_at DATETIME,
+            last_login_at DATETIME,
+            totp_secret VARCHAR(255) DEFAULT NULL,
+            require_mfa BOOLEAN NOT NULL DEFAULT 0,
+            otp_channel TEXT DEFAULT NULL,
+            CONSTRAINT uniq UNIQUE (tenant, username)
+        );
+
+        INSERT INTO accounts(id, username, password, locked, require_new_password, password_changed_at, created_at, updated_at, deleted_at, last_login_at, totp_secret, require_mfa, otp_channel)
+        SELECT id, username, password, locked, require_new_password, password_changed_at, created_at, updated_at, deleted_at, last_login_at, totp_secret, require_mfa, otp_channel
+        FROM accounts_old;
+
+        DROP TABLE accounts_old;
+
+        COMMIT;
+    `)
+	return err
+}
+
+// accountsSchema returns the statement that created the accounts table, so that migrations which
+// rebuild it can tell whether they have already run.
+func accountsSchema(db *sqlx.DB) (string, error) {
+	var schema string
+	err := db.Get(&schema, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'accounts'")
+	return schema, err
+}
+
+func createOrganizations(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS organizations (
+            id INTEGER PRIMARY KEY,
+            name TEXT NOT NULL,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS organization_memberships (
+            organization_id INTEGER NOT NULL,
+            account_id INTEGER NOT NULL,
+            roles TEXT NOT NULL DEFAULT '',
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL,
+            CONSTRAINT uniq UNIQUE (organization_id, account_id)
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE INDEX IF NOT EXISTS organization_memberships_by_account_id ON organization_memberships (account_id)
+    `)
+	return err
+}
+
+func createAcceptedInvitations(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS accepted_invitations (
+            id TEXT PRIMARY KEY,
+            account_id INTEGER NOT NULL,
+            created_at DATETIME NOT NULL
+        )
+    `)
+	return err
+}
+
+func createRefreshTokenSupersededAtField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE refresh_tokens ADD superseded_at DATETIME DEFAULT NULL
+    `)
+	if isDuplicateError(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+package sqlite3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveTOTPSecrets(t *testing.T) {
+	db, err := TestDB()
+	require.NoError(t, err)
+	defer db.Close()
+	store := &AccountStore{db}
+
+	account, err := store.Create(context.Background(), "legacy@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	_, err = db.Exec("UPDATE accounts SET totp_secret = ? WHERE id = ?", "secret", account.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, moveTOTPSecrets(db))
+	require.NoError(t, moveTOTPSecrets(db))
+
+	account, err = store.Find(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.True(t, account.TOTPEnabled())
+	assert.False(t, account.TOTPSecret.Valid)
+
+	authenticators, err := store.GetTOTPAuthenticators(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, authenticators, 1)
+	assert.Equal(t, "secret", authenticators[0].Secret)
+}
@@ -1,6 +1,7 @@
 package sqlite3
 
 import (
+	"context"
 	"database/sql"
 	"encoding/hex"
 	"time"
@@ -14,14 +15,14 @@ import (
 )
 
 type RefreshTokenStore struct {
-	sqlx.Ext
+	sqlx.ExtContext
 	TTL time.Duration
 }
 
 func (s *RefreshTokenStore) Clean(reporter ops.ErrorReporter) {
 	go func() {
 		for range time.Tick(time.Minute + jitter()) {
-			_, err := s.Exec("DELETE FROM refresh_tokens WHERE expires_at < ?", time.Now())
+			_, err := s.ExecContext(context.Background(), "DELETE FROM refresh_tokens WHERE expires_at < ?", time.Now())
 			if err != nil {
 				reporter.ReportError(errors.Wrap(err, "RefreshTokenStore Clean"))
 			}
@@ -30,14 +31,14 @@ func (s *RefreshTokenStore) Clean(reporter ops.ErrorReporter) {
 	}()
 }
 
-func (s *RefreshTokenStore) Create(accountID int) (models.RefreshToken, error) {
+func (s *RefreshTokenStore) Create(ctx context.Context, accountID int) (models.RefreshToken, error) {
 	binToken, err := lib.GenerateToken()
 	if err != nil {
 		return "", err
 	}
 	token := hex.EncodeToString(binToken)
 
-	_, err = s.Exec(
+	_, err = s.ExecContext(ctx,
 		"INSERT INTO refresh_tokens (account_id, token, expires_at) VALUES (?, ?, ?)",
 		accountID,
 		token,
@@ -49,10 +50,26 @@ func (s *RefreshTokenStore) Create(accountID int) (models.RefreshToken, error) {
 	return models.RefreshToken(token), nil
 }
 
-func (s *RefreshTokenStore) Find(token models.RefreshToken) (int, error) {
+func (s *RefreshTokenStore) FindSessionID(ctx context.Context, token models.RefreshToken) (string, error) {
+	var sessionID sql.NullString
+	err := s.QueryRowxContext(ctx,
+		"SELECT session_id FROM refresh_tokens WHERE token = ?",
+		token,
+	).Scan(&sessionID)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return sessionID.String, nil
+}
+
+func (s *RefreshTokenStore) Find(ctx context.Context, token models.RefreshToken) (int, error) {
 	var accountID int
-	err := s.QueryRowx(
-		"SELECT account_id FROM refresh_tokens WHERE token = ? AND expires_at > ?",
+	err := s.QueryRowxContext(ctx,
+		"SELECT account_id FROM refresh_tokens WHERE token = ? AND NOT superseded AND expires_at > ?",
 		token,
 		time.Now(),
 	).Scan(&accountID)
@@ -66,9 +83,9 @@ func (s *RefreshTokenStore) Find(token models.RefreshToken) (int, error) {
 	return accountID, nil
 }
 
-func (s *RefreshTokenStore) Touch(token models.RefreshToken, accountID int) error {
-	_, err := s.Exec(
-		"UPDATE refresh_tokens SET expires_at = ? WHERE token = ? AND expires_at > ?",
+func (s *RefreshTokenStore) Touch(ctx context.Context, token models.RefreshToken, accountID int) error {
+	_, err := s.ExecContext(ctx,
+		"UPDATE refresh_tokens SET expires_at = ? WHERE token = ? AND NOT superseded AND expires_at > ?",
 		time.Now().Add(s.TTL),
 		token,
 		time.Now(),
@@ -76,10 +93,10 @@ func (s *RefreshTokenStore) Touch(token models.RefreshToken, accountID int) erro
 	return err
 }
 
-func (s *RefreshTokenStore) FindAll(accountID int) ([]models.RefreshToken, error) {
+func (s *RefreshTokenStore) FindAll(ctx context.Context, accountID int) ([]models.RefreshToken, error) {
 	var tokens []models.RefreshToken
-	rows, err := s.Query(
-		"SELECT token FROM refresh_tokens WHERE account_id = ? AND expires_at > ?",
+	rows, err := s.QueryContext(ctx,
+		"SELECT token FROM refresh_tokens WHERE account_id = ? AND NOT superseded AND expires_at > ?",
 		accountID,
 		time.Now(),
 	)
@@ -99,7 +116,89 @@ func (s *RefreshTokenStore) FindAll(accountID int) ([]models.RefreshToken, error
 	return tokens, nil
 }
 
-func (s *RefreshTokenStore) Revoke(token models.RefreshToken) error {
-	_, err := s.Exec("DELETE FROM refresh_tokens WHERE token = ?", token)
+func (s *RefreshTokenStore) Revoke(ctx context.Context, token models.RefreshToken) error {
+	_, err := s.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE token = ?", token)
+	return err
+}
+
+func (s *RefreshTokenStore) Rotate(ctx context.Context, token models.RefreshToken, accountID int) (models.RefreshToken, error) {
+	result, err := s.ExecContext(ctx,
+		"UPDATE refresh_tokens SET superseded = 1, superseded_at = ?, family = COALESCE(family, token) WHERE token = ? AND NOT superseded AND expires_at > ?",
+		time.Now(),
+		token,
+		time.Now(),
+	)
+	if err != nil {
+		return "", err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+	if count == 0 {
+		return "", nil
+	}
+
+	binToken, err := lib.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	newToken := hex.EncodeToString(binToken)
+
+	_, err = s.ExecContext(ctx,
+		"INSERT INTO refresh_tokens (account_id, token, family, session_id, expires_at) SELECT ?, ?, family, session_id, ? FROM refresh_tokens WHERE token = ?",
+		accountID,
+		newToken,
+		time.Now().Add(s.TTL),
+		token,
+	)
+	if err != nil {
+		return "", err
+	}
+	return models.RefreshToken(newToken), nil
+}
+
+func (s *RefreshTokenStore) RevokeFamily(ctx context.Context, token models.RefreshToken, grace time.Duration) (int, error) {
+	var accountID int
+	var family string
+	err := s.QueryRowxContext(ctx,
+		"SELECT account_id, family FROM refresh_tokens WHERE token = ? AND superseded AND (superseded_at IS NULL OR superseded_at <= ?) AND expires_at > ?",
+		token,
+		time.Now().Add(-grace),
+		time.Now(),
+	).Scan(&accountID, &family)
+
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	_, err = s.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE family = ?", family)
+	if err != nil {
+		return 0, err
+	}
+	return accountID, nil
+}
+
+func (s *RefreshTokenStore) SetSessionID(ctx context.Context, token models.RefreshToken, sessionID string) error {
+	_, err := s.ExecContext(ctx, "UPDATE refresh_tokens SET session_id = ? WHERE token = ?", sessionID, token)
 	return err
 }
+
+func (s *RefreshTokenStore) FindBySessionID(ctx context.Context, sessionID string) (models.RefreshToken, error) {
+	var token string
+	err := s.QueryRowxContext(ctx,
+		"SELECT token FROM refresh_tokens WHERE session_id = ? AND NOT superseded AND expires_at > ?",
+		sessionID,
+		time.Now(),
+	).Scan(&token)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return models.RefreshToken(token), nil
+}
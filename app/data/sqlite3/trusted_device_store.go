@@ -0,0 +1,104 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+type TrustedDeviceStore struct {
+	sqlx.ExtContext
+	TTL time.Duration
+}
+
+func (s *TrustedDeviceStore) Clean(reporter ops.ErrorReporter) {
+	go func() {
+		for range time.Tick(time.Minute + jitter()) {
+			_, err := s.ExecContext(context.Background(), "DELETE FROM trusted_devices WHERE expires_at < ?", time.Now())
+			if err != nil {
+				reporter.ReportError(errors.Wrap(err, "TrustedDeviceStore Clean"))
+			}
+			time.Sleep(time.Minute)
+		}
+	}()
+}
+
+func (s *TrustedDeviceStore) Create(ctx context.Context, accountID int, name string) (*models.TrustedDevice, error) {
+	binID, err := lib.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	device := &models.TrustedDevice{
+		ID:        hex.EncodeToString(binID),
+		AccountID: accountID,
+		Name:      name,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.TTL),
+	}
+	_, err = s.ExecContext(ctx,
+		"INSERT INTO trusted_devices (id, account_id, name, created_at, expires_at) VALUES (?, ?, ?, ?, ?)",
+		device.ID,
+		device.AccountID,
+		device.Name,
+		device.CreatedAt,
+		device.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *TrustedDeviceStore) Find(ctx context.Context, id string) (*models.TrustedDevice, error) {
+	device := models.TrustedDevice{}
+	err := sqlx.GetContext(ctx, s, &device,
+		"SELECT * FROM trusted_devices WHERE id = ? AND expires_at > ?",
+		id,
+		time.Now(),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (s *TrustedDeviceStore) FindAll(ctx context.Context, accountID int) ([]*models.TrustedDevice, error) {
+	devices := []*models.TrustedDevice{}
+	err := sqlx.SelectContext(ctx, s, &devices,
+		"SELECT * FROM trusted_devices WHERE account_id = ? AND expires_at > ? ORDER BY created_at",
+		accountID,
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (s *TrustedDeviceStore) Revoke(ctx context.Context, accountID int, id string) (bool, error) {
+	result, err := s.ExecContext(ctx, "DELETE FROM trusted_devices WHERE account_id = ? AND id = ?", accountID, id)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *TrustedDeviceStore) RevokeAll(ctx context.Context, accountID int) error {
+	_, err := s.ExecContext(ctx, "DELETE FROM trusted_devices WHERE account_id = ?", accountID)
+	return err
+}
@@ -0,0 +1,20 @@
+package sqlite3_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedDeviceStore(t *testing.T) {
+	for _, tester := range testers.TrustedDeviceStoreTesters {
+		db, err := sqlite3.TestDB()
+		require.NoError(t, err)
+		store := &sqlite3.TrustedDeviceStore{db, time.Minute}
+		tester(t, store)
+		db.Close()
+	}
+}
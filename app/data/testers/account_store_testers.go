@@ -1,10 +1,14 @@
 package testers
 
 import (
+	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tenants"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -12,12 +16,17 @@ import (
 var AccountStoreTesters = []func(*testing.T, data.AccountStore){
 	testCreate,
 	testFindByUsername,
+	testTenants,
 	testLockAndUnlock,
 	testArchive,
 	testArchiveWithOauth,
 	testRequireNewPassword,
 	testSetPassword,
-	testSetAndDeleteTOTP,
+	testTOTPAuthenticators,
+	testPersonalAccessTokens,
+	testArchiveWithPersonalAccessTokens,
+	testSetRequireMFA,
+	testSetOTPChannel,
 	testUpdateUsername,
 	testAddOauthAccount,
 	testFindByOauthAccount,
@@ -37,7 +46,7 @@ func getOpenConnectionCount(store data.AccountStore) int {
 }
 
 func testCreate(t *testing.T, store data.AccountStore) {
-	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
 	assert.NotEqual(t, 0, account.ID)
 	assert.Equal(t, "authn@keratin.tech", account.Username)
@@ -45,7 +54,7 @@ func testCreate(t *testing.T, store data.AccountStore) {
 	assert.NotEmpty(t, account.CreatedAt)
 	assert.NotEmpty(t, account.UpdatedAt)
 
-	account, err = store.Create("authn@keratin.tech", []byte("password"))
+	account, err = store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	if account != nil {
 		assert.NotEqual(t, nil, account)
 	}
@@ -53,7 +62,7 @@ func testCreate(t *testing.T, store data.AccountStore) {
 		t.Errorf("expected uniqueness error, got %T %v", err, err)
 	}
 
-	account, err = store.Create("AUTHN@KERATIN.TECH", []byte("password"))
+	account, err = store.Create(context.Background(), "AUTHN@KERATIN.TECH", []byte("password"))
 	if account != nil {
 		assert.NotEqual(t, nil, account)
 	}
@@ -66,18 +75,18 @@ func testCreate(t *testing.T, store data.AccountStore) {
 }
 
 func testFindByUsername(t *testing.T, store data.AccountStore) {
-	account, err := store.FindByUsername("authn@keratin.tech")
+	account, err := store.FindByUsername(context.Background(), "authn@keratin.tech")
 	assert.NoError(t, err)
 	assert.Nil(t, account)
 
-	_, err = store.Create("authn@keratin.tech", []byte("password"))
+	_, err = store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
 
-	account, err = store.FindByUsername("authn@keratin.tech")
+	account, err = store.FindByUsername(context.Background(), "authn@keratin.tech")
 	assert.NoError(t, err)
 	assert.NotNil(t, account)
 
-	account, err = store.FindByUsername("AUTHN@KERATIN.TECH")
+	account, err = store.FindByUsername(context.Background(), "AUTHN@KERATIN.TECH")
 	assert.NoError(t, err)
 	assert.NotNil(t, account)
 
@@ -85,24 +94,59 @@ func testFindByUsername(t *testing.T, store data.AccountStore) {
 	assert.Equal(t, 1, getOpenConnectionCount(store))
 }
 
+func testTenants(t *testing.T, store data.AccountStore) {
+	acme := tenants.WithContext(context.Background(), "acme")
+	globex := tenants.WithContext(context.Background(), "globex")
+
+	acmeAccount, err := store.Create(acme, "authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	assert.Equal(t, "acme", acmeAccount.Tenant)
+
+	globexAccount, err := store.Create(globex, "authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	assert.NotEqual(t, acmeAccount.ID, globexAccount.ID)
+
+	_, err = store.Create(acme, "AUTHN@keratin.tech", []byte("password"))
+	assert.True(t, data.IsUniquenessError(err))
+
+	account, err := store.FindByUsername(globex, "authn@keratin.tech")
+	require.NoError(t, err)
+	require.NotNil(t, account)
+	assert.Equal(t, globexAccount.ID, account.ID)
+	assert.Equal(t, "globex", account.Tenant)
+
+	account, err = store.FindByUsername(context.Background(), "authn@keratin.tech")
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	account, err = store.Find(globex, acmeAccount.ID)
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	account, err = store.Find(context.Background(), acmeAccount.ID)
+	require.NoError(t, err)
+	require.NotNil(t, account)
+	assert.Equal(t, "acme", account.Tenant)
+}
+
 func testLockAndUnlock(t *testing.T, store data.AccountStore) {
-	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
 	require.False(t, account.Locked)
 
-	ok, err := store.Lock(account.ID)
+	ok, err := store.Lock(context.Background(), account.ID)
 	assert.True(t, ok)
 	require.NoError(t, err)
 
-	after, err := store.Find(account.ID)
+	after, err := store.Find(context.Background(), account.ID)
 	require.NoError(t, err)
 	assert.True(t, after.Locked)
 
-	ok, err = store.Unlock(account.ID)
+	ok, err = store.Unlock(context.Background(), account.ID)
 	assert.True(t, ok)
 	require.NoError(t, err)
 
-	after2, err := store.Find(account.ID)
+	after2, err := store.Find(context.Background(), account.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, after2)
 	assert.False(t, after2.Locked)
@@ -112,24 +156,24 @@ func testLockAndUnlock(t *testing.T, store data.AccountStore) {
 }
 
 func testArchive(t *testing.T, store data.AccountStore) {
-	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
 	require.Empty(t, account.DeletedAt)
 
-	ok, err := store.Archive(account.ID)
+	ok, err := store.Archive(context.Background(), account.ID)
 	assert.True(t, ok)
 	require.NoError(t, err)
 
-	after, err := store.Find(account.ID)
+	after, err := store.Find(context.Background(), account.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, after)
 	assert.Empty(t, after.Username)
 	assert.Empty(t, after.Password)
 	assert.NotEmpty(t, after.DeletedAt)
 
-	account2, err := store.Create("authn@keratin.tech", []byte("password"))
+	account2, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	if assert.NoError(t, err) {
-		ok, err = store.Archive(account2.ID)
+		ok, err = store.Archive(context.Background(), account2.ID)
 		assert.True(t, ok)
 		assert.NoError(t, err)
 	}
@@ -139,16 +183,16 @@ func testArchive(t *testing.T, store data.AccountStore) {
 }
 
 func testArchiveWithOauth(t *testing.T, store data.AccountStore) {
-	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
-	err = store.AddOauthAccount(account.ID, "PROVIDER", "PROVIDERID", "email", "token")
+	err = store.AddOauthAccount(context.Background(), account.ID, "PROVIDER", "PROVIDERID", "email", "token")
 	require.NoError(t, err)
 
-	ok, err := store.Archive(account.ID)
+	ok, err := store.Archive(context.Background(), account.ID)
 	assert.True(t, ok)
 	require.NoError(t, err)
 
-	found, err := store.FindByOauthAccount("PROVIDER", "PROVIDERID")
+	found, err := store.FindByOauthAccount(context.Background(), "PROVIDER", "PROVIDERID")
 	require.NoError(t, err)
 	assert.Empty(t, found)
 
@@ -157,15 +201,15 @@ func testArchiveWithOauth(t *testing.T, store data.AccountStore) {
 }
 
 func testRequireNewPassword(t *testing.T, store data.AccountStore) {
-	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
 	require.False(t, account.RequireNewPassword)
 
-	ok, err := store.RequireNewPassword(account.ID)
+	ok, err := store.RequireNewPassword(context.Background(), account.ID)
 	assert.True(t, ok)
 	require.NoError(t, err)
 
-	after, err := store.Find(account.ID)
+	after, err := store.Find(context.Background(), account.ID)
 	require.NoError(t, err)
 	assert.True(t, after.RequireNewPassword)
 
@@ -174,17 +218,17 @@ func testRequireNewPassword(t *testing.T, store data.AccountStore) {
 }
 
 func testSetPassword(t *testing.T, store data.AccountStore) {
-	account, err := store.Create("authn@keratin.tech", []byte("old"))
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("old"))
 	require.NoError(t, err)
-	ok, err := store.RequireNewPassword(account.ID)
+	ok, err := store.RequireNewPassword(context.Background(), account.ID)
 	require.True(t, ok)
 	require.NoError(t, err)
 
-	ok, err = store.SetPassword(account.ID, []byte("new"))
+	ok, err = store.SetPassword(context.Background(), account.ID, []byte("new"))
 	assert.True(t, ok)
 	require.NoError(t, err)
 
-	after, err := store.Find(account.ID)
+	after, err := store.Find(context.Background(), account.ID)
 	require.NoError(t, err)
 	assert.Equal(t, []byte("new"), after.Password)
 	assert.False(t, after.RequireNewPassword)
@@ -194,60 +238,244 @@ func testSetPassword(t *testing.T, store data.AccountStore) {
 	assert.Equal(t, 1, getOpenConnectionCount(store))
 }
 
-func testSetAndDeleteTOTP(t *testing.T, store data.AccountStore) {
-	account, err := store.Create("authn@keratin.tech", []byte("password"))
+func testTOTPAuthenticators(t *testing.T, store data.AccountStore) {
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
 	assert.False(t, account.TOTPEnabled())
-	assert.False(t, account.TOTPSecret.Valid)
 
-	//Check set
-	ok, err := store.SetTOTPSecret(account.ID, []byte("secret"))
-	assert.True(t, ok)
+	//Check add
+	phone, err := store.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("secret"), 0)
+	require.NoError(t, err)
+	assert.NotEmpty(t, phone.ID)
+	tablet, err := store.AddTOTPAuthenticator(context.Background(), account.ID, "tablet", []byte("other"), 50)
 	require.NoError(t, err)
 
-	after, err := store.Find(account.ID)
+	after, err := store.Find(context.Background(), account.ID)
 	require.NoError(t, err)
-	assert.Equal(t, "secret", after.TOTPSecret.String)
 	assert.True(t, after.TOTPEnabled())
-	assert.True(t, after.TOTPSecret.Valid)
+	assert.Equal(t, 2, after.TOTPAuthenticators)
 
-	//Check delete
-	ok, err = store.DeleteTOTPSecret(account.ID)
+	authenticators, err := store.GetTOTPAuthenticators(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, authenticators, 2)
+	assert.Equal(t, "phone", authenticators[0].Name)
+	assert.Equal(t, "secret", authenticators[0].Secret)
+	assert.Nil(t, authenticators[0].LastUsedAt)
+	assert.Equal(t, "tablet", authenticators[1].Name)
+	assert.Equal(t, int64(50), authenticators[1].LastUsedStep)
+
+	//Check last used
+	ok, err := store.SetTOTPAuthenticatorLastUsed(context.Background(), account.ID, phone.ID, 100)
+	require.NoError(t, err)
 	assert.True(t, ok)
+	authenticators, err = store.GetTOTPAuthenticators(context.Background(), account.ID)
 	require.NoError(t, err)
+	assert.NotNil(t, authenticators[0].LastUsedAt)
+	assert.Equal(t, int64(100), authenticators[0].LastUsedStep)
 
-	after, err = store.Find(account.ID)
+	//Check replayed steps
+	ok, err = store.SetTOTPAuthenticatorLastUsed(context.Background(), account.ID, phone.ID, 100)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = store.SetTOTPAuthenticatorLastUsed(context.Background(), account.ID, phone.ID, 99)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = store.SetTOTPAuthenticatorLastUsed(context.Background(), account.ID, phone.ID, 101)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	//Check delete one
+	ok, err = store.DeleteTOTPAuthenticator(context.Background(), account.ID+1, phone.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = store.DeleteTOTPAuthenticator(context.Background(), account.ID, phone.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	authenticators, err = store.GetTOTPAuthenticators(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, authenticators, 1)
+	assert.Equal(t, tablet.ID, authenticators[0].ID)
+
+	//Check delete all
+	ok, err = store.DeleteTOTPAuthenticators(context.Background(), account.ID)
+	assert.True(t, ok)
+	require.NoError(t, err)
+
+	after, err = store.Find(context.Background(), account.ID)
 	require.NoError(t, err)
 	assert.False(t, after.TOTPEnabled())
-	assert.False(t, after.TOTPSecret.Valid)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
+func testPersonalAccessTokens(t *testing.T, store data.AccountStore) {
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+
+	//Check add
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	cli, err := store.AddPersonalAccessToken(context.Background(), account.ID, "cli", "hash1", models.NameList{"read", "write"}, "https://app.example.com", &expiresAt)
+	require.NoError(t, err)
+	assert.NotEmpty(t, cli.ID)
+	ci, err := store.AddPersonalAccessToken(context.Background(), account.ID, "ci", "hash2", models.NameList{}, "https://app.example.com", nil)
+	require.NoError(t, err)
+
+	_, err = store.AddPersonalAccessToken(context.Background(), account.ID, "dup", "hash1", models.NameList{}, "", nil)
+	if assert.Error(t, err) {
+		assert.True(t, data.IsUniquenessError(err))
+	}
+
+	tokens, err := store.GetPersonalAccessTokens(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "cli", tokens[0].Name)
+	assert.Equal(t, models.NameList{"read", "write"}, tokens[0].Scopes)
+	assert.Equal(t, "https://app.example.com", tokens[0].Audience)
+	if assert.NotNil(t, tokens[0].ExpiresAt) {
+		assert.True(t, expiresAt.Equal(*tokens[0].ExpiresAt))
+	}
+	assert.Nil(t, tokens[0].LastUsedAt)
+	assert.Equal(t, "ci", tokens[1].Name)
+	assert.Nil(t, tokens[1].ExpiresAt)
+
+	//Check find
+	found, err := store.FindPersonalAccessToken(context.Background(), "hash2")
+	require.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, ci.ID, found.ID)
+		assert.Equal(t, account.ID, found.AccountID)
+	}
+	found, err = store.FindPersonalAccessToken(context.Background(), "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	//Check last used
+	ok, err := store.SetPersonalAccessTokenLastUsed(context.Background(), cli.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	found, err = store.FindPersonalAccessToken(context.Background(), "hash1")
+	require.NoError(t, err)
+	assert.NotNil(t, found.LastUsedAt)
+
+	//Check delete
+	ok, err = store.DeletePersonalAccessToken(context.Background(), account.ID+1, cli.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = store.DeletePersonalAccessToken(context.Background(), account.ID, cli.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	tokens, err = store.GetPersonalAccessTokens(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, ci.ID, tokens[0].ID)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
+func testArchiveWithPersonalAccessTokens(t *testing.T, store data.AccountStore) {
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	_, err = store.AddPersonalAccessToken(context.Background(), account.ID, "cli", "hash", models.NameList{}, "", nil)
+	require.NoError(t, err)
+
+	ok, err := store.Archive(context.Background(), account.ID)
+	assert.True(t, ok)
+	require.NoError(t, err)
+
+	found, err := store.FindPersonalAccessToken(context.Background(), "hash")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
+func testSetRequireMFA(t *testing.T, store data.AccountStore) {
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	assert.False(t, account.RequireMFA)
+
+	ok, err := store.SetRequireMFA(context.Background(), account.ID, true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	after, err := store.Find(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.True(t, after.RequireMFA)
+
+	ok, err = store.SetRequireMFA(context.Background(), account.ID, false)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	after, err = store.Find(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.False(t, after.RequireMFA)
+
+	ok, err = store.SetRequireMFA(context.Background(), 0, true)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
+func testSetOTPChannel(t *testing.T, store data.AccountStore) {
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	assert.False(t, account.OTPChannelEnabled())
+
+	ok, err := store.SetOTPChannel(context.Background(), account.ID, "sms")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	after, err := store.Find(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.True(t, after.OTPChannelEnabled())
+	assert.Equal(t, "sms", after.OTPChannel.String)
+
+	ok, err = store.SetOTPChannel(context.Background(), account.ID, "")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	after, err = store.Find(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.False(t, after.OTPChannelEnabled())
+
+	ok, err = store.SetOTPChannel(context.Background(), 0, "sms")
+	require.NoError(t, err)
+	assert.False(t, ok)
 
 	// Assert that db connections are released to pool
 	assert.Equal(t, 1, getOpenConnectionCount(store))
 }
 
 func testUpdateUsername(t *testing.T, store data.AccountStore) {
-	other, err := store.Create("other", []byte("other"))
+	other, err := store.Create(context.Background(), "other", []byte("other"))
 	require.NoError(t, err)
 
-	account, err := store.Create("old", []byte("old"))
+	account, err := store.Create(context.Background(), "old", []byte("old"))
 	require.NoError(t, err)
 
-	ok, err := store.UpdateUsername(account.ID, "new")
+	ok, err := store.UpdateUsername(context.Background(), account.ID, "new")
 	assert.True(t, ok)
 	require.NoError(t, err)
 
-	after, err := store.Find(account.ID)
+	after, err := store.Find(context.Background(), account.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "new", after.Username)
 
-	ok, err = store.UpdateUsername(account.ID, other.Username)
+	ok, err = store.UpdateUsername(context.Background(), account.ID, other.Username)
 	assert.False(t, ok)
 	if err == nil || !data.IsUniquenessError(err) {
 		t.Errorf("expected uniqueness error, got %T %v", err, err)
 	}
 
 	// "changing" to existing username
-	_, err = store.UpdateUsername(account.ID, "new")
+	_, err = store.UpdateUsername(context.Background(), account.ID, "new")
 	require.NoError(t, err)
 
 	// Assert that db connections are released to pool
@@ -255,16 +483,16 @@ func testUpdateUsername(t *testing.T, store data.AccountStore) {
 }
 
 func testAddOauthAccount(t *testing.T, store data.AccountStore) {
-	found, err := store.GetOauthAccounts(1)
+	found, err := store.GetOauthAccounts(context.Background(), 1)
 	require.NoError(t, err)
 	assert.Len(t, found, 0)
 
-	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	assert.NoError(t, err)
-	err = store.AddOauthAccount(account.ID, "OAUTHPROVIDER", "PROVIDERID", "email", "TOKEN")
+	err = store.AddOauthAccount(context.Background(), account.ID, "OAUTHPROVIDER", "PROVIDERID", "email", "TOKEN")
 	assert.NoError(t, err)
 
-	found, err = store.GetOauthAccounts(account.ID)
+	found, err = store.GetOauthAccounts(context.Background(), account.ID)
 	require.NoError(t, err)
 	assert.Len(t, found, 1)
 	assert.Equal(t, account.ID, found[0].AccountID)
@@ -274,7 +502,7 @@ func testAddOauthAccount(t *testing.T, store data.AccountStore) {
 	assert.NotEmpty(t, found[0].CreatedAt)
 	assert.NotEmpty(t, found[0].UpdatedAt)
 
-	err = store.AddOauthAccount(account.ID, "OAUTHPROVIDER", "PROVIDERID2", "email", "TOKEN")
+	err = store.AddOauthAccount(context.Background(), account.ID, "OAUTHPROVIDER", "PROVIDERID2", "email", "TOKEN")
 	if err == nil || !data.IsUniquenessError(err) {
 		t.Errorf("expected uniqueness error, got %T %v", err, err)
 	}
@@ -284,24 +512,24 @@ func testAddOauthAccount(t *testing.T, store data.AccountStore) {
 }
 
 func testFindByOauthAccount(t *testing.T, store data.AccountStore) {
-	found, err := store.FindByOauthAccount("unknown", "unknown")
+	found, err := store.FindByOauthAccount(context.Background(), "unknown", "unknown")
 	assert.NoError(t, err)
 	assert.Nil(t, found)
 
-	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	account, err := store.Create(context.Background(), "authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
-	err = store.AddOauthAccount(account.ID, "OAUTHPROVIDER", "PROVIDERID", "email", "TOKEN")
+	err = store.AddOauthAccount(context.Background(), account.ID, "OAUTHPROVIDER", "PROVIDERID", "email", "TOKEN")
 	require.NoError(t, err)
 
-	found, err = store.FindByOauthAccount("unknown", "PROVIDERID")
+	found, err = store.FindByOauthAccount(context.Background(), "unknown", "PROVIDERID")
 	assert.NoError(t, err)
 	assert.Nil(t, found)
 
-	found, err = store.FindByOauthAccount("OAUTHPROVIDER", "unknown")
+	found, err = store.FindByOauthAccount(context.Background(), "OAUTHPROVIDER", "unknown")
 	assert.NoError(t, err)
 	assert.Nil(t, found)
 
-	found, err = store.FindByOauthAccount("OAUTHPROVIDER", "PROVIDERID")
+	found, err = store.FindByOauthAccount(context.Background(), "OAUTHPROVIDER", "PROVIDERID")
 	assert.NoError(t, err)
 	assert.Equal(t, account.ID, found.ID)
 
@@ -310,14 +538,14 @@ func testFindByOauthAccount(t *testing.T, store data.AccountStore) {
 }
 
 func testSetLastLogin(t *testing.T, store data.AccountStore) {
-	account, err := store.Create("old", []byte("old"))
+	account, err := store.Create(context.Background(), "old", []byte("old"))
 	require.NoError(t, err)
 
-	rowsIsAffected, err := store.SetLastLogin(account.ID)
+	rowsIsAffected, err := store.SetLastLogin(context.Background(), account.ID)
 	require.NoError(t, err)
 	require.Equal(t, true, rowsIsAffected)
 
-	after, err := store.Find(account.ID)
+	after, err := store.Find(context.Background(), account.ID)
 	require.NoError(t, err)
 	assert.NotEqual(t, nil, after.LastLoginAt)
 
@@ -1,6 +1,7 @@
 package testers
 
 import (
+	"context"
 	"strconv"
 	"testing"
 	"time"
@@ -18,11 +19,11 @@ var ActivesTesters = []func(*testing.T, data.Actives){
 }
 
 func testActivesTrack(t *testing.T, actives data.Actives) {
-	require.NoError(t, actives.Track(1))
-	require.NoError(t, actives.Track(5))
-	require.NoError(t, actives.Track(6))
+	require.NoError(t, actives.Track(context.Background(), 1))
+	require.NoError(t, actives.Track(context.Background(), 5))
+	require.NoError(t, actives.Track(context.Background(), 6))
 
-	report, err := actives.ActivesByDay()
+	report, err := actives.ActivesByDay(context.Background())
 	require.NoError(t, err)
 	if assert.Len(t, report, 1) {
 		assert.Equal(t, []int{3}, mapVals(report))
@@ -30,9 +31,9 @@ func testActivesTrack(t *testing.T, actives data.Actives) {
 }
 
 func testActivesActivesByDay(t *testing.T, actives data.Actives) {
-	require.NoError(t, actives.Track(1))
+	require.NoError(t, actives.Track(context.Background(), 1))
 
-	report, err := actives.ActivesByDay()
+	report, err := actives.ActivesByDay(context.Background())
 	require.NoError(t, err)
 	if assert.Len(t, report, 1) {
 		assert.Equal(t, map[string]int{time.Now().In(time.UTC).Format("2006-01-02"): 1}, report)
@@ -40,9 +41,9 @@ func testActivesActivesByDay(t *testing.T, actives data.Actives) {
 }
 
 func testActivesActivesByWeek(t *testing.T, actives data.Actives) {
-	require.NoError(t, actives.Track(1))
+	require.NoError(t, actives.Track(context.Background(), 1))
 
-	report, err := actives.ActivesByWeek()
+	report, err := actives.ActivesByWeek(context.Background())
 	require.NoError(t, err)
 	if assert.Len(t, report, 1) {
 		y, w := time.Now().In(time.UTC).ISOWeek()
@@ -52,9 +53,9 @@ func testActivesActivesByWeek(t *testing.T, actives data.Actives) {
 }
 
 func testActivesActivesByMonth(t *testing.T, actives data.Actives) {
-	require.NoError(t, actives.Track(1))
+	require.NoError(t, actives.Track(context.Background(), 1))
 
-	report, err := actives.ActivesByMonth()
+	report, err := actives.ActivesByMonth(context.Background())
 	require.NoError(t, err)
 	if assert.Len(t, report, 1) {
 		assert.Equal(t, map[string]int{time.Now().In(time.UTC).Format("2006-01"): 1}, report)
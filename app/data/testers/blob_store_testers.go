@@ -1,6 +1,7 @@
 package testers
 
 import (
+	"context"
 	"testing"
 
 	"github.com/keratin/authn-server/app/data"
@@ -12,56 +13,90 @@ var BlobStoreTesters = []func(*testing.T, data.BlobStore){
 	testRead,
 	testWriteNX,
 	testWrite,
+	testRewrite,
 	testDelete,
+	testNames,
 }
 
 func testRead(t *testing.T, bs data.BlobStore) {
-	blob, err := bs.Read("unknown")
+	blob, err := bs.Read(context.Background(), "unknown")
 	assert.NoError(t, err)
 	assert.Empty(t, blob)
 
-	ok, err := bs.WriteNX("blob", []byte("val"))
+	ok, err := bs.WriteNX(context.Background(), "blob", []byte("val"))
 	require.NoError(t, err)
 	require.True(t, ok)
 
-	blob, err = bs.Read("blob")
+	blob, err = bs.Read(context.Background(), "blob")
 	assert.NoError(t, err)
 	assert.Equal(t, "val", string(blob))
 }
 
 func testWriteNX(t *testing.T, bs data.BlobStore) {
-	set, err := bs.WriteNX("key", []byte("first"))
+	set, err := bs.WriteNX(context.Background(), "key", []byte("first"))
 	assert.NoError(t, err)
 	assert.True(t, set)
 
-	set, err = bs.WriteNX("key", []byte("second"))
+	set, err = bs.WriteNX(context.Background(), "key", []byte("second"))
 	assert.NoError(t, err)
 	assert.False(t, set)
 }
 
 func testWrite(t *testing.T, bs data.BlobStore) {
-	set, err := bs.Write("key", []byte("first"))
+	set, err := bs.Write(context.Background(), "key", []byte("first"))
 	assert.NoError(t, err)
 	assert.Equal(t, true, set)
 
-	set, err = bs.Write("key", []byte("second"))
+	set, err = bs.Write(context.Background(), "key", []byte("second"))
 	assert.NoError(t, err)
 	assert.Equal(t, true, set)
 }
 
+func testRewrite(t *testing.T, bs data.BlobStore) {
+	set, err := bs.Rewrite(context.Background(), "key", []byte("first"))
+	assert.NoError(t, err)
+	assert.False(t, set)
+
+	_, err = bs.Write(context.Background(), "key", []byte("first"))
+	require.NoError(t, err)
+
+	set, err = bs.Rewrite(context.Background(), "key", []byte("second"))
+	assert.NoError(t, err)
+	assert.True(t, set)
+
+	blob, err := bs.Read(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(blob))
+}
+
 func testDelete(t *testing.T, bs data.BlobStore) {
-	set, err := bs.Write("key", []byte("first"))
+	set, err := bs.Write(context.Background(), "key", []byte("first"))
 	assert.NoError(t, err)
 	assert.Equal(t, true, set)
 
-	blob, err := bs.Read("key")
+	blob, err := bs.Read(context.Background(), "key")
 	assert.NoError(t, err)
 	assert.Equal(t, "first", string(blob))
 
-	err = bs.Delete("key")
+	err = bs.Delete(context.Background(), "key")
 	assert.NoError(t, err)
 
-	blob, err = bs.Read("key")
+	blob, err = bs.Read(context.Background(), "key")
 	assert.NoError(t, err)
 	assert.Nil(t, blob)
 }
+
+func testNames(t *testing.T, bs data.BlobStore) {
+	for _, name := range []string{"a:1", "a:2", "b:1"} {
+		_, err := bs.Write(context.Background(), name, []byte("val"))
+		require.NoError(t, err)
+	}
+
+	names, err := bs.Names(context.Background(), "a:")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a:1", "a:2"}, names)
+
+	names, err = bs.Names(context.Background(), "c:")
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
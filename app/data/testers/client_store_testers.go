@@ -0,0 +1,105 @@
+package testers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ClientStoreTesters = []func(*testing.T, data.ClientStore){
+	testClientCreate,
+	testClientFindAll,
+	testClientUpdate,
+	testClientDelete,
+}
+
+func testClientCreate(t *testing.T, store data.ClientStore) {
+	// finding nothing
+	found, err := store.Find(context.Background(), "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	client := &models.Client{
+		ID:        "a1b2c3",
+		Name:      "billing",
+		Secret:    []byte("hashed"),
+		Scopes:    models.NameList{"read", "write"},
+		Audiences: models.NameList{"https://api.example.com"},
+	}
+	err = store.Create(context.Background(), client)
+	require.NoError(t, err)
+	assert.NotEmpty(t, client.CreatedAt)
+
+	found, err = store.Find(context.Background(), "a1b2c3")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "billing", found.Name)
+	assert.Equal(t, []byte("hashed"), found.Secret)
+	assert.Equal(t, "", found.PublicKey)
+	assert.Equal(t, models.NameList{"read", "write"}, found.Scopes)
+	assert.Equal(t, models.NameList{"https://api.example.com"}, found.Audiences)
+
+	// client IDs are unique
+	err = store.Create(context.Background(), &models.Client{ID: "a1b2c3", Name: "other"})
+	if assert.Error(t, err) {
+		assert.True(t, data.IsUniquenessError(err))
+	}
+}
+
+func testClientFindAll(t *testing.T, store data.ClientStore) {
+	clients, err := store.FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, clients, 0)
+
+	err = store.Create(context.Background(), &models.Client{ID: "a1b2c3", Name: "billing", PublicKey: "PEM"})
+	require.NoError(t, err)
+
+	clients, err = store.FindAll(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, clients, 1) {
+		assert.Equal(t, "a1b2c3", clients[0].ID)
+		assert.Equal(t, "PEM", clients[0].PublicKey)
+		assert.Empty(t, clients[0].Secret)
+		assert.Empty(t, clients[0].Scopes)
+	}
+}
+
+func testClientUpdate(t *testing.T, store data.ClientStore) {
+	updated, err := store.Update(context.Background(), "unknown", "billing", nil, nil)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	err = store.Create(context.Background(), &models.Client{ID: "a1b2c3", Name: "billing", Scopes: models.NameList{"read"}})
+	require.NoError(t, err)
+
+	updated, err = store.Update(context.Background(), "a1b2c3", "invoicing", models.NameList{"write"}, models.NameList{"https://api.example.com"})
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	found, err := store.Find(context.Background(), "a1b2c3")
+	require.NoError(t, err)
+	assert.Equal(t, "invoicing", found.Name)
+	assert.Equal(t, models.NameList{"write"}, found.Scopes)
+	assert.Equal(t, models.NameList{"https://api.example.com"}, found.Audiences)
+}
+
+func testClientDelete(t *testing.T, store data.ClientStore) {
+	deleted, err := store.Delete(context.Background(), "unknown")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+
+	err = store.Create(context.Background(), &models.Client{ID: "a1b2c3", Name: "billing"})
+	require.NoError(t, err)
+
+	deleted, err = store.Delete(context.Background(), "a1b2c3")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	found, err := store.Find(context.Background(), "a1b2c3")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+}
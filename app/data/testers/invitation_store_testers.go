@@ -0,0 +1,35 @@
+package testers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var InvitationStoreTesters = []func(*testing.T, data.InvitationStore){
+	testInvitationAccept,
+}
+
+func testInvitationAccept(t *testing.T, store data.InvitationStore) {
+	accountID, err := store.FindAccepted(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Empty(t, accountID)
+
+	err = store.Accept(context.Background(), "abc123", 42)
+	require.NoError(t, err)
+
+	accountID, err = store.FindAccepted(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, 42, accountID)
+
+	accountID, err = store.FindAccepted(context.Background(), "def456")
+	require.NoError(t, err)
+	assert.Empty(t, accountID)
+
+	// accepting again
+	err = store.Accept(context.Background(), "abc123", 43)
+	assert.Error(t, err)
+}
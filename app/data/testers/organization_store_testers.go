@@ -0,0 +1,133 @@
+package testers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var OrganizationStoreTesters = []func(*testing.T, data.OrganizationStore){
+	testOrganizationCreate,
+	testOrganizationUpdate,
+	testOrganizationMemberships,
+	testOrganizationDelete,
+}
+
+func testOrganizationCreate(t *testing.T, store data.OrganizationStore) {
+	// finding nothing
+	found, err := store.Find(context.Background(), 1234)
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	organization, err := store.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	assert.NotEmpty(t, organization.ID)
+	assert.NotEmpty(t, organization.CreatedAt)
+
+	found, err = store.Find(context.Background(), organization.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "Acme", found.Name)
+}
+
+func testOrganizationUpdate(t *testing.T, store data.OrganizationStore) {
+	ok, err := store.Update(context.Background(), 1234, "Globex")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	organization, err := store.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+
+	ok, err = store.Update(context.Background(), organization.ID, "Globex")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	found, err := store.Find(context.Background(), organization.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Globex", found.Name)
+}
+
+func testOrganizationMemberships(t *testing.T, store data.OrganizationStore) {
+	acme, err := store.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	globex, err := store.Create(context.Background(), "Globex")
+	require.NoError(t, err)
+
+	membership, err := store.FindMembership(context.Background(), acme.ID, 1)
+	require.NoError(t, err)
+	assert.Nil(t, membership)
+
+	err = store.SetMembership(context.Background(), acme.ID, 1, models.NameList{"admin"})
+	require.NoError(t, err)
+	err = store.SetMembership(context.Background(), acme.ID, 2, models.NameList{})
+	require.NoError(t, err)
+	err = store.SetMembership(context.Background(), globex.ID, 1, models.NameList{"billing", "viewer"})
+	require.NoError(t, err)
+
+	membership, err = store.FindMembership(context.Background(), acme.ID, 1)
+	require.NoError(t, err)
+	require.NotNil(t, membership)
+	assert.Equal(t, "Acme", membership.OrganizationName)
+	assert.Equal(t, models.NameList{"admin"}, membership.Roles)
+
+	// replacing roles
+	err = store.SetMembership(context.Background(), acme.ID, 1, models.NameList{"owner", "admin"})
+	require.NoError(t, err)
+	membership, err = store.FindMembership(context.Background(), acme.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.NameList{"owner", "admin"}, membership.Roles)
+
+	members, err := store.GetMembers(context.Background(), acme.ID)
+	require.NoError(t, err)
+	if assert.Len(t, members, 2) {
+		assert.Equal(t, 1, members[0].AccountID)
+		assert.Equal(t, 2, members[1].AccountID)
+		assert.Empty(t, members[1].Roles)
+	}
+
+	memberships, err := store.GetMemberships(context.Background(), 1)
+	require.NoError(t, err)
+	if assert.Len(t, memberships, 2) {
+		assert.Equal(t, acme.ID, memberships[0].OrganizationID)
+		assert.Equal(t, "Globex", memberships[1].OrganizationName)
+		assert.Equal(t, models.NameList{"billing", "viewer"}, memberships[1].Roles)
+	}
+
+	ok, err := store.DeleteMembership(context.Background(), acme.ID, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.DeleteMembership(context.Background(), acme.ID, 1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	memberships, err = store.GetMemberships(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Len(t, memberships, 1)
+}
+
+func testOrganizationDelete(t *testing.T, store data.OrganizationStore) {
+	ok, err := store.Delete(context.Background(), 1234)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	organization, err := store.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	err = store.SetMembership(context.Background(), organization.ID, 1, models.NameList{"admin"})
+	require.NoError(t, err)
+
+	ok, err = store.Delete(context.Background(), organization.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	found, err := store.Find(context.Background(), organization.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	memberships, err := store.GetMemberships(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, memberships)
+}
@@ -1,7 +1,9 @@
 package testers
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/models"
@@ -15,20 +17,24 @@ var RefreshTokenStoreTesters = []func(*testing.T, data.RefreshTokenStore){
 	testRefreshTokenFindAll,
 	testRefreshTokenCreate,
 	testRefreshTokenRevoke,
+	testRefreshTokenRotate,
+	testRefreshTokenRevokeFamily,
+	testRefreshTokenSessionID,
+	testRefreshTokenFindSessionID,
 }
 
 // TODO: find way to test that expired tokens are not found
 func testRefreshTokenFind(t *testing.T, store data.RefreshTokenStore) {
 	// finding nothing
-	id, err := store.Find(models.RefreshToken("a1b2c3"))
+	id, err := store.Find(context.Background(), models.RefreshToken("a1b2c3"))
 	assert.Empty(t, id)
 	assert.NoError(t, err)
 
 	// finding something
 	id2 := 123
-	token, err := store.Create(id2)
+	token, err := store.Create(context.Background(), id2)
 	require.NoError(t, err)
-	found, err := store.Find(token)
+	found, err := store.Find(context.Background(), token)
 	if assert.NoError(t, err) {
 		assert.Equal(t, found, id2)
 	}
@@ -36,7 +42,7 @@ func testRefreshTokenFind(t *testing.T, store data.RefreshTokenStore) {
 
 // TODO: find way to test for not touching expired tokens
 func testRefreshTokenTouch(t *testing.T, store data.RefreshTokenStore) {
-	err := store.Touch(models.RefreshToken("a1b2c3"), 123)
+	err := store.Touch(context.Background(), models.RefreshToken("a1b2c3"), 123)
 	assert.NoError(t, err)
 }
 
@@ -45,15 +51,15 @@ func testRefreshTokenFindAll(t *testing.T, store data.RefreshTokenStore) {
 	id := 123
 
 	// finding nothing
-	tokens, err := store.FindAll(id)
+	tokens, err := store.FindAll(context.Background(), id)
 	assert.NoError(t, err)
 	assert.Len(t, tokens, 0)
 
-	token, err := store.Create(id)
+	token, err := store.Create(context.Background(), id)
 	require.NoError(t, err)
 
 	// finding something
-	tokens2, err := store.FindAll(id)
+	tokens2, err := store.FindAll(context.Background(), id)
 	assert.NoError(t, err)
 	assert.Equal(t, []models.RefreshToken{token}, tokens2)
 }
@@ -61,11 +67,11 @@ func testRefreshTokenFindAll(t *testing.T, store data.RefreshTokenStore) {
 func testRefreshTokenCreate(t *testing.T, store data.RefreshTokenStore) {
 	id := 123
 
-	token, err := store.Create(id)
+	token, err := store.Create(context.Background(), id)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
-	tokens, err := store.FindAll(id)
+	tokens, err := store.FindAll(context.Background(), id)
 	assert.NoError(t, err)
 	assert.Equal(t, []models.RefreshToken{token}, tokens)
 }
@@ -73,29 +79,153 @@ func testRefreshTokenCreate(t *testing.T, store data.RefreshTokenStore) {
 func testRefreshTokenRevoke(t *testing.T, store data.RefreshTokenStore) {
 	id := 123
 
-	err := store.Revoke(models.RefreshToken("a1b2c3"))
+	err := store.Revoke(context.Background(), models.RefreshToken("a1b2c3"))
 	assert.NoError(t, err)
 
-	token, err := store.Create(id)
+	token, err := store.Create(context.Background(), id)
 	require.NoError(t, err)
 
-	found, err := store.Find(token)
+	found, err := store.Find(context.Background(), token)
 	if assert.NoError(t, err) {
 		assert.Equal(t, found, id)
 	}
 
-	tokens, err := store.FindAll(id)
+	tokens, err := store.FindAll(context.Background(), id)
 	assert.NoError(t, err)
 	assert.Equal(t, []models.RefreshToken{token}, tokens)
 
-	err = store.Revoke(token)
+	err = store.Revoke(context.Background(), token)
 	assert.NoError(t, err)
 
-	found2, err := store.Find(token)
+	found2, err := store.Find(context.Background(), token)
 	assert.Empty(t, found2)
 	assert.NoError(t, err)
 
-	tokens2, err := store.FindAll(id)
+	tokens2, err := store.FindAll(context.Background(), id)
 	assert.NoError(t, err)
 	assert.Len(t, tokens2, 0)
 }
+
+func testRefreshTokenRotate(t *testing.T, store data.RefreshTokenStore) {
+	id := 123
+
+	token, err := store.Create(context.Background(), id)
+	require.NoError(t, err)
+
+	rotated, err := store.Rotate(context.Background(), token, id)
+	require.NoError(t, err)
+	assert.NotEmpty(t, rotated)
+	assert.NotEqual(t, token, rotated)
+
+	found, err := store.Find(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	found, err = store.Find(context.Background(), rotated)
+	assert.NoError(t, err)
+	assert.Equal(t, id, found)
+
+	tokens, err := store.FindAll(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RefreshToken{rotated}, tokens)
+
+	// a token may only be rotated once
+	again, err := store.Rotate(context.Background(), token, id)
+	assert.NoError(t, err)
+	assert.Empty(t, again)
+}
+
+func testRefreshTokenRevokeFamily(t *testing.T, store data.RefreshTokenStore) {
+	id := 123
+
+	// unknown and active tokens are not superseded
+	found, err := store.RevokeFamily(context.Background(), models.RefreshToken("a1b2c3"), 0)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	token, err := store.Create(context.Background(), id)
+	require.NoError(t, err)
+	found, err = store.RevokeFamily(context.Background(), token, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	other, err := store.Create(context.Background(), id)
+	require.NoError(t, err)
+
+	rotated, err := store.Rotate(context.Background(), token, id)
+	require.NoError(t, err)
+	rotated2, err := store.Rotate(context.Background(), rotated, id)
+	require.NoError(t, err)
+
+	// a token superseded within the grace period is not treated as reused
+	found, err = store.RevokeFamily(context.Background(), rotated, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+	found, err = store.Find(context.Background(), rotated2)
+	assert.NoError(t, err)
+	assert.Equal(t, id, found)
+
+	found, err = store.RevokeFamily(context.Background(), token, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, id, found)
+
+	found, err = store.Find(context.Background(), rotated2)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	// other sessions remain active
+	tokens, err := store.FindAll(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RefreshToken{other}, tokens)
+}
+
+func testRefreshTokenSessionID(t *testing.T, store data.RefreshTokenStore) {
+	id := 123
+
+	// finding nothing
+	found, err := store.FindBySessionID(context.Background(), "unknown-session")
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	token, err := store.Create(context.Background(), id)
+	require.NoError(t, err)
+	err = store.SetSessionID(context.Background(), token, "session-a")
+	require.NoError(t, err)
+
+	found, err = store.FindBySessionID(context.Background(), "session-a")
+	assert.NoError(t, err)
+	assert.Equal(t, token, found)
+
+	// the session follows a rotation
+	rotated, err := store.Rotate(context.Background(), token, id)
+	require.NoError(t, err)
+	found, err = store.FindBySessionID(context.Background(), "session-a")
+	assert.NoError(t, err)
+	assert.Equal(t, rotated, found)
+
+	// revoked sessions are not found
+	err = store.Revoke(context.Background(), rotated)
+	require.NoError(t, err)
+	found, err = store.FindBySessionID(context.Background(), "session-a")
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func testRefreshTokenFindSessionID(t *testing.T, store data.RefreshTokenStore) {
+	// finding nothing
+	sessionID, err := store.FindSessionID(context.Background(), models.RefreshToken("a1b2c3"))
+	assert.NoError(t, err)
+	assert.Empty(t, sessionID)
+
+	token, err := store.Create(context.Background(), 123)
+	require.NoError(t, err)
+	sessionID, err = store.FindSessionID(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Empty(t, sessionID)
+
+	err = store.SetSessionID(context.Background(), token, "session-a")
+	require.NoError(t, err)
+	sessionID, err = store.FindSessionID(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, "session-a", sessionID)
+}
@@ -0,0 +1,116 @@
+package testers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var TrustedDeviceStoreTesters = []func(*testing.T, data.TrustedDeviceStore){
+	testTrustedDeviceCreate,
+	testTrustedDeviceFind,
+	testTrustedDeviceFindAll,
+	testTrustedDeviceRevoke,
+	testTrustedDeviceRevokeAll,
+}
+
+func testTrustedDeviceCreate(t *testing.T, store data.TrustedDeviceStore) {
+	device, err := store.Create(context.Background(), 123, "Firefox")
+	require.NoError(t, err)
+	assert.NotEmpty(t, device.ID)
+	assert.Equal(t, 123, device.AccountID)
+	assert.Equal(t, "Firefox", device.Name)
+	assert.True(t, device.ExpiresAt.After(device.CreatedAt))
+
+	device2, err := store.Create(context.Background(), 123, "Firefox")
+	require.NoError(t, err)
+	assert.NotEqual(t, device.ID, device2.ID)
+}
+
+// TODO: find way to test that expired devices are not found
+func testTrustedDeviceFind(t *testing.T, store data.TrustedDeviceStore) {
+	// finding nothing
+	found, err := store.Find(context.Background(), "a1b2c3")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	// finding something
+	device, err := store.Create(context.Background(), 123, "Firefox")
+	require.NoError(t, err)
+	found, err = store.Find(context.Background(), device.ID)
+	require.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, device.ID, found.ID)
+		assert.Equal(t, 123, found.AccountID)
+		assert.Equal(t, "Firefox", found.Name)
+	}
+}
+
+func testTrustedDeviceFindAll(t *testing.T, store data.TrustedDeviceStore) {
+	// finding nothing
+	devices, err := store.FindAll(context.Background(), 123)
+	assert.NoError(t, err)
+	assert.Len(t, devices, 0)
+
+	// finding something
+	device, err := store.Create(context.Background(), 123, "Firefox")
+	require.NoError(t, err)
+	_, err = store.Create(context.Background(), 456, "Safari")
+	require.NoError(t, err)
+
+	devices, err = store.FindAll(context.Background(), 123)
+	assert.NoError(t, err)
+	if assert.Len(t, devices, 1) {
+		assert.Equal(t, device.ID, devices[0].ID)
+	}
+}
+
+func testTrustedDeviceRevoke(t *testing.T, store data.TrustedDeviceStore) {
+	device, err := store.Create(context.Background(), 123, "Firefox")
+	require.NoError(t, err)
+
+	// revoking from another account
+	revoked, err := store.Revoke(context.Background(), 456, device.ID)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = store.Revoke(context.Background(), 123, device.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	found, err := store.Find(context.Background(), device.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	devices, err := store.FindAll(context.Background(), 123)
+	require.NoError(t, err)
+	assert.Len(t, devices, 0)
+
+	// revoking again
+	revoked, err = store.Revoke(context.Background(), 123, device.ID)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func testTrustedDeviceRevokeAll(t *testing.T, store data.TrustedDeviceStore) {
+	device1, err := store.Create(context.Background(), 123, "Firefox")
+	require.NoError(t, err)
+	device2, err := store.Create(context.Background(), 123, "Safari")
+	require.NoError(t, err)
+	other, err := store.Create(context.Background(), 456, "Chrome")
+	require.NoError(t, err)
+
+	err = store.RevokeAll(context.Background(), 123)
+	require.NoError(t, err)
+
+	for _, id := range []string{device1.ID, device2.ID} {
+		found, err := store.Find(context.Background(), id)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	}
+	found, err := store.Find(context.Background(), other.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, found)
+}
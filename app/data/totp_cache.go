@@ -1,22 +1,27 @@
 package data
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pkg/errors"
 )
 
+func init() {
+	registerEncryptedBlobPrefix("totp:")
+}
+
 type TOTPCache interface {
-	CacheTOTPSecret(accountID int, secret []byte) error
-	LoadTOTPSecret(accountID int) ([]byte, error)
-	RemoveTOTPSecret(accountID int) error
+	CacheTOTPSecret(ctx context.Context, accountID int, secret []byte) error
+	LoadTOTPSecret(ctx context.Context, accountID int) ([]byte, error)
+	RemoveTOTPSecret(ctx context.Context, accountID int) error
 }
 type totpCache struct {
 	ebs *EncryptedBlobStore
 }
 
-func (t *totpCache) RemoveTOTPSecret(accountID int) error {
-	return t.ebs.Delete(fmt.Sprintf("totp:%d", accountID))
+func (t *totpCache) RemoveTOTPSecret(ctx context.Context, accountID int) error {
+	return t.ebs.Delete(ctx, fmt.Sprintf("totp:%d", accountID))
 }
 
 func NewTOTPCache(ebs *EncryptedBlobStore) TOTPCache {
@@ -25,18 +30,18 @@ func NewTOTPCache(ebs *EncryptedBlobStore) TOTPCache {
 	}
 }
 
-func (t *totpCache) CacheTOTPSecret(accountID int, secret []byte) error {
+func (t *totpCache) CacheTOTPSecret(ctx context.Context, accountID int, secret []byte) error {
 	keyName := fmt.Sprintf("totp:%d", accountID)
-	_, err := t.ebs.Write(keyName, secret)
+	_, err := t.ebs.Write(ctx, keyName, secret)
 	if err != nil {
 		return errors.Wrap(err, "CacheTOTPSecret")
 	}
 	return nil
 }
 
-func (t *totpCache) LoadTOTPSecret(accountID int) ([]byte, error) {
+func (t *totpCache) LoadTOTPSecret(ctx context.Context, accountID int) ([]byte, error) {
 	keyName := fmt.Sprintf("totp:%d", accountID)
-	val, err := t.ebs.Read(keyName)
+	val, err := t.ebs.Read(ctx, keyName)
 	if err != nil {
 		return nil, errors.Wrap(err, "LoadTOTPSecret")
 	}
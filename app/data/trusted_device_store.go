@@ -0,0 +1,54 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	dataRedis "github.com/keratin/authn-server/app/data/redis"
+	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/ops"
+)
+
+type TrustedDeviceStore interface {
+	// Generates and persists a trusted device for the given accountID. The name is only meant to
+	// help a user recognize the device.
+	Create(ctx context.Context, accountID int, name string) (*models.TrustedDevice, error)
+
+	// Finds the device, if it is registered and unexpired. An empty value indicates that no trusted
+	// device was found.
+	Find(ctx context.Context, id string) (*models.TrustedDevice, error)
+
+	// Returns all devices that are trusted for the specified account.
+	FindAll(ctx context.Context, accountID int) ([]*models.TrustedDevice, error)
+
+	// Revokes the device, if it is trusted for the specified account.
+	Revoke(ctx context.Context, accountID int, id string) (bool, error)
+
+	// Revokes every device that is trusted for the specified account.
+	RevokeAll(ctx context.Context, accountID int) error
+}
+
+func NewTrustedDeviceStore(db *sqlx.DB, redis *redis.Client, reporter ops.ErrorReporter, ttl time.Duration) (TrustedDeviceStore, error) {
+	if redis != nil {
+		return &dataRedis.TrustedDeviceStore{
+			Client: redis,
+			TTL:    ttl,
+		}, nil
+	}
+
+	switch db.DriverName() {
+	case "sqlite3":
+		store := &sqlite3.TrustedDeviceStore{
+			ExtContext: db,
+			TTL:        ttl,
+		}
+		store.Clean(reporter)
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unsupported driver: %v", db.DriverName())
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type ErrMissingEnvVar string
@@ -50,14 +52,66 @@ func lookupBool(name string, def bool) (bool, error) {
 
 func LookupURL(name string) (*url.URL, error) {
 	if val, ok := os.LookupEnv(name); ok {
-		url, err := url.ParseRequestURI(val)
-		if err == nil {
-			if _, ok := supportedSchemes[url.Scheme]; !ok {
-				return nil, fmt.Errorf("unsupported URL: %v", val)
+		return parseURL(val)
+	}
+	return nil, nil
+}
+
+func lookupURLs(name string) ([]*url.URL, error) {
+	var urls []*url.URL
+	if val, ok := os.LookupEnv(name); ok && val != "" {
+		for _, str := range strings.Split(val, ",") {
+			url, err := parseURL(strings.TrimSpace(str))
+			if err != nil {
+				return nil, err
 			}
-			return url, nil
+			urls = append(urls, url)
 		}
-		return nil, err
 	}
-	return nil, nil
+	return urls, nil
+}
+
+func parseURL(val string) (*url.URL, error) {
+	url, err := url.ParseRequestURI(val)
+	if err == nil {
+		if _, ok := supportedSchemes[url.Scheme]; !ok {
+			return nil, fmt.Errorf("unsupported URL: %v", val)
+		}
+		return url, nil
+	}
+	return nil, err
+}
+
+// LookupDomainDurations reads a default number of seconds, optionally followed by overrides for
+// specific application domains.
+//
+// example: 86400,admin.example.com=3600
+func LookupDomainDurations(name string) (DomainDurations, error) {
+	durations := DomainDurations{}
+	val, ok := os.LookupEnv(name)
+	if !ok || val == "" {
+		return durations, nil
+	}
+
+	for _, str := range strings.Split(val, ",") {
+		pieces := strings.SplitN(strings.TrimSpace(str), "=", 2)
+		seconds, err := strconv.Atoi(pieces[len(pieces)-1])
+		if err != nil {
+			return durations, err
+		}
+		if seconds < 0 {
+			return durations, fmt.Errorf("negative duration: %v", str)
+		}
+
+		duration := time.Duration(seconds) * time.Second
+		if len(pieces) == 1 {
+			durations.Default = duration
+			continue
+		}
+		if durations.Domains == nil {
+			durations.Domains = map[string]time.Duration{}
+		}
+		durations.Domains[pieces[0]] = duration
+	}
+	return durations, nil
 }
@@ -3,6 +3,7 @@ package app_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/stretchr/testify/assert"
@@ -59,3 +60,37 @@ func TestLookupURL(t *testing.T) {
 		}
 	})
 }
+
+func TestLookupDomainDurations(t *testing.T) {
+	envName := "TEST_LOOKUP_DOMAIN_DURATIONS"
+
+	t.Run("failing cases", func(t *testing.T) {
+		for _, env := range []string{"abc", "example.com", "example.com=abc", "-1"} {
+			err := os.Setenv(envName, env)
+			require.NoError(t, err)
+
+			_, err = app.LookupDomainDurations(envName)
+			assert.Error(t, err, env)
+		}
+	})
+
+	t.Run("passing cases", func(t *testing.T) {
+		err := os.Setenv(envName, "86400, admin.example.com=3600,localhost:8080=0")
+		require.NoError(t, err)
+
+		durations, err := app.LookupDomainDurations(envName)
+		require.NoError(t, err)
+		assert.Equal(t, 24*time.Hour, durations.For("example.com"))
+		assert.Equal(t, time.Hour, durations.For("admin.example.com"))
+		assert.Equal(t, time.Duration(0), durations.For("localhost:8080"))
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		err := os.Unsetenv(envName)
+		require.NoError(t, err)
+
+		durations, err := app.LookupDomainDurations(envName)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), durations.For("example.com"))
+	})
+}
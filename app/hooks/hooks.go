@@ -0,0 +1,96 @@
+// Package hooks asks the app for a decision before AuthN creates an account or issues a session.
+// Unlike notifications, hooks are called synchronously and are not retried, so that the app may
+// deny the action or add claims to the identity tokens of the session.
+package hooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Result is the decision of a hook. Claims are added to identity tokens, but may not replace any
+// claims that AuthN sets itself.
+type Result struct {
+	Allow  bool                   `json:"allow"`
+	Reason string                 `json:"reason,omitempty"`
+	Claims map[string]interface{} `json:"claims,omitempty"`
+}
+
+// Client describes who made the request, so that hooks may consider it.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+// WithClient remembers the client of the request in the context.
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFromContext returns the client of the request, or an empty client when none was set.
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+// Call posts the values to the hook, signed like notifications, and returns its decision. Failing to
+// get a decision within the timeout is an error.
+func Call(ctx context.Context, destination *url.URL, timeout time.Duration, signingKey []byte, values url.Values) (*Result, error) {
+	client := ClientFromContext(ctx)
+	values.Set("ip", client.IP)
+	values.Set("user_agent", client.UserAgent)
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	body := values.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, destination.String(), strings.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "NewRequest")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if signingKey != nil {
+		hm := hmac.New(sha256.New, signingKey)
+		hm.Write([]byte(body))
+		req.Header.Set("X-Authn-Notification-Signature", hex.EncodeToString(hm.Sum(nil)))
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			// avoid reporting the URL with potential HTTP auth credentials
+			return nil, errors.Wrap(urlErr.Err, "Post")
+		}
+		return nil, errors.Wrap(err, "Post")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 299 {
+		return nil, fmt.Errorf("Status Code: %v", res.StatusCode)
+	}
+
+	result := Result{}
+	err = json.NewDecoder(res.Body).Decode(&result)
+	if err != nil {
+		return nil, errors.Wrap(err, "Decode")
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,91 @@
+package hooks_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/hooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientContext(t *testing.T) {
+	assert.Equal(t, hooks.Client{}, hooks.ClientFromContext(context.Background()))
+
+	ctx := hooks.WithClient(context.Background(), hooks.Client{IP: "127.0.0.1", UserAgent: "Test"})
+	assert.Equal(t, hooks.Client{IP: "127.0.0.1", UserAgent: "Test"}, hooks.ClientFromContext(ctx))
+}
+
+func TestCall(t *testing.T) {
+	signingKey := []byte("key-a-reno")
+
+	var received url.Values
+	var signature string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received, err = url.ParseQuery(string(body))
+		require.NoError(t, err)
+		signature = r.Header.Get("X-Authn-Notification-Signature")
+
+		switch r.URL.Path {
+		case "/allow":
+			w.Write([]byte(`{"allow": true, "claims": {"plan": "pro"}}`))
+		case "/deny":
+			w.Write([]byte(`{"allow": false, "reason": "SANCTIONED"}`))
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte(`{"allow": true}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer hook.Close()
+
+	call := func(path string) (*hooks.Result, error) {
+		destination, err := url.Parse(hook.URL + path)
+		require.NoError(t, err)
+		ctx := hooks.WithClient(context.Background(), hooks.Client{IP: "127.0.0.1", UserAgent: "Test"})
+		return hooks.Call(ctx, destination, 50*time.Millisecond, signingKey, url.Values{"username": []string{"user"}})
+	}
+
+	t.Run("allowing with claims", func(t *testing.T) {
+		result, err := call("/allow")
+		require.NoError(t, err)
+		assert.True(t, result.Allow)
+		assert.Equal(t, map[string]interface{}{"plan": "pro"}, result.Claims)
+
+		assert.Equal(t, "user", received.Get("username"))
+		assert.Equal(t, "127.0.0.1", received.Get("ip"))
+		assert.Equal(t, "Test", received.Get("user_agent"))
+
+		hm := hmac.New(sha256.New, signingKey)
+		hm.Write([]byte(received.Encode()))
+		assert.Equal(t, hex.EncodeToString(hm.Sum(nil)), signature)
+	})
+
+	t.Run("denying with a reason", func(t *testing.T) {
+		result, err := call("/deny")
+		require.NoError(t, err)
+		assert.False(t, result.Allow)
+		assert.Equal(t, "SANCTIONED", result.Reason)
+	})
+
+	t.Run("timing out", func(t *testing.T) {
+		_, err := call("/slow")
+		assert.Error(t, err)
+	})
+
+	t.Run("failing", func(t *testing.T) {
+		_, err := call("/fail")
+		assert.Error(t, err)
+	})
+}
@@ -8,12 +8,16 @@ import (
 
 type Account struct {
 	ID                 int
+	Tenant             string
 	Username           string
 	Password           []byte
 	Locked             bool
 	RequireNewPassword bool           `db:"require_new_password"`
 	PasswordChangedAt  time.Time      `db:"password_changed_at"`
-	TOTPSecret         sql.NullString `db:"totp_secret"`
+	TOTPSecret         sql.NullString `db:"totp_secret"` // deprecated: moved to TOTPAuthenticator
+	TOTPAuthenticators int            `db:"totp_authenticators"`
+	RequireMFA         bool           `db:"require_mfa"`
+	OTPChannel         sql.NullString `db:"otp_channel"`
 	OauthAccounts      []*OauthAccount
 	LastLoginAt        *time.Time `db:"last_login_at"`
 	CreatedAt          time.Time  `db:"created_at"`
@@ -27,10 +31,17 @@ func (a Account) Archived() bool {
 
 // TOTPEnabled returns true if OTP is enabled on the account
 func (a Account) TOTPEnabled() bool {
-	if a.TOTPSecret.Valid && a.TOTPSecret.String != "" {
-		return true
-	}
-	return false
+	return a.TOTPAuthenticators > 0
+}
+
+// OTPChannelEnabled returns true if one-time codes are delivered to the account as a second factor
+func (a Account) OTPChannelEnabled() bool {
+	return a.OTPChannel.Valid && a.OTPChannel.String != ""
+}
+
+// MFAEnabled returns true if the account has any second factor
+func (a Account) MFAEnabled() bool {
+	return a.TOTPEnabled() || a.OTPChannelEnabled()
 }
 
 func (a Account) MarshalJSON() ([]byte, error) {
@@ -46,19 +57,23 @@ func (a Account) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(struct {
 		ID                int             `json:"id"`
+		Tenant            string          `json:"tenant,omitempty"`
 		Username          string          `json:"username"`
 		OauthAccounts     []*OauthAccount `json:"oauth_accounts"`
 		LastLoginAt       string          `json:"last_login_at"`
 		PasswordChangedAt string          `json:"password_changed_at"`
 		Locked            bool            `json:"locked"`
 		Deleted           bool            `json:"deleted"`
+		RequireMFA        bool            `json:"require_mfa"`
 	}{
 		ID:                a.ID,
+		Tenant:            a.Tenant,
 		Username:          a.Username,
 		OauthAccounts:     a.OauthAccounts,
 		LastLoginAt:       formattedLastLogin,
 		PasswordChangedAt: formattedPasswordChangedAt,
 		Locked:            a.Locked,
 		Deleted:           a.DeletedAt != nil,
+		RequireMFA:        a.RequireMFA,
 	})
 }
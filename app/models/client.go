@@ -0,0 +1,72 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Client is a service that authenticates as itself, rather than on behalf of an account. It proves
+// its identity with either a secret (stored hashed) or a JWT signed by the private half of its
+// public key. It may only be issued tokens for the scopes and audiences it has been granted.
+type Client struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Secret    []byte    `json:"-"`
+	PublicKey string    `json:"public_key,omitempty" db:"public_key"`
+	Scopes    NameList  `json:"scopes"`
+	Audiences NameList  `json:"audiences"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NameList is a list of names that is stored as a space-separated string, like the scope parameter
+// of OAuth 2.0.
+type NameList []string
+
+// ParseNameList splits a space-separated string.
+func ParseNameList(s string) NameList {
+	return NameList(strings.Fields(s))
+}
+
+func (l NameList) String() string {
+	return strings.Join(l, " ")
+}
+
+// Contains is true when every name is in the list.
+func (l NameList) Contains(names ...string) bool {
+	for _, name := range names {
+		found := false
+		for _, n := range l {
+			if n == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Value implements driver.Valuer
+func (l NameList) Value() (driver.Value, error) {
+	return l.String(), nil
+}
+
+// Scan implements sql.Scanner
+func (l *NameList) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		*l = ParseNameList(v)
+	case []byte:
+		*l = ParseNameList(string(v))
+	case nil:
+		*l = NameList{}
+	default:
+		return fmt.Errorf("unsupported name list: %T", src)
+	}
+	return nil
+}
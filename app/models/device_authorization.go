@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// DeviceAuthorization is a pending login for a device that can't easily use a browser, like a CLI or
+// a TV. The device shows a user code, and a user who is logged in on a browser approves it. The
+// device then exchanges its device code for a session on behalf of the approving account.
+type DeviceAuthorization struct {
+	UserCode            string    `json:"user_code"`
+	Session             bool      `json:"session"`
+	AccountID           int       `json:"account_id"`
+	Audience            string    `json:"audience"`
+	AuthMethodReference []string  `json:"amr"`
+	ExpiresAt           time.Time `json:"expires_at"`
+	PolledAt            time.Time `json:"polled_at"`
+}
+
+// Approved is true once a user has approved the device.
+func (a *DeviceAuthorization) Approved() bool {
+	return a.AccountID != 0
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Organization is a group of accounts, like a customer of a B2B application. Accounts belong to an
+// organization through a Membership that assigns their roles.
+type Organization struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Membership assigns roles in an organization to an account. The organization's name is included
+// for convenience when listing memberships.
+type Membership struct {
+	OrganizationID   int       `json:"organization_id" db:"organization_id"`
+	OrganizationName string    `json:"organization_name" db:"organization_name"`
+	AccountID        int       `json:"account_id" db:"account_id"`
+	Roles            NameList  `json:"roles"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
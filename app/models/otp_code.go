@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// OTPCode is a one-time code that was delivered to a user through a channel like email or SMS.
+// Only a hash of the code is kept.
+type OTPCode struct {
+	ID        string    `json:"id"`
+	Channel   string    `json:"channel"`
+	Hash      []byte    `json:"hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// PersonalAccessToken lets an account authenticate non-interactively, like from a CLI. Only a hash
+// of the token is stored. It may be exchanged for identity tokens with the same audience and scopes
+// until it expires or is deleted.
+type PersonalAccessToken struct {
+	ID         int        `json:"id"`
+	AccountID  int        `json:"-" db:"account_id"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	Scopes     NameList   `json:"scopes"`
+	Audience   string     `json:"audience"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+}
+
+// Expired is true once the token has outlived its expiration, if it has one.
+func (t PersonalAccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// TOTPAuthenticator is a device, like an authenticator app on a phone, that generates TOTP codes
+// for an account. The secret is encrypted. The time step of the last accepted code is kept so that
+// codes may not be replayed.
+type TOTPAuthenticator struct {
+	ID           int        `json:"id"`
+	AccountID    int        `json:"-" db:"account_id"`
+	Name         string     `json:"name"`
+	Secret       string     `json:"-"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at" db:"last_used_at"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+}
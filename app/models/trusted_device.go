@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// TrustedDevice is a browser that may skip the second factor when logging in to an account.
+type TrustedDevice struct {
+	ID        string    `json:"id"`
+	AccountID int       `json:"-" db:"account_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
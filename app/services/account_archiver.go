@@ -1,12 +1,24 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
 )
 
-func AccountArchiver(store data.AccountStore, tokenStore data.RefreshTokenStore, accountID int) error {
-	affected, err := store.Archive(accountID)
+func AccountArchiver(ctx context.Context, store data.AccountStore, tokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, r ops.ErrorReporter, accountID int) error {
+	account, err := store.Find(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+
+	affected, err := store.Archive(ctx, accountID)
 	if err != nil {
 		return errors.Wrap(err, "Archive")
 	}
@@ -14,5 +26,5 @@ func AccountArchiver(store data.AccountStore, tokenStore data.RefreshTokenStore,
 		return FieldErrors{{"account", ErrNotFound}}
 	}
 
-	return SessionBatchEnder(tokenStore, accountID)
+	return SessionBatchEnder(ctx, tokenStore, keyStore, cfg, r, account.Tenant, accountID)
 }
@@ -1,26 +1,31 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestAccountArchiver(t *testing.T) {
+	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
 	accountStore := mock.NewAccountStore()
 	refreshStore := mock.NewRefreshTokenStore()
 
 	t.Run("existing account", func(t *testing.T) {
-		account, err := accountStore.Create("test@keratin.tech", []byte("password"))
+		account, err := accountStore.Create(context.Background(), "test@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		errs := services.AccountArchiver(accountStore, refreshStore, account.ID)
+		errs := services.AccountArchiver(context.Background(), accountStore, refreshStore, nil, &app.Config{}, reporter, account.ID)
 		assert.Empty(t, errs)
 
-		acct, err := accountStore.Find(account.ID)
+		acct, err := accountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.Empty(t, acct.Username)
 		assert.Empty(t, acct.Password)
@@ -28,21 +33,21 @@ func TestAccountArchiver(t *testing.T) {
 	})
 
 	t.Run("logged in account", func(t *testing.T) {
-		account, err := accountStore.Create("loggedin@keratin.tech", []byte("password"))
+		account, err := accountStore.Create(context.Background(), "loggedin@keratin.tech", []byte("password"))
 		require.NoError(t, err)
-		token1, err := refreshStore.Create(account.ID)
+		token1, err := refreshStore.Create(context.Background(), account.ID)
 		require.NoError(t, err)
 
-		errs := services.AccountArchiver(accountStore, refreshStore, account.ID)
+		errs := services.AccountArchiver(context.Background(), accountStore, refreshStore, nil, &app.Config{}, reporter, account.ID)
 		assert.Empty(t, errs)
 
-		id, err := refreshStore.Find(token1)
+		id, err := refreshStore.Find(context.Background(), token1)
 		require.NoError(t, err)
 		assert.Empty(t, id)
 	})
 
 	t.Run("unknown account", func(t *testing.T) {
-		errs := services.AccountArchiver(accountStore, refreshStore, 123456789)
+		errs := services.AccountArchiver(context.Background(), accountStore, refreshStore, nil, &app.Config{}, reporter, 123456789)
 		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, errs)
 	})
 }
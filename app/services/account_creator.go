@@ -1,16 +1,18 @@
 package services
 
 import (
+	"context"
 	"strings"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tenants"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func AccountCreator(store data.AccountStore, cfg *app.Config, username string, password string) (*models.Account, error) {
+func AccountCreator(ctx context.Context, store data.AccountStore, cfg *app.Config, username string, password string) (*models.Account, error) {
 	username = strings.TrimSpace(username)
 
 	errs := FieldErrors{}
@@ -20,7 +22,7 @@ func AccountCreator(store data.AccountStore, cfg *app.Config, username string, p
 		errs = append(errs, *fieldError)
 	}
 
-	fieldError = PasswordValidator(cfg, username, password)
+	fieldError = PasswordValidator(cfg, tenants.Name(ctx), username, password)
 	if fieldError != nil {
 		errs = append(errs, *fieldError)
 	}
@@ -29,12 +31,20 @@ func AccountCreator(store data.AccountStore, cfg *app.Config, username string, p
 		return nil, errs
 	}
 
+	err := preRegistrationHook(ctx, cfg, username)
+	if err != nil {
+		if _, ok := err.(FieldErrors); ok {
+			return nil, err
+		}
+		return nil, errors.Wrap(err, "preRegistrationHook")
+	}
+
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), cfg.BcryptCost)
 	if err != nil {
 		return nil, errors.Wrap(err, "bcrypt")
 	}
 
-	acc, err := store.Create(username, hash)
+	acc, err := store.Create(ctx, username, hash)
 	if err != nil {
 		if data.IsUniquenessError(err) {
 			return nil, FieldErrors{{"username", ErrTaken}}
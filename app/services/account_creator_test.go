@@ -1,11 +1,17 @@
 package services_test
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,7 +31,7 @@ func TestAccountCreatorSuccess(t *testing.T) {
 
 	for _, tc := range testCases {
 		cfg := tc.config
-		acc, err := services.AccountCreator(store, &cfg, tc.username, tc.password)
+		acc, err := services.AccountCreator(context.Background(), store, &cfg, tc.username, tc.password)
 		require.NoError(t, err)
 		assert.NotEqual(t, 0, acc.ID)
 		assert.Equal(t, tc.username, acc.Username)
@@ -36,7 +42,7 @@ var pw = []byte("$2a$04$ZOBA8E3nT68/ArE6NDnzfezGWEgM6YrE17PrOtSjT5.U/ZGoxyh7e")
 
 func TestAccountCreatorFailure(t *testing.T) {
 	store := mock.NewAccountStore()
-	_, setupErr := store.Create("existing@test.com", pw)
+	_, setupErr := store.Create(context.Background(), "existing@test.com", pw)
 	require.NoError(t, setupErr)
 
 	testCases := []struct {
@@ -64,10 +70,68 @@ func TestAccountCreatorFailure(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.username, func(t *testing.T) {
 			cfg := tc.config
-			acc, err := services.AccountCreator(store, &cfg, tc.username, tc.password)
+			acc, err := services.AccountCreator(context.Background(), store, &cfg, tc.username, tc.password)
 			if assert.Equal(t, tc.errors, err) {
 				assert.Empty(t, acc)
 			}
 		})
 	}
 }
+
+func TestAccountCreatorWithTenantPasswordPolicy(t *testing.T) {
+	store := mock.NewAccountStore()
+	score := 4
+	cfg := app.Config{
+		Tenants: map[string]*app.TenantConfig{"acme": {PasswordMinComplexity: &score}},
+	}
+
+	_, err := services.AccountCreator(tenants.WithContext(context.Background(), "acme"), store, &cfg, "username", "PASSword")
+	assert.Equal(t, services.FieldErrors{{"password", "INSECURE"}}, err)
+
+	_, err = services.AccountCreator(tenants.WithContext(context.Background(), "globex"), store, &cfg, "username", "PASSword")
+	assert.NoError(t, err)
+}
+
+func TestAccountCreatorWithPreRegistrationHook(t *testing.T) {
+	var received url.Values
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		received = r.PostForm
+		if received.Get("username") == "denied" {
+			w.Write([]byte(`{"allow": false}`))
+		} else {
+			w.Write([]byte(`{"allow": true}`))
+		}
+	}))
+	defer hook.Close()
+	hookURL, err := url.Parse(hook.URL)
+	require.NoError(t, err)
+
+	store := mock.NewAccountStore()
+	cfg := &app.Config{BcryptCost: 4, AppPreRegistrationURL: hookURL, HookTimeout: time.Second}
+	ctx := tenants.WithContext(context.Background(), "acme")
+
+	t.Run("allowed", func(t *testing.T) {
+		account, err := services.AccountCreator(ctx, store, cfg, "allowed", "PASSword")
+		require.NoError(t, err)
+		assert.NotEmpty(t, account.ID)
+		assert.Equal(t, "allowed", received.Get("username"))
+		assert.Equal(t, "acme", received.Get("tenant"))
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		_, err := services.AccountCreator(ctx, store, cfg, "denied", "PASSword")
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrDenied}}, err)
+
+		account, err := store.FindByUsername(ctx, "denied")
+		require.NoError(t, err)
+		assert.Nil(t, account)
+	})
+
+	t.Run("invalid username", func(t *testing.T) {
+		received = nil
+		_, err := services.AccountCreator(ctx, store, cfg, "", "PASSword")
+		assert.Error(t, err)
+		assert.Nil(t, received)
+	})
+}
@@ -1,13 +1,15 @@
 package services
 
 import (
+	"context"
+
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/models"
 	"github.com/pkg/errors"
 )
 
-func AccountGetter(store data.AccountStore, accountID int) (*models.Account, error) {
-	account, err := store.Find(accountID)
+func AccountGetter(ctx context.Context, store data.AccountStore, accountID int) (*models.Account, error) {
+	account, err := store.Find(ctx, accountID)
 	if err != nil {
 		return nil, errors.Wrap(err, "Find")
 	}
@@ -15,7 +17,7 @@ func AccountGetter(store data.AccountStore, accountID int) (*models.Account, err
 		return nil, FieldErrors{{"account", ErrNotFound}}
 	}
 
-	oauthAccounts, err := store.GetOauthAccounts(accountID)
+	oauthAccounts, err := store.GetOauthAccounts(ctx, accountID)
 	if err != nil {
 		return nil, errors.Wrap(err, "GetOauthAccounts")
 	}
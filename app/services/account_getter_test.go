@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"sort"
 	"testing"
 
@@ -13,7 +14,7 @@ func TestAccountGetter(t *testing.T) {
 
 	t.Run("get non existing account", func(t *testing.T) {
 		accountStore := mock.NewAccountStore()
-		account, err := services.AccountGetter(accountStore, 9999)
+		account, err := services.AccountGetter(context.Background(), accountStore, 9999)
 
 		require.NotNil(t, err)
 		require.Nil(t, account)
@@ -21,10 +22,10 @@ func TestAccountGetter(t *testing.T) {
 
 	t.Run("returns empty map when no oauth accounts", func(t *testing.T) {
 		accountStore := mock.NewAccountStore()
-		acc, err := accountStore.Create("user@keratin.tech", []byte("password"))
+		acc, err := accountStore.Create(context.Background(), "user@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		account, err := services.AccountGetter(accountStore, acc.ID)
+		account, err := services.AccountGetter(context.Background(), accountStore, acc.ID)
 		require.NoError(t, err)
 
 		require.Equal(t, 0, len(account.OauthAccounts))
@@ -32,16 +33,16 @@ func TestAccountGetter(t *testing.T) {
 
 	t.Run("returns oauth accounts for different providers", func(t *testing.T) {
 		accountStore := mock.NewAccountStore()
-		acc, err := accountStore.Create("user@keratin.tech", []byte("password"))
+		acc, err := accountStore.Create(context.Background(), "user@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		err = accountStore.AddOauthAccount(acc.ID, "test", "ID1", "email1", "TOKEN1")
+		err = accountStore.AddOauthAccount(context.Background(), acc.ID, "test", "ID1", "email1", "TOKEN1")
 		require.NoError(t, err)
 
-		err = accountStore.AddOauthAccount(acc.ID, "trial", "ID2", "email2", "TOKEN2")
+		err = accountStore.AddOauthAccount(context.Background(), acc.ID, "trial", "ID2", "email2", "TOKEN2")
 		require.NoError(t, err)
 
-		account, err := services.AccountGetter(accountStore, acc.ID)
+		account, err := services.AccountGetter(context.Background(), accountStore, acc.ID)
 		require.NoError(t, err)
 
 		oAccounts := account.OauthAccounts
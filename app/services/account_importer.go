@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"regexp"
 
 	"golang.org/x/crypto/bcrypt"
@@ -13,7 +14,7 @@ import (
 
 var bcryptPattern = regexp.MustCompile(`\A\$2[ayb]\$[0-9]{2}\$[A-Za-z0-9\.\/]{53}\z`)
 
-func AccountImporter(store data.AccountStore, cfg *app.Config, username string, password string, locked bool) (*models.Account, error) {
+func AccountImporter(ctx context.Context, store data.AccountStore, cfg *app.Config, username string, password string, locked bool) (*models.Account, error) {
 	if username == "" {
 		return nil, FieldErrors{{"username", ErrMissing}}
 	}
@@ -32,7 +33,7 @@ func AccountImporter(store data.AccountStore, cfg *app.Config, username string,
 		}
 	}
 
-	acc, err := store.Create(username, hash)
+	acc, err := store.Create(ctx, username, hash)
 	if err != nil {
 		if data.IsUniquenessError(err) {
 			return nil, FieldErrors{{"username", ErrTaken}}
@@ -43,7 +44,7 @@ func AccountImporter(store data.AccountStore, cfg *app.Config, username string,
 
 	if locked {
 		acc.Locked = true
-		_, err := store.Lock(acc.ID)
+		_, err := store.Lock(ctx, acc.ID)
 		if err != nil {
 			return nil, errors.Wrap(err, "Lock")
 		}
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
 	"golang.org/x/crypto/bcrypt"
@@ -21,7 +22,7 @@ func TestAccountImporter(t *testing.T) {
 		BcryptCost: 4,
 	}
 
-	_, err := accountStore.Create("existing", []byte("secret"))
+	_, err := accountStore.Create(context.Background(), "existing", []byte("secret"))
 	require.NoError(t, err)
 
 	testCases := []struct {
@@ -39,7 +40,7 @@ func TestAccountImporter(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		account, errors := services.AccountImporter(accountStore, cfg, tc.username, string(tc.password), tc.locked)
+		account, errors := services.AccountImporter(context.Background(), accountStore, cfg, tc.username, string(tc.password), tc.locked)
 		if tc.errors == nil {
 			assert.Empty(t, errors)
 			assert.NotEmpty(t, account)
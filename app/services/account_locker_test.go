@@ -1,60 +1,65 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestAccountLocker(t *testing.T) {
+	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
 	accountStore := mock.NewAccountStore()
 	refreshStore := mock.NewRefreshTokenStore()
 
 	t.Run("logged in account", func(t *testing.T) {
-		account, err := accountStore.Create("loggedin@keratin.tech", []byte("password"))
+		account, err := accountStore.Create(context.Background(), "loggedin@keratin.tech", []byte("password"))
 		require.NoError(t, err)
-		token1, err := refreshStore.Create(account.ID)
+		token1, err := refreshStore.Create(context.Background(), account.ID)
 		require.NoError(t, err)
 
-		errs := services.AccountLocker(accountStore, refreshStore, account.ID)
+		errs := services.AccountLocker(context.Background(), accountStore, refreshStore, nil, &app.Config{}, reporter, account.ID)
 		assert.Empty(t, errs)
 
-		id, err := refreshStore.Find(token1)
+		id, err := refreshStore.Find(context.Background(), token1)
 		require.NoError(t, err)
 		assert.Empty(t, id)
 	})
 
 	t.Run("locked account", func(t *testing.T) {
-		account, err := accountStore.Create("locked@keratin.tech", []byte("password"))
+		account, err := accountStore.Create(context.Background(), "locked@keratin.tech", []byte("password"))
 		require.NoError(t, err)
-		_, err = accountStore.Lock(account.ID)
+		_, err = accountStore.Lock(context.Background(), account.ID)
 		require.NoError(t, err)
 
-		errs := services.AccountLocker(accountStore, refreshStore, account.ID)
+		errs := services.AccountLocker(context.Background(), accountStore, refreshStore, nil, &app.Config{}, reporter, account.ID)
 		assert.Empty(t, errs)
 
-		acct, err := accountStore.Find(account.ID)
+		acct, err := accountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.True(t, acct.Locked)
 	})
 
 	t.Run("unlocked account", func(t *testing.T) {
-		account, err := accountStore.Create("unlocked@keratin.tech", []byte("password"))
+		account, err := accountStore.Create(context.Background(), "unlocked@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		errs := services.AccountLocker(accountStore, refreshStore, account.ID)
+		errs := services.AccountLocker(context.Background(), accountStore, refreshStore, nil, &app.Config{}, reporter, account.ID)
 		assert.Empty(t, errs)
 
-		acct, err := accountStore.Find(account.ID)
+		acct, err := accountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.True(t, acct.Locked)
 	})
 
 	t.Run("unknown account", func(t *testing.T) {
-		errs := services.AccountLocker(accountStore, refreshStore, 123456789)
+		errs := services.AccountLocker(context.Background(), accountStore, refreshStore, nil, &app.Config{}, reporter, 123456789)
 		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, errs)
 	})
 }
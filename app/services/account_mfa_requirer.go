@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+func AccountMFARequirer(ctx context.Context, store data.AccountStore, accountID int, required bool) error {
+	affected, err := store.SetRequireMFA(ctx, accountID, required)
+	if err != nil {
+		return errors.Wrap(err, "SetRequireMFA")
+	}
+	if !affected {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountMFARequirer(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+
+	t.Run("requiring MFA", func(t *testing.T) {
+		account, err := accountStore.Create(context.Background(), "required@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+
+		errs := services.AccountMFARequirer(context.Background(), accountStore, account.ID, true)
+		assert.Empty(t, errs)
+
+		acct, err := accountStore.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.True(t, acct.RequireMFA)
+	})
+
+	t.Run("no longer requiring MFA", func(t *testing.T) {
+		account, err := accountStore.Create(context.Background(), "optional@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		_, err = accountStore.SetRequireMFA(context.Background(), account.ID, true)
+		require.NoError(t, err)
+
+		errs := services.AccountMFARequirer(context.Background(), accountStore, account.ID, false)
+		assert.Empty(t, errs)
+
+		acct, err := accountStore.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.False(t, acct.RequireMFA)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		errs := services.AccountMFARequirer(context.Background(), accountStore, 123456789, true)
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, errs)
+	})
+}
@@ -1,12 +1,14 @@
 package services
 
 import (
+	"context"
+
 	"github.com/keratin/authn-server/app/data"
 	"github.com/pkg/errors"
 )
 
-func AccountUnlocker(store data.AccountStore, accountID int) error {
-	affected, err := store.Unlock(accountID)
+func AccountUnlocker(ctx context.Context, store data.AccountStore, accountID int) error {
+	affected, err := store.Unlock(ctx, accountID)
 	if err != nil {
 		return errors.Wrap(err, "Unlock")
 	}
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/keratin/authn-server/app/data/mock"
@@ -12,12 +13,12 @@ import (
 func TestAccountUnlocker(t *testing.T) {
 	store := mock.NewAccountStore()
 
-	lockedAccount, err := store.Create("locked@keratin.tech", []byte("password"))
+	lockedAccount, err := store.Create(context.Background(), "locked@keratin.tech", []byte("password"))
 	require.NoError(t, err)
-	_, err = store.Lock(lockedAccount.ID)
+	_, err = store.Lock(context.Background(), lockedAccount.ID)
 	require.NoError(t, err)
 
-	unlockedAccount, err := store.Create("unlocked@keratin.tech", []byte("password"))
+	unlockedAccount, err := store.Create(context.Background(), "unlocked@keratin.tech", []byte("password"))
 	require.NoError(t, err)
 
 	var testCases = []struct {
@@ -30,10 +31,10 @@ func TestAccountUnlocker(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		errs := services.AccountUnlocker(store, tc.accountID)
+		errs := services.AccountUnlocker(context.Background(), store, tc.accountID)
 		if tc.errors == nil {
 			assert.Empty(t, errs)
-			acct, err := store.Find(tc.accountID)
+			acct, err := store.Find(context.Background(), tc.accountID)
 			require.NoError(t, err)
 			assert.False(t, acct.Locked)
 		} else {
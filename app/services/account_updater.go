@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"strings"
 
 	"github.com/keratin/authn-server/app"
@@ -8,7 +9,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func AccountUpdater(store data.AccountStore, cfg *app.Config, accountID int, username string) error {
+func AccountUpdater(ctx context.Context, store data.AccountStore, cfg *app.Config, accountID int, username string) error {
 	username = strings.TrimSpace(username)
 
 	fieldError := UsernameValidator(cfg, username)
@@ -16,7 +17,7 @@ func AccountUpdater(store data.AccountStore, cfg *app.Config, accountID int, use
 		return FieldErrors{*fieldError}
 	}
 
-	affected, err := store.UpdateUsername(accountID, username)
+	affected, err := store.UpdateUsername(ctx, accountID, username)
 	if err != nil {
 		if data.IsUniquenessError(err) {
 			return FieldErrors{{"username", ErrTaken}}
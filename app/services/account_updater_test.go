@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/keratin/authn-server/app/services"
@@ -14,7 +15,7 @@ import (
 
 func TestAccountUpdater(t *testing.T) {
 	accountStore := mock.NewAccountStore()
-	existing, err := accountStore.Create("existing", []byte("secret"))
+	existing, err := accountStore.Create(context.Background(), "existing", []byte("secret"))
 	require.NoError(t, err)
 
 	t.Run("email usernames", func(t *testing.T) {
@@ -23,16 +24,16 @@ func TestAccountUpdater(t *testing.T) {
 		}
 
 		t.Run("success", func(t *testing.T) {
-			err := services.AccountUpdater(accountStore, cfg, existing.ID, "new@email.tech")
+			err := services.AccountUpdater(context.Background(), accountStore, cfg, existing.ID, "new@email.tech")
 			require.NoError(t, err)
 
-			found, err := accountStore.Find(existing.ID)
+			found, err := accountStore.Find(context.Background(), existing.ID)
 			require.NoError(t, err)
 			assert.Equal(t, "new@email.tech", found.Username)
 		})
 
 		t.Run("invalid", func(t *testing.T) {
-			err := services.AccountUpdater(accountStore, cfg, existing.ID, "invalid")
+			err := services.AccountUpdater(context.Background(), accountStore, cfg, existing.ID, "invalid")
 			assert.Equal(t, services.FieldErrors{{"username", services.ErrFormatInvalid}}, err)
 		})
 	})
@@ -43,10 +44,10 @@ func TestAccountUpdater(t *testing.T) {
 			UsernameMinLength: 3,
 		}
 
-		other, err := accountStore.Create("other", []byte("secret"))
+		other, err := accountStore.Create(context.Background(), "other", []byte("secret"))
 		require.NoError(t, err)
 
-		err = services.AccountUpdater(accountStore, cfg, existing.ID, other.Username)
+		err = services.AccountUpdater(context.Background(), accountStore, cfg, existing.ID, other.Username)
 		assert.Equal(t, services.FieldErrors{{"username", services.ErrTaken}}, err)
 	})
 
@@ -57,16 +58,16 @@ func TestAccountUpdater(t *testing.T) {
 		}
 
 		t.Run("success", func(t *testing.T) {
-			err := services.AccountUpdater(accountStore, cfg, existing.ID, "newname")
+			err := services.AccountUpdater(context.Background(), accountStore, cfg, existing.ID, "newname")
 			require.NoError(t, err)
 
-			found, err := accountStore.Find(existing.ID)
+			found, err := accountStore.Find(context.Background(), existing.ID)
 			require.NoError(t, err)
 			assert.Equal(t, "newname", found.Username)
 		})
 
 		t.Run("invalid", func(t *testing.T) {
-			err := services.AccountUpdater(accountStore, cfg, existing.ID, "nope")
+			err := services.AccountUpdater(context.Background(), accountStore, cfg, existing.ID, "nope")
 			assert.Equal(t, services.FieldErrors{{"username", services.ErrFormatInvalid}}, err)
 		})
 	})
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"net/url"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/logouts"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// BackchannelLogoutSender delivers a logout token to the back-channel logout URI of an application
+// domain. An empty sessionID ends every session of the account.
+func BackchannelLogoutSender(cfg *app.Config, keyStore data.KeyStore, domain string, accountID int, sessionID string) error {
+	logout, err := logouts.New(cfg, accountID, sessionID, domain)
+	if err != nil {
+		return errors.Wrap(err, "logouts.New")
+	}
+	logoutToken, err := logout.Sign(keyStore.Key())
+	if err != nil {
+		return errors.Wrap(err, "Sign")
+	}
+
+	err = WebhookSender(cfg.BackchannelLogoutURIs[domain], &url.Values{
+		"logout_token": []string{logoutToken},
+	}, timeSensitiveDelivery, nil)
+	if err != nil {
+		return errors.Wrap(err, "Webhook")
+	}
+
+	return nil
+}
+
+// BackchannelLogout notifies the application domains of the tenant that have a back-channel logout
+// URI. Each is notified in the background, so that a slow app does not delay the others. Domains of
+// other tenants can not have seen the account's sessions.
+func BackchannelLogout(cfg *app.Config, keyStore data.KeyStore, r ops.ErrorReporter, tenant string, accountID int, sessionID string) {
+	for domain := range cfg.BackchannelLogoutURIs {
+		if cfg.TenantFor(domain) != tenant {
+			continue
+		}
+		go func(domain string) {
+			err := BackchannelLogoutSender(cfg, keyStore, domain, accountID, sessionID)
+			if err != nil {
+				r.ReportError(err)
+			}
+		}(domain)
+	}
+}
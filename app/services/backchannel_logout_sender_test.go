@@ -0,0 +1,58 @@
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/logouts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackchannelLogoutSender(t *testing.T) {
+	var received url.Values
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/logout" {
+			require.NoError(t, r.ParseForm())
+			received = r.PostForm
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer remoteApp.Close()
+	serverURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(key)
+	cfg := &app.Config{
+		AuthNURL: &url.URL{Scheme: "http", Host: "authn.example.com"},
+		BackchannelLogoutURIs: map[string]*url.URL{
+			"app.example.com": {Scheme: "http", Host: serverURL.Host, Path: "/logout"},
+		},
+	}
+
+	t.Run("posting a logout token", func(t *testing.T) {
+		err := services.BackchannelLogoutSender(cfg, keyStore, "app.example.com", 123, "session-a")
+		require.NoError(t, err)
+
+		token, err := jwt.ParseSigned(received.Get("logout_token"))
+		require.NoError(t, err)
+		claims := logouts.Claims{}
+		err = token.Claims(key.Public(), &claims)
+		require.NoError(t, err)
+		assert.Equal(t, "123", claims.Subject)
+		assert.Equal(t, "session-a", claims.SessionID)
+		assert.Equal(t, jwt.Audience{"app.example.com"}, claims.Audience)
+		assert.Contains(t, claims.Events, logouts.Event)
+	})
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ClientAssertionType identifies a client_assertion as a JWT signed by the client (private_key_jwt).
+const ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// clientAssertionMaxLifetime limits how long an assertion may be used, and so how long its jti
+// must be remembered.
+const clientAssertionMaxLifetime = 5 * time.Minute
+
+// ClientAuthenticator verifies the credentials of a service client. A client with a secret must
+// provide it, and a client with a public key must provide a JWT assertion that it signed. The
+// assertion's audience must be AuthN or its token endpoint, and it must be short-lived and have
+// a jti that has not been used before.
+func ClientAuthenticator(
+	ctx context.Context, store data.ClientStore, assertions data.ClientAssertionCache, cfg *app.Config,
+	clientID string, secret string, assertion string,
+) (*models.Client, error) {
+	var token *jwt.JSONWebToken
+	if assertion != "" {
+		var err error
+		token, err = jwt.ParseSigned(assertion)
+		if err != nil {
+			return nil, FieldErrors{{"client", ErrFailed}}
+		}
+		if clientID == "" {
+			unverified := jwt.Claims{}
+			err = token.UnsafeClaimsWithoutVerification(&unverified)
+			if err != nil {
+				return nil, FieldErrors{{"client", ErrFailed}}
+			}
+			clientID = unverified.Subject
+		}
+	}
+	if clientID == "" {
+		return nil, FieldErrors{{"client", ErrFailed}}
+	}
+
+	client, err := store.Find(ctx, clientID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Find")
+	}
+	if client == nil {
+		return nil, FieldErrors{{"client", ErrFailed}}
+	}
+
+	if client.PublicKey != "" {
+		if token == nil {
+			return nil, FieldErrors{{"client", ErrFailed}}
+		}
+		claims, ok := verifyClientAssertion(cfg, client, token)
+		if !ok {
+			return nil, FieldErrors{{"client", ErrFailed}}
+		}
+		fresh, err := assertions.UseClientAssertion(ctx, client.ID, claims.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "UseClientAssertion")
+		}
+		if !fresh {
+			return nil, FieldErrors{{"client", ErrFailed}}
+		}
+		return client, nil
+	}
+
+	if secret == "" || bcrypt.CompareHashAndPassword(client.Secret, []byte(secret)) != nil {
+		return nil, FieldErrors{{"client", ErrFailed}}
+	}
+	return client, nil
+}
+
+func verifyClientAssertion(cfg *app.Config, client *models.Client, token *jwt.JSONWebToken) (*jwt.Claims, bool) {
+	key, err := parseClientPublicKey(client.PublicKey)
+	if err != nil {
+		return nil, false
+	}
+
+	claims := jwt.Claims{}
+	err = token.Claims(key, &claims)
+	if err != nil {
+		return nil, false
+	}
+	if claims.Expiry == nil || claims.ID == "" {
+		return nil, false
+	}
+	now := time.Now()
+	err = claims.Validate(jwt.Expected{
+		Issuer:  client.ID,
+		Subject: client.ID,
+		Time:    now,
+	})
+	if err != nil {
+		return nil, false
+	}
+
+	// a long-lived assertion would need its jti remembered for too long
+	expiry := claims.Expiry.Time()
+	if expiry.Sub(now) > clientAssertionMaxLifetime {
+		return nil, false
+	}
+	if claims.IssuedAt != nil && expiry.Sub(claims.IssuedAt.Time()) > clientAssertionMaxLifetime {
+		return nil, false
+	}
+
+	tokenEndpoint := cfg.AuthNURL.String() + "/oauth/token"
+	if !claims.Audience.Contains(cfg.AuthNURL.String()) && !claims.Audience.Contains(tokenEndpoint) {
+		return nil, false
+	}
+	return &claims, true
+}
@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientAuthenticator(t *testing.T) {
+	store := mock.NewClientStore()
+	assertions := mock.NewClientAssertionCache()
+	cfg := &app.Config{
+		BcryptCost: 4,
+		AuthNURL:   &url.URL{Scheme: "https", Host: "authn.example.com"},
+	}
+	failure := services.FieldErrors{{"client", services.ErrFailed}}
+
+	secretClient, secret, err := services.ClientCreator(context.Background(), store, cfg, "billing", "", nil, nil)
+	require.NoError(t, err)
+
+	key, err := private.GenerateKeyFor(private.ES256, 0)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	keyClient, _, err := services.ClientCreator(context.Background(), store, cfg, "reports", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil, nil)
+	require.NoError(t, err)
+
+	assertion := func(signer *private.Key, claims jwt.Claims) string {
+		s, err := jose.NewSigner(jose.SigningKey{Algorithm: signer.Algorithm(), Key: signer.PrivateKey}, nil)
+		require.NoError(t, err)
+		str, err := jwt.Signed(s).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+		return str
+	}
+	jti := 0
+	validClaims := func() jwt.Claims {
+		jti++
+		return jwt.Claims{
+			ID:       strconv.Itoa(jti),
+			Issuer:   keyClient.ID,
+			Subject:  keyClient.ID,
+			Audience: jwt.Audience{"https://authn.example.com/oauth/token"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		}
+	}
+
+	t.Run("valid secret", func(t *testing.T) {
+		client, err := services.ClientAuthenticator(context.Background(), store, assertions, cfg, secretClient.ID, secret, "")
+		require.NoError(t, err)
+		assert.Equal(t, secretClient.ID, client.ID)
+	})
+
+	t.Run("invalid secret", func(t *testing.T) {
+		_, err := services.ClientAuthenticator(context.Background(), store, assertions, cfg, secretClient.ID, "wrong", "")
+		assert.Equal(t, failure, err)
+	})
+
+	t.Run("unknown client", func(t *testing.T) {
+		_, err := services.ClientAuthenticator(context.Background(), store, assertions, cfg, "unknown", secret, "")
+		assert.Equal(t, failure, err)
+	})
+
+	t.Run("valid assertion", func(t *testing.T) {
+		client, err := services.ClientAuthenticator(context.Background(), store, assertions, cfg, "", "", assertion(key, validClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, keyClient.ID, client.ID)
+	})
+
+	t.Run("assertion from another key", func(t *testing.T) {
+		otherKey, err := private.GenerateKeyFor(private.ES256, 0)
+		require.NoError(t, err)
+
+		_, err = services.ClientAuthenticator(context.Background(), store, assertions, cfg, "", "", assertion(otherKey, validClaims()))
+		assert.Equal(t, failure, err)
+	})
+
+	t.Run("expired assertion", func(t *testing.T) {
+		claims := validClaims()
+		claims.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+		_, err := services.ClientAuthenticator(context.Background(), store, assertions, cfg, "", "", assertion(key, claims))
+		assert.Equal(t, failure, err)
+	})
+
+	t.Run("assertion for another audience", func(t *testing.T) {
+		claims := validClaims()
+		claims.Audience = jwt.Audience{"https://other.example.com"}
+
+		_, err := services.ClientAuthenticator(context.Background(), store, assertions, cfg, "", "", assertion(key, claims))
+		assert.Equal(t, failure, err)
+	})
+
+	t.Run("replayed assertion", func(t *testing.T) {
+		replayed := assertion(key, validClaims())
+		_, err := services.ClientAuthenticator(context.Background(), store, assertions, cfg, "", "", replayed)
+		require.NoError(t, err)
+
+		_, err = services.ClientAuthenticator(context.Background(), store, assertions, cfg, "", "", replayed)
+		assert.Equal(t, failure, err)
+	})
+
+	t.Run("assertion without jti", func(t *testing.T) {
+		claims := validClaims()
+		claims.ID = ""
+
+		_, err := services.ClientAuthenticator(context.Background(), store, assertions, cfg, "", "", assertion(key, claims))
+		assert.Equal(t, failure, err)
+	})
+
+	t.Run("long-lived assertion", func(t *testing.T) {
+		claims := validClaims()
+		claims.Expiry = jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+		_, err := services.ClientAuthenticator(context.Background(), store, assertions, cfg, "", "", assertion(key, claims))
+		assert.Equal(t, failure, err)
+
+		claims = validClaims()
+		claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+		_, err = services.ClientAuthenticator(context.Background(), store, assertions, cfg, "", "", assertion(key, claims))
+		assert.Equal(t, failure, err)
+	})
+
+	t.Run("secret for a client with a public key", func(t *testing.T) {
+		_, err := services.ClientAuthenticator(context.Background(), store, assertions, cfg, keyClient.ID, secret, "")
+		assert.Equal(t, failure, err)
+	})
+}
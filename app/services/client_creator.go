@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ClientCreator registers a service client. Clients without a public key are given a secret, which
+// is returned here and never again.
+func ClientCreator(
+	ctx context.Context, store data.ClientStore, cfg *app.Config,
+	name string, publicKey string, scopes models.NameList, audiences models.NameList,
+) (*models.Client, string, error) {
+	name = strings.TrimSpace(name)
+	publicKey = strings.TrimSpace(publicKey)
+
+	errs := FieldErrors{}
+	if name == "" {
+		errs = append(errs, FieldError{"name", ErrMissing})
+	}
+	if publicKey != "" {
+		if _, err := parseClientPublicKey(publicKey); err != nil {
+			errs = append(errs, FieldError{"public_key", ErrFormatInvalid})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, "", errs
+	}
+
+	binID, err := lib.GenerateToken()
+	if err != nil {
+		return nil, "", errors.Wrap(err, "GenerateToken")
+	}
+	client := &models.Client{
+		ID:        hex.EncodeToString(binID),
+		Name:      name,
+		PublicKey: publicKey,
+		Scopes:    scopes,
+		Audiences: audiences,
+	}
+
+	var secret string
+	if publicKey == "" {
+		binSecret, err := lib.GenerateToken()
+		if err != nil {
+			return nil, "", errors.Wrap(err, "GenerateToken")
+		}
+		secret = hex.EncodeToString(binSecret)
+		client.Secret, err = bcrypt.GenerateFromPassword([]byte(secret), cfg.BcryptCost)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "bcrypt")
+		}
+	}
+
+	err = store.Create(ctx, client)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Create")
+	}
+
+	return client, secret, nil
+}
+
+// parseClientPublicKey reads a PEM public key in PKIX format.
+func parseClientPublicKey(data string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
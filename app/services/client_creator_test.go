@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestClientCreator(t *testing.T) {
+	store := mock.NewClientStore()
+	cfg := &app.Config{BcryptCost: 4}
+
+	t.Run("with a secret", func(t *testing.T) {
+		client, secret, err := services.ClientCreator(context.Background(), store, cfg, "billing", "", models.NameList{"read"}, models.NameList{"https://api.example.com"})
+		require.NoError(t, err)
+		assert.NotEmpty(t, client.ID)
+		assert.NotEmpty(t, secret)
+
+		found, err := store.Find(context.Background(), client.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "billing", found.Name)
+		assert.Equal(t, models.NameList{"read"}, found.Scopes)
+		assert.NoError(t, bcrypt.CompareHashAndPassword(found.Secret, []byte(secret)))
+	})
+
+	t.Run("with a public key", func(t *testing.T) {
+		key, err := private.GenerateKeyFor(private.ES256, 0)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKIXPublicKey(key.Public())
+		require.NoError(t, err)
+		publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+		client, secret, err := services.ClientCreator(context.Background(), store, cfg, "billing", publicKey, nil, nil)
+		require.NoError(t, err)
+		assert.Empty(t, secret)
+		assert.Empty(t, client.Secret)
+		assert.NotEmpty(t, client.PublicKey)
+	})
+
+	failureCases := []struct {
+		name      string
+		publicKey string
+		errors    services.FieldErrors
+	}{
+		{"", "", services.FieldErrors{{"name", services.ErrMissing}}},
+		{"  ", "", services.FieldErrors{{"name", services.ErrMissing}}},
+		{"billing", "not a key", services.FieldErrors{{"public_key", services.ErrFormatInvalid}}},
+	}
+	for _, fc := range failureCases {
+		t.Run(fc.name+fc.publicKey, func(t *testing.T) {
+			_, _, err := services.ClientCreator(context.Background(), store, cfg, fc.name, fc.publicKey, nil, nil)
+			assert.Equal(t, fc.errors, err)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+func ClientDeleter(ctx context.Context, store data.ClientStore, id string) error {
+	ok, err := store.Delete(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "Delete")
+	}
+	if !ok {
+		return FieldErrors{{"client", ErrNotFound}}
+	}
+
+	return nil
+}
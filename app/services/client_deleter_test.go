@@ -0,0 +1,32 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientDeleter(t *testing.T) {
+	store := mock.NewClientStore()
+	err := store.Create(context.Background(), &models.Client{ID: "a1b2c3", Name: "billing"})
+	require.NoError(t, err)
+
+	t.Run("deleting", func(t *testing.T) {
+		err := services.ClientDeleter(context.Background(), store, "a1b2c3")
+		require.NoError(t, err)
+
+		client, err := store.Find(context.Background(), "a1b2c3")
+		require.NoError(t, err)
+		assert.Nil(t, client)
+	})
+
+	t.Run("unknown client", func(t *testing.T) {
+		err := services.ClientDeleter(context.Background(), store, "unknown")
+		assert.Equal(t, services.FieldErrors{{"client", services.ErrNotFound}}, err)
+	})
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/access"
+	"github.com/pkg/errors"
+)
+
+// ClientTokenCreator issues an access token to a client for the requested scopes and audiences,
+// which must have been granted to the client. When none are requested, everything that was granted
+// is included.
+func ClientTokenCreator(
+	keyStore data.KeyStore, cfg *app.Config,
+	client *models.Client, scope string, audience string,
+) (string, models.NameList, error) {
+	scopes := models.ParseNameList(scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	} else if !client.Scopes.Contains(scopes...) {
+		return "", nil, FieldErrors{{"scope", ErrFormatInvalid}}
+	}
+
+	audiences := models.ParseNameList(audience)
+	if len(audiences) == 0 {
+		audiences = client.Audiences
+	} else if !client.Audiences.Contains(audiences...) {
+		return "", nil, FieldErrors{{"audience", ErrFormatInvalid}}
+	}
+
+	token, err := access.New(cfg, client, scopes, audiences).Sign(keyStore.Key())
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Sign")
+	}
+
+	return token, scopes, nil
+}
@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientTokenCreator(t *testing.T) {
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(key)
+	cfg := &app.Config{
+		AuthNURL:       &url.URL{Scheme: "https", Host: "authn.example.com"},
+		AccessTokenTTL: time.Hour,
+	}
+	client := &models.Client{
+		ID:        "a1b2c3",
+		Scopes:    models.NameList{"read", "write"},
+		Audiences: models.NameList{"https://api.example.com", "https://reports.example.com"},
+	}
+
+	parse := func(token string) access.Claims {
+		parsed, err := jwt.ParseSigned(token)
+		require.NoError(t, err)
+		claims := access.Claims{}
+		require.NoError(t, parsed.Claims(key.Public(), &claims))
+		return claims
+	}
+
+	t.Run("everything granted", func(t *testing.T) {
+		token, scopes, err := services.ClientTokenCreator(keyStore, cfg, client, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, models.NameList{"read", "write"}, scopes)
+
+		claims := parse(token)
+		assert.Equal(t, "a1b2c3", claims.Subject)
+		assert.Equal(t, "read write", claims.Scope)
+		assert.Equal(t, jwt.Audience{"https://api.example.com", "https://reports.example.com"}, claims.Audience)
+	})
+
+	t.Run("requested subset", func(t *testing.T) {
+		token, scopes, err := services.ClientTokenCreator(keyStore, cfg, client, "read", "https://api.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, models.NameList{"read"}, scopes)
+
+		claims := parse(token)
+		assert.Equal(t, "read", claims.Scope)
+		assert.Equal(t, jwt.Audience{"https://api.example.com"}, claims.Audience)
+	})
+
+	t.Run("scope not granted", func(t *testing.T) {
+		_, _, err := services.ClientTokenCreator(keyStore, cfg, client, "read admin", "")
+		assert.Equal(t, services.FieldErrors{{"scope", services.ErrFormatInvalid}}, err)
+	})
+
+	t.Run("audience not granted", func(t *testing.T) {
+		_, _, err := services.ClientTokenCreator(keyStore, cfg, client, "", "https://other.example.com")
+		assert.Equal(t, services.FieldErrors{{"audience", services.ErrFormatInvalid}}, err)
+	})
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+func ClientUpdater(
+	ctx context.Context, store data.ClientStore,
+	id string, name string, scopes models.NameList, audiences models.NameList,
+) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return FieldErrors{{"name", ErrMissing}}
+	}
+
+	ok, err := store.Update(ctx, id, name, scopes, audiences)
+	if err != nil {
+		return errors.Wrap(err, "Update")
+	}
+	if !ok {
+		return FieldErrors{{"client", ErrNotFound}}
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientUpdater(t *testing.T) {
+	store := mock.NewClientStore()
+	err := store.Create(context.Background(), &models.Client{ID: "a1b2c3", Name: "billing"})
+	require.NoError(t, err)
+
+	t.Run("updating", func(t *testing.T) {
+		err := services.ClientUpdater(context.Background(), store, "a1b2c3", "invoicing", models.NameList{"read"}, nil)
+		require.NoError(t, err)
+
+		client, err := store.Find(context.Background(), "a1b2c3")
+		require.NoError(t, err)
+		assert.Equal(t, "invoicing", client.Name)
+		assert.Equal(t, models.NameList{"read"}, client.Scopes)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		err := services.ClientUpdater(context.Background(), store, "a1b2c3", "", nil, nil)
+		assert.Equal(t, services.FieldErrors{{"name", services.ErrMissing}}, err)
+	})
+
+	t.Run("unknown client", func(t *testing.T) {
+		err := services.ClientUpdater(context.Background(), store, "unknown", "billing", nil, nil)
+		assert.Equal(t, services.FieldErrors{{"client", services.ErrNotFound}}, err)
+	})
+}
@@ -1,12 +1,12 @@
 package services
 
 import (
+	"context"
+
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/models"
-	"github.com/keratin/authn-server/lib/compat"
 	"github.com/pkg/errors"
-	"github.com/pquerna/otp/totp"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -17,12 +17,12 @@ var emptyHashes = map[int]string{
 	12: "$2a$12$w58M3IGXURRAqXQ/OAsMmuqcV4YqP3WyJ.yHvHI5ANUK1bRWxeceK",
 }
 
-func CredentialsVerifier(store data.AccountStore, cfg *app.Config, username string, password, otpCode string) (*models.Account, error) {
+func CredentialsVerifier(ctx context.Context, store data.AccountStore, codes data.OTPCodeCache, cfg *app.Config, username string, password, otpCode string) (*models.Account, error) {
 	if username == "" && password == "" {
 		return nil, FieldErrors{{"credentials", ErrFailed}}
 	}
 
-	account, err := store.FindByUsername(username)
+	account, err := store.FindByUsername(ctx, username)
 	if err != nil {
 		return nil, errors.Wrap(err, "FindByUsername")
 	}
@@ -48,17 +48,12 @@ func CredentialsVerifier(store data.AccountStore, cfg *app.Config, username stri
 	}
 
 	//Check OTP MFA
-	if account.TOTPEnabled() {
-		secret, err := compat.Decrypt([]byte(account.TOTPSecret.String), cfg.DBEncryptionKey)
-		if err != nil {
-			return nil, errors.Wrap(err, "TOTPDecrypt")
-		}
-		if !totp.Validate(otpCode, secret) {
-			if otpCode == "" {
-				return nil, FieldErrors{{"otp", ErrMissing}}
-			}
-			return nil, FieldErrors{{"otp", ErrInvalidOrExpired}}
-		}
+	err = OTPVerifier(ctx, store, codes, cfg, account, otpCode)
+	if IsMissingOTP(err) {
+		// the account is returned so that the login may be completed with a challenge
+		return account, err
+	} else if err != nil {
+		return nil, err
 	}
 
 	return account, nil
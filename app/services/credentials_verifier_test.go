@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -19,10 +20,10 @@ func TestCredentialsVerifierSuccess(t *testing.T) {
 
 	cfg := app.Config{BcryptCost: 4}
 	store := mock.NewAccountStore()
-	_, err := store.Create(username, bcrypted)
+	_, err := store.Create(context.Background(), username, bcrypted)
 	require.NoError(t, err)
 
-	acc, err := services.CredentialsVerifier(store, &cfg, username, password, "")
+	acc, err := services.CredentialsVerifier(context.Background(), store, mock.NewOTPCodeCache(), &cfg, username, password, "")
 	require.NoError(t, err)
 	assert.NotEqual(t, 0, acc.ID)
 	assert.Equal(t, username, acc.Username)
@@ -39,30 +40,107 @@ func TestCredentialsVerifierWithOTPSuccess(t *testing.T) {
 
 	cfg := app.Config{BcryptCost: 4, DBEncryptionKey: dbEncryptionKey}
 	store := mock.NewAccountStore()
-	account, _ := store.Create(username, bcrypted)
-	_, err := store.SetTOTPSecret(account.ID, totpSecretEnc)
+	account, _ := store.Create(context.Background(), username, bcrypted)
+	_, err := store.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
 	require.NoError(t, err)
 
 	code, err := totp.GenerateCode(totpSecret, time.Now())
 	require.NoError(t, err)
 
-	acc, err := services.CredentialsVerifier(store, &cfg, username, password, code)
+	acc, err := services.CredentialsVerifier(context.Background(), store, mock.NewOTPCodeCache(), &cfg, username, password, code)
 	require.NoError(t, err)
 	assert.NotEqual(t, 0, acc.ID)
 	assert.Equal(t, username, acc.Username)
 }
 
+func TestCredentialsVerifierWithSecondAuthenticator(t *testing.T) {
+	username := "myname"
+	password := "mysecret"
+	dbEncryptionKey := []byte("DLz2TNDRdWWA5w8YNeCJ7uzcS4WDzQmB")
+	// nolint: gosec
+	totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
+	totpSecretEnc := []byte("cli6azfL5i7PAnh8U/w3Zbglsm3XcdaGODy+Ga5QqT02c9hotDAR1Y28--3UihzsJhw/+EU3R6--qUw9L8DwN5XPVfOStshKzA==")
+	bcrypted := []byte("$2a$04$lzQPXlov4RFLxps1uUGq4e4wmVjLYz3WrqQw4bSdfIiJRyo3/fk3C")
+
+	cfg := app.Config{BcryptCost: 4, DBEncryptionKey: dbEncryptionKey}
+	store := mock.NewAccountStore()
+	account, _ := store.Create(context.Background(), username, bcrypted)
+	otherSecretEnc, err := cfg.DBEncryptionKeyring().Encrypt([]byte("OUXDHF6KVG2GK2LQMN3VK3DUOBQWK4TT"))
+	require.NoError(t, err)
+	_, err = store.AddTOTPAuthenticator(context.Background(), account.ID, "tablet", otherSecretEnc, 0)
+	require.NoError(t, err)
+	phone, err := store.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
+	require.NoError(t, err)
+
+	code, err := totp.GenerateCode(totpSecret, time.Now())
+	require.NoError(t, err)
+
+	acc, err := services.CredentialsVerifier(context.Background(), store, mock.NewOTPCodeCache(), &cfg, username, password, code)
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, acc.ID)
+
+	authenticators, err := store.GetTOTPAuthenticators(context.Background(), account.ID)
+	require.NoError(t, err)
+	for _, authenticator := range authenticators {
+		if authenticator.ID == phone.ID {
+			assert.NotNil(t, authenticator.LastUsedAt)
+		} else {
+			assert.Nil(t, authenticator.LastUsedAt)
+		}
+	}
+}
+
+func TestCredentialsVerifierWithReplayedOTP(t *testing.T) {
+	username := "myname"
+	password := "mysecret"
+	dbEncryptionKey := []byte("DLz2TNDRdWWA5w8YNeCJ7uzcS4WDzQmB")
+	// nolint: gosec
+	totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
+	totpSecretEnc := []byte("cli6azfL5i7PAnh8U/w3Zbglsm3XcdaGODy+Ga5QqT02c9hotDAR1Y28--3UihzsJhw/+EU3R6--qUw9L8DwN5XPVfOStshKzA==")
+	bcrypted := []byte("$2a$04$lzQPXlov4RFLxps1uUGq4e4wmVjLYz3WrqQw4bSdfIiJRyo3/fk3C")
+
+	cfg := app.Config{BcryptCost: 4, DBEncryptionKey: dbEncryptionKey}
+	store := mock.NewAccountStore()
+	account, _ := store.Create(context.Background(), username, bcrypted)
+	_, err := store.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
+	require.NoError(t, err)
+
+	code, err := totp.GenerateCode(totpSecret, time.Now())
+	require.NoError(t, err)
+	_, err = services.CredentialsVerifier(context.Background(), store, mock.NewOTPCodeCache(), &cfg, username, password, code)
+	require.NoError(t, err)
+
+	t.Run("same code", func(t *testing.T) {
+		_, errs := services.CredentialsVerifier(context.Background(), store, mock.NewOTPCodeCache(), &cfg, username, password, code)
+		assert.Equal(t, services.FieldErrors{{"otp", "INVALID_OR_EXPIRED"}}, errs)
+	})
+
+	t.Run("code from an earlier time step", func(t *testing.T) {
+		earlier, err := totp.GenerateCode(totpSecret, time.Now().Add(-30*time.Second))
+		require.NoError(t, err)
+		_, errs := services.CredentialsVerifier(context.Background(), store, mock.NewOTPCodeCache(), &cfg, username, password, earlier)
+		assert.Equal(t, services.FieldErrors{{"otp", "INVALID_OR_EXPIRED"}}, errs)
+	})
+
+	t.Run("code from a later time step", func(t *testing.T) {
+		later, err := totp.GenerateCode(totpSecret, time.Now().Add(30*time.Second))
+		require.NoError(t, err)
+		_, err = services.CredentialsVerifier(context.Background(), store, mock.NewOTPCodeCache(), &cfg, username, password, later)
+		assert.NoError(t, err)
+	})
+}
+
 func TestCredentialsVerifierFailure(t *testing.T) {
 	password := "mysecret"
 	bcrypted := []byte("$2a$04$lzQPXlov4RFLxps1uUGq4e4wmVjLYz3WrqQw4bSdfIiJRyo3/fk3C")
 
 	cfg := app.Config{BcryptCost: 4}
 	store := mock.NewAccountStore()
-	_, _ = store.Create("known", bcrypted)
-	acc, _ := store.Create("locked", bcrypted)
-	_, _ = store.Lock(acc.ID)
-	acc, _ = store.Create("expired", bcrypted)
-	_, _ = store.RequireNewPassword(acc.ID)
+	_, _ = store.Create(context.Background(), "known", bcrypted)
+	acc, _ := store.Create(context.Background(), "locked", bcrypted)
+	_, _ = store.Lock(context.Background(), acc.ID)
+	acc, _ = store.Create(context.Background(), "expired", bcrypted)
+	_, _ = store.RequireNewPassword(context.Background(), acc.ID)
 
 	testCases := []struct {
 		username string
@@ -79,7 +157,7 @@ func TestCredentialsVerifierFailure(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		_, errs := services.CredentialsVerifier(store, &cfg, tc.username, tc.password, "")
+		_, errs := services.CredentialsVerifier(context.Background(), store, mock.NewOTPCodeCache(), &cfg, tc.username, tc.password, "")
 		assert.Equal(t, tc.errors, errs)
 	}
 }
@@ -93,8 +171,8 @@ func TestCredentialsVerifierWithOTPFailure(t *testing.T) {
 
 	cfg := app.Config{BcryptCost: 4, DBEncryptionKey: dbEncryptionKey}
 	store := mock.NewAccountStore()
-	account, _ := store.Create(username, bcrypted)
-	_, err := store.SetTOTPSecret(account.ID, totpSecretEnc)
+	account, _ := store.Create(context.Background(), username, bcrypted)
+	_, err := store.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
 	require.NoError(t, err)
 
 	testCases := []struct {
@@ -106,7 +184,7 @@ func TestCredentialsVerifierWithOTPFailure(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		_, errs := services.CredentialsVerifier(store, &cfg, username, password, tc.code)
+		_, errs := services.CredentialsVerifier(context.Background(), store, mock.NewOTPCodeCache(), &cfg, username, password, tc.code)
 		assert.Equal(t, tc.errors, errs)
 	}
 }
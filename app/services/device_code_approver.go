@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// DeviceCodeApprover allows the device that is showing the user code to log in to the account. The
+// device's session will be for the same application domain and authentication methods as the session
+// that approved it.
+func DeviceCodeApprover(ctx context.Context, codes data.DeviceCodeCache, userCode string, accountID int, audience string, amr []string) error {
+	if userCode == "" {
+		return FieldErrors{{"user_code", ErrMissing}}
+	}
+
+	deviceCode, err := codes.FindDeviceCode(ctx, normalizeUserCode(userCode))
+	if err != nil {
+		return errors.Wrap(err, "FindDeviceCode")
+	}
+	if deviceCode == "" {
+		return FieldErrors{{"user_code", ErrInvalidOrExpired}}
+	}
+
+	authorization, err := codes.LoadDeviceAuthorization(ctx, deviceCode)
+	if err != nil {
+		return errors.Wrap(err, "LoadDeviceAuthorization")
+	}
+	if authorization == nil || authorization.Approved() || time.Now().After(authorization.ExpiresAt) {
+		return FieldErrors{{"user_code", ErrInvalidOrExpired}}
+	}
+
+	// only the first of any concurrent approvals is kept
+	authorization.AccountID = accountID
+	authorization.Audience = audience
+	authorization.AuthMethodReference = amr
+	approved, err := codes.ApproveDeviceAuthorization(ctx, deviceCode, authorization)
+	if err != nil {
+		return errors.Wrap(err, "ApproveDeviceAuthorization")
+	}
+	if !approved {
+		return FieldErrors{{"user_code", ErrInvalidOrExpired}}
+	}
+
+	return nil
+}
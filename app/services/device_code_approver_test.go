@@ -0,0 +1,77 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceCodeApprover(t *testing.T) {
+	codes := mock.NewDeviceCodeCache()
+	cfg := &app.Config{
+		DeviceCodeTTL: time.Minute,
+	}
+
+	t.Run("approving a pending code", func(t *testing.T) {
+		deviceCode, userCode, err := services.DeviceCodeCreator(context.Background(), codes, cfg, false)
+		require.NoError(t, err)
+
+		err = services.DeviceCodeApprover(context.Background(), codes, userCode, 123, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+
+		authorization, err := codes.LoadDeviceAuthorization(context.Background(), deviceCode)
+		require.NoError(t, err)
+		assert.True(t, authorization.Approved())
+		assert.Equal(t, 123, authorization.AccountID)
+		assert.Equal(t, "example.com", authorization.Audience)
+		assert.Equal(t, []string{"pwd"}, authorization.AuthMethodReference)
+	})
+
+	t.Run("approving a code as typed", func(t *testing.T) {
+		_, userCode, err := services.DeviceCodeCreator(context.Background(), codes, cfg, false)
+		require.NoError(t, err)
+
+		typed := strings.ToLower(strings.Replace(userCode, "-", " ", 1))
+		err = services.DeviceCodeApprover(context.Background(), codes, typed, 123, "example.com", []string{"pwd"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("approving a code twice", func(t *testing.T) {
+		_, userCode, err := services.DeviceCodeCreator(context.Background(), codes, cfg, false)
+		require.NoError(t, err)
+
+		err = services.DeviceCodeApprover(context.Background(), codes, userCode, 123, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+		err = services.DeviceCodeApprover(context.Background(), codes, userCode, 234, "example.com", []string{"pwd"})
+		assert.Equal(t, services.FieldErrors{{"user_code", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("approving an expired code", func(t *testing.T) {
+		err := codes.CacheDeviceAuthorization(context.Background(), "expired", &models.DeviceAuthorization{
+			UserCode:  "BBBB-BBBB",
+			ExpiresAt: time.Now().Add(-time.Second),
+		})
+		require.NoError(t, err)
+
+		err = services.DeviceCodeApprover(context.Background(), codes, "BBBB-BBBB", 123, "example.com", []string{"pwd"})
+		assert.Equal(t, services.FieldErrors{{"user_code", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("approving an unknown code", func(t *testing.T) {
+		err := services.DeviceCodeApprover(context.Background(), codes, "CCCC-CCCC", 123, "example.com", []string{"pwd"})
+		assert.Equal(t, services.FieldErrors{{"user_code", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("approving without a code", func(t *testing.T) {
+		err := services.DeviceCodeApprover(context.Background(), codes, "", 123, "example.com", []string{"pwd"})
+		assert.Equal(t, services.FieldErrors{{"user_code", services.ErrMissing}}, err)
+	})
+}
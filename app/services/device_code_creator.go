@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib"
+	"github.com/pkg/errors"
+)
+
+// DeviceCodeInterval is how long a device must wait between polls for its tokens.
+const DeviceCodeInterval = 5 * time.Second
+
+// userCodeAlphabet has no vowels, so that codes don't spell words, and no digits that could be
+// confused with letters. Codes are case insensitive.
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// DeviceCodeCreator starts the device authorization grant, as specified by RFC 8628. The device
+// polls with the device code while a user approves the user code in a browser. The session token
+// will only be given to the device when it was requested.
+func DeviceCodeCreator(ctx context.Context, codes data.DeviceCodeCache, cfg *app.Config, session bool) (string, string, error) {
+	binCode, err := lib.GenerateToken()
+	if err != nil {
+		return "", "", errors.Wrap(err, "GenerateToken")
+	}
+	deviceCode := hex.EncodeToString(binCode)
+
+	// user codes are short, so a pending code could be generated again
+	var userCode string
+	for i := 0; i < 3 && userCode == ""; i++ {
+		candidate, err := generateUserCode()
+		if err != nil {
+			return "", "", errors.Wrap(err, "generateUserCode")
+		}
+		existing, err := codes.FindDeviceCode(ctx, candidate)
+		if err != nil {
+			return "", "", errors.Wrap(err, "FindDeviceCode")
+		}
+		if existing == "" {
+			userCode = candidate
+		}
+	}
+	if userCode == "" {
+		return "", "", fmt.Errorf("user code collisions")
+	}
+
+	err = codes.CacheDeviceAuthorization(ctx, deviceCode, &models.DeviceAuthorization{
+		UserCode:  userCode,
+		Session:   session,
+		ExpiresAt: time.Now().Add(cfg.DeviceCodeTTL),
+	})
+	if err != nil {
+		return "", "", errors.Wrap(err, "CacheDeviceAuthorization")
+	}
+
+	return deviceCode, userCode, nil
+}
+
+// generateUserCode returns eight random letters in the format XXXX-XXXX.
+func generateUserCode() (string, error) {
+	code := make([]byte, 8)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeAlphabet[n.Int64()]
+	}
+	return formatUserCode(string(code)), nil
+}
+
+// normalizeUserCode allows a user code to be entered in any case, with or without the dash.
+func normalizeUserCode(userCode string) string {
+	letters := strings.Builder{}
+	for _, r := range strings.ToUpper(userCode) {
+		if r >= 'A' && r <= 'Z' {
+			letters.WriteRune(r)
+		}
+	}
+	return formatUserCode(letters.String())
+}
+
+func formatUserCode(letters string) string {
+	if len(letters) != 8 {
+		return letters
+	}
+	return letters[:4] + "-" + letters[4:]
+}
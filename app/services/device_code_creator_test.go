@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceCodeCreator(t *testing.T) {
+	codes := mock.NewDeviceCodeCache()
+	cfg := &app.Config{
+		DeviceCodeTTL: time.Minute,
+	}
+
+	t.Run("creates a pending authorization", func(t *testing.T) {
+		deviceCode, userCode, err := services.DeviceCodeCreator(context.Background(), codes, cfg, true)
+		require.NoError(t, err)
+		assert.NotEmpty(t, deviceCode)
+		assert.Regexp(t, regexp.MustCompile(`^[B-Z]{4}-[B-Z]{4}$`), userCode)
+
+		found, err := codes.FindDeviceCode(context.Background(), userCode)
+		require.NoError(t, err)
+		assert.Equal(t, deviceCode, found)
+
+		authorization, err := codes.LoadDeviceAuthorization(context.Background(), deviceCode)
+		require.NoError(t, err)
+		require.NotNil(t, authorization)
+		assert.Equal(t, userCode, authorization.UserCode)
+		assert.True(t, authorization.Session)
+		assert.False(t, authorization.Approved())
+		assert.WithinDuration(t, time.Now().Add(time.Minute), authorization.ExpiresAt, time.Second)
+	})
+
+	t.Run("creates unique codes", func(t *testing.T) {
+		deviceCode1, userCode1, err := services.DeviceCodeCreator(context.Background(), codes, cfg, false)
+		require.NoError(t, err)
+		deviceCode2, userCode2, err := services.DeviceCodeCreator(context.Background(), codes, cfg, false)
+		require.NoError(t, err)
+		assert.NotEqual(t, deviceCode1, deviceCode2)
+		assert.NotEqual(t, userCode1, userCode2)
+	})
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// DeviceTokenCreator exchanges an approved device code for a new session, as specified by RFC 8628.
+// Until the code is approved, polling more often than DeviceCodeInterval is an error. A device code
+// may only be exchanged once, and the session token is only returned when it was requested.
+func DeviceTokenCreator(
+	ctx context.Context, codes data.DeviceCodeCache, accountStore data.AccountStore, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, actives data.Actives, cfg *app.Config, reporter ops.ErrorReporter,
+	deviceCode string,
+) (string, string, error) {
+	authorization, err := codes.LoadDeviceAuthorization(ctx, deviceCode)
+	if err != nil {
+		return "", "", errors.Wrap(err, "LoadDeviceAuthorization")
+	}
+	if authorization == nil {
+		return "", "", FieldErrors{{"device_code", ErrNotFound}}
+	}
+	if time.Now().After(authorization.ExpiresAt) {
+		err = codes.RemoveDeviceAuthorization(ctx, deviceCode)
+		if err != nil {
+			return "", "", errors.Wrap(err, "RemoveDeviceAuthorization")
+		}
+		return "", "", FieldErrors{{"device_code", ErrExpired}}
+	}
+
+	if !authorization.Approved() {
+		now := time.Now()
+		tooSoon := now.Sub(authorization.PolledAt) < DeviceCodeInterval
+		authorization.PolledAt = now
+		err = codes.CacheDeviceAuthorization(ctx, deviceCode, authorization)
+		if err != nil {
+			return "", "", errors.Wrap(err, "CacheDeviceAuthorization")
+		}
+		if tooSoon {
+			return "", "", FieldErrors{{"device_code", ErrSlowDown}}
+		}
+		return "", "", FieldErrors{{"device_code", ErrPending}}
+	}
+
+	// only one of any concurrent polls may exchange the code
+	claimed, err := codes.ClaimDeviceAuthorization(ctx, deviceCode)
+	if err != nil {
+		return "", "", errors.Wrap(err, "ClaimDeviceAuthorization")
+	}
+	if !claimed {
+		return "", "", FieldErrors{{"device_code", ErrNotFound}}
+	}
+
+	// the account may have changed since it was approved
+	account, err := accountStore.Find(ctx, authorization.AccountID)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() || account.Locked {
+		return "", "", FieldErrors{{"account", ErrDenied}}
+	}
+
+	audience := route.ParseDomain(authorization.Audience)
+	sessionToken, identityToken, err := SessionCreator(
+		ctx, accountStore, refreshTokenStore, keyStore, actives, cfg, reporter,
+		account.ID, &audience, nil, authorization.AuthMethodReference,
+	)
+	if err != nil {
+		if _, ok := err.(FieldErrors); ok {
+			return "", "", err
+		}
+		return "", "", errors.Wrap(err, "SessionCreator")
+	}
+	// a session that is restricted to setting up MFA is no use to a device
+	if identityToken == "" {
+		return "", "", FieldErrors{{"account", ErrDenied}}
+	}
+
+	if !authorization.Session {
+		sessionToken = ""
+	}
+	return sessionToken, identityToken, nil
+}
@@ -0,0 +1,154 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceTokenCreator(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+		AccessTokenTTL:    time.Hour,
+		DeviceCodeTTL:     time.Minute,
+	}
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(key)
+	codes := mock.NewDeviceCodeCache()
+	accountStore := mock.NewAccountStore()
+	refreshStore := mock.NewRefreshTokenStore()
+	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
+
+	account, err := accountStore.Create(context.Background(), "device@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+
+	invoke := func(deviceCode string) (string, string, error) {
+		return services.DeviceTokenCreator(
+			context.Background(), codes, accountStore, refreshStore, keyStore, nil, cfg, reporter,
+			deviceCode,
+		)
+	}
+
+	t.Run("pending code", func(t *testing.T) {
+		deviceCode, _, err := services.DeviceCodeCreator(context.Background(), codes, cfg, false)
+		require.NoError(t, err)
+
+		_, _, err = invoke(deviceCode)
+		assert.Equal(t, services.FieldErrors{{"device_code", services.ErrPending}}, err)
+
+		// polling again immediately
+		_, _, err = invoke(deviceCode)
+		assert.Equal(t, services.FieldErrors{{"device_code", services.ErrSlowDown}}, err)
+	})
+
+	t.Run("approved code", func(t *testing.T) {
+		deviceCode, userCode, err := services.DeviceCodeCreator(context.Background(), codes, cfg, false)
+		require.NoError(t, err)
+		err = services.DeviceCodeApprover(context.Background(), codes, userCode, account.ID, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+
+		sessionToken, identityToken, err := invoke(deviceCode)
+		require.NoError(t, err)
+		assert.Empty(t, sessionToken)
+		claims, err := identities.Parse(identityToken, cfg, keyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", claims.Audience[0])
+		assert.Equal(t, []string{"pwd"}, claims.AuthMethodReference)
+
+		// exchanging the code again
+		_, _, err = invoke(deviceCode)
+		assert.Equal(t, services.FieldErrors{{"device_code", services.ErrNotFound}}, err)
+	})
+
+	t.Run("approved code with session", func(t *testing.T) {
+		deviceCode, userCode, err := services.DeviceCodeCreator(context.Background(), codes, cfg, true)
+		require.NoError(t, err)
+		err = services.DeviceCodeApprover(context.Background(), codes, userCode, account.ID, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+
+		sessionToken, identityToken, err := invoke(deviceCode)
+		require.NoError(t, err)
+		assert.NotEmpty(t, sessionToken)
+		assert.NotEmpty(t, identityToken)
+	})
+
+	t.Run("approved code for locked account", func(t *testing.T) {
+		locked, err := accountStore.Create(context.Background(), "locked@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		deviceCode, userCode, err := services.DeviceCodeCreator(context.Background(), codes, cfg, false)
+		require.NoError(t, err)
+		err = services.DeviceCodeApprover(context.Background(), codes, userCode, locked.ID, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+		_, err = accountStore.Lock(context.Background(), locked.ID)
+		require.NoError(t, err)
+
+		_, _, err = invoke(deviceCode)
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrDenied}}, err)
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		err := codes.CacheDeviceAuthorization(context.Background(), "expired", &models.DeviceAuthorization{
+			UserCode:  "BBBB-BBBB",
+			AccountID: account.ID,
+			ExpiresAt: time.Now().Add(-time.Second),
+		})
+		require.NoError(t, err)
+
+		_, _, err = invoke("expired")
+		assert.Equal(t, services.FieldErrors{{"device_code", services.ErrExpired}}, err)
+	})
+
+	t.Run("concurrent polls of an approved code", func(t *testing.T) {
+		ebs := data.NewEncryptedBlobStore(mock.NewBlobStore(time.Minute, time.Second), compat.NewKeyring([]byte("secretsecretsecretsecretsecret12")))
+		codes := data.NewDeviceCodeCache(ebs)
+		deviceCode, userCode, err := services.DeviceCodeCreator(context.Background(), codes, cfg, false)
+		require.NoError(t, err)
+		err = services.DeviceCodeApprover(context.Background(), codes, userCode, account.ID, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		exchanged := 0
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, identityToken, err := services.DeviceTokenCreator(
+					context.Background(), codes, accountStore, refreshStore, keyStore, nil, cfg, reporter,
+					deviceCode,
+				)
+				if err == nil && identityToken != "" {
+					mutex.Lock()
+					exchanged++
+					mutex.Unlock()
+				} else {
+					assert.Equal(t, services.FieldErrors{{"device_code", services.ErrNotFound}}, err)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, exchanged)
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		_, _, err := invoke("unknown")
+		assert.Equal(t, services.FieldErrors{{"device_code", services.ErrNotFound}}, err)
+	})
+}
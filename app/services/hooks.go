@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/hooks"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/pkg/errors"
+)
+
+// preRegistrationHook asks the app whether the username may sign up. It allows everything unless
+// APP_PRE_REGISTRATION_URL is configured.
+func preRegistrationHook(ctx context.Context, cfg *app.Config, username string) error {
+	if cfg.AppPreRegistrationURL == nil {
+		return nil
+	}
+
+	result, err := hooks.Call(ctx, cfg.AppPreRegistrationURL, cfg.HookTimeout, cfg.AppSigningKey, url.Values{
+		"username": []string{username},
+		"tenant":   []string{tenants.Name(ctx)},
+	})
+	if err != nil {
+		return errors.Wrap(err, "Call")
+	}
+	return hookDenial(result)
+}
+
+// preLoginHook asks the app whether the account may log in to the audience, and returns any extra
+// claims for its identity tokens. It allows everything unless APP_PRE_LOGIN_URL is configured.
+func preLoginHook(ctx context.Context, cfg *app.Config, account *models.Account, audience string, amr []string) (map[string]interface{}, error) {
+	if cfg.AppPreLoginURL == nil {
+		return nil, nil
+	}
+
+	result, err := hooks.Call(ctx, cfg.AppPreLoginURL, cfg.HookTimeout, cfg.AppSigningKey, url.Values{
+		"account_id": []string{strconv.Itoa(account.ID)},
+		"username":   []string{account.Username},
+		"tenant":     []string{account.Tenant},
+		"domain":     []string{audience},
+		"amr":        []string{strings.Join(amr, " ")},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Call")
+	}
+	if err = hookDenial(result); err != nil {
+		return nil, err
+	}
+	return result.Claims, nil
+}
+
+// hookDenial converts a denial into an error, with the app's reason if it gave one.
+func hookDenial(result *hooks.Result) error {
+	if result.Allow {
+		return nil
+	}
+	if result.Reason != "" {
+		return FieldErrors{{"account", result.Reason}}
+	}
+	return FieldErrors{{"account", ErrDenied}}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/hex"
 
 	"github.com/keratin/authn-server/app"
@@ -23,9 +24,9 @@ import (
 // * account is locked
 // * linkable account is already linked
 // * identity's email is already registered
-func IdentityReconciler(accountStore data.AccountStore, cfg *app.Config, providerName string, providerUser *oauth.UserInfo, providerToken *oauth2.Token, linkableAccountID int) (*models.Account, error) {
+func IdentityReconciler(ctx context.Context, accountStore data.AccountStore, cfg *app.Config, providerName string, providerUser *oauth.UserInfo, providerToken *oauth2.Token, linkableAccountID int) (*models.Account, error) {
 	// 1. check for linked account
-	linkedAccount, err := accountStore.FindByOauthAccount(providerName, providerUser.ID)
+	linkedAccount, err := accountStore.FindByOauthAccount(ctx, providerName, providerUser.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "FindByOauthAccount")
 	}
@@ -38,7 +39,7 @@ func IdentityReconciler(accountStore data.AccountStore, cfg *app.Config, provide
 			return nil, errors.New("account locked")
 		}
 
-		err = updateUserInfo(accountStore, linkedAccount.ID, providerName, providerUser)
+		err = updateUserInfo(ctx, accountStore, linkedAccount.ID, providerName, providerUser)
 		if err != nil {
 			return nil, errors.Wrap(err, "updateUserInfo")
 		}
@@ -48,14 +49,14 @@ func IdentityReconciler(accountStore data.AccountStore, cfg *app.Config, provide
 
 	// 2. attempt linking to existing account
 	if linkableAccountID != 0 {
-		err = accountStore.AddOauthAccount(linkableAccountID, providerName, providerUser.ID, providerUser.Email, providerToken.AccessToken)
+		err = accountStore.AddOauthAccount(ctx, linkableAccountID, providerName, providerUser.ID, providerUser.Email, providerToken.AccessToken)
 		if err != nil {
 			if data.IsUniquenessError(err) {
 				return nil, errors.New("session conflict")
 			}
 			return nil, errors.Wrap(err, "AddOauthAccount")
 		}
-		sessionAccount, err := accountStore.Find(linkableAccountID)
+		sessionAccount, err := accountStore.Find(ctx, linkableAccountID)
 		if err != nil {
 			return nil, errors.Wrap(err, "Find")
 		}
@@ -69,11 +70,11 @@ func IdentityReconciler(accountStore data.AccountStore, cfg *app.Config, provide
 	}
 	// TODO: transactional account + identity
 	// Note we hex encode token because zxcvbn does not seem to like non-printable characters
-	newAccount, err := AccountCreator(accountStore, cfg, providerUser.Email, hex.EncodeToString(rand))
+	newAccount, err := AccountCreator(ctx, accountStore, cfg, providerUser.Email, hex.EncodeToString(rand))
 	if err != nil {
 		return nil, errors.Wrap(err, "AccountCreator")
 	}
-	err = accountStore.AddOauthAccount(newAccount.ID, providerName, providerUser.ID, providerUser.Email, providerToken.AccessToken)
+	err = accountStore.AddOauthAccount(ctx, newAccount.ID, providerName, providerUser.ID, providerUser.Email, providerToken.AccessToken)
 	if err != nil {
 		// this should not happen since oauth details used to lookup account above
 		// not sure how best to test but feels appropriate to return error if encountered
@@ -82,8 +83,8 @@ func IdentityReconciler(accountStore data.AccountStore, cfg *app.Config, provide
 	return newAccount, nil
 }
 
-func updateUserInfo(accountStore data.AccountStore, accountID int, providerName string, providerUser *oauth.UserInfo) error {
-	oAccounts, err := accountStore.GetOauthAccounts(accountID)
+func updateUserInfo(ctx context.Context, accountStore data.AccountStore, accountID int, providerName string, providerUser *oauth.UserInfo) error {
+	oAccounts, err := accountStore.GetOauthAccounts(ctx, accountID)
 	if err != nil {
 		return errors.Wrap(err, "GetOauthAccounts")
 	}
@@ -98,7 +99,7 @@ func updateUserInfo(accountStore data.AccountStore, accountID int, providerName
 		}
 
 		if oAccount.GetEmail() != providerUser.Email {
-			_, err = accountStore.UpdateOauthAccount(accountID, oAccount.Provider, providerUser.Email)
+			_, err = accountStore.UpdateOauthAccount(ctx, accountID, oAccount.Provider, providerUser.Email)
 			if err != nil {
 				return errors.Wrap(err, "UpdateOauthAccount")
 			}
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -20,12 +21,12 @@ func TestIdentityReconciler(t *testing.T) {
 	cfg := &app.Config{}
 
 	t.Run("linked account", func(t *testing.T) {
-		acct, err := store.Create("linked@test.com", []byte("password"))
+		acct, err := store.Create(context.Background(), "linked@test.com", []byte("password"))
 		require.NoError(t, err)
-		err = store.AddOauthAccount(acct.ID, "testProvider", "123", "email", "TOKEN")
+		err = store.AddOauthAccount(context.Background(), acct.ID, "testProvider", "123", "email", "TOKEN")
 		require.NoError(t, err)
 
-		found, err := services.IdentityReconciler(store, cfg, "testProvider", &oauth.UserInfo{ID: "123", Email: "linked@test.com"}, &oauth2.Token{}, 0)
+		found, err := services.IdentityReconciler(context.Background(), store, cfg, "testProvider", &oauth.UserInfo{ID: "123", Email: "linked@test.com"}, &oauth2.Token{}, 0)
 		assert.NoError(t, err)
 		if assert.NotNil(t, found) {
 			assert.Equal(t, found.Username, "linked@test.com")
@@ -33,23 +34,23 @@ func TestIdentityReconciler(t *testing.T) {
 	})
 
 	t.Run("linked account that is locked", func(t *testing.T) {
-		acct, err := store.Create("linkedlocked@test.com", []byte("password"))
+		acct, err := store.Create(context.Background(), "linkedlocked@test.com", []byte("password"))
 		require.NoError(t, err)
-		err = store.AddOauthAccount(acct.ID, "testProvider", "234", "email", "TOKEN")
+		err = store.AddOauthAccount(context.Background(), acct.ID, "testProvider", "234", "email", "TOKEN")
 		require.NoError(t, err)
-		_, err = store.Lock(acct.ID)
+		_, err = store.Lock(context.Background(), acct.ID)
 		require.NoError(t, err)
 
-		found, err := services.IdentityReconciler(store, cfg, "testProvider", &oauth.UserInfo{ID: "234", Email: "linkedlocked@test.com"}, &oauth2.Token{}, 0)
+		found, err := services.IdentityReconciler(context.Background(), store, cfg, "testProvider", &oauth.UserInfo{ID: "234", Email: "linkedlocked@test.com"}, &oauth2.Token{}, 0)
 		assert.Error(t, err)
 		assert.Nil(t, found)
 	})
 
 	t.Run("linkable account", func(t *testing.T) {
-		acct, err := store.Create("linkable@test.com", []byte("password"))
+		acct, err := store.Create(context.Background(), "linkable@test.com", []byte("password"))
 		require.NoError(t, err)
 
-		found, err := services.IdentityReconciler(store, cfg, "testProvider", &oauth.UserInfo{ID: "345", Email: "linkable@test.com"}, &oauth2.Token{}, acct.ID)
+		found, err := services.IdentityReconciler(context.Background(), store, cfg, "testProvider", &oauth.UserInfo{ID: "345", Email: "linkable@test.com"}, &oauth2.Token{}, acct.ID)
 		assert.NoError(t, err)
 		if assert.NotNil(t, found) {
 			assert.Equal(t, found.Username, "linkable@test.com")
@@ -57,18 +58,18 @@ func TestIdentityReconciler(t *testing.T) {
 	})
 
 	t.Run("linkable account that is linked", func(t *testing.T) {
-		acct, err := store.Create("linkablelinked@test.com", []byte("password"))
+		acct, err := store.Create(context.Background(), "linkablelinked@test.com", []byte("password"))
 		require.NoError(t, err)
-		err = store.AddOauthAccount(acct.ID, "testProvider", "0", "email", "TOKEN")
+		err = store.AddOauthAccount(context.Background(), acct.ID, "testProvider", "0", "email", "TOKEN")
 		require.NoError(t, err)
 
-		found, err := services.IdentityReconciler(store, cfg, "testProvider", &oauth.UserInfo{ID: "456", Email: "linkablelinked@test.com"}, &oauth2.Token{}, acct.ID)
+		found, err := services.IdentityReconciler(context.Background(), store, cfg, "testProvider", &oauth.UserInfo{ID: "456", Email: "linkablelinked@test.com"}, &oauth2.Token{}, acct.ID)
 		assert.Error(t, err)
 		assert.Nil(t, found)
 	})
 
 	t.Run("new account", func(t *testing.T) {
-		found, err := services.IdentityReconciler(store, cfg, "testProvider", &oauth.UserInfo{ID: "567", Email: "new@test.com"}, &oauth2.Token{}, 0)
+		found, err := services.IdentityReconciler(context.Background(), store, cfg, "testProvider", &oauth.UserInfo{ID: "567", Email: "new@test.com"}, &oauth2.Token{}, 0)
 		assert.NoError(t, err)
 		if assert.NotNil(t, found) {
 			assert.Equal(t, found.Username, "new@test.com")
@@ -76,10 +77,10 @@ func TestIdentityReconciler(t *testing.T) {
 	})
 
 	t.Run("new account with username collision", func(t *testing.T) {
-		_, err := store.Create("existing@test.com", []byte("password"))
+		_, err := store.Create(context.Background(), "existing@test.com", []byte("password"))
 		require.NoError(t, err)
 
-		found, err := services.IdentityReconciler(store, cfg, "testProvider", &oauth.UserInfo{ID: "678", Email: "existing@test.com"}, &oauth2.Token{}, 0)
+		found, err := services.IdentityReconciler(context.Background(), store, cfg, "testProvider", &oauth.UserInfo{ID: "678", Email: "existing@test.com"}, &oauth2.Token{}, 0)
 		assert.Error(t, err)
 		assert.Nil(t, found)
 	})
@@ -89,17 +90,17 @@ func TestIdentityReconciler(t *testing.T) {
 		providerAccountId := "666"
 		email := "update-missing-oauth-email@test.com"
 
-		account, err := store.Create(email, []byte("password"))
+		account, err := store.Create(context.Background(), email, []byte("password"))
 		require.NoError(t, err)
 
-		err = store.AddOauthAccount(account.ID, provider, providerAccountId, "", "TOKEN")
+		err = store.AddOauthAccount(context.Background(), account.ID, provider, providerAccountId, "", "TOKEN")
 		require.NoError(t, err)
 
-		found, err := services.IdentityReconciler(store, cfg, provider, &oauth.UserInfo{ID: providerAccountId, Email: email}, &oauth2.Token{}, 0)
+		found, err := services.IdentityReconciler(context.Background(), store, cfg, provider, &oauth.UserInfo{ID: providerAccountId, Email: email}, &oauth2.Token{}, 0)
 		assert.NoError(t, err)
 		assert.NotNil(t, found)
 
-		oAccounts, err := store.GetOauthAccounts(account.ID)
+		oAccounts, err := store.GetOauthAccounts(context.Background(), account.ID)
 		assert.NoError(t, err)
 		assert.Equal(t, 1, len(oAccounts))
 		assert.Equal(t, email, oAccounts[0].GetEmail())
@@ -110,17 +111,17 @@ func TestIdentityReconciler(t *testing.T) {
 		providerAccountId := "777"
 		email := "update-outdate-oauth-email@test.com"
 
-		account, err := store.Create(email, []byte("password"))
+		account, err := store.Create(context.Background(), email, []byte("password"))
 		require.NoError(t, err)
 
-		err = store.AddOauthAccount(account.ID, provider, providerAccountId, "email@email.com", "TOKEN")
+		err = store.AddOauthAccount(context.Background(), account.ID, provider, providerAccountId, "email@email.com", "TOKEN")
 		require.NoError(t, err)
 
-		found, err := services.IdentityReconciler(store, cfg, provider, &oauth.UserInfo{ID: providerAccountId, Email: email}, &oauth2.Token{}, 0)
+		found, err := services.IdentityReconciler(context.Background(), store, cfg, provider, &oauth.UserInfo{ID: providerAccountId, Email: email}, &oauth2.Token{}, 0)
 		assert.NoError(t, err)
 		assert.NotNil(t, found)
 
-		oAccounts, err := store.GetOauthAccounts(account.ID)
+		oAccounts, err := store.GetOauthAccounts(context.Background(), account.ID)
 		assert.NoError(t, err)
 		assert.Equal(t, 1, len(oAccounts))
 		assert.Equal(t, email, oAccounts[0].GetEmail())
@@ -1,11 +1,13 @@
 package services
 
 import (
+	"context"
+
 	"github.com/keratin/authn-server/app/data"
 )
 
-func IdentityRemover(store data.AccountStore, accountId int, providers []string) error {
-	account, err := store.Find(accountId)
+func IdentityRemover(ctx context.Context, store data.AccountStore, accountId int, providers []string) error {
+	account, err := store.Find(ctx, accountId)
 	if err != nil {
 		return err
 	}
@@ -15,7 +17,7 @@ func IdentityRemover(store data.AccountStore, accountId int, providers []string)
 	}
 
 	for _, provider := range providers {
-		_, err = store.DeleteOauthAccount(accountId, provider)
+		_, err = store.DeleteOauthAccount(ctx, accountId, provider)
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/keratin/authn-server/app/data/mock"
@@ -11,13 +12,13 @@ import (
 func TestIdentityRemover(t *testing.T) {
 	t.Run("delete non existing oauth accounts", func(t *testing.T) {
 		accountStore := mock.NewAccountStore()
-		account, err := accountStore.Create("deleted@keratin.tech", []byte("password"))
+		account, err := accountStore.Create(context.Background(), "deleted@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		err = services.IdentityRemover(accountStore, account.ID, []string{"test"})
+		err = services.IdentityRemover(context.Background(), accountStore, account.ID, []string{"test"})
 		require.NoError(t, err)
 
-		oAccount, err := accountStore.GetOauthAccounts(account.ID)
+		oAccount, err := accountStore.GetOauthAccounts(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		require.Equal(t, len(oAccount), 0)
@@ -25,16 +26,16 @@ func TestIdentityRemover(t *testing.T) {
 
 	t.Run("delete account", func(t *testing.T) {
 		accountStore := mock.NewAccountStore()
-		account, err := accountStore.Create("deleted@keratin.tech", []byte("password"))
+		account, err := accountStore.Create(context.Background(), "deleted@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		err = accountStore.AddOauthAccount(account.ID, "test", "TESTID", "email", "TOKEN")
+		err = accountStore.AddOauthAccount(context.Background(), account.ID, "test", "TESTID", "email", "TOKEN")
 		require.NoError(t, err)
 
-		err = services.IdentityRemover(accountStore, account.ID, []string{"test"})
+		err = services.IdentityRemover(context.Background(), accountStore, account.ID, []string{"test"})
 		require.NoError(t, err)
 
-		oAccount, err := accountStore.GetOauthAccounts(account.ID)
+		oAccount, err := accountStore.GetOauthAccounts(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		require.Equal(t, len(oAccount), 0)
@@ -42,19 +43,19 @@ func TestIdentityRemover(t *testing.T) {
 
 	t.Run("delete multiple accounts", func(t *testing.T) {
 		accountStore := mock.NewAccountStore()
-		account, err := accountStore.Create("deleted@keratin.tech", []byte("password"))
+		account, err := accountStore.Create(context.Background(), "deleted@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		err = accountStore.AddOauthAccount(account.ID, "test", "TESTID", "email", "TOKEN")
+		err = accountStore.AddOauthAccount(context.Background(), account.ID, "test", "TESTID", "email", "TOKEN")
 		require.NoError(t, err)
 
-		err = accountStore.AddOauthAccount(account.ID, "trial", "TESTID", "email", "TOKEN")
+		err = accountStore.AddOauthAccount(context.Background(), account.ID, "trial", "TESTID", "email", "TOKEN")
 		require.NoError(t, err)
 
-		err = services.IdentityRemover(accountStore, account.ID, []string{"test", "trial"})
+		err = services.IdentityRemover(context.Background(), accountStore, account.ID, []string{"test", "trial"})
 		require.NoError(t, err)
 
-		oAccount, err := accountStore.GetOauthAccounts(account.ID)
+		oAccount, err := accountStore.GetOauthAccounts(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		require.Equal(t, len(oAccount), 0)
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/pkg/errors"
+)
+
+// InvitationAcceptor creates an account with the username of an invitation. An invitation may only
+// be accepted once, and only in the tenant it was created for.
+func InvitationAcceptor(ctx context.Context, accountStore data.AccountStore, invitationStore data.InvitationStore, cfg *app.Config, invitation string, password string) (*models.Account, error) {
+	claims, err := invitations.Parse(invitation, cfg)
+	if err != nil || claims.Tenant != tenants.Name(ctx) {
+		return nil, FieldErrors{{"invitation", ErrInvalidOrExpired}}
+	}
+
+	acceptedBy, err := invitationStore.FindAccepted(ctx, claims.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "FindAccepted")
+	}
+	if acceptedBy != 0 {
+		return nil, FieldErrors{{"invitation", ErrInvalidOrExpired}}
+	}
+
+	account, err := AccountCreator(ctx, accountStore, cfg, claims.Subject, password)
+	if err != nil {
+		return nil, err
+	}
+
+	err = invitationStore.Accept(ctx, claims.ID, account.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Accept")
+	}
+
+	return account, nil
+}
@@ -0,0 +1,78 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationAcceptor(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	invitationStore := mock.NewInvitationStore()
+	cfg := &app.Config{
+		AuthNURL:                  &url.URL{Scheme: "https", Host: "authn.example.com"},
+		InvitationTokenSigningKey: []byte("key-a-reno"),
+		InvitationTokenTTL:        time.Hour,
+		BcryptCost:                4,
+	}
+
+	t.Run("valid invitation", func(t *testing.T) {
+		invitation, err := services.InvitationCreator(context.Background(), accountStore, cfg, "invited@example.com")
+		require.NoError(t, err)
+
+		account, err := services.InvitationAcceptor(context.Background(), accountStore, invitationStore, cfg, invitation, "PASSword")
+		require.NoError(t, err)
+		assert.Equal(t, "invited@example.com", account.Username)
+
+		t.Run("accepting again", func(t *testing.T) {
+			_, err := services.InvitationAcceptor(context.Background(), accountStore, invitationStore, cfg, invitation, "PASSword")
+			assert.Equal(t, services.FieldErrors{{"invitation", services.ErrInvalidOrExpired}}, err)
+		})
+
+		t.Run("after the account is archived", func(t *testing.T) {
+			_, err := accountStore.Archive(context.Background(), account.ID)
+			require.NoError(t, err)
+
+			_, err = services.InvitationAcceptor(context.Background(), accountStore, invitationStore, cfg, invitation, "PASSword")
+			assert.Equal(t, services.FieldErrors{{"invitation", services.ErrInvalidOrExpired}}, err)
+		})
+	})
+
+	t.Run("invalid password", func(t *testing.T) {
+		invitation, err := services.InvitationCreator(context.Background(), accountStore, cfg, "weak@example.com")
+		require.NoError(t, err)
+
+		_, err = services.InvitationAcceptor(context.Background(), accountStore, invitationStore, cfg, invitation, "")
+		assert.Equal(t, services.FieldErrors{{"password", services.ErrMissing}}, err)
+
+		// the invitation is not used up
+		_, err = services.InvitationAcceptor(context.Background(), accountStore, invitationStore, cfg, invitation, "PASSword")
+		assert.NoError(t, err)
+	})
+
+	t.Run("other tenant", func(t *testing.T) {
+		ctx := tenants.WithContext(context.Background(), "acme")
+		invitation, err := services.InvitationCreator(ctx, accountStore, cfg, "tenant@example.com")
+		require.NoError(t, err)
+
+		_, err = services.InvitationAcceptor(context.Background(), accountStore, invitationStore, cfg, invitation, "PASSword")
+		assert.Equal(t, services.FieldErrors{{"invitation", services.ErrInvalidOrExpired}}, err)
+
+		account, err := services.InvitationAcceptor(ctx, accountStore, invitationStore, cfg, invitation, "PASSword")
+		require.NoError(t, err)
+		assert.Equal(t, "acme", account.Tenant)
+	})
+
+	t.Run("invalid invitation", func(t *testing.T) {
+		_, err := services.InvitationAcceptor(context.Background(), accountStore, invitationStore, cfg, "not.a.token", "PASSword")
+		assert.Equal(t, services.FieldErrors{{"invitation", services.ErrInvalidOrExpired}}, err)
+	})
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/pkg/errors"
+)
+
+// InvitationCreator returns a signed invitation for a username that is not yet taken in the tenant
+// of the context. The invitation allows the username to sign up even when signup is disabled.
+func InvitationCreator(ctx context.Context, store data.AccountStore, cfg *app.Config, username string) (string, error) {
+	username = strings.TrimSpace(username)
+
+	fieldError := UsernameValidator(cfg, username)
+	if fieldError != nil {
+		return "", FieldErrors{*fieldError}
+	}
+
+	account, err := store.FindByUsername(ctx, username)
+	if err != nil {
+		return "", errors.Wrap(err, "FindByUsername")
+	}
+	if account != nil {
+		return "", FieldErrors{{"username", ErrTaken}}
+	}
+
+	invitation, err := invitations.New(cfg, username, tenants.Name(ctx))
+	if err != nil {
+		return "", errors.Wrap(err, "New Invitation")
+	}
+	invitationStr, err := invitation.Sign(cfg.InvitationTokenSigningKey)
+	if err != nil {
+		return "", errors.Wrap(err, "Sign")
+	}
+
+	return invitationStr, nil
+}
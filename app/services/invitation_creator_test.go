@@ -0,0 +1,61 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationCreator(t *testing.T) {
+	store := mock.NewAccountStore()
+	cfg := &app.Config{
+		AuthNURL:                  &url.URL{Scheme: "https", Host: "authn.example.com"},
+		InvitationTokenSigningKey: []byte("key-a-reno"),
+		InvitationTokenTTL:        time.Hour,
+		UsernameIsEmail:           true,
+	}
+	_, err := store.Create(context.Background(), "existing@example.com", []byte("password"))
+	require.NoError(t, err)
+
+	t.Run("new username", func(t *testing.T) {
+		invitation, err := services.InvitationCreator(context.Background(), store, cfg, " invited@example.com ")
+		require.NoError(t, err)
+
+		claims, err := invitations.Parse(invitation, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "invited@example.com", claims.Subject)
+		assert.Empty(t, claims.Tenant)
+	})
+
+	t.Run("tenant username", func(t *testing.T) {
+		ctx := tenants.WithContext(context.Background(), "acme")
+		invitation, err := services.InvitationCreator(ctx, store, cfg, "existing@example.com")
+		require.NoError(t, err)
+
+		claims, err := invitations.Parse(invitation, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", claims.Tenant)
+	})
+
+	t.Run("taken username", func(t *testing.T) {
+		_, err := services.InvitationCreator(context.Background(), store, cfg, "existing@example.com")
+		assert.Equal(t, services.FieldErrors{{"username", services.ErrTaken}}, err)
+	})
+
+	t.Run("invalid username", func(t *testing.T) {
+		_, err := services.InvitationCreator(context.Background(), store, cfg, "invited")
+		assert.Equal(t, services.FieldErrors{{"username", services.ErrFormatInvalid}}, err)
+
+		_, err = services.InvitationCreator(context.Background(), store, &app.Config{}, "")
+		assert.Equal(t, services.FieldErrors{{"username", services.ErrMissing}}, err)
+	})
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"net/url"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// InvitationSender delivers an invitation from InvitationCreator to the configured app.
+func InvitationSender(cfg *app.Config, username string, invitation string, logger logrus.FieldLogger) error {
+	err := WebhookSender(cfg.AppInvitationURL, &url.Values{
+		"username":   []string{username},
+		"invitation": []string{invitation},
+	}, timeSensitiveDelivery, cfg.AppSigningKey)
+	if err != nil {
+		return errors.Wrap(err, "Webhook")
+	}
+
+	logger.WithField("username", username).Info("sent invitation")
+
+	return nil
+}
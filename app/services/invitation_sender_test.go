@@ -0,0 +1,48 @@
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationSender(t *testing.T) {
+	var received url.Values
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, ok := r.BasicAuth()
+
+		if !ok || u != "user" || p != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+		} else if r.URL.Path == "/invitations" {
+			require.NoError(t, r.ParseForm())
+			received = r.PostForm
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer remoteApp.Close()
+	serverURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+
+	invoke := func() error {
+		cfg := &app.Config{
+			AppInvitationURL: &url.URL{Scheme: "http", Host: serverURL.Host, Path: "/invitations", User: url.UserPassword("user", "pass")},
+		}
+		return services.InvitationSender(cfg, "invited@example.com", "token", logrus.New())
+	}
+
+	t.Run("posting to remote app", func(t *testing.T) {
+		err := invoke()
+		require.NoError(t, err)
+		assert.Equal(t, "invited@example.com", received.Get("username"))
+		assert.Equal(t, "token", received.Get("invitation"))
+	})
+}
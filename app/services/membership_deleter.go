@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+func MembershipDeleter(ctx context.Context, store data.OrganizationStore, organizationID int, accountID int) error {
+	ok, err := store.DeleteMembership(ctx, organizationID, accountID)
+	if err != nil {
+		return errors.Wrap(err, "DeleteMembership")
+	}
+	if !ok {
+		return FieldErrors{{"membership", ErrNotFound}}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMembershipDeleter(t *testing.T) {
+	store := mock.NewOrganizationStore()
+	organization, err := store.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	err = store.SetMembership(context.Background(), organization.ID, 1, models.NameList{"admin"})
+	require.NoError(t, err)
+
+	t.Run("removing a member", func(t *testing.T) {
+		err := services.MembershipDeleter(context.Background(), store, organization.ID, 1)
+		require.NoError(t, err)
+
+		membership, err := store.FindMembership(context.Background(), organization.ID, 1)
+		require.NoError(t, err)
+		assert.Nil(t, membership)
+	})
+
+	t.Run("unknown membership", func(t *testing.T) {
+		err := services.MembershipDeleter(context.Background(), store, organization.ID, 1)
+		assert.Equal(t, services.FieldErrors{{"membership", services.ErrNotFound}}, err)
+	})
+}
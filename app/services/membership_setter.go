@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+// MembershipSetter adds an account to an organization with a space-separated list of roles, or
+// replaces the roles of an existing member.
+func MembershipSetter(
+	ctx context.Context, organizationStore data.OrganizationStore, accountStore data.AccountStore,
+	organizationID int, accountID int, roles string,
+) error {
+	organization, err := organizationStore.Find(ctx, organizationID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if organization == nil {
+		return FieldErrors{{"organization", ErrNotFound}}
+	}
+
+	account, err := accountStore.Find(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+
+	err = organizationStore.SetMembership(ctx, organizationID, accountID, models.ParseNameList(roles))
+	if err != nil {
+		return errors.Wrap(err, "SetMembership")
+	}
+
+	return nil
+}
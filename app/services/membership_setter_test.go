@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMembershipSetter(t *testing.T) {
+	organizationStore := mock.NewOrganizationStore()
+	accountStore := mock.NewAccountStore()
+	organization, err := organizationStore.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	account, err := accountStore.Create(context.Background(), "user@example.com", []byte("password"))
+	require.NoError(t, err)
+
+	t.Run("adding a member", func(t *testing.T) {
+		err := services.MembershipSetter(context.Background(), organizationStore, accountStore, organization.ID, account.ID, "admin billing")
+		require.NoError(t, err)
+
+		membership, err := organizationStore.FindMembership(context.Background(), organization.ID, account.ID)
+		require.NoError(t, err)
+		require.NotNil(t, membership)
+		assert.Equal(t, models.NameList{"admin", "billing"}, membership.Roles)
+	})
+
+	t.Run("replacing roles", func(t *testing.T) {
+		err := services.MembershipSetter(context.Background(), organizationStore, accountStore, organization.ID, account.ID, "viewer")
+		require.NoError(t, err)
+
+		membership, err := organizationStore.FindMembership(context.Background(), organization.ID, account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.NameList{"viewer"}, membership.Roles)
+	})
+
+	t.Run("unknown organization", func(t *testing.T) {
+		err := services.MembershipSetter(context.Background(), organizationStore, accountStore, 9999, account.ID, "")
+		assert.Equal(t, services.FieldErrors{{"organization", services.ErrNotFound}}, err)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		err := services.MembershipSetter(context.Background(), organizationStore, accountStore, organization.ID, 9999, "")
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, err)
+	})
+
+	t.Run("archived account", func(t *testing.T) {
+		archived, err := accountStore.Create(context.Background(), "archived@example.com", []byte("password"))
+		require.NoError(t, err)
+		_, err = accountStore.Archive(context.Background(), archived.ID)
+		require.NoError(t, err)
+
+		err = services.MembershipSetter(context.Background(), organizationStore, accountStore, organization.ID, archived.ID, "")
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, err)
+	})
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tokens/mfa"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/pkg/errors"
+)
+
+// MFAChallengeCreator returns a signed challenge that allows a login on the audience to be completed
+// with a second factor. The amr lists the factors that have already been verified.
+func MFAChallengeCreator(cfg *app.Config, accountID int, audience *route.Domain, amr []string) (string, error) {
+	challenge, err := mfa.New(cfg, accountID, audience.String(), amr)
+	if err != nil {
+		return "", errors.Wrap(err, "New")
+	}
+	return challenge.Sign(cfg.MFAChallengeSigningKey)
+}
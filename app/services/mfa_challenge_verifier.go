@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/mfa"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/pkg/errors"
+)
+
+// MFAChallengeVerifier completes a login on the audience that was started with MFAChallengeCreator.
+// It returns the accountID and the full list of verified factors. Each challenge may complete one
+// login.
+func MFAChallengeVerifier(
+	ctx context.Context, store data.AccountStore, codes data.OTPCodeCache, challenges data.MFAChallengeCache, cfg *app.Config,
+	audience *route.Domain, token string, otpCode string,
+) (int, []string, error) {
+	claims, err := mfa.Parse(token, cfg, audience.String())
+	if err != nil {
+		return 0, nil, FieldErrors{{"mfa_token", ErrInvalidOrExpired}}
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "Atoi")
+	}
+
+	account, err := store.Find(ctx, id)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "Find")
+	}
+	if account == nil {
+		return 0, nil, FieldErrors{{"account", ErrNotFound}}
+	} else if account.Locked {
+		return 0, nil, FieldErrors{{"account", ErrLocked}}
+	} else if account.Archived() {
+		return 0, nil, FieldErrors{{"account", ErrLocked}}
+	}
+
+	if !account.MFAEnabled() {
+		return 0, nil, FieldErrors{{"otp", ErrNotFound}}
+	}
+	err = OTPVerifier(ctx, store, codes, cfg, account, otpCode)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// the challenge is claimed after the code is verified, so that a mistyped code may be retried
+	fresh, err := challenges.UseMFAChallenge(ctx, account.ID, claims.ID)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "UseMFAChallenge")
+	}
+	if !fresh {
+		return 0, nil, FieldErrors{{"mfa_token", ErrInvalidOrExpired}}
+	}
+
+	amr := append(append([]string{}, claims.AuthMethodReference...), OTPMethod(account))
+	return account.ID, amr, nil
+}
@@ -0,0 +1,146 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/pquerna/otp/totp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAChallengeVerifier(t *testing.T) {
+	// nolint: gosec
+	totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
+	accountStore := mock.NewAccountStore()
+	otpCodes := mock.NewOTPCodeCache()
+	challenges := mock.NewMFAChallengeCache()
+	domain := &route.Domain{Hostname: "example.com"}
+	cfg := &app.Config{
+		AuthNURL:               &url.URL{Scheme: "http", Host: "authn.example.com"},
+		MFAChallengeSigningKey: []byte("challenge-a-reno"),
+		MFAChallengeTTL:        time.Minute,
+		DBEncryptionKey:        []byte("DLz2TNDRdWWA5w8YNeCJ7uzcS4WDzQmB"),
+	}
+
+	newAccount := func(username string, withTOTP bool) int {
+		account, err := accountStore.Create(context.Background(), username, []byte("password"))
+		require.NoError(t, err)
+		if withTOTP {
+			secret, err := cfg.DBEncryptionKeyring().Encrypt([]byte(totpSecret))
+			require.NoError(t, err)
+			_, err = accountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", secret, 0)
+			require.NoError(t, err)
+		}
+		return account.ID
+	}
+
+	newChallenge := func(accountID int) string {
+		challenge, err := services.MFAChallengeCreator(cfg, accountID, domain, []string{"pwd"})
+		require.NoError(t, err)
+		return challenge
+	}
+
+	t.Run("with a valid challenge and code", func(t *testing.T) {
+		accountID := newAccount("valid@keratin.tech", true)
+		code, err := totp.GenerateCode(totpSecret, time.Now())
+		require.NoError(t, err)
+
+		id, amr, err := services.MFAChallengeVerifier(context.Background(), accountStore, otpCodes, challenges, cfg, domain, newChallenge(accountID), code)
+		require.NoError(t, err)
+		assert.Equal(t, accountID, id)
+		assert.Equal(t, []string{"pwd", "otp"}, amr)
+	})
+
+	t.Run("with a code sent by email", func(t *testing.T) {
+		accountID := newAccount("email@keratin.tech", false)
+		_, err := accountStore.SetOTPChannel(context.Background(), accountID, services.OTPChannelEmail)
+		require.NoError(t, err)
+		account, err := accountStore.Find(context.Background(), accountID)
+		require.NoError(t, err)
+
+		deliveryCfg, delivered := otpDeliveryConfig(t)
+		cfg.AppOTPDeliveryURL = deliveryCfg.AppOTPDeliveryURL
+		cfg.OTPCodeSigningKey = deliveryCfg.OTPCodeSigningKey
+		cfg.OTPCodeTTL = deliveryCfg.OTPCodeTTL
+		err = services.OTPCodeSender(context.Background(), otpCodes, cfg, account, services.OTPChannelEmail, logrus.New())
+		require.NoError(t, err)
+
+		id, amr, err := services.MFAChallengeVerifier(context.Background(), accountStore, otpCodes, challenges, cfg, domain, newChallenge(accountID), delivered().Get("code"))
+		require.NoError(t, err)
+		assert.Equal(t, accountID, id)
+		assert.Equal(t, []string{"pwd", "mfa"}, amr)
+	})
+
+	t.Run("with an invalid code", func(t *testing.T) {
+		accountID := newAccount("invalid-code@keratin.tech", true)
+
+		_, _, err := services.MFAChallengeVerifier(context.Background(), accountStore, otpCodes, challenges, cfg, domain, newChallenge(accountID), "123456")
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrInvalidOrExpired}}, err)
+
+		_, _, err = services.MFAChallengeVerifier(context.Background(), accountStore, otpCodes, challenges, cfg, domain, newChallenge(accountID), "")
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrMissing}}, err)
+	})
+
+	t.Run("with an invalid challenge", func(t *testing.T) {
+		_, _, err := services.MFAChallengeVerifier(context.Background(), accountStore, otpCodes, challenges, cfg, domain, "invalid", "123456")
+		assert.Equal(t, services.FieldErrors{{"mfa_token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("with a used challenge", func(t *testing.T) {
+		accountID := newAccount("used@keratin.tech", false)
+		_, err := accountStore.SetOTPChannel(context.Background(), accountID, services.OTPChannelEmail)
+		require.NoError(t, err)
+		account, err := accountStore.Find(context.Background(), accountID)
+		require.NoError(t, err)
+		challenge := newChallenge(accountID)
+
+		deliveryCfg, delivered := otpDeliveryConfig(t)
+		cfg.AppOTPDeliveryURL = deliveryCfg.AppOTPDeliveryURL
+		cfg.OTPCodeSigningKey = deliveryCfg.OTPCodeSigningKey
+		cfg.OTPCodeTTL = deliveryCfg.OTPCodeTTL
+		err = services.OTPCodeSender(context.Background(), otpCodes, cfg, account, services.OTPChannelEmail, logrus.New())
+		require.NoError(t, err)
+		_, _, err = services.MFAChallengeVerifier(context.Background(), accountStore, otpCodes, challenges, cfg, domain, challenge, delivered().Get("code"))
+		require.NoError(t, err)
+
+		// a fresh code does not make the challenge good for another login
+		err = services.OTPCodeSender(context.Background(), otpCodes, cfg, account, services.OTPChannelEmail, logrus.New())
+		require.NoError(t, err)
+		_, _, err = services.MFAChallengeVerifier(context.Background(), accountStore, otpCodes, challenges, cfg, domain, challenge, delivered().Get("code"))
+		assert.Equal(t, services.FieldErrors{{"mfa_token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("on a different domain", func(t *testing.T) {
+		accountID := newAccount("domain@keratin.tech", true)
+		code, err := totp.GenerateCode(totpSecret, time.Now())
+		require.NoError(t, err)
+
+		other := &route.Domain{Hostname: "other.com"}
+		_, _, err = services.MFAChallengeVerifier(context.Background(), accountStore, otpCodes, challenges, cfg, other, newChallenge(accountID), code)
+		assert.Equal(t, services.FieldErrors{{"mfa_token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("without mfa", func(t *testing.T) {
+		accountID := newAccount("nomfa@keratin.tech", false)
+
+		_, _, err := services.MFAChallengeVerifier(context.Background(), accountStore, otpCodes, challenges, cfg, domain, newChallenge(accountID), "123456")
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrNotFound}}, err)
+	})
+
+	t.Run("with a locked account", func(t *testing.T) {
+		accountID := newAccount("locked@keratin.tech", true)
+		_, err := accountStore.Lock(context.Background(), accountID)
+		require.NoError(t, err)
+
+		_, _, err = services.MFAChallengeVerifier(context.Background(), accountStore, otpCodes, challenges, cfg, domain, newChallenge(accountID), "123456")
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrLocked}}, err)
+	})
+}
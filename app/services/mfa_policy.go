@@ -0,0 +1,17 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+)
+
+// MFARequired is true when the policy of the account's tenant requires the account to use MFA. The
+// policy may apply to every account, to accounts with an email username in specific domains, or to
+// flagged accounts.
+func MFARequired(cfg *app.Config, account *models.Account) bool {
+	if cfg.MFARequiredFor(account.Tenant) || account.RequireMFA {
+		return true
+	}
+	domains := cfg.MFARequiredDomainsFor(account.Tenant)
+	return len(domains) > 0 && isEmail(account.Username) && hasDomain(account.Username, domains)
+}
@@ -0,0 +1,50 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMFARequired(t *testing.T) {
+	testCases := []struct {
+		cfg      app.Config
+		account  models.Account
+		required bool
+	}{
+		{app.Config{}, models.Account{Username: "user@example.com"}, false},
+		{app.Config{MFARequired: true}, models.Account{Username: "user@example.com"}, true},
+		{app.Config{}, models.Account{Username: "user@example.com", RequireMFA: true}, true},
+		{app.Config{MFARequiredDomains: []string{"admin.example.com"}}, models.Account{Username: "user@example.com"}, false},
+		{app.Config{MFARequiredDomains: []string{"admin.example.com"}}, models.Account{Username: "user@admin.example.com"}, true},
+		{app.Config{MFARequiredDomains: []string{"admin.example.com"}}, models.Account{Username: "username"}, false},
+	}
+
+	required, notRequired := true, false
+	tenantCfg := app.Config{
+		MFARequired: true,
+		Tenants: map[string]*app.TenantConfig{
+			"acme":    {MFARequired: &notRequired},
+			"globex":  {MFARequired: &notRequired, MFARequiredDomains: []string{"admin.example.com"}},
+			"initech": {MFARequired: &required},
+		},
+	}
+	testCases = append(testCases, []struct {
+		cfg      app.Config
+		account  models.Account
+		required bool
+	}{
+		{tenantCfg, models.Account{Username: "user@example.com"}, true},
+		{tenantCfg, models.Account{Username: "user@example.com", Tenant: "acme"}, false},
+		{tenantCfg, models.Account{Username: "user@example.com", Tenant: "globex"}, false},
+		{tenantCfg, models.Account{Username: "user@admin.example.com", Tenant: "globex"}, true},
+		{tenantCfg, models.Account{Username: "user@example.com", Tenant: "initech"}, true},
+	}...)
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.required, services.MFARequired(&tc.cfg, &tc.account), tc.account.Username)
+	}
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+func OrganizationCreator(ctx context.Context, store data.OrganizationStore, name string) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, FieldErrors{{"name", ErrMissing}}
+	}
+
+	organization, err := store.Create(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Create")
+	}
+
+	return organization, nil
+}
@@ -0,0 +1,27 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationCreator(t *testing.T) {
+	store := mock.NewOrganizationStore()
+
+	t.Run("creating", func(t *testing.T) {
+		organization, err := services.OrganizationCreator(context.Background(), store, " Acme ")
+		require.NoError(t, err)
+		assert.NotEmpty(t, organization.ID)
+		assert.Equal(t, "Acme", organization.Name)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		_, err := services.OrganizationCreator(context.Background(), store, " ")
+		assert.Equal(t, services.FieldErrors{{"name", services.ErrMissing}}, err)
+	})
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// OrganizationDeleter deletes an organization and all of its memberships. Sessions where it is the
+// active organization will no longer include it in identity tokens.
+func OrganizationDeleter(ctx context.Context, store data.OrganizationStore, id int) error {
+	ok, err := store.Delete(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "Delete")
+	}
+	if !ok {
+		return FieldErrors{{"organization", ErrNotFound}}
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationDeleter(t *testing.T) {
+	store := mock.NewOrganizationStore()
+	organization, err := store.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	err = store.SetMembership(context.Background(), organization.ID, 1, models.NameList{"admin"})
+	require.NoError(t, err)
+
+	t.Run("deleting", func(t *testing.T) {
+		err := services.OrganizationDeleter(context.Background(), store, organization.ID)
+		require.NoError(t, err)
+
+		found, err := store.Find(context.Background(), organization.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		membership, err := store.FindMembership(context.Background(), organization.ID, 1)
+		require.NoError(t, err)
+		assert.Nil(t, membership)
+	})
+
+	t.Run("unknown organization", func(t *testing.T) {
+		err := services.OrganizationDeleter(context.Background(), store, 9999)
+		assert.Equal(t, services.FieldErrors{{"organization", services.ErrNotFound}}, err)
+	})
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// OrganizationSelector verifies that an account may make an organization active in its session. A
+// zero organizationID clears the selection, and is always allowed.
+func OrganizationSelector(ctx context.Context, store data.OrganizationStore, accountID int, organizationID int) error {
+	if organizationID == 0 {
+		return nil
+	}
+
+	membership, err := store.FindMembership(ctx, organizationID, accountID)
+	if err != nil {
+		return errors.Wrap(err, "FindMembership")
+	}
+	if membership == nil {
+		return FieldErrors{{"organization_id", ErrNotFound}}
+	}
+
+	return nil
+}
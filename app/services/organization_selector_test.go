@@ -0,0 +1,35 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationSelector(t *testing.T) {
+	store := mock.NewOrganizationStore()
+	organization, err := store.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	err = store.SetMembership(context.Background(), organization.ID, 1, models.NameList{"admin"})
+	require.NoError(t, err)
+
+	t.Run("member", func(t *testing.T) {
+		err := services.OrganizationSelector(context.Background(), store, 1, organization.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("clearing the selection", func(t *testing.T) {
+		err := services.OrganizationSelector(context.Background(), store, 2, 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("non-member", func(t *testing.T) {
+		err := services.OrganizationSelector(context.Background(), store, 2, organization.ID)
+		assert.Equal(t, services.FieldErrors{{"organization_id", services.ErrNotFound}}, err)
+	})
+}
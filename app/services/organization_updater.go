@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+func OrganizationUpdater(ctx context.Context, store data.OrganizationStore, id int, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return FieldErrors{{"name", ErrMissing}}
+	}
+
+	ok, err := store.Update(ctx, id, name)
+	if err != nil {
+		return errors.Wrap(err, "Update")
+	}
+	if !ok {
+		return FieldErrors{{"organization", ErrNotFound}}
+	}
+
+	return nil
+}
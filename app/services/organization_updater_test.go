@@ -0,0 +1,36 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationUpdater(t *testing.T) {
+	store := mock.NewOrganizationStore()
+	organization, err := store.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+
+	t.Run("updating", func(t *testing.T) {
+		err := services.OrganizationUpdater(context.Background(), store, organization.ID, "Globex")
+		require.NoError(t, err)
+
+		found, err := store.Find(context.Background(), organization.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Globex", found.Name)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		err := services.OrganizationUpdater(context.Background(), store, organization.ID, "")
+		assert.Equal(t, services.FieldErrors{{"name", services.ErrMissing}}, err)
+	})
+
+	t.Run("unknown organization", func(t *testing.T) {
+		err := services.OrganizationUpdater(context.Background(), store, 9999, "Globex")
+		assert.Equal(t, services.FieldErrors{{"organization", services.ErrNotFound}}, err)
+	})
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/sirupsen/logrus"
+)
+
+// OTPChannelCreator begins setting up a channel as the account's second factor by sending a code
+// through it. The channel is not established until the code is confirmed with OTPChannelSetter.
+func OTPChannelCreator(ctx context.Context, accountStore data.AccountStore, codes data.OTPCodeCache, cfg *app.Config, accountID int, channel string, logger logrus.FieldLogger) error {
+	if channel != OTPChannelEmail && channel != OTPChannelSMS {
+		return FieldErrors{{"channel", ErrFormatInvalid}}
+	}
+
+	account, err := AccountGetter(ctx, accountStore, accountID)
+	if err != nil {
+		return err
+	}
+	if account.MFAEnabled() {
+		return FieldErrors{{"otp", ErrTaken}}
+	}
+
+	return OTPCodeSender(ctx, codes, cfg, account, channel, logger)
+}
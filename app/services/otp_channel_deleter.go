@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// OTPChannelDeleter removes the OTP channel from the specified account, unless the MFA policy
+// applies to it. Any trusted devices are revoked along with the factor.
+func OTPChannelDeleter(ctx context.Context, accountStore data.AccountStore, trustedDeviceStore data.TrustedDeviceStore, cfg *app.Config, accountID int) error {
+	account, err := AccountGetter(ctx, accountStore, accountID)
+	if err != nil {
+		return err
+	}
+	if MFARequired(cfg, account) {
+		return FieldErrors{{"otp", ErrRequired}}
+	}
+
+	affected, err := accountStore.SetOTPChannel(ctx, accountID, "")
+	if err != nil {
+		return errors.Wrap(err, "SetOTPChannel")
+	}
+	if !affected {
+		return errors.New("unable to delete otp channel")
+	}
+
+	err = trustedDeviceStore.RevokeAll(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "RevokeAll")
+	}
+
+	return nil
+}
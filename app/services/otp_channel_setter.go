@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+// OTPChannelSetter establishes the channel that delivered the code as the account's second factor,
+// if the code is correct. It returns the updated account, and revokes any devices that were trusted
+// before the factor was established.
+func OTPChannelSetter(ctx context.Context, accountStore data.AccountStore, trustedDeviceStore data.TrustedDeviceStore, codes data.OTPCodeCache, cfg *app.Config, accountID int, code string) (*models.Account, error) {
+	account, err := AccountGetter(ctx, accountStore, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.MFAEnabled() {
+		return nil, FieldErrors{{"otp", ErrTaken}}
+	}
+
+	channel, err := OTPCodeVerifier(ctx, codes, cfg, account.ID, code)
+	if err != nil {
+		if IsMissingOTP(err) {
+			return nil, FieldErrors{{"otp", ErrInvalidOrExpired}}
+		}
+		return nil, err
+	}
+
+	affected, err := accountStore.SetOTPChannel(ctx, account.ID, channel)
+	if err != nil {
+		return nil, errors.Wrap(err, "SetOTPChannel")
+	}
+	if !affected {
+		return nil, errors.New("unable to set otp channel")
+	}
+
+	err = trustedDeviceStore.RevokeAll(ctx, account.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "RevokeAll")
+	}
+
+	account.OTPChannel = sql.NullString{String: channel, Valid: true}
+	return account, nil
+}
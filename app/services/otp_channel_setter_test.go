@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTPChannelSetter(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	codes := mock.NewOTPCodeCache()
+	trustedDeviceStore := mock.NewTrustedDeviceStore(time.Hour)
+	cfg, delivered := otpDeliveryConfig(t)
+
+	t.Run("with the delivered code", func(t *testing.T) {
+		account, err := accountStore.Create(context.Background(), "sms@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		err = services.OTPChannelCreator(context.Background(), accountStore, codes, cfg, account.ID, "sms", logrus.New())
+		require.NoError(t, err)
+		_, err = trustedDeviceStore.Create(context.Background(), account.ID, "laptop")
+		require.NoError(t, err)
+
+		updated, err := services.OTPChannelSetter(context.Background(), accountStore, trustedDeviceStore, codes, cfg, account.ID, delivered().Get("code"))
+		require.NoError(t, err)
+		assert.Equal(t, "sms", updated.OTPChannel.String)
+
+		account, err = accountStore.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.True(t, account.OTPChannelEnabled())
+		assert.Equal(t, "sms", services.OTPMethod(account))
+
+		devices, err := trustedDeviceStore.FindAll(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+
+		err = services.OTPChannelCreator(context.Background(), accountStore, codes, cfg, account.ID, "email", logrus.New())
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrTaken}}, err)
+	})
+
+	t.Run("with a wrong code", func(t *testing.T) {
+		account, err := accountStore.Create(context.Background(), "wrong@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		err = services.OTPChannelCreator(context.Background(), accountStore, codes, cfg, account.ID, "email", logrus.New())
+		require.NoError(t, err)
+
+		_, err = services.OTPChannelSetter(context.Background(), accountStore, trustedDeviceStore, codes, cfg, account.ID, "")
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrInvalidOrExpired}}, err)
+
+		account, err = accountStore.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.False(t, account.OTPChannelEnabled())
+	})
+
+	t.Run("with an unknown channel", func(t *testing.T) {
+		account, err := accountStore.Create(context.Background(), "fax@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+
+		err = services.OTPChannelCreator(context.Background(), accountStore, codes, cfg, account.ID, "fax", logrus.New())
+		assert.Equal(t, services.FieldErrors{{"channel", services.ErrFormatInvalid}}, err)
+	})
+}
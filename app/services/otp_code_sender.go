@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// OTP channels that may deliver a one-time code
+const (
+	OTPChannelEmail = "email"
+	OTPChannelSMS   = "sms"
+)
+
+// OTPCodeSender generates a one-time code for the account and delivers it through the channel. It
+// replaces any code that was previously sent to the account, but only once that code has expired,
+// so that sending again can not be used to reset the limit on attempts.
+func OTPCodeSender(ctx context.Context, codes data.OTPCodeCache, cfg *app.Config, account *models.Account, channel string, logger logrus.FieldLogger) error {
+	if account == nil || account.Locked {
+		return nil
+	}
+
+	existing, err := codes.LoadOTPCode(ctx, account.ID)
+	if err != nil {
+		return errors.Wrap(err, "LoadOTPCode")
+	}
+	if existing != nil && existing.ExpiresAt.After(time.Now()) {
+		logger.WithFields(logrus.Fields{"accountID": account.ID, "channel": channel}).Info("otp code already sent")
+		return nil
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return errors.Wrap(err, "Int")
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+	id, err := lib.GenerateToken()
+	if err != nil {
+		return errors.Wrap(err, "GenerateToken")
+	}
+
+	err = codes.CacheOTPCode(ctx, account.ID, &models.OTPCode{
+		ID:        hex.EncodeToString(id),
+		Channel:   channel,
+		Hash:      otpCodeHash(cfg, account.ID, code),
+		ExpiresAt: time.Now().Add(cfg.OTPCodeTTL),
+	})
+	if err != nil {
+		return errors.Wrap(err, "CacheOTPCode")
+	}
+
+	err = WebhookSender(cfg.AppOTPDeliveryURL, &url.Values{
+		"account_id": []string{strconv.Itoa(account.ID)},
+		"channel":    []string{channel},
+		"code":       []string{code},
+	}, timeSensitiveDelivery, cfg.AppSigningKey)
+	if err != nil {
+		return errors.Wrap(err, "Webhook")
+	}
+
+	logger.WithFields(logrus.Fields{"accountID": account.ID, "channel": channel}).Info("sent otp code")
+
+	return nil
+}
+
+// otpCodeHash binds a code to the account, so that a code can not be moved between accounts.
+func otpCodeHash(cfg *app.Config, accountID int, code string) []byte {
+	mac := hmac.New(sha256.New, cfg.OTPCodeSigningKey)
+	mac.Write([]byte(strconv.Itoa(accountID) + ":" + code))
+	return mac.Sum(nil)
+}
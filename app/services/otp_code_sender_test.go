@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// otpDeliveryConfig builds a config that delivers codes to a fake remote app. The returned
+// function returns the last delivered code.
+func otpDeliveryConfig(t *testing.T) (*app.Config, func() url.Values) {
+	var delivered url.Values
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		delivered = r.PostForm
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(remoteApp.Close)
+	serverURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+
+	cfg := &app.Config{
+		AppOTPDeliveryURL: serverURL,
+		OTPCodeSigningKey: []byte("otp-code"),
+		OTPCodeTTL:        time.Minute,
+	}
+	return cfg, func() url.Values { return delivered }
+}
+
+func TestOTPCodeSender(t *testing.T) {
+	codes := mock.NewOTPCodeCache()
+	cfg, delivered := otpDeliveryConfig(t)
+
+	t.Run("delivering a code", func(t *testing.T) {
+		err := services.OTPCodeSender(context.Background(), codes, cfg, &models.Account{ID: 1234}, services.OTPChannelSMS, logrus.New())
+		require.NoError(t, err)
+
+		assert.Equal(t, "1234", delivered().Get("account_id"))
+		assert.Equal(t, "sms", delivered().Get("channel"))
+		assert.Regexp(t, "^[0-9]{6}$", delivered().Get("code"))
+
+		otpCode, err := codes.LoadOTPCode(context.Background(), 1234)
+		require.NoError(t, err)
+		require.NotNil(t, otpCode)
+		assert.Equal(t, "sms", otpCode.Channel)
+		assert.NotContains(t, string(otpCode.Hash), delivered().Get("code"))
+	})
+
+	t.Run("before the previous code expires", func(t *testing.T) {
+		err := services.OTPCodeSender(context.Background(), codes, cfg, &models.Account{ID: 3456}, services.OTPChannelSMS, logrus.New())
+		require.NoError(t, err)
+		sent := delivered().Get("code")
+		previous, err := codes.LoadOTPCode(context.Background(), 3456)
+		require.NoError(t, err)
+
+		err = services.OTPCodeSender(context.Background(), codes, cfg, &models.Account{ID: 3456}, services.OTPChannelSMS, logrus.New())
+		require.NoError(t, err)
+		assert.Equal(t, sent, delivered().Get("code"))
+
+		otpCode, err := codes.LoadOTPCode(context.Background(), 3456)
+		require.NoError(t, err)
+		assert.Equal(t, previous.ID, otpCode.ID)
+	})
+
+	t.Run("with locked account", func(t *testing.T) {
+		err := services.OTPCodeSender(context.Background(), codes, cfg, &models.Account{ID: 2345, Locked: true}, services.OTPChannelSMS, logrus.New())
+		assert.NoError(t, err)
+
+		otpCode, err := codes.LoadOTPCode(context.Background(), 2345)
+		require.NoError(t, err)
+		assert.Nil(t, otpCode)
+	})
+
+	t.Run("with unknown account", func(t *testing.T) {
+		err := services.OTPCodeSender(context.Background(), codes, cfg, nil, services.OTPChannelSMS, logrus.New())
+		assert.NoError(t, err)
+	})
+}
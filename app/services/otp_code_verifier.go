@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// otpCodeMaxAttempts limits how many guesses may be made against a single code.
+const otpCodeMaxAttempts = 5
+
+// OTPCodeVerifier checks a code that was sent by OTPCodeSender. A code may be used only once, and
+// is refused after too many attempts. It returns the channel that delivered the code.
+func OTPCodeVerifier(ctx context.Context, codes data.OTPCodeCache, cfg *app.Config, accountID int, code string) (string, error) {
+	if code == "" {
+		return "", FieldErrors{{"otp", ErrMissing}}
+	}
+
+	otpCode, err := codes.LoadOTPCode(ctx, accountID)
+	if err != nil {
+		return "", errors.Wrap(err, "LoadOTPCode")
+	}
+	if otpCode == nil || otpCode.ExpiresAt.Before(time.Now()) {
+		return "", FieldErrors{{"otp", ErrInvalidOrExpired}}
+	}
+
+	ok, err := codes.RecordOTPAttempt(ctx, accountID, otpCode, otpCodeMaxAttempts)
+	if err != nil {
+		return "", errors.Wrap(err, "RecordOTPAttempt")
+	}
+	if !ok || !hmac.Equal(otpCode.Hash, otpCodeHash(cfg, accountID, code)) {
+		return "", FieldErrors{{"otp", ErrInvalidOrExpired}}
+	}
+
+	// only one request may use the code
+	ok, err = codes.ClaimOTPCode(ctx, accountID, otpCode)
+	if err != nil {
+		return "", errors.Wrap(err, "ClaimOTPCode")
+	}
+	if !ok {
+		return "", FieldErrors{{"otp", ErrInvalidOrExpired}}
+	}
+
+	return otpCode.Channel, nil
+}
@@ -0,0 +1,72 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTPCodeVerifier(t *testing.T) {
+	codes := mock.NewOTPCodeCache()
+	cfg, delivered := otpDeliveryConfig(t)
+
+	send := func(accountID int) string {
+		err := services.OTPCodeSender(context.Background(), codes, cfg, &models.Account{ID: accountID}, services.OTPChannelEmail, logrus.New())
+		require.NoError(t, err)
+		return delivered().Get("code")
+	}
+
+	t.Run("with the delivered code", func(t *testing.T) {
+		code := send(1)
+
+		channel, err := services.OTPCodeVerifier(context.Background(), codes, cfg, 1, code)
+		require.NoError(t, err)
+		assert.Equal(t, "email", channel)
+
+		_, err = services.OTPCodeVerifier(context.Background(), codes, cfg, 1, code)
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("with another account's code", func(t *testing.T) {
+		code := send(2)
+		send(3)
+
+		_, err := services.OTPCodeVerifier(context.Background(), codes, cfg, 3, code)
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("with a missing code", func(t *testing.T) {
+		send(4)
+
+		_, err := services.OTPCodeVerifier(context.Background(), codes, cfg, 4, "")
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrMissing}}, err)
+	})
+
+	t.Run("after too many attempts", func(t *testing.T) {
+		code := send(5)
+		for i := 0; i < 5; i++ {
+			_, err := services.OTPCodeVerifier(context.Background(), codes, cfg, 5, "abcdef")
+			assert.Equal(t, services.FieldErrors{{"otp", services.ErrInvalidOrExpired}}, err)
+		}
+
+		_, err := services.OTPCodeVerifier(context.Background(), codes, cfg, 5, code)
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("after expiring", func(t *testing.T) {
+		code := send(6)
+		otpCode, err := codes.LoadOTPCode(context.Background(), 6)
+		require.NoError(t, err)
+		otpCode.ExpiresAt = otpCode.ExpiresAt.Add(-cfg.OTPCodeTTL)
+		require.NoError(t, codes.CacheOTPCode(context.Background(), 6, otpCode))
+
+		_, err = services.OTPCodeVerifier(context.Background(), codes, cfg, 6, code)
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrInvalidOrExpired}}, err)
+	})
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+// OTPVerifier checks the code against the account's second factor, if one has been set up. The
+// second factor may be a TOTP authenticator or a code that was delivered through a channel.
+func OTPVerifier(ctx context.Context, store data.AccountStore, codes data.OTPCodeCache, cfg *app.Config, account *models.Account, otpCode string) error {
+	if account.OTPChannelEnabled() {
+		channel, err := OTPCodeVerifier(ctx, codes, cfg, account.ID, otpCode)
+		if err != nil {
+			return err
+		}
+		if channel != account.OTPChannel.String {
+			return FieldErrors{{"otp", ErrInvalidOrExpired}}
+		}
+		return nil
+	}
+
+	if !account.TOTPEnabled() {
+		return nil
+	}
+	if otpCode == "" {
+		return FieldErrors{{"otp", ErrMissing}}
+	}
+
+	valid, err := totpVerifier(ctx, store, cfg, account.ID, otpCode)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return FieldErrors{{"otp", ErrInvalidOrExpired}}
+	}
+
+	return nil
+}
+
+// totpVerifier checks the code against each of the account's TOTP authenticators, and records the
+// time step of the matching authenticator. A code is rejected if the authenticator has already been
+// used for the same or a later time step, so that an observed code may not be replayed.
+func totpVerifier(ctx context.Context, store data.AccountStore, cfg *app.Config, accountID int, otpCode string) (bool, error) {
+	authenticators, err := store.GetTOTPAuthenticators(ctx, accountID)
+	if err != nil {
+		return false, errors.Wrap(err, "GetTOTPAuthenticators")
+	}
+
+	for _, authenticator := range authenticators {
+		secret, err := cfg.DBEncryptionKeyring().Decrypt([]byte(authenticator.Secret))
+		if err != nil {
+			return false, errors.Wrap(err, "TOTPDecrypt")
+		}
+		step, valid := totpStep(cfg, otpCode, secret, time.Now())
+		if valid {
+			fresh, err := store.SetTOTPAuthenticatorLastUsed(ctx, accountID, authenticator.ID, step)
+			if err != nil {
+				return false, errors.Wrap(err, "SetTOTPAuthenticatorLastUsed")
+			}
+			return fresh, nil
+		}
+	}
+
+	return false, nil
+}
+
+// OTPMethod is the authentication method reference for the account's second factor.
+func OTPMethod(account *models.Account) string {
+	switch account.OTPChannel.String {
+	case OTPChannelEmail:
+		return "mfa"
+	case OTPChannelSMS:
+		return "sms"
+	default:
+		return "otp"
+	}
+}
+
+// IsMissingOTP is true when a login was rejected only because the second factor was not provided.
+func IsMissingOTP(err error) bool {
+	fe, ok := err.(FieldErrors)
+	return ok && len(fe) == 1 && fe[0] == FieldError{"otp", ErrMissing}
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/ops"
@@ -8,8 +10,8 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func PasswordChanger(store data.AccountStore, r ops.ErrorReporter, cfg *app.Config, id int, currentPassword string, password string) error {
-	account, err := store.Find(id)
+func PasswordChanger(ctx context.Context, store data.AccountStore, r ops.ErrorReporter, cfg *app.Config, id int, currentPassword string, password string) error {
+	account, err := store.Find(ctx, id)
 	if err != nil {
 		return errors.Wrap(err, "Find")
 	}
@@ -24,5 +26,5 @@ func PasswordChanger(store data.AccountStore, r ops.ErrorReporter, cfg *app.Conf
 		return FieldErrors{{"credentials", ErrFailed}}
 	}
 
-	return PasswordSetter(store, r, cfg, id, password)
+	return PasswordSetter(ctx, store, r, cfg, id, password)
 }
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
 	"golang.org/x/crypto/bcrypt"
@@ -24,7 +25,7 @@ func TestPasswordChanger(t *testing.T) {
 	}
 
 	invoke := func(id int, currentPassword string, password string) error {
-		return services.PasswordChanger(accountStore, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, id, currentPassword, password)
+		return services.PasswordChanger(context.Background(), accountStore, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, id, currentPassword, password)
 	}
 
 	factory := func(username string, password string) (*models.Account, error) {
@@ -33,7 +34,7 @@ func TestPasswordChanger(t *testing.T) {
 			return nil, errors.Wrap(err, "bcrypt")
 		}
 
-		return accountStore.Create(username, hash)
+		return accountStore.Create(context.Background(), username, hash)
 	}
 
 	account, err := factory("existing@keratin.tech", "old")
@@ -42,13 +43,13 @@ func TestPasswordChanger(t *testing.T) {
 	t.Run("it resets RequireNoPassword", func(t *testing.T) {
 		expired, err := factory("expired@keratin.tech", "old")
 		require.NoError(t, err)
-		_, err = accountStore.RequireNewPassword(expired.ID)
+		_, err = accountStore.RequireNewPassword(context.Background(), expired.ID)
 		require.NoError(t, err)
 
 		err = invoke(expired.ID, "old", "0a0b0c0d0e0f")
 		assert.NoError(t, err)
 
-		account, err := accountStore.Find(expired.ID)
+		account, err := accountStore.Find(context.Background(), expired.ID)
 		require.NoError(t, err)
 		assert.False(t, account.RequireNewPassword)
 		assert.NotEqual(t, expired.Password, account.Password)
@@ -62,7 +63,7 @@ func TestPasswordChanger(t *testing.T) {
 	t.Run("with a locked account", func(t *testing.T) {
 		lockedAccount, err := factory("locked@keratin.tech", "old")
 		require.NoError(t, err)
-		_, err = accountStore.Lock(lockedAccount.ID)
+		_, err = accountStore.Lock(context.Background(), lockedAccount.ID)
 		require.NoError(t, err)
 
 		err = invoke(lockedAccount.ID, "old", "0ab0c0d0e0f")
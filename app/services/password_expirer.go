@@ -1,12 +1,24 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
 )
 
-func PasswordExpirer(store data.AccountStore, tokenStore data.RefreshTokenStore, accountID int) error {
-	affected, err := store.RequireNewPassword(accountID)
+func PasswordExpirer(ctx context.Context, store data.AccountStore, tokenStore data.RefreshTokenStore, trustedDeviceStore data.TrustedDeviceStore, keyStore data.KeyStore, cfg *app.Config, r ops.ErrorReporter, accountID int) error {
+	account, err := store.Find(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+
+	affected, err := store.RequireNewPassword(ctx, accountID)
 	if err != nil {
 		return errors.Wrap(err, "RequireNewPassword")
 	}
@@ -14,5 +26,11 @@ func PasswordExpirer(store data.AccountStore, tokenStore data.RefreshTokenStore,
 		return FieldErrors{{"account", ErrNotFound}}
 	}
 
-	return SessionBatchEnder(tokenStore, accountID)
+	// a device trusted with the old password must not skip MFA for the new one
+	err = trustedDeviceStore.RevokeAll(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "RevokeAll")
+	}
+
+	return SessionBatchEnder(ctx, tokenStore, keyStore, cfg, r, account.Tenant, accountID)
 }
@@ -1,44 +1,57 @@
 package services_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestPasswordExpirer(t *testing.T) {
+	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
 	accountStore := mock.NewAccountStore()
 	refreshStore := mock.NewRefreshTokenStore()
+	trustedDeviceStore := mock.NewTrustedDeviceStore(time.Hour)
 
 	t.Run("active account", func(t *testing.T) {
-		account, err := accountStore.Create("active", []byte("secret"))
+		account, err := accountStore.Create(context.Background(), "active", []byte("secret"))
 		require.NoError(t, err)
-		token1, err := refreshStore.Create(account.ID)
+		token1, err := refreshStore.Create(context.Background(), account.ID)
 		require.NoError(t, err)
-		token2, err := refreshStore.Create(account.ID)
+		token2, err := refreshStore.Create(context.Background(), account.ID)
+		require.NoError(t, err)
+		_, err = trustedDeviceStore.Create(context.Background(), account.ID, "laptop")
 		require.NoError(t, err)
 
-		errors := services.PasswordExpirer(accountStore, refreshStore, account.ID)
+		errors := services.PasswordExpirer(context.Background(), accountStore, refreshStore, trustedDeviceStore, nil, &app.Config{}, reporter, account.ID)
 		assert.Empty(t, errors)
 
-		account, err = accountStore.Find(account.ID)
+		account, err = accountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.NotEmpty(t, account.RequireNewPassword)
 		assert.False(t, account.TOTPSecret.Valid)
 
-		id, err := refreshStore.Find(token1)
+		id, err := refreshStore.Find(context.Background(), token1)
 		require.NoError(t, err)
 		assert.Empty(t, id)
-		id, err = refreshStore.Find(token2)
+		id, err = refreshStore.Find(context.Background(), token2)
 		require.NoError(t, err)
 		assert.Empty(t, id)
+
+		devices, err := trustedDeviceStore.FindAll(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
 	})
 
 	t.Run("unknown account", func(t *testing.T) {
-		errors := services.PasswordExpirer(accountStore, refreshStore, 0)
+		errors := services.PasswordExpirer(context.Background(), accountStore, refreshStore, trustedDeviceStore, nil, &app.Config{}, reporter, 0)
 		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, errors)
 	})
 }
@@ -1,55 +1,59 @@
 package services
 
 import (
+	"context"
 	"strconv"
 
-	"github.com/keratin/authn-server/lib/compat"
 	"github.com/keratin/authn-server/ops"
-	"github.com/pquerna/otp/totp"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/tokens/resets"
 	"github.com/pkg/errors"
 )
 
-func PasswordResetter(store data.AccountStore, r ops.ErrorReporter, cfg *app.Config, token string, password string, totpCode string) (int, error) {
+// PasswordResetter sets a new password for the account of a reset token, after checking the
+// account's second factor. It returns the account, which is also returned when only the OTP is
+// missing so that a code may be sent.
+func PasswordResetter(ctx context.Context, store data.AccountStore, codes data.OTPCodeCache, r ops.ErrorReporter, cfg *app.Config, token string, password string, otpCode string) (*models.Account, error) {
 	claims, err := resets.Parse(token, cfg)
 	if err != nil {
-		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
+		return nil, FieldErrors{{"token", ErrInvalidOrExpired}}
 	}
 
 	id, err := strconv.Atoi(claims.Subject)
 	if err != nil {
-		return 0, errors.Wrap(err, "Atoi")
+		return nil, errors.Wrap(err, "Atoi")
 	}
 
-	account, err := store.Find(id)
+	account, err := store.Find(ctx, id)
 	if err != nil {
-		return 0, errors.Wrap(err, "Find")
+		return nil, errors.Wrap(err, "Find")
 	}
 	if account == nil {
-		return 0, FieldErrors{{"account", ErrNotFound}}
+		return nil, FieldErrors{{"account", ErrNotFound}}
 	} else if account.Locked {
-		return 0, FieldErrors{{"account", ErrLocked}}
+		return nil, FieldErrors{{"account", ErrLocked}}
 	} else if account.Archived() {
-		return 0, FieldErrors{{"account", ErrLocked}}
+		return nil, FieldErrors{{"account", ErrLocked}}
 	}
 
 	if claims.LockExpired(account.PasswordChangedAt) {
-		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
+		return nil, FieldErrors{{"token", ErrInvalidOrExpired}}
 	}
 
 	//Check OTP MFA
-	if account.TOTPEnabled() {
-		secret, err := compat.Decrypt([]byte(account.TOTPSecret.String), cfg.DBEncryptionKey)
-		if err != nil {
-			return 0, errors.Wrap(err, "TOTPDecrypt")
-		}
-		if !totp.Validate(totpCode, secret) {
-			return 0, FieldErrors{{"otp", ErrInvalidOrExpired}}
-		}
+	err = OTPVerifier(ctx, store, codes, cfg, account, otpCode)
+	if IsMissingOTP(err) {
+		return account, err
+	} else if err != nil {
+		return nil, err
 	}
 
-	return account.ID, PasswordSetter(store, r, cfg, id, password)
+	err = PasswordSetter(ctx, store, r, cfg, id, password)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
 }
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"net/url"
 	"testing"
 	"time"
@@ -18,6 +19,7 @@ import (
 
 func TestPasswordResetter(t *testing.T) {
 	accountStore := mock.NewAccountStore()
+	otpCodes := mock.NewOTPCodeCache()
 	cfg := &app.Config{
 		AuthNURL:              &url.URL{Scheme: "http", Host: "authn.example.com"},
 		BcryptCost:            4,
@@ -34,23 +36,23 @@ func TestPasswordResetter(t *testing.T) {
 	}
 
 	invoke := func(token string, password string) error {
-		_, err := services.PasswordResetter(accountStore, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, token, password, "")
+		_, err := services.PasswordResetter(context.Background(), accountStore, otpCodes, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, token, password, "")
 		return err
 	}
 
-	account, err := accountStore.Create("existing@keratin.tech", []byte("old"))
+	account, err := accountStore.Create(context.Background(), "existing@keratin.tech", []byte("old"))
 	require.NoError(t, err)
 
 	t.Run("sets new password", func(t *testing.T) {
-		expired, err := accountStore.Create("expired@keratin.tech", []byte("old"))
+		expired, err := accountStore.Create(context.Background(), "expired@keratin.tech", []byte("old"))
 		require.NoError(t, err)
-		_, err = accountStore.RequireNewPassword(expired.ID)
+		_, err = accountStore.RequireNewPassword(context.Background(), expired.ID)
 		require.NoError(t, err)
 
 		err = invoke(newToken(expired.ID, expired.PasswordChangedAt), "0a0b0c0d0e0f")
 		assert.NoError(t, err)
 
-		account, err := accountStore.Find(expired.ID)
+		account, err := accountStore.Find(context.Background(), expired.ID)
 		require.NoError(t, err)
 		assert.NotEqual(t, expired.Password, account.Password)
 		assert.False(t, account.RequireNewPassword)
@@ -73,9 +75,9 @@ func TestPasswordResetter(t *testing.T) {
 	})
 
 	t.Run("on an archived account", func(t *testing.T) {
-		archived, err := accountStore.Create("archived@keratin.tech", []byte("old"))
+		archived, err := accountStore.Create(context.Background(), "archived@keratin.tech", []byte("old"))
 		require.NoError(t, err)
-		_, err = accountStore.Archive(archived.ID)
+		_, err = accountStore.Archive(context.Background(), archived.ID)
 		require.NoError(t, err)
 
 		token := newToken(archived.ID, archived.PasswordChangedAt)
@@ -85,9 +87,9 @@ func TestPasswordResetter(t *testing.T) {
 	})
 
 	t.Run("on a locked account", func(t *testing.T) {
-		locked, err := accountStore.Create("locked@keratin.tech", []byte("old"))
+		locked, err := accountStore.Create(context.Background(), "locked@keratin.tech", []byte("old"))
 		require.NoError(t, err)
-		_, err = accountStore.Lock(locked.ID)
+		_, err = accountStore.Lock(context.Background(), locked.ID)
 		require.NoError(t, err)
 
 		token := newToken(locked.ID, locked.PasswordChangedAt)
@@ -121,6 +123,7 @@ func TestPasswordResetterWithOTP(t *testing.T) {
 	totpSecretEnc := []byte("cli6azfL5i7PAnh8U/w3Zbglsm3XcdaGODy+Ga5QqT02c9hotDAR1Y28--3UihzsJhw/+EU3R6--qUw9L8DwN5XPVfOStshKzA==")
 
 	accountStore := mock.NewAccountStore()
+	otpCodes := mock.NewOTPCodeCache()
 	cfg := &app.Config{
 		AuthNURL:              &url.URL{Scheme: "http", Host: "authn.example.com"},
 		BcryptCost:            4,
@@ -137,17 +140,17 @@ func TestPasswordResetterWithOTP(t *testing.T) {
 		return token
 	}
 
-	invoke := func(token string, password string, totpCode string) error {
-		_, err := services.PasswordResetter(accountStore, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, token, password, totpCode)
+	invoke := func(token string, password string, otpCode string) error {
+		_, err := services.PasswordResetter(context.Background(), accountStore, otpCodes, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, token, password, otpCode)
 		return err
 	}
 
 	t.Run("sets new password", func(t *testing.T) {
-		expired, err := accountStore.Create("first@keratin.tech", []byte("old"))
+		expired, err := accountStore.Create(context.Background(), "first@keratin.tech", []byte("old"))
 		require.NoError(t, err)
-		_, err = accountStore.SetTOTPSecret(expired.ID, totpSecretEnc)
+		_, err = accountStore.AddTOTPAuthenticator(context.Background(), expired.ID, "phone", totpSecretEnc, 0)
 		require.NoError(t, err)
-		_, err = accountStore.RequireNewPassword(expired.ID)
+		_, err = accountStore.RequireNewPassword(context.Background(), expired.ID)
 		require.NoError(t, err)
 
 		code, err := totp.GenerateCode(totpSecret, time.Now())
@@ -156,21 +159,48 @@ func TestPasswordResetterWithOTP(t *testing.T) {
 		err = invoke(newToken(expired.ID, expired.PasswordChangedAt), "0a0b0c0d0e0f", code)
 		assert.NoError(t, err)
 
-		account, err := accountStore.Find(expired.ID)
+		account, err := accountStore.Find(context.Background(), expired.ID)
 		require.NoError(t, err)
 		assert.NotEqual(t, expired.Password, account.Password)
 		assert.False(t, account.RequireNewPassword)
 	})
 
 	t.Run("without totp code", func(t *testing.T) {
-		expired, err := accountStore.Create("second@keratin.tech", []byte("old"))
+		expired, err := accountStore.Create(context.Background(), "second@keratin.tech", []byte("old"))
 		require.NoError(t, err)
-		_, err = accountStore.RequireNewPassword(expired.ID)
+		_, err = accountStore.RequireNewPassword(context.Background(), expired.ID)
 		require.NoError(t, err)
-		_, err = accountStore.SetTOTPSecret(expired.ID, totpSecretEnc)
+		_, err = accountStore.AddTOTPAuthenticator(context.Background(), expired.ID, "phone", totpSecretEnc, 0)
 		require.NoError(t, err)
 
 		err = invoke(newToken(expired.ID, expired.PasswordChangedAt), "0a0b0c0d0e0f", "12345")
 		assert.Equal(t, services.FieldErrors{{"otp", "INVALID_OR_EXPIRED"}}, err)
+
+		account, err := services.PasswordResetter(context.Background(), accountStore, otpCodes, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, newToken(expired.ID, expired.PasswordChangedAt), "0a0b0c0d0e0f", "")
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrMissing}}, err)
+		require.NotNil(t, account)
+		assert.Equal(t, expired.ID, account.ID)
+	})
+
+	t.Run("with a code sent by email", func(t *testing.T) {
+		expired, err := accountStore.Create(context.Background(), "email@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		_, err = accountStore.SetOTPChannel(context.Background(), expired.ID, services.OTPChannelEmail)
+		require.NoError(t, err)
+		expired, err = accountStore.Find(context.Background(), expired.ID)
+		require.NoError(t, err)
+
+		deliveryCfg, delivered := otpDeliveryConfig(t)
+		cfg.AppOTPDeliveryURL = deliveryCfg.AppOTPDeliveryURL
+		cfg.OTPCodeSigningKey = deliveryCfg.OTPCodeSigningKey
+		cfg.OTPCodeTTL = deliveryCfg.OTPCodeTTL
+
+		err = invoke(newToken(expired.ID, expired.PasswordChangedAt), "0a0b0c0d0e0f", "")
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrMissing}}, err)
+
+		err = services.OTPCodeSender(context.Background(), otpCodes, cfg, expired, services.OTPChannelEmail, logrus.New())
+		require.NoError(t, err)
+		err = invoke(newToken(expired.ID, expired.PasswordChangedAt), "0a0b0c0d0e0f", delivered().Get("code"))
+		assert.NoError(t, err)
 	})
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"net/url"
 	"strconv"
 
@@ -11,13 +12,13 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func PasswordSetter(store data.AccountStore, r ops.ErrorReporter, cfg *app.Config, accountID int, password string) error {
-	account, err := store.Find(accountID)
+func PasswordSetter(ctx context.Context, store data.AccountStore, r ops.ErrorReporter, cfg *app.Config, accountID int, password string) error {
+	account, err := store.Find(ctx, accountID)
 	if err != nil {
 		return FieldErrors{{"account", ErrNotFound}}
 	}
 
-	fieldError := PasswordValidator(cfg, account.Username, password)
+	fieldError := PasswordValidator(cfg, account.Tenant, account.Username, password)
 	if fieldError != nil {
 		return FieldErrors{*fieldError}
 	}
@@ -27,7 +28,7 @@ func PasswordSetter(store data.AccountStore, r ops.ErrorReporter, cfg *app.Confi
 		return errors.Wrap(err, "GenerateFromPassword")
 	}
 
-	affected, err := store.SetPassword(accountID, hash)
+	affected, err := store.SetPassword(ctx, accountID, hash)
 	if err != nil {
 		return errors.Wrap(err, "SetPassword")
 	}
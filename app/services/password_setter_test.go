@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/keratin/authn-server/app"
@@ -20,20 +21,20 @@ func TestPasswordSetter(t *testing.T) {
 	}
 
 	invoke := func(id int, password string) error {
-		return services.PasswordSetter(accountStore, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, id, password)
+		return services.PasswordSetter(context.Background(), accountStore, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, id, password)
 	}
 
-	account, err := accountStore.Create("existing@keratin.example.com", []byte("old"))
+	account, err := accountStore.Create(context.Background(), "existing@keratin.example.com", []byte("old"))
 	require.NoError(t, err)
 
 	t.Run("sets password", func(t *testing.T) {
-		_, err = accountStore.RequireNewPassword(account.ID)
+		_, err = accountStore.RequireNewPassword(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		err := invoke(account.ID, "0a0b0c0d0e0f0")
 		assert.NoError(t, err)
 
-		after, err := accountStore.Find(account.ID)
+		after, err := accountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.NotEqual(t, account.Password, after.Password)
 		assert.False(t, account.RequireNewPassword)
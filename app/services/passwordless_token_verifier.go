@@ -1,56 +1,51 @@
 package services
 
 import (
+	"context"
 	"strconv"
 
-	"github.com/keratin/authn-server/lib/compat"
 	"github.com/keratin/authn-server/ops"
-	"github.com/pquerna/otp/totp"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/tokens/passwordless"
 	"github.com/pkg/errors"
 )
 
-func PasswordlessTokenVerifier(store data.AccountStore, r ops.ErrorReporter, cfg *app.Config, token string, otpCode string) (int, error) {
+func PasswordlessTokenVerifier(ctx context.Context, store data.AccountStore, codes data.OTPCodeCache, r ops.ErrorReporter, cfg *app.Config, token string, otpCode string) (*models.Account, error) {
 	claims, err := passwordless.Parse(token, cfg)
 	if err != nil {
-		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
+		return nil, FieldErrors{{"token", ErrInvalidOrExpired}}
 	}
 
 	id, err := strconv.Atoi(claims.Subject)
 	if err != nil {
-		return 0, errors.Wrap(err, "Atoi")
+		return nil, errors.Wrap(err, "Atoi")
 	}
 
-	account, err := store.Find(id)
+	account, err := store.Find(ctx, id)
 	if err != nil {
-		return 0, errors.Wrap(err, "Find")
+		return nil, errors.Wrap(err, "Find")
 	}
 	if account == nil {
-		return 0, FieldErrors{{"account", ErrNotFound}}
+		return nil, FieldErrors{{"account", ErrNotFound}}
 	} else if account.Locked {
-		return 0, FieldErrors{{"account", ErrLocked}}
+		return nil, FieldErrors{{"account", ErrLocked}}
 	} else if account.Archived() {
-		return 0, FieldErrors{{"account", ErrLocked}}
+		return nil, FieldErrors{{"account", ErrLocked}}
 	} else if account.LastLoginAt != nil && account.LastLoginAt.After(claims.IssuedAt.Time()) {
-		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
+		return nil, FieldErrors{{"token", ErrInvalidOrExpired}}
 	}
 
 	//Check OTP MFA
-	if account.TOTPEnabled() {
-		secret, err := compat.Decrypt([]byte(account.TOTPSecret.String), cfg.DBEncryptionKey)
-		if err != nil {
-			return 0, errors.Wrap(err, "TOTPDecrypt")
-		}
-		if !totp.Validate(otpCode, secret) {
-			if otpCode == "" {
-				return 0, FieldErrors{{"otp", ErrMissing}}
-			}
-			return 0, FieldErrors{{"otp", ErrInvalidOrExpired}}
-		}
+	err = OTPVerifier(ctx, store, codes, cfg, account, otpCode)
+	if IsMissingOTP(err) {
+		// the account is returned so that the login may be completed with a challenge
+		return account, err
+	} else if err != nil {
+		return nil, err
 	}
 
-	return account.ID, nil
+	return account, nil
 }
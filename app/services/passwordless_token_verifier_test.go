@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"net/url"
 	"testing"
 	"time"
@@ -34,12 +35,12 @@ func TestPasswordlessTokenVerifier(t *testing.T) {
 	}
 
 	invoke := func(token string) error {
-		_, err := services.PasswordlessTokenVerifier(accountStore, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, token, "")
+		_, err := services.PasswordlessTokenVerifier(context.Background(), accountStore, mock.NewOTPCodeCache(), &ops.LogReporter{FieldLogger: logrus.New()}, cfg, token, "")
 		return err
 	}
 
 	t.Run("when token is valid", func(t *testing.T) {
-		account, err := accountStore.Create("valid@keratin.tech", []byte("old"))
+		account, err := accountStore.Create(context.Background(), "valid@keratin.tech", []byte("old"))
 		require.NoError(t, err)
 
 		token := newToken(account.ID)
@@ -57,9 +58,9 @@ func TestPasswordlessTokenVerifier(t *testing.T) {
 	})
 
 	t.Run("on an archived account", func(t *testing.T) {
-		archived, err := accountStore.Create("archived@keratin.tech", []byte("old"))
+		archived, err := accountStore.Create(context.Background(), "archived@keratin.tech", []byte("old"))
 		require.NoError(t, err)
-		_, err = accountStore.Archive(archived.ID)
+		_, err = accountStore.Archive(context.Background(), archived.ID)
 		require.NoError(t, err)
 
 		token := newToken(archived.ID)
@@ -69,9 +70,9 @@ func TestPasswordlessTokenVerifier(t *testing.T) {
 	})
 
 	t.Run("on a locked account", func(t *testing.T) {
-		locked, err := accountStore.Create("locked@keratin.tech", []byte("old"))
+		locked, err := accountStore.Create(context.Background(), "locked@keratin.tech", []byte("old"))
 		require.NoError(t, err)
-		_, err = accountStore.Lock(locked.ID)
+		_, err = accountStore.Lock(context.Background(), locked.ID)
 		require.NoError(t, err)
 
 		token := newToken(locked.ID)
@@ -81,12 +82,12 @@ func TestPasswordlessTokenVerifier(t *testing.T) {
 	})
 
 	t.Run("when account has logged in again", func(t *testing.T) {
-		account, err := accountStore.Create("account@keratin.tech", []byte("old"))
+		account, err := accountStore.Create(context.Background(), "account@keratin.tech", []byte("old"))
 		require.NoError(t, err)
 
 		token := newToken(account.ID)
 
-		_, err = accountStore.SetLastLogin(account.ID)
+		_, err = accountStore.SetLastLogin(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		err = invoke(token)
@@ -123,14 +124,14 @@ func TestPasswordlessTokenVerifierWithOTP(t *testing.T) {
 	}
 
 	invoke := func(token string, totpCode string) error {
-		_, err := services.PasswordlessTokenVerifier(accountStore, &ops.LogReporter{FieldLogger: logrus.New()}, cfg, token, totpCode)
+		_, err := services.PasswordlessTokenVerifier(context.Background(), accountStore, mock.NewOTPCodeCache(), &ops.LogReporter{FieldLogger: logrus.New()}, cfg, token, totpCode)
 		return err
 	}
 
 	t.Run("with good code", func(t *testing.T) {
-		account, err := accountStore.Create("first@keratin.tech", []byte("old"))
+		account, err := accountStore.Create(context.Background(), "first@keratin.tech", []byte("old"))
 		require.NoError(t, err)
-		_, err = accountStore.SetTOTPSecret(account.ID, totpSecretEnc)
+		_, err = accountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
 		require.NoError(t, err)
 		token := newToken(account.ID)
 
@@ -142,9 +143,9 @@ func TestPasswordlessTokenVerifierWithOTP(t *testing.T) {
 	})
 
 	t.Run("with bad code", func(t *testing.T) {
-		account, err := accountStore.Create("second@keratin.tech", []byte("old"))
+		account, err := accountStore.Create(context.Background(), "second@keratin.tech", []byte("old"))
 		require.NoError(t, err)
-		_, err = accountStore.SetTOTPSecret(account.ID, totpSecretEnc)
+		_, err = accountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
 		require.NoError(t, err)
 		token := newToken(account.ID)
 
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib"
+	"github.com/pkg/errors"
+)
+
+// personalAccessTokenPrefix makes personal access tokens recognizable, e.g. by secret scanners.
+const personalAccessTokenPrefix = "pat_"
+
+// PersonalAccessTokenCreator creates a personal access token for the account. The token is returned
+// here and never again, since only a hash is stored. The scopes must be allowed by configuration,
+// and an expiration of zero seconds means the token does not expire.
+func PersonalAccessTokenCreator(
+	ctx context.Context, store data.AccountStore, cfg *app.Config,
+	accountID int, audience string, name string, scope string, expiresIn int,
+) (*models.PersonalAccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	scopes := models.ParseNameList(scope)
+
+	errs := FieldErrors{}
+	if name == "" {
+		errs = append(errs, FieldError{"name", ErrMissing})
+	}
+	if !models.NameList(cfg.PersonalAccessTokenScopes).Contains(scopes...) {
+		errs = append(errs, FieldError{"scope", ErrFormatInvalid})
+	}
+	if expiresIn < 0 {
+		errs = append(errs, FieldError{"expiresIn", ErrFormatInvalid})
+	}
+	if len(errs) > 0 {
+		return nil, "", errs
+	}
+
+	var expiresAt *time.Time
+	if expiresIn > 0 {
+		t := time.Now().Add(time.Duration(expiresIn) * time.Second)
+		expiresAt = &t
+	}
+
+	binToken, err := lib.GenerateToken()
+	if err != nil {
+		return nil, "", errors.Wrap(err, "GenerateToken")
+	}
+	token := personalAccessTokenPrefix + hex.EncodeToString(binToken)
+
+	pat, err := store.AddPersonalAccessToken(ctx, accountID, name, hashPersonalAccessToken(token), scopes, audience, expiresAt)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "AddPersonalAccessToken")
+	}
+
+	return pat, token, nil
+}
+
+// hashPersonalAccessToken is a plain digest rather than a password hash because tokens are random
+// and must be found by their hash.
+func hashPersonalAccessToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,64 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalAccessTokenCreator(t *testing.T) {
+	store := mock.NewAccountStore()
+	cfg := &app.Config{PersonalAccessTokenScopes: []string{"read", "write"}}
+
+	account, err := store.Create(context.Background(), "test user", []byte("password"))
+	require.NoError(t, err)
+
+	t.Run("without expiration", func(t *testing.T) {
+		pat, token, err := services.PersonalAccessTokenCreator(context.Background(), store, cfg, account.ID, "https://app.example.com", "cli", "read", 0)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(token, "pat_"))
+		assert.NotContains(t, pat.TokenHash, token)
+		assert.Equal(t, models.NameList{"read"}, pat.Scopes)
+		assert.Equal(t, "https://app.example.com", pat.Audience)
+		assert.Nil(t, pat.ExpiresAt)
+
+		tokens, err := store.GetPersonalAccessTokens(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Len(t, tokens, 1)
+	})
+
+	t.Run("with expiration", func(t *testing.T) {
+		pat, _, err := services.PersonalAccessTokenCreator(context.Background(), store, cfg, account.ID, "https://app.example.com", "ci", "", 3600)
+		require.NoError(t, err)
+		if assert.NotNil(t, pat.ExpiresAt) {
+			assert.WithinDuration(t, time.Now().Add(time.Hour), *pat.ExpiresAt, time.Minute)
+		}
+	})
+
+	failureCases := []struct {
+		name      string
+		scope     string
+		expiresIn int
+		errors    services.FieldErrors
+	}{
+		{"", "", 0, services.FieldErrors{{"name", services.ErrMissing}}},
+		{"  ", "", 0, services.FieldErrors{{"name", services.ErrMissing}}},
+		{"cli", "admin", 0, services.FieldErrors{{"scope", services.ErrFormatInvalid}}},
+		{"cli", "read admin", 0, services.FieldErrors{{"scope", services.ErrFormatInvalid}}},
+		{"cli", "", -1, services.FieldErrors{{"expiresIn", services.ErrFormatInvalid}}},
+	}
+	for _, fc := range failureCases {
+		t.Run(fc.name+fc.scope, func(t *testing.T) {
+			_, _, err := services.PersonalAccessTokenCreator(context.Background(), store, cfg, account.ID, "https://app.example.com", fc.name, fc.scope, fc.expiresIn)
+			assert.Equal(t, fc.errors, err)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// PersonalAccessTokenDeleter revokes one of the account's personal access tokens.
+func PersonalAccessTokenDeleter(ctx context.Context, store data.AccountStore, accountID int, tokenID int) error {
+	deleted, err := store.DeletePersonalAccessToken(ctx, accountID, tokenID)
+	if err != nil {
+		return errors.Wrap(err, "DeletePersonalAccessToken")
+	}
+	if !deleted {
+		return FieldErrors{{"token", ErrNotFound}}
+	}
+
+	return nil
+}
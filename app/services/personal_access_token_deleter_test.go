@@ -0,0 +1,35 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalAccessTokenDeleter(t *testing.T) {
+	store := mock.NewAccountStore()
+
+	account, err := store.Create(context.Background(), "test user", []byte("password"))
+	require.NoError(t, err)
+	pat, err := store.AddPersonalAccessToken(context.Background(), account.ID, "cli", "hash", models.NameList{}, "", nil)
+	require.NoError(t, err)
+
+	t.Run("another account's token", func(t *testing.T) {
+		err := services.PersonalAccessTokenDeleter(context.Background(), store, account.ID+1, pat.ID)
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrNotFound}}, err)
+	})
+
+	t.Run("own token", func(t *testing.T) {
+		err := services.PersonalAccessTokenDeleter(context.Background(), store, account.ID, pat.ID)
+		assert.NoError(t, err)
+
+		found, err := store.FindPersonalAccessToken(context.Background(), "hash")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/pkg/errors"
+)
+
+// PersonalAccessTokenExchanger verifies a personal access token and issues a short-lived identity
+// token in its place, so that downstream services can verify it like any other. The account must
+// still be in good standing.
+func PersonalAccessTokenExchanger(
+	ctx context.Context, store data.AccountStore, keyStore data.KeyStore, cfg *app.Config,
+	token string,
+) (string, error) {
+	if !strings.HasPrefix(token, personalAccessTokenPrefix) {
+		return "", FieldErrors{{"token", ErrInvalidOrExpired}}
+	}
+
+	pat, err := store.FindPersonalAccessToken(ctx, hashPersonalAccessToken(token))
+	if err != nil {
+		return "", errors.Wrap(err, "FindPersonalAccessToken")
+	}
+	if pat == nil || pat.Expired(time.Now()) {
+		return "", FieldErrors{{"token", ErrInvalidOrExpired}}
+	}
+
+	account, err := store.Find(ctx, pat.AccountID)
+	if err != nil {
+		return "", errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return "", FieldErrors{{"token", ErrInvalidOrExpired}}
+	}
+	if account.Locked {
+		return "", FieldErrors{{"account", ErrLocked}}
+	}
+
+	_, err = store.SetPersonalAccessTokenLastUsed(ctx, pat.ID)
+	if err != nil {
+		return "", errors.Wrap(err, "SetPersonalAccessTokenLastUsed")
+	}
+
+	identityToken, err := identities.NewForPersonalAccessToken(cfg, pat).Sign(keyStore.Key())
+	if err != nil {
+		return "", errors.Wrap(err, "Sign")
+	}
+
+	return identityToken, nil
+}
@@ -0,0 +1,91 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalAccessTokenExchanger(t *testing.T) {
+	store := mock.NewAccountStore()
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(key)
+	cfg := &app.Config{
+		AuthNURL:                  &url.URL{Scheme: "http", Host: "authn.example.com"},
+		AccessTokenTTL:            time.Hour,
+		PersonalAccessTokenScopes: []string{"read"},
+	}
+
+	createToken := func(username string, expiresIn int) (int, string) {
+		account, err := store.Create(context.Background(), username, []byte("password"))
+		require.NoError(t, err)
+		_, token, err := services.PersonalAccessTokenCreator(context.Background(), store, cfg, account.ID, "https://app.example.com", "cli", "read", expiresIn)
+		require.NoError(t, err)
+		return account.ID, token
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		accountID, token := createToken("valid", 3600)
+
+		identityToken, err := services.PersonalAccessTokenExchanger(context.Background(), store, keyStore, cfg, token)
+		require.NoError(t, err)
+
+		claims, err := identities.Parse(identityToken, cfg, keyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, "read", claims.Scope)
+		assert.Equal(t, []string{"pat"}, claims.AuthMethodReference)
+		assert.True(t, claims.Audience.Contains("https://app.example.com"))
+
+		tokens, err := store.GetPersonalAccessTokens(context.Background(), accountID)
+		require.NoError(t, err)
+		assert.NotNil(t, tokens[0].LastUsedAt)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err := services.PersonalAccessTokenExchanger(context.Background(), store, keyStore, cfg, "pat_unknown")
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		account, err := store.Create(context.Background(), "expired", []byte("password"))
+		require.NoError(t, err)
+		token := "pat_expired"
+		digest := sha256.Sum256([]byte(token))
+		expiresAt := time.Now().Add(-time.Second)
+		_, err = store.AddPersonalAccessToken(context.Background(), account.ID, "cli", hex.EncodeToString(digest[:]), nil, "https://app.example.com", &expiresAt)
+		require.NoError(t, err)
+
+		_, err = services.PersonalAccessTokenExchanger(context.Background(), store, keyStore, cfg, token)
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("locked account", func(t *testing.T) {
+		accountID, token := createToken("locked", 0)
+		_, err := store.Lock(context.Background(), accountID)
+		require.NoError(t, err)
+
+		_, err = services.PersonalAccessTokenExchanger(context.Background(), store, keyStore, cfg, token)
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrLocked}}, err)
+	})
+
+	t.Run("archived account", func(t *testing.T) {
+		accountID, token := createToken("archived", 0)
+		_, err := store.Archive(context.Background(), accountID)
+		require.NoError(t, err)
+
+		_, err = services.PersonalAccessTokenExchanger(context.Background(), store, keyStore, cfg, token)
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+}
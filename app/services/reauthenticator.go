@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Reauthenticator verifies the password and second factor for the account of an existing session,
+// so that the session may be used for sensitive actions.
+func Reauthenticator(ctx context.Context, store data.AccountStore, codes data.OTPCodeCache, cfg *app.Config, accountID int, password string, otpCode string) (*models.Account, error) {
+	account, err := AccountGetter(ctx, store, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = bcrypt.CompareHashAndPassword(account.Password, []byte(password))
+	if err != nil {
+		return nil, FieldErrors{{"credentials", ErrFailed}}
+	}
+	if account.Locked {
+		return nil, FieldErrors{{"account", ErrLocked}}
+	}
+
+	err = OTPVerifier(ctx, store, codes, cfg, account, otpCode)
+	if IsMissingOTP(err) {
+		// the account is returned so that a code may be delivered
+		return account, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReauthenticator(t *testing.T) {
+	password := "mysecret"
+	bcrypted := []byte("$2a$04$lzQPXlov4RFLxps1uUGq4e4wmVjLYz3WrqQw4bSdfIiJRyo3/fk3C")
+	// nolint: gosec
+	totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
+	totpSecretEnc := []byte("cli6azfL5i7PAnh8U/w3Zbglsm3XcdaGODy+Ga5QqT02c9hotDAR1Y28--3UihzsJhw/+EU3R6--qUw9L8DwN5XPVfOStshKzA==")
+
+	cfg := &app.Config{BcryptCost: 4, DBEncryptionKey: []byte("DLz2TNDRdWWA5w8YNeCJ7uzcS4WDzQmB")}
+	store := mock.NewAccountStore()
+
+	t.Run("with password", func(t *testing.T) {
+		account, err := store.Create(context.Background(), "password", bcrypted)
+		require.NoError(t, err)
+
+		found, err := services.Reauthenticator(context.Background(), store, mock.NewOTPCodeCache(), cfg, account.ID, password, "")
+		require.NoError(t, err)
+		assert.Equal(t, account.ID, found.ID)
+
+		_, err = services.Reauthenticator(context.Background(), store, mock.NewOTPCodeCache(), cfg, account.ID, "wrong", "")
+		assert.Equal(t, services.FieldErrors{{"credentials", services.ErrFailed}}, err)
+	})
+
+	t.Run("with TOTP", func(t *testing.T) {
+		account, err := store.Create(context.Background(), "totp", bcrypted)
+		require.NoError(t, err)
+		_, err = store.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
+		require.NoError(t, err)
+
+		found, err := services.Reauthenticator(context.Background(), store, mock.NewOTPCodeCache(), cfg, account.ID, password, "")
+		assert.True(t, services.IsMissingOTP(err))
+		assert.Equal(t, account.ID, found.ID)
+
+		code, err := totp.GenerateCode(totpSecret, time.Now())
+		require.NoError(t, err)
+		_, err = services.Reauthenticator(context.Background(), store, mock.NewOTPCodeCache(), cfg, account.ID, password, code)
+		assert.NoError(t, err)
+	})
+
+	t.Run("locked account", func(t *testing.T) {
+		account, err := store.Create(context.Background(), "locked", bcrypted)
+		require.NoError(t, err)
+		_, err = store.Lock(context.Background(), account.ID)
+		require.NoError(t, err)
+
+		_, err = services.Reauthenticator(context.Background(), store, mock.NewOTPCodeCache(), cfg, account.ID, password, "")
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrLocked}}, err)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		_, err := services.Reauthenticator(context.Background(), store, mock.NewOTPCodeCache(), cfg, 9999, password, "")
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, err)
+	})
+}
@@ -1,19 +1,28 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
 )
 
-func SessionBatchEnder(store data.RefreshTokenStore, accountID int) error {
-	tokens, err := store.FindAll(accountID)
+// SessionBatchEnder revokes every token of the account, and sends a logout token for the account to
+// any back-channel logout URIs of the account's tenant.
+func SessionBatchEnder(ctx context.Context, store data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, r ops.ErrorReporter, tenant string, accountID int) error {
+	tokens, err := store.FindAll(ctx, accountID)
 	if err != nil {
 		return err
 	}
 	for _, token := range tokens {
-		err = store.Revoke(token)
+		err = store.Revoke(ctx, token)
 		if err != nil {
 			return err
 		}
 	}
+	if len(tokens) > 0 {
+		BackchannelLogout(cfg, keyStore, r, tenant, accountID, "")
+	}
 	return nil
 }
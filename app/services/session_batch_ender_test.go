@@ -1,36 +1,85 @@
 package services_test
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/logouts"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
 
 func TestSessionBatchEnder(t *testing.T) {
+	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
 	store := mock.NewRefreshTokenStore()
 
 	t.Run("revoking nothing", func(t *testing.T) {
 		id := 123
-		err := services.SessionBatchEnder(store, id)
+		err := services.SessionBatchEnder(context.Background(), store, nil, &app.Config{}, reporter, "", id)
 		assert.NoError(t, err)
 	})
 
 	t.Run("revoking something", func(t *testing.T) {
 		id := 234
-		_, err := store.Create(id)
+		_, err := store.Create(context.Background(), id)
 		require.NoError(t, err)
 
-		found, err := store.FindAll(id)
+		found, err := store.FindAll(context.Background(), id)
 		require.NoError(t, err)
 		require.Len(t, found, 1)
 
-		err = services.SessionBatchEnder(store, id)
+		err = services.SessionBatchEnder(context.Background(), store, nil, &app.Config{}, reporter, "", id)
 		assert.NoError(t, err)
 
-		found, err = store.FindAll(id)
+		found, err = store.FindAll(context.Background(), id)
 		assert.NoError(t, err)
 		assert.Len(t, found, 0)
 	})
+	t.Run("sends back-channel logout", func(t *testing.T) {
+		received := make(chan string, 1)
+		remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- r.FormValue("logout_token")
+		}))
+		defer remoteApp.Close()
+		serverURL, err := url.Parse(remoteApp.URL)
+		require.NoError(t, err)
+
+		key, err := private.GenerateKey(512)
+		require.NoError(t, err)
+		cfg := &app.Config{
+			AuthNURL:              &url.URL{Scheme: "http", Host: "authn.example.com"},
+			BackchannelLogoutURIs: map[string]*url.URL{"app.example.com": serverURL},
+		}
+
+		id := 345
+		_, err = store.Create(context.Background(), id)
+		require.NoError(t, err)
+
+		err = services.SessionBatchEnder(context.Background(), store, mock.NewKeyStore(key), cfg, reporter, "", id)
+		require.NoError(t, err)
+
+		select {
+		case logoutToken := <-received:
+			parsed, err := jwt.ParseSigned(logoutToken)
+			require.NoError(t, err)
+			claims := logouts.Claims{}
+			require.NoError(t, parsed.Claims(key.Public(), &claims))
+			assert.Equal(t, "345", claims.Subject)
+			assert.Empty(t, claims.SessionID)
+		case <-time.After(time.Second):
+			assert.Fail(t, "logout token was not sent")
+		}
+	})
 }
@@ -1,9 +1,12 @@
 package services
 
 import (
+	"context"
+
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tenants"
 	"github.com/keratin/authn-server/app/tokens/identities"
 	"github.com/keratin/authn-server/app/tokens/sessions"
 	"github.com/keratin/authn-server/lib/route"
@@ -11,39 +14,63 @@ import (
 	"github.com/pkg/errors"
 )
 
+// SessionCreator logs in to a new session. If the MFA policy applies to an account that has not set
+// up MFA, the session is restricted to setting it up and no identity token is returned. The app's
+// pre-login hook may deny the session or add claims to its identity tokens.
 func SessionCreator(
-	accountStore data.AccountStore, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, actives data.Actives, cfg *app.Config, reporter ops.ErrorReporter,
+	ctx context.Context, accountStore data.AccountStore, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, actives data.Actives, cfg *app.Config, reporter ops.ErrorReporter,
 	accountID int, audience *route.Domain, existingToken *models.RefreshToken, amr []string,
 ) (string, string, error) {
-	var err error
-	err = SessionEnder(refreshTokenStore, existingToken)
+	account, err := accountStore.Find(ctx, accountID)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Find")
+	}
+
+	// the app may deny the login or add claims, before anything changes
+	var extraClaims map[string]interface{}
+	if account != nil {
+		extraClaims, err = preLoginHook(ctx, cfg, account, audience.String(), amr)
+		if err != nil {
+			if _, ok := err.(FieldErrors); ok {
+				return "", "", err
+			}
+			return "", "", errors.Wrap(err, "preLoginHook")
+		}
+	}
+
+	err = SessionEnder(ctx, refreshTokenStore, keyStore, cfg, reporter, tenants.Name(ctx), existingToken)
 	if err != nil {
 		reporter.ReportError(errors.Wrap(err, "SessionEnder"))
 	}
 
 	// track actives
 	if actives != nil {
-		err = actives.Track(accountID)
+		err = actives.Track(ctx, accountID)
 		if err != nil {
 			reporter.ReportError(errors.Wrap(err, "Track"))
 		}
 	}
 
 	// track last activity
-	_, err = accountStore.SetLastLogin(accountID)
+	_, err = accountStore.SetLastLogin(ctx, accountID)
 	if err != nil {
 		reporter.ReportError(errors.Wrap(err, "SetLastLogin"))
 	}
 
 	// create new session token
-	session, err := sessions.New(refreshTokenStore, cfg, accountID, audience.String(), amr)
+	session, err := sessions.New(ctx, refreshTokenStore, cfg, accountID, audience.String(), amr)
 	if err != nil {
 		return "", "", errors.Wrap(err, "sessions.New")
 	}
+	session.MFAEnrollment = account != nil && MFARequired(cfg, account) && !account.MFAEnabled()
+	session.ExtraClaims = extraClaims
 	sessionToken, err := session.Sign(cfg.SessionSigningKey)
 	if err != nil {
 		return "", "", errors.Wrap(err, "session.Sign")
 	}
+	if session.MFAEnrollment {
+		return sessionToken, "", nil
+	}
 
 	// create new identity token
 	identityToken, err := identities.New(cfg, session, accountID, audience.String()).Sign(keyStore.Key())
@@ -1,13 +1,20 @@
 package services_test
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/data/private"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/sessions"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/ops"
 	"github.com/sirupsen/logrus"
@@ -27,19 +34,19 @@ func TestSessionCreator(t *testing.T) {
 	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
 
 	audience := &route.Domain{Hostname: "authn.example.com", Port: "8080"}
-	account, err := accountStore.Create("existing", []byte("secret"))
+	account, err := accountStore.Create(context.Background(), "existing", []byte("secret"))
 	require.NoError(t, err)
 
 	t.Run("tracks last login while generating tokens", func(t *testing.T) {
 		identityToken, refreshToken, err := services.SessionCreator(
-			accountStore, refreshStore, keyStore, nil, cfg, reporter,
+			context.Background(), accountStore, refreshStore, keyStore, nil, cfg, reporter,
 			account.ID, audience, nil, nil,
 		)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, identityToken)
 		assert.NotEmpty(t, refreshToken)
 
-		found, err := accountStore.Find(account.ID)
+		found, err := accountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.NotEqual(t, nil, found.LastLoginAt)
 	})
@@ -47,28 +54,117 @@ func TestSessionCreator(t *testing.T) {
 	t.Run("tracks actives", func(t *testing.T) {
 		activesStore := mock.NewActives()
 		_, _, err := services.SessionCreator(
-			accountStore, refreshStore, keyStore, activesStore, cfg, reporter,
+			context.Background(), accountStore, refreshStore, keyStore, activesStore, cfg, reporter,
 			account.ID, audience, nil, nil,
 		)
 		require.NoError(t, err)
 
-		report, err := activesStore.ActivesByDay()
+		report, err := activesStore.ActivesByDay(context.Background())
 		require.NoError(t, err)
 		assert.Len(t, report, 1)
 	})
 
 	t.Run("ends existing session", func(t *testing.T) {
-		token, err := refreshStore.Create(account.ID)
+		token, err := refreshStore.Create(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		_, _, err = services.SessionCreator(
-			accountStore, refreshStore, keyStore, nil, cfg, reporter,
+			context.Background(), accountStore, refreshStore, keyStore, nil, cfg, reporter,
 			account.ID, audience, &token, nil,
 		)
 		assert.NoError(t, err)
 
-		foundID, err := refreshStore.Find(token)
+		foundID, err := refreshStore.Find(context.Background(), token)
 		assert.Empty(t, foundID)
 		assert.NoError(t, err)
 	})
 }
+
+func TestSessionCreatorWithPreLoginHook(t *testing.T) {
+	var received url.Values
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		received = r.PostForm
+		switch received.Get("username") {
+		case "allowed":
+			w.Write([]byte(`{"allow": true, "claims": {"plan": "pro", "sub": "someone else"}}`))
+		case "denied":
+			w.Write([]byte(`{"allow": false, "reason": "BILLING_PAST_DUE"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer hook.Close()
+	hookURL, err := url.Parse(hook.URL)
+	require.NoError(t, err)
+
+	cfg := &app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+		AppPreLoginURL:    hookURL,
+		HookTimeout:       time.Second,
+	}
+	rsaKey, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(rsaKey)
+	refreshStore := mock.NewRefreshTokenStore()
+	accountStore := mock.NewAccountStore()
+	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
+	audience := &route.Domain{Hostname: "authn.example.com", Port: "8080"}
+
+	t.Run("allowed with claims", func(t *testing.T) {
+		account, err := accountStore.Create(context.Background(), "allowed", []byte("secret"))
+		require.NoError(t, err)
+
+		sessionToken, identityToken, err := services.SessionCreator(
+			context.Background(), accountStore, refreshStore, keyStore, nil, cfg, reporter,
+			account.ID, audience, nil, []string{"pwd"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(account.ID), received.Get("account_id"))
+		assert.Equal(t, "authn.example.com:8080", received.Get("domain"))
+		assert.Equal(t, "pwd", received.Get("amr"))
+
+		session, err := sessions.Parse(sessionToken, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "pro", session.ExtraClaims["plan"])
+
+		token, err := jwt.ParseSigned(identityToken)
+		require.NoError(t, err)
+		claims := map[string]interface{}{}
+		require.NoError(t, token.UnsafeClaimsWithoutVerification(&claims))
+		assert.Equal(t, "pro", claims["plan"])
+		assert.Equal(t, strconv.Itoa(account.ID), claims["sub"])
+	})
+
+	t.Run("denied with a reason", func(t *testing.T) {
+		account, err := accountStore.Create(context.Background(), "denied", []byte("secret"))
+		require.NoError(t, err)
+		existing, err := refreshStore.Create(context.Background(), account.ID)
+		require.NoError(t, err)
+
+		_, _, err = services.SessionCreator(
+			context.Background(), accountStore, refreshStore, keyStore, nil, cfg, reporter,
+			account.ID, audience, &existing, []string{"pwd"},
+		)
+		assert.Equal(t, services.FieldErrors{{"account", "BILLING_PAST_DUE"}}, err)
+
+		// the existing session is not ended
+		foundID, err := refreshStore.Find(context.Background(), existing)
+		require.NoError(t, err)
+		assert.Equal(t, account.ID, foundID)
+	})
+
+	t.Run("failing hook", func(t *testing.T) {
+		account, err := accountStore.Create(context.Background(), "unknown", []byte("secret"))
+		require.NoError(t, err)
+
+		_, _, err = services.SessionCreator(
+			context.Background(), accountStore, refreshStore, keyStore, nil, cfg, reporter,
+			account.ID, audience, nil, []string{"pwd"},
+		)
+		require.Error(t, err)
+		_, isFieldErrors := err.(services.FieldErrors)
+		assert.False(t, isFieldErrors)
+	})
+}
@@ -1,16 +1,45 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
 )
 
+// SessionEnder revokes the token of a session, and sends a logout token for the session to any
+// back-channel logout URIs of the session's tenant.
 func SessionEnder(
-	refreshTokenStore data.RefreshTokenStore,
-	existingToken *models.RefreshToken,
+	ctx context.Context, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, r ops.ErrorReporter,
+	tenant string, existingToken *models.RefreshToken,
 ) (err error) {
-	if existingToken != nil {
-		return refreshTokenStore.Revoke(*existingToken)
+	if existingToken == nil {
+		return nil
+	}
+	if len(cfg.BackchannelLogoutURIs) == 0 {
+		return refreshTokenStore.Revoke(ctx, *existingToken)
+	}
+
+	accountID, err := refreshTokenStore.Find(ctx, *existingToken)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	sessionID, err := refreshTokenStore.FindSessionID(ctx, *existingToken)
+	if err != nil {
+		return errors.Wrap(err, "FindSessionID")
+	}
+
+	err = refreshTokenStore.Revoke(ctx, *existingToken)
+	if err != nil {
+		return err
+	}
+
+	// apps can only identify sessions by the session IDs of their identity tokens
+	if accountID != 0 && sessionID != "" {
+		BackchannelLogout(cfg, keyStore, r, tenant, accountID, sessionID)
 	}
 	return nil
 }
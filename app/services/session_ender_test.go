@@ -1,31 +1,81 @@
 package services_test
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/logouts"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
 
 func TestSessionEnder(t *testing.T) {
+	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
 	accountID := 0
 	refreshStore := mock.NewRefreshTokenStore()
 
 	t.Run("revokes token", func(t *testing.T) {
-		token, err := refreshStore.Create(accountID)
+		token, err := refreshStore.Create(context.Background(), accountID)
 		require.NoError(t, err)
 
-		err = services.SessionEnder(refreshStore, &token)
+		err = services.SessionEnder(context.Background(), refreshStore, nil, &app.Config{}, reporter, "", &token)
 		assert.NoError(t, err)
 
-		foundID, err := refreshStore.Find(token)
+		foundID, err := refreshStore.Find(context.Background(), token)
 		assert.Empty(t, foundID)
 		assert.NoError(t, err)
 	})
 
 	t.Run("ignores missing token", func(t *testing.T) {
-		err := services.SessionEnder(refreshStore, nil)
+		err := services.SessionEnder(context.Background(), refreshStore, nil, &app.Config{}, reporter, "", nil)
 		assert.NoError(t, err)
 	})
+
+	t.Run("sends back-channel logout", func(t *testing.T) {
+		received := make(chan string, 1)
+		remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- r.FormValue("logout_token")
+		}))
+		defer remoteApp.Close()
+		serverURL, err := url.Parse(remoteApp.URL)
+		require.NoError(t, err)
+
+		key, err := private.GenerateKey(512)
+		require.NoError(t, err)
+		cfg := &app.Config{
+			AuthNURL:              &url.URL{Scheme: "http", Host: "authn.example.com"},
+			BackchannelLogoutURIs: map[string]*url.URL{"app.example.com": serverURL},
+		}
+
+		token, err := refreshStore.Create(context.Background(), 123)
+		require.NoError(t, err)
+		err = refreshStore.SetSessionID(context.Background(), token, "session-a")
+		require.NoError(t, err)
+
+		err = services.SessionEnder(context.Background(), refreshStore, mock.NewKeyStore(key), cfg, reporter, "", &token)
+		require.NoError(t, err)
+
+		select {
+		case logoutToken := <-received:
+			parsed, err := jwt.ParseSigned(logoutToken)
+			require.NoError(t, err)
+			claims := logouts.Claims{}
+			require.NoError(t, parsed.Claims(key.Public(), &claims))
+			assert.Equal(t, "123", claims.Subject)
+			assert.Equal(t, "session-a", claims.SessionID)
+		case <-time.After(time.Second):
+			assert.Fail(t, "logout token was not sent")
+		}
+	})
 }
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// SessionImpersonator creates a session for an account on behalf of an actor, like a member of
+// support staff. The session and its identity tokens carry the actor in an "act" claim and an amr of
+// "imp", and the impersonation is recorded in the audit log. It is not tracked as the user's login.
+func SessionImpersonator(
+	ctx context.Context, accountStore data.AccountStore, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, logger logrus.FieldLogger,
+	accountID int, domain string, actor string, reason string,
+) (string, string, error) {
+	actor = strings.TrimSpace(actor)
+
+	errs := FieldErrors{}
+	if actor == "" {
+		errs = append(errs, FieldError{"actor", ErrMissing})
+	}
+	audience := ""
+	for _, d := range cfg.ApplicationDomains {
+		if d.String() == domain {
+			audience = domain
+		}
+	}
+	if domain == "" {
+		errs = append(errs, FieldError{"domain", ErrMissing})
+	} else if audience == "" {
+		errs = append(errs, FieldError{"domain", ErrFormatInvalid})
+	}
+	if len(errs) > 0 {
+		return "", "", errs
+	}
+
+	account, err := accountStore.Find(ctx, accountID)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return "", "", FieldErrors{{"account", ErrNotFound}}
+	}
+	if account.Locked {
+		return "", "", FieldErrors{{"account", ErrLocked}}
+	}
+	if account.Tenant != cfg.TenantFor(audience) {
+		return "", "", FieldErrors{{"domain", ErrFormatInvalid}}
+	}
+
+	session, err := sessions.New(ctx, refreshTokenStore, cfg, accountID, audience, []string{"imp"})
+	if err != nil {
+		return "", "", errors.Wrap(err, "sessions.New")
+	}
+	session.Actor = &sessions.Actor{Subject: actor}
+	sessionToken, err := session.Sign(cfg.SessionSigningKey)
+	if err != nil {
+		return "", "", errors.Wrap(err, "session.Sign")
+	}
+
+	identityToken, err := identities.New(cfg, session, accountID, audience).Sign(keyStore.Key())
+	if err != nil {
+		return "", "", errors.Wrap(err, "identities.New")
+	}
+
+	logger.WithFields(logrus.Fields{
+		"audit":     "impersonation",
+		"actor":     actor,
+		"accountID": accountID,
+		"domain":    audience,
+		"sessionID": session.SessionID,
+		"reason":    reason,
+	}).Warn("impersonation session created")
+
+	return sessionToken, identityToken, nil
+}
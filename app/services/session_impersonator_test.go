@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionImpersonator(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	refreshStore := mock.NewRefreshTokenStore()
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(key)
+	cfg := &app.Config{
+		AuthNURL:            &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey:   []byte("key-a-reno"),
+		AccessTokenTTL:      time.Hour,
+		ApplicationDomains:  []route.Domain{{Hostname: "app.example.com"}},
+		ImpersonationMaxAge: 15 * time.Minute,
+	}
+	logger, hook := test.NewNullLogger()
+
+	account, err := accountStore.Create(context.Background(), "user@example.com", []byte("password"))
+	require.NoError(t, err)
+
+	t.Run("active account", func(t *testing.T) {
+		sessionToken, identityToken, err := services.SessionImpersonator(context.Background(), accountStore, refreshStore, keyStore, cfg, logger, account.ID, "app.example.com", "admin@example.com", "ticket 123")
+		require.NoError(t, err)
+
+		session, err := sessions.Parse(sessionToken, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"imp"}, session.AuthMethodReference)
+		if assert.NotNil(t, session.Actor) {
+			assert.Equal(t, "admin@example.com", session.Actor.Subject)
+		}
+		assert.False(t, session.Expired(cfg, time.Now()))
+		assert.True(t, session.Expired(cfg, time.Now().Add(20*time.Minute)))
+		assert.False(t, session.Fresh(0, time.Now()))
+
+		identity, err := identities.Parse(identityToken, cfg, keyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"imp"}, identity.AuthMethodReference)
+		if assert.NotNil(t, identity.Actor) {
+			assert.Equal(t, "admin@example.com", identity.Actor.Subject)
+		}
+		assert.Equal(t, session.SessionID, identity.SessionID)
+
+		entry := hook.LastEntry()
+		if assert.NotNil(t, entry) {
+			assert.Equal(t, "impersonation", entry.Data["audit"])
+			assert.Equal(t, "admin@example.com", entry.Data["actor"])
+			assert.Equal(t, account.ID, entry.Data["accountID"])
+			assert.Equal(t, "ticket 123", entry.Data["reason"])
+		}
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		_, _, err := services.SessionImpersonator(context.Background(), accountStore, refreshStore, keyStore, cfg, logger, 9999, "app.example.com", "admin@example.com", "")
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, err)
+	})
+
+	t.Run("locked account", func(t *testing.T) {
+		locked, err := accountStore.Create(context.Background(), "locked@example.com", []byte("password"))
+		require.NoError(t, err)
+		_, err = accountStore.Lock(context.Background(), locked.ID)
+		require.NoError(t, err)
+
+		_, _, err = services.SessionImpersonator(context.Background(), accountStore, refreshStore, keyStore, cfg, logger, locked.ID, "app.example.com", "admin@example.com", "")
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrLocked}}, err)
+	})
+
+	t.Run("account of another tenant", func(t *testing.T) {
+		tenantAccount, err := accountStore.Create(tenants.WithContext(context.Background(), "acme"), "user@example.com", []byte("password"))
+		require.NoError(t, err)
+
+		_, _, err = services.SessionImpersonator(context.Background(), accountStore, refreshStore, keyStore, cfg, logger, tenantAccount.ID, "app.example.com", "admin@example.com", "")
+		assert.Equal(t, services.FieldErrors{{"domain", services.ErrFormatInvalid}}, err)
+	})
+
+	failureCases := []struct {
+		domain string
+		actor  string
+		errors services.FieldErrors
+	}{
+		{"app.example.com", "", services.FieldErrors{{"actor", services.ErrMissing}}},
+		{"", "admin@example.com", services.FieldErrors{{"domain", services.ErrMissing}}},
+		{"evil.example.com", "admin@example.com", services.FieldErrors{{"domain", services.ErrFormatInvalid}}},
+	}
+	for _, fc := range failureCases {
+		t.Run(fc.domain+" "+fc.actor, func(t *testing.T) {
+			_, _, err := services.SessionImpersonator(context.Background(), accountStore, refreshStore, keyStore, cfg, logger, account.ID, fc.domain, fc.actor, "")
+			assert.Equal(t, fc.errors, err)
+		})
+	}
+}
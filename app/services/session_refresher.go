@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+	"strconv"
+
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/lib/route"
@@ -12,25 +15,45 @@ import (
 )
 
 func SessionRefresher(
-	refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, actives data.Actives, cfg *app.Config, reporter ops.ErrorReporter,
+	ctx context.Context, refreshTokenStore data.RefreshTokenStore, organizationStore data.OrganizationStore, keyStore data.KeyStore, actives data.Actives, cfg *app.Config, reporter ops.ErrorReporter,
 	session *sessions.Claims, accountID int, audience *route.Domain,
 ) (string, error) {
 	// track actives
 	if actives != nil {
-		err := actives.Track(accountID)
+		err := actives.Track(ctx, accountID)
 		if err != nil {
 			reporter.ReportError(errors.Wrap(err, "Track"))
 		}
 	}
 
 	// extend refresh token expiration
-	err := refreshTokenStore.Touch(models.RefreshToken(session.Subject), accountID)
+	err := refreshTokenStore.Touch(ctx, models.RefreshToken(session.Subject), accountID)
 	if err != nil {
 		return "", errors.Wrap(err, "Touch")
 	}
 
+	// map the session ID for introspection, including sessions that predate the mapping
+	err = refreshTokenStore.SetSessionID(ctx, models.RefreshToken(session.Subject), session.SessionID)
+	if err != nil {
+		return "", errors.Wrap(err, "SetSessionID")
+	}
+
 	// create new identity token
-	identityToken, err := identities.New(cfg, session, accountID, audience.String()).Sign(keyStore.Key())
+	identity := identities.New(cfg, session, accountID, audience.String())
+
+	// describe the active organization, unless the account has since been removed from it
+	if session.OrganizationID != 0 {
+		membership, err := organizationStore.FindMembership(ctx, session.OrganizationID, accountID)
+		if err != nil {
+			return "", errors.Wrap(err, "FindMembership")
+		}
+		if membership != nil {
+			identity.OrganizationID = strconv.Itoa(membership.OrganizationID)
+			identity.Roles = membership.Roles
+		}
+	}
+
+	identityToken, err := identity.Sign(keyStore.Key())
 	if err != nil {
 		return "", errors.Wrap(err, "New")
 	}
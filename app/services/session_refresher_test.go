@@ -1,13 +1,16 @@
 package services_test
 
 import (
+	"context"
 	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/app/tokens/identities"
 	"github.com/keratin/authn-server/app/tokens/sessions"
@@ -26,11 +29,12 @@ func TestSessionRefresher(t *testing.T) {
 		AuthNURL: &url.URL{Scheme: "http", Host: "authn.example.com"},
 	}
 	refreshStore := mock.NewRefreshTokenStore()
+	organizationStore := mock.NewOrganizationStore()
 	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
 
 	accountID := 0
 	audience := &route.Domain{Hostname: "authn.example.com", Port: "8080"}
-	session, err := sessions.New(refreshStore, cfg, accountID, audience.String(), []string{"pwd"})
+	session, err := sessions.New(context.Background(), refreshStore, cfg, accountID, audience.String(), []string{"pwd"})
 	require.NoError(t, err)
 	assert.NotEmpty(t, session.SessionID)
 
@@ -38,7 +42,7 @@ func TestSessionRefresher(t *testing.T) {
 		activesStore := mock.NewActives()
 
 		identityToken, err := services.SessionRefresher(
-			refreshStore, keyStore, activesStore, cfg, reporter,
+			context.Background(), refreshStore, organizationStore, keyStore, activesStore, cfg, reporter,
 			session, accountID, audience,
 		)
 		assert.NoError(t, err)
@@ -52,17 +56,47 @@ func TestSessionRefresher(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, session.SessionID, claims.SessionID)
 
-		report, err := activesStore.ActivesByDay()
+		report, err := activesStore.ActivesByDay(context.Background())
 		require.NoError(t, err)
 		assert.Len(t, report, 1)
 	})
 
 	t.Run("ignores actives when not configured", func(t *testing.T) {
 		identityToken, err := services.SessionRefresher(
-			refreshStore, keyStore, nil, cfg, reporter,
+			context.Background(), refreshStore, organizationStore, keyStore, nil, cfg, reporter,
 			session, accountID, audience,
 		)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, identityToken)
 	})
+
+	t.Run("embeds the active organization", func(t *testing.T) {
+		organization, err := organizationStore.Create(context.Background(), "Acme")
+		require.NoError(t, err)
+		err = organizationStore.SetMembership(context.Background(), organization.ID, accountID, models.NameList{"admin", "billing"})
+		require.NoError(t, err)
+
+		identityToken, err := services.SessionRefresher(
+			context.Background(), refreshStore, organizationStore, keyStore, nil, cfg, reporter,
+			session.WithOrganization(organization.ID), accountID, audience,
+		)
+		require.NoError(t, err)
+		claims, err := identities.Parse(identityToken, cfg, keyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(organization.ID), claims.OrganizationID)
+		assert.Equal(t, []string{"admin", "billing"}, claims.Roles)
+
+		// after the account is removed from the organization
+		_, err = organizationStore.DeleteMembership(context.Background(), organization.ID, accountID)
+		require.NoError(t, err)
+		identityToken, err = services.SessionRefresher(
+			context.Background(), refreshStore, organizationStore, keyStore, nil, cfg, reporter,
+			session.WithOrganization(organization.ID), accountID, audience,
+		)
+		require.NoError(t, err)
+		claims, err = identities.Parse(identityToken, cfg, keyStore.Keys())
+		require.NoError(t, err)
+		assert.Empty(t, claims.OrganizationID)
+		assert.Empty(t, claims.Roles)
+	})
 }
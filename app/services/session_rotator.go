@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/pkg/errors"
+)
+
+// SessionRotator replaces the session's refresh token with a new token in the same family, and
+// returns the updated session along with its signed form. A nil session indicates that the refresh
+// token had already been replaced.
+func SessionRotator(
+	ctx context.Context, refreshTokenStore data.RefreshTokenStore, cfg *app.Config,
+	session *sessions.Claims, accountID int,
+) (*sessions.Claims, string, error) {
+	token, err := refreshTokenStore.Rotate(ctx, models.RefreshToken(session.Subject), accountID)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Rotate")
+	}
+	if token == "" {
+		return nil, "", nil
+	}
+
+	rotated := session.Refreshed()
+	rotated.Subject = string(token)
+	sessionToken, err := rotated.Sign(cfg.SessionSigningKey)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Sign")
+	}
+
+	return rotated, sessionToken, nil
+}
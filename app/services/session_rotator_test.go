@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRotator(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+	}
+	refreshStore := mock.NewRefreshTokenStore()
+
+	accountID := 123
+	session, err := sessions.New(context.Background(), refreshStore, cfg, accountID, "example.com", []string{"pwd"})
+	require.NoError(t, err)
+
+	rotated, sessionToken, err := services.SessionRotator(context.Background(), refreshStore, cfg, session, accountID)
+	require.NoError(t, err)
+	require.NotNil(t, rotated)
+	assert.NotEqual(t, session.Subject, rotated.Subject)
+	assert.Equal(t, session.SessionID, rotated.SessionID)
+	assert.Equal(t, session.IssuedAt, rotated.IssuedAt)
+
+	parsed, err := sessions.Parse(sessionToken, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.Subject, parsed.Subject)
+
+	found, err := refreshStore.Find(context.Background(), models.RefreshToken(rotated.Subject))
+	require.NoError(t, err)
+	assert.Equal(t, accountID, found)
+
+	t.Run("with a replaced token", func(t *testing.T) {
+		rotated, sessionToken, err := services.SessionRotator(context.Background(), refreshStore, cfg, session, accountID)
+		require.NoError(t, err)
+		assert.Nil(t, rotated)
+		assert.Empty(t, sessionToken)
+	})
+}
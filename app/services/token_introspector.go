@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/pkg/errors"
+)
+
+// Introspection describes a token as specified by RFC 7662. Only active tokens are described.
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Subject   string `json:"sub,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	Expiry    int64  `json:"exp,omitempty"`
+
+	// tenant of the session, for back-channel logouts
+	tenant string
+}
+
+// TokenIntrospector reports whether a session token or identity token belongs to a live session.
+// Personal access tokens, and identity tokens exchanged for them, are active while the personal
+// access token is. Tokens that can not be parsed are inactive rather than invalid.
+func TokenIntrospector(
+	ctx context.Context, accountStore data.AccountStore, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config,
+	token string,
+) (*Introspection, error) {
+	if strings.HasPrefix(token, personalAccessTokenPrefix) {
+		pat, err := accountStore.FindPersonalAccessToken(ctx, hashPersonalAccessToken(token))
+		if err != nil {
+			return nil, errors.Wrap(err, "FindPersonalAccessToken")
+		}
+		introspection := &Introspection{}
+		if pat != nil && pat.ExpiresAt != nil {
+			introspection.Expiry = pat.ExpiresAt.Unix()
+		}
+		return introspectPersonalAccessToken(ctx, accountStore, pat, introspection)
+	}
+
+	refreshToken, patID, introspection := parseIntrospectable(cfg, keyStore, token)
+	if patID != 0 {
+		accountID, err := strconv.Atoi(introspection.Subject)
+		if err != nil {
+			return &Introspection{Active: false}, nil
+		}
+		pats, err := accountStore.GetPersonalAccessTokens(ctx, accountID)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetPersonalAccessTokens")
+		}
+		var pat *models.PersonalAccessToken
+		for _, p := range pats {
+			if p.ID == patID {
+				pat = p
+			}
+		}
+		return introspectPersonalAccessToken(ctx, accountStore, pat, introspection)
+	}
+	if refreshToken == "" && introspection.SessionID != "" {
+		found, err := refreshTokenStore.FindBySessionID(ctx, introspection.SessionID)
+		if err != nil {
+			return nil, errors.Wrap(err, "FindBySessionID")
+		}
+		refreshToken = found
+	}
+	if refreshToken == "" {
+		return &Introspection{Active: false}, nil
+	}
+
+	accountID, err := refreshTokenStore.Find(ctx, refreshToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "Find")
+	}
+	if accountID == 0 {
+		return &Introspection{Active: false}, nil
+	}
+	if introspection.Subject != "" && introspection.Subject != strconv.Itoa(accountID) {
+		return &Introspection{Active: false}, nil
+	}
+
+	introspection.Active = true
+	introspection.Subject = strconv.Itoa(accountID)
+	return introspection, nil
+}
+
+// introspectPersonalAccessToken completes the introspection of a personal access token, which is
+// active until it expires or is deleted and while its account is in good standing.
+func introspectPersonalAccessToken(
+	ctx context.Context, accountStore data.AccountStore, pat *models.PersonalAccessToken, introspection *Introspection,
+) (*Introspection, error) {
+	if pat == nil || pat.Expired(time.Now()) {
+		return &Introspection{Active: false}, nil
+	}
+	account, err := accountStore.Find(ctx, pat.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() || account.Locked {
+		return &Introspection{Active: false}, nil
+	}
+
+	introspection.Active = true
+	introspection.Subject = strconv.Itoa(pat.AccountID)
+	return introspection, nil
+}
+
+// parseIntrospectable reads a session token or identity token. Session tokens know their refresh
+// token, while identity tokens must be looked up by their session ID or personal access token ID.
+func parseIntrospectable(cfg *app.Config, keyStore data.KeyStore, token string) (models.RefreshToken, int, *Introspection) {
+	if session, err := sessions.Parse(token, cfg); err == nil {
+		if session.Expired(cfg, time.Now()) {
+			return "", 0, &Introspection{}
+		}
+		return models.RefreshToken(session.Subject), 0, &Introspection{SessionID: session.SessionID, tenant: cfg.TenantFor(session.Azp)}
+	}
+
+	if identity, err := identities.Parse(token, cfg, keyStore.Keys()); err == nil {
+		introspection := &Introspection{Subject: identity.Subject, SessionID: identity.SessionID, tenant: identity.Tenant}
+		if identity.Expiry != nil {
+			introspection.Expiry = int64(*identity.Expiry)
+		}
+		return "", identity.PersonalAccessTokenID, introspection
+	}
+
+	return "", 0, &Introspection{}
+}
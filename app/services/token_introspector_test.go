@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenIntrospector(t *testing.T) {
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(key)
+	cfg := &app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+		AccessTokenTTL:    time.Hour,
+	}
+	refreshStore := mock.NewRefreshTokenStore()
+	accountStore := mock.NewAccountStore()
+
+	newSession := func(accountID int) (*sessions.Claims, string, string) {
+		session, err := sessions.New(context.Background(), refreshStore, cfg, accountID, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+		sessionToken, err := session.Sign(cfg.SessionSigningKey)
+		require.NoError(t, err)
+		identityToken, err := identities.New(cfg, session, accountID, "example.com").Sign(key)
+		require.NoError(t, err)
+		return session, sessionToken, identityToken
+	}
+
+	t.Run("session token", func(t *testing.T) {
+		session, sessionToken, _ := newSession(123)
+
+		introspection, err := services.TokenIntrospector(context.Background(), accountStore, refreshStore, keyStore, cfg, sessionToken)
+		require.NoError(t, err)
+		assert.Equal(t, &services.Introspection{Active: true, Subject: "123", SessionID: session.SessionID}, introspection)
+	})
+
+	t.Run("identity token", func(t *testing.T) {
+		session, _, identityToken := newSession(123)
+
+		introspection, err := services.TokenIntrospector(context.Background(), accountStore, refreshStore, keyStore, cfg, identityToken)
+		require.NoError(t, err)
+		assert.True(t, introspection.Active)
+		assert.Equal(t, "123", introspection.Subject)
+		assert.Equal(t, session.SessionID, introspection.SessionID)
+		assert.NotEmpty(t, introspection.Expiry)
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		session, sessionToken, identityToken := newSession(123)
+		err := refreshStore.Revoke(context.Background(), models.RefreshToken(session.Subject))
+		require.NoError(t, err)
+
+		for _, token := range []string{sessionToken, identityToken} {
+			introspection, err := services.TokenIntrospector(context.Background(), accountStore, refreshStore, keyStore, cfg, token)
+			require.NoError(t, err)
+			assert.Equal(t, &services.Introspection{Active: false}, introspection)
+		}
+	})
+
+	t.Run("personal access token", func(t *testing.T) {
+		account, err := accountStore.Create(context.Background(), "pat@example.com", []byte("password"))
+		require.NoError(t, err)
+		pat, token, err := services.PersonalAccessTokenCreator(context.Background(), accountStore, cfg, account.ID, "https://app.example.com", "cli", "", 0)
+		require.NoError(t, err)
+		identityToken, err := services.PersonalAccessTokenExchanger(context.Background(), accountStore, keyStore, cfg, token)
+		require.NoError(t, err)
+
+		for _, tok := range []string{token, identityToken} {
+			introspection, err := services.TokenIntrospector(context.Background(), accountStore, refreshStore, keyStore, cfg, tok)
+			require.NoError(t, err)
+			assert.True(t, introspection.Active)
+			assert.Equal(t, strconv.Itoa(account.ID), introspection.Subject)
+		}
+
+		_, err = accountStore.DeletePersonalAccessToken(context.Background(), account.ID, pat.ID)
+		require.NoError(t, err)
+		for _, tok := range []string{token, identityToken} {
+			introspection, err := services.TokenIntrospector(context.Background(), accountStore, refreshStore, keyStore, cfg, tok)
+			require.NoError(t, err)
+			assert.Equal(t, &services.Introspection{Active: false}, introspection)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		introspection, err := services.TokenIntrospector(context.Background(), accountStore, refreshStore, keyStore, cfg, "not.a.token")
+		require.NoError(t, err)
+		assert.Equal(t, &services.Introspection{Active: false}, introspection)
+	})
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// TokenRevoker ends the session of a session token or identity token, as specified by RFC 7009.
+// Tokens that are unknown or already inactive are ignored.
+func TokenRevoker(
+	ctx context.Context, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, r ops.ErrorReporter,
+	token string,
+) error {
+	refreshToken, _, introspection := parseIntrospectable(cfg, keyStore, token)
+	if refreshToken == "" && introspection.SessionID != "" {
+		found, err := refreshTokenStore.FindBySessionID(ctx, introspection.SessionID)
+		if err != nil {
+			return errors.Wrap(err, "FindBySessionID")
+		}
+		refreshToken = found
+	}
+	if refreshToken == "" {
+		return nil
+	}
+
+	return SessionEnder(ctx, refreshTokenStore, keyStore, cfg, r, introspection.tenant, &refreshToken)
+}
@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRevoker(t *testing.T) {
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(key)
+	cfg := &app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+		AccessTokenTTL:    time.Hour,
+	}
+	refreshStore := mock.NewRefreshTokenStore()
+
+	t.Run("session token", func(t *testing.T) {
+		session, err := sessions.New(context.Background(), refreshStore, cfg, 123, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+		sessionToken, err := session.Sign(cfg.SessionSigningKey)
+		require.NoError(t, err)
+
+		err = services.TokenRevoker(context.Background(), refreshStore, keyStore, cfg, &ops.LogReporter{FieldLogger: logrus.New()}, sessionToken)
+		require.NoError(t, err)
+
+		found, err := refreshStore.Find(context.Background(), models.RefreshToken(session.Subject))
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("identity token", func(t *testing.T) {
+		session, err := sessions.New(context.Background(), refreshStore, cfg, 123, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+		identityToken, err := identities.New(cfg, session, 123, "example.com").Sign(key)
+		require.NoError(t, err)
+
+		err = services.TokenRevoker(context.Background(), refreshStore, keyStore, cfg, &ops.LogReporter{FieldLogger: logrus.New()}, identityToken)
+		require.NoError(t, err)
+
+		found, err := refreshStore.Find(context.Background(), models.RefreshToken(session.Subject))
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		err := services.TokenRevoker(context.Background(), refreshStore, keyStore, cfg, &ops.LogReporter{FieldLogger: logrus.New()}, "not.a.token")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// TOTPAuthenticatorDeleter removes one TOTP authenticator from the specified account. The last
+// authenticator may not be removed if the MFA policy applies to the account. Any trusted devices
+// are revoked along with the authenticator.
+func TOTPAuthenticatorDeleter(ctx context.Context, accountStore data.AccountStore, trustedDeviceStore data.TrustedDeviceStore, cfg *app.Config, accountID int, authenticatorID int) error {
+	account, err := AccountGetter(ctx, accountStore, accountID)
+	if err != nil {
+		return err
+	}
+	if account.TOTPAuthenticators == 1 && MFARequired(cfg, account) {
+		return FieldErrors{{"totp", ErrRequired}}
+	}
+
+	deleted, err := accountStore.DeleteTOTPAuthenticator(ctx, accountID, authenticatorID)
+	if err != nil {
+		return errors.Wrap(err, "DeleteTOTPAuthenticator")
+	}
+	if !deleted {
+		return FieldErrors{{"authenticator", ErrNotFound}}
+	}
+
+	err = trustedDeviceStore.RevokeAll(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "RevokeAll")
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPAuthenticatorDeleter(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	trustedDeviceStore := mock.NewTrustedDeviceStore(time.Hour)
+	cfg := &app.Config{MFARequired: true}
+
+	account, err := accountStore.Create(context.Background(), "test user", []byte("password"))
+	require.NoError(t, err)
+	phone, err := accountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("test"), 0)
+	require.NoError(t, err)
+	tablet, err := accountStore.AddTOTPAuthenticator(context.Background(), account.ID, "tablet", []byte("test"), 0)
+	require.NoError(t, err)
+
+	t.Run("no account", func(t *testing.T) {
+		deleteErr := services.TOTPAuthenticatorDeleter(context.Background(), accountStore, trustedDeviceStore, cfg, 0, phone.ID)
+		assert.Error(t, deleteErr)
+	})
+
+	t.Run("unknown authenticator", func(t *testing.T) {
+		deleteErr := services.TOTPAuthenticatorDeleter(context.Background(), accountStore, trustedDeviceStore, cfg, account.ID, 9999)
+		assert.Equal(t, services.FieldErrors{{"authenticator", services.ErrNotFound}}, deleteErr)
+	})
+
+	t.Run("one of several", func(t *testing.T) {
+		_, err := trustedDeviceStore.Create(context.Background(), account.ID, "laptop")
+		require.NoError(t, err)
+
+		deleteErr := services.TOTPAuthenticatorDeleter(context.Background(), accountStore, trustedDeviceStore, cfg, account.ID, phone.ID)
+		assert.NoError(t, deleteErr)
+
+		devices, err := trustedDeviceStore.FindAll(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+	})
+
+	t.Run("last one required by policy", func(t *testing.T) {
+		deleteErr := services.TOTPAuthenticatorDeleter(context.Background(), accountStore, trustedDeviceStore, cfg, account.ID, tablet.ID)
+		assert.Equal(t, services.FieldErrors{{"totp", services.ErrRequired}}, deleteErr)
+
+		after, err := accountStore.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.True(t, after.TOTPEnabled())
+	})
+}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/pkg/errors"
@@ -8,29 +11,27 @@ import (
 	"github.com/pquerna/otp/totp"
 )
 
-var ErrExistingTOTPSecret = errors.New("a OTP secret has already been established for this account")
+var ErrExistingTOTPSecret = errors.New("a OTP channel has already been established for this account")
 
-// TOTPCreator handles the creation and storage of new OTP tokens
-func TOTPCreator(accountStore data.AccountStore, totpCache data.TOTPCache, accountID int, audience *route.Domain) (*otp.Key, error) {
-	account, err := AccountGetter(accountStore, accountID)
+// TOTPCreator handles the creation and storage of new OTP tokens. An account may have several TOTP
+// authenticators, but not alongside an OTP channel.
+func TOTPCreator(ctx context.Context, accountStore data.AccountStore, totpCache data.TOTPCache, cfg *app.Config, accountID int, audience *route.Domain) (*otp.Key, error) {
+	account, err := AccountGetter(ctx, accountStore, accountID)
 	if err != nil {
 		return nil, err
 	}
 
-	if account.TOTPEnabled() {
+	if account.OTPChannelEnabled() {
 		return nil, ErrExistingTOTPSecret
 	}
 
 	//Generate totp key
-	key, err := totp.Generate(totp.GenerateOpts{
-		Issuer:      audience.Hostname,
-		AccountName: account.Username,
-	})
+	key, err := totp.Generate(totpGenerateOpts(cfg, audience, account.Username))
 	if err != nil {
 		return nil, err
 	}
 
-	if err := totpCache.CacheTOTPSecret(account.ID, []byte(key.Secret())); err != nil {
+	if err := totpCache.CacheTOTPSecret(ctx, account.ID, []byte(key.Secret())); err != nil {
 		return nil, errors.Wrap(err, "TOTPCreator")
 	}
 
@@ -1,12 +1,17 @@
 package services_test
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/lib/route"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -14,8 +19,9 @@ import (
 func TestTOTPCreator(t *testing.T) {
 	accountStore := mock.NewAccountStore()
 	totpCache := mock.NewTOTPCache(0)
+	cfg := &app.Config{}
 
-	account, err := accountStore.Create("test user", []byte("password"))
+	account, err := accountStore.Create(context.Background(), "test user", []byte("password"))
 	require.NoError(t, err)
 
 	audience := &route.Domain{
@@ -24,25 +30,35 @@ func TestTOTPCreator(t *testing.T) {
 	}
 
 	t.Run("no account", func(t *testing.T) {
-		key, createErr := services.TOTPCreator(accountStore, totpCache, 0, audience)
+		key, createErr := services.TOTPCreator(context.Background(), accountStore, totpCache, cfg, 0, audience)
 		assert.Nil(t, key)
 		assert.Error(t, createErr)
 	})
 
 	t.Run("account exists", func(t *testing.T) {
-		key, createErr := services.TOTPCreator(accountStore, totpCache, account.ID, audience)
+		key, createErr := services.TOTPCreator(context.Background(), accountStore, totpCache, cfg, account.ID, audience)
 		require.NoError(t, createErr)
 		require.NotNil(t, key)
-		gotKey, gotErr := totpCache.LoadTOTPSecret(account.ID)
+		gotKey, gotErr := totpCache.LoadTOTPSecret(context.Background(), account.ID)
 		assert.Nil(t, gotErr)
 		assert.Equal(t, string(gotKey), key.Secret())
 
 		t.Run("already enrolled", func(t *testing.T) {
-			set, setErr := accountStore.SetTOTPSecret(account.ID, []byte(key.Secret()))
+			set, setErr := accountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte(key.Secret()), 0)
+			require.NotNil(t, set)
+			require.NoError(t, setErr)
+
+			another, createErr := services.TOTPCreator(context.Background(), accountStore, totpCache, cfg, account.ID, audience)
+			require.NoError(t, createErr)
+			assert.NotNil(t, another)
+		})
+
+		t.Run("otp channel established", func(t *testing.T) {
+			set, setErr := accountStore.SetOTPChannel(context.Background(), account.ID, services.OTPChannelEmail)
 			require.True(t, set)
 			require.NoError(t, setErr)
 
-			key, createErr = services.TOTPCreator(accountStore, totpCache, account.ID, audience)
+			key, createErr = services.TOTPCreator(context.Background(), accountStore, totpCache, cfg, account.ID, audience)
 			assert.Nil(t, key)
 			assert.Error(t, createErr)
 			assert.True(t, errors.Is(createErr, services.ErrExistingTOTPSecret))
@@ -50,8 +66,39 @@ func TestTOTPCreator(t *testing.T) {
 	})
 
 	t.Run("account exists - cache error", func(t *testing.T) {
-		key, createErr := services.TOTPCreator(accountStore, mock.NewTOTPCache(account.ID), account.ID, audience)
+		key, createErr := services.TOTPCreator(context.Background(), accountStore, mock.NewTOTPCache(account.ID), cfg, account.ID, audience)
 		assert.Error(t, createErr)
 		assert.Nil(t, key)
 	})
 }
+
+func TestTOTPCreatorWithCustomParameters(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	totpCache := mock.NewTOTPCache(0)
+	cfg := &app.Config{
+		TOTPIssuer:      "Example",
+		TOTPDigits:      8,
+		TOTPPeriod:      60 * time.Second,
+		TOTPAlgorithm:   "SHA256",
+		DBEncryptionKey: []byte("XXXXXXXXXXXXXXXX"),
+	}
+
+	account, err := accountStore.Create(context.Background(), "test user", []byte("password"))
+	require.NoError(t, err)
+
+	key, err := services.TOTPCreator(context.Background(), accountStore, totpCache, cfg, account.ID, &route.Domain{Hostname: "testhost"})
+	require.NoError(t, err)
+	assert.Equal(t, "Example", key.Issuer())
+	assert.Contains(t, key.URL(), "digits=8")
+	assert.Contains(t, key.URL(), "period=60")
+	assert.Contains(t, key.URL(), "algorithm=SHA256")
+
+	code, err := totp.GenerateCodeCustom(key.Secret(), time.Now(), totp.ValidateOpts{
+		Period:    60,
+		Digits:    otp.DigitsEight,
+		Algorithm: otp.AlgorithmSHA256,
+	})
+	require.NoError(t, err)
+	err = services.TOTPSetter(context.Background(), accountStore, mock.NewTrustedDeviceStore(time.Hour), totpCache, cfg, account.ID, code, "")
+	assert.NoError(t, err)
+}
@@ -1,14 +1,26 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/pkg/errors"
 )
 
-// TOTPDeleter removes OTP from the specified account
-func TOTPDeleter(accountStore data.AccountStore, accountID int) error {
-	//Delete totp secret in database
-	affected, err := accountStore.DeleteTOTPSecret(accountID)
+// TOTPDeleter removes every TOTP authenticator from the specified account, unless the MFA policy
+// applies to it. Any trusted devices are revoked along with the factor.
+func TOTPDeleter(ctx context.Context, accountStore data.AccountStore, trustedDeviceStore data.TrustedDeviceStore, cfg *app.Config, accountID int) error {
+	account, err := AccountGetter(ctx, accountStore, accountID)
+	if err != nil {
+		return err
+	}
+	if MFARequired(cfg, account) {
+		return FieldErrors{{"totp", ErrRequired}}
+	}
+
+	//Delete totp secrets in database
+	affected, err := accountStore.DeleteTOTPAuthenticators(ctx, accountID)
 	if err != nil {
 		return errors.Wrap(err, "TOTPDeleter")
 	}
@@ -16,5 +28,10 @@ func TOTPDeleter(accountStore data.AccountStore, accountID int) error {
 		return errors.New("unable to delete totp secret")
 	}
 
+	err = trustedDeviceStore.RevokeAll(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "RevokeAll")
+	}
+
 	return nil
 }
@@ -1,8 +1,11 @@
 package services_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/stretchr/testify/assert"
@@ -11,25 +14,47 @@ import (
 
 func TestTOTPDeleter(t *testing.T) {
 	accountStore := mock.NewAccountStore()
+	trustedDeviceStore := mock.NewTrustedDeviceStore(time.Hour)
+	cfg := &app.Config{}
 
-	account, err := accountStore.Create("test user", []byte("password"))
+	account, err := accountStore.Create(context.Background(), "test user", []byte("password"))
 	require.NoError(t, err)
 
 	t.Run("no account", func(t *testing.T) {
-		deleteErr := services.TOTPDeleter(accountStore, 0)
+		deleteErr := services.TOTPDeleter(context.Background(), accountStore, trustedDeviceStore, cfg, 0)
 		assert.Error(t, deleteErr)
 	})
 	t.Run("no secret", func(t *testing.T) {
-		deleteErr := services.TOTPDeleter(accountStore, account.ID)
+		deleteErr := services.TOTPDeleter(context.Background(), accountStore, trustedDeviceStore, cfg, account.ID)
 		assert.Error(t, deleteErr)
 	})
 
 	t.Run("secret", func(t *testing.T) {
-		set, setErr := accountStore.SetTOTPSecret(account.ID, []byte("test"))
-		assert.True(t, set)
+		set, setErr := accountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("test"), 0)
+		assert.NotNil(t, set)
 		assert.NoError(t, setErr)
 
-		deleteErr := services.TOTPDeleter(accountStore, account.ID)
+		_, err := trustedDeviceStore.Create(context.Background(), account.ID, "laptop")
+		require.NoError(t, err)
+
+		deleteErr := services.TOTPDeleter(context.Background(), accountStore, trustedDeviceStore, cfg, account.ID)
 		assert.NoError(t, deleteErr)
+
+		devices, err := trustedDeviceStore.FindAll(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+	})
+
+	t.Run("required by policy", func(t *testing.T) {
+		set, setErr := accountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("test"), 0)
+		assert.NotNil(t, set)
+		assert.NoError(t, setErr)
+
+		deleteErr := services.TOTPDeleter(context.Background(), accountStore, trustedDeviceStore, &app.Config{MFARequired: true}, account.ID)
+		assert.Equal(t, services.FieldErrors{{"totp", services.ErrRequired}}, deleteErr)
+
+		after, err := accountStore.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.True(t, after.TOTPEnabled())
 	})
 }
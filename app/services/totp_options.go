@@ -0,0 +1,73 @@
+package services
+
+import (
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/hotp"
+	"github.com/pquerna/otp/totp"
+)
+
+// totpSkew is how many time steps before or after the current one are accepted, to allow for
+// clock drift on the authenticator.
+const totpSkew = 1
+
+// totpGenerateOpts returns the parameters for a new TOTP secret. The issuer defaults to the
+// audience's hostname.
+func totpGenerateOpts(cfg *app.Config, audience *route.Domain, accountName string) totp.GenerateOpts {
+	opts := totpValidateOpts(cfg)
+	issuer := cfg.TOTPIssuer
+	if issuer == "" {
+		issuer = audience.Hostname
+	}
+	return totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: accountName,
+		Period:      opts.Period,
+		Digits:      opts.Digits,
+		Algorithm:   opts.Algorithm,
+	}
+}
+
+// totpValidateOpts returns the configured TOTP parameters, with the library defaults for any that
+// are unset.
+func totpValidateOpts(cfg *app.Config) totp.ValidateOpts {
+	opts := totp.ValidateOpts{
+		Period:    30,
+		Skew:      totpSkew,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	if cfg.TOTPPeriod >= time.Second {
+		opts.Period = uint(cfg.TOTPPeriod / time.Second)
+	}
+	if cfg.TOTPDigits == 8 {
+		opts.Digits = otp.DigitsEight
+	}
+	switch cfg.TOTPAlgorithm {
+	case "SHA256":
+		opts.Algorithm = otp.AlgorithmSHA256
+	case "SHA512":
+		opts.Algorithm = otp.AlgorithmSHA512
+	}
+	return opts
+}
+
+// totpStep finds the time step that the code was generated for. Codes from neighboring time steps
+// are accepted within the allowed skew.
+func totpStep(cfg *app.Config, code string, secret string, now time.Time) (int64, bool) {
+	opts := totpValidateOpts(cfg)
+	current := now.Unix() / int64(opts.Period)
+	for _, step := range []int64{current, current - totpSkew, current + totpSkew} {
+		valid, err := hotp.ValidateCustom(code, uint64(step), secret, hotp.ValidateOpts{
+			Digits:    opts.Digits,
+			Algorithm: opts.Algorithm,
+		})
+		if err == nil && valid {
+			return step, true
+		}
+	}
+	return 0, false
+}
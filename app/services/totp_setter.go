@@ -1,49 +1,63 @@
 package services
 
 import (
+	"context"
+	"time"
+
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
-	"github.com/keratin/authn-server/lib/compat"
 	"github.com/pkg/errors"
-	"github.com/pquerna/otp/totp"
 )
 
-// TOTPSetter persists the OTP secret to the accountID if code is correct
-func TOTPSetter(accountStore data.AccountStore, totpCache data.TOTPCache, cfg *app.Config, accountID int, code string) error {
+// defaultTOTPAuthenticatorName is used when an authenticator is not named
+const defaultTOTPAuthenticatorName = "Authenticator"
+
+// TOTPSetter adds the OTP secret to the accountID as a named authenticator if code is correct. Any
+// trusted devices are revoked, since they were trusted for the previous factors.
+func TOTPSetter(ctx context.Context, accountStore data.AccountStore, trustedDeviceStore data.TrustedDeviceStore, totpCache data.TOTPCache, cfg *app.Config, accountID int, code string, name string) error {
 	if code == "" { //Fail early if code is empty
 		return FieldErrors{{"otp", ErrInvalidOrExpired}}
 	}
 
-	account, err := AccountGetter(accountStore, accountID)
+	account, err := AccountGetter(ctx, accountStore, accountID)
 	if err != nil {
 		return err
 	}
 
-	secret, err := totpCache.LoadTOTPSecret(account.ID)
+	secret, err := totpCache.LoadTOTPSecret(ctx, account.ID)
 	if err != nil { //Error with redis itself
 		return err
 	}
 
-	if !totp.Validate(code, string(secret)) { //Either cache expiry or validation error
+	step, valid := totpStep(cfg, code, string(secret), time.Now())
+	if !valid { //Either cache expiry or validation error
 		return FieldErrors{{"otp", ErrInvalidOrExpired}}
 	}
 
-	secret, err = compat.Encrypt(secret, cfg.DBEncryptionKey)
+	secret, err = cfg.DBEncryptionKeyring().Encrypt(secret)
 	if err != nil {
 		return err
 	}
 
-	//Persist totp secret that was loaded from cache to db
-	affected, err := accountStore.SetTOTPSecret(accountID, secret)
+	if name == "" {
+		name = defaultTOTPAuthenticatorName
+	} else if len(name) > maxDeviceNameLength {
+		name = name[:maxDeviceNameLength]
+	}
+
+	//Persist totp secret that was loaded from cache to db, so that the confirmation code can not be replayed
+	_, err = accountStore.AddTOTPAuthenticator(ctx, accountID, name, secret, step)
 	if err != nil {
 		return errors.Wrap(err, "TOTPSetter")
 	}
-	if !affected {
-		return errors.New("unable to set totp secret")
+
+	err = trustedDeviceStore.RevokeAll(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "RevokeAll")
 	}
 
 	// error here is not end of world it should timeout
-	_ = totpCache.RemoveTOTPSecret(account.ID)
+	_ = totpCache.RemoveTOTPSecret(ctx, account.ID)
 
 	return nil
 }
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -16,21 +17,22 @@ import (
 func TestTOTPSetter(t *testing.T) {
 	accountStore := mock.NewAccountStore(mock.WithSetTOTPFailureID(1))
 	// create first so it gets the "failure ID" of 1
-	failSetAccount, err := accountStore.Create("bad secret user", []byte("password"))
+	failSetAccount, err := accountStore.Create(context.Background(), "bad secret user", []byte("password"))
 	require.NoError(t, err)
-	account, err := accountStore.Create("test user", []byte("password"))
+	account, err := accountStore.Create(context.Background(), "test user", []byte("password"))
 	require.NoError(t, err)
-	noSecretAccount, err := accountStore.Create("bad user", []byte("password"))
+	noSecretAccount, err := accountStore.Create(context.Background(), "bad user", []byte("password"))
 	require.NoError(t, err)
 
 	totpCache := mock.NewTOTPCache(noSecretAccount.ID)
+	trustedDeviceStore := mock.NewTrustedDeviceStore(time.Hour)
 	// nolint: gosec
 	totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
-	require.NoError(t, totpCache.CacheTOTPSecret(account.ID, []byte(totpSecret)))
-	require.NoError(t, totpCache.CacheTOTPSecret(failSetAccount.ID, []byte(totpSecret)))
+	require.NoError(t, totpCache.CacheTOTPSecret(context.Background(), account.ID, []byte(totpSecret)))
+	require.NoError(t, totpCache.CacheTOTPSecret(context.Background(), failSetAccount.ID, []byte(totpSecret)))
 
 	t.Run("no code", func(t *testing.T) {
-		setErr := services.TOTPSetter(nil, nil, nil, 0, "")
+		setErr := services.TOTPSetter(context.Background(), nil, nil, nil, nil, 0, "", "")
 		assert.Error(t, setErr)
 
 		var v services.FieldErrors
@@ -43,12 +45,12 @@ func TestTOTPSetter(t *testing.T) {
 	})
 
 	t.Run("no account", func(t *testing.T) {
-		setErr := services.TOTPSetter(accountStore, nil, nil, 0, "")
+		setErr := services.TOTPSetter(context.Background(), accountStore, trustedDeviceStore, nil, nil, 0, "", "")
 		assert.Error(t, setErr)
 	})
 
 	t.Run("no secret in cache", func(t *testing.T) {
-		setErr := services.TOTPSetter(accountStore, totpCache, nil, noSecretAccount.ID, "xxx")
+		setErr := services.TOTPSetter(context.Background(), accountStore, trustedDeviceStore, totpCache, nil, noSecretAccount.ID, "xxx", "")
 		assert.Error(t, setErr)
 	})
 
@@ -56,7 +58,7 @@ func TestTOTPSetter(t *testing.T) {
 		code, generateErr := totp.GenerateCode(totpSecret, time.Now())
 		require.NoError(t, generateErr)
 		// Invalid key length will cause error
-		setErr := services.TOTPSetter(accountStore, totpCache, &app.Config{DBEncryptionKey: []byte("XXX")}, account.ID, code)
+		setErr := services.TOTPSetter(context.Background(), accountStore, trustedDeviceStore, totpCache, &app.Config{DBEncryptionKey: []byte("XXX")}, account.ID, code, "")
 		assert.Error(t, setErr)
 	})
 
@@ -64,34 +66,50 @@ func TestTOTPSetter(t *testing.T) {
 		code, generateErr := totp.GenerateCode(totpSecret, time.Now())
 		require.NoError(t, generateErr)
 		// Invalid key length will cause error
-		setErr := services.TOTPSetter(accountStore, totpCache, &app.Config{DBEncryptionKey: []byte("XXXXXXXXXXXXXXXX")}, failSetAccount.ID, code)
+		setErr := services.TOTPSetter(context.Background(), accountStore, trustedDeviceStore, totpCache, &app.Config{DBEncryptionKey: []byte("XXXXXXXXXXXXXXXX")}, failSetAccount.ID, code, "")
 		assert.Error(t, setErr)
 	})
 
 	t.Run("happy", func(t *testing.T) {
+		_, err := trustedDeviceStore.Create(context.Background(), account.ID, "laptop")
+		require.NoError(t, err)
 		code, generateErr := totp.GenerateCode(totpSecret, time.Now())
 		require.NoError(t, generateErr)
-		setErr := services.TOTPSetter(accountStore, totpCache, &app.Config{DBEncryptionKey: []byte("XXXXXXXXXXXXXXXX")}, account.ID, code)
+		setErr := services.TOTPSetter(context.Background(), accountStore, trustedDeviceStore, totpCache, &app.Config{DBEncryptionKey: []byte("XXXXXXXXXXXXXXXX")}, account.ID, code, "phone")
 		assert.NoError(t, setErr)
 
-		cachedSecret, checkErr := totpCache.LoadTOTPSecret(account.ID)
+		// devices trusted before the factor changed must complete it again
+		devices, err := trustedDeviceStore.FindAll(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+
+		cachedSecret, checkErr := totpCache.LoadTOTPSecret(context.Background(), account.ID)
 		assert.NoError(t, checkErr)
 		assert.Nil(t, cachedSecret)
 
-		t.Run("set unaffected", func(t *testing.T) {
-			// re-cache the secret - we want this to try to set secret again
-			require.NoError(t, totpCache.CacheTOTPSecret(account.ID, []byte(totpSecret)))
-			// the mock account store is coded internally to return "unaffected" from SetTOTPSecret
-			// if it receives the secret already set on the account found from lookup.
-			// So if we try to set the same secret again we should get an error.
-			setErr = services.TOTPSetter(accountStore, totpCache, &app.Config{DBEncryptionKey: []byte("XXXXXXXXXXXXXXXX")}, account.ID, code)
-			assert.Error(t, setErr)
+		// the confirmation code may not be used again
+		authenticators, err := accountStore.GetTOTPAuthenticators(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Len(t, authenticators, 1)
+		assert.NotZero(t, authenticators[0].LastUsedStep)
+		ok, err := accountStore.SetTOTPAuthenticatorLastUsed(context.Background(), account.ID, authenticators[0].ID, authenticators[0].LastUsedStep)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		t.Run("another authenticator", func(t *testing.T) {
+			require.NoError(t, totpCache.CacheTOTPSecret(context.Background(), account.ID, []byte(totpSecret)))
+			setErr = services.TOTPSetter(context.Background(), accountStore, trustedDeviceStore, totpCache, &app.Config{DBEncryptionKey: []byte("XXXXXXXXXXXXXXXX")}, account.ID, code, "")
+			assert.NoError(t, setErr)
 
-			cachedSecret, checkErr = totpCache.LoadTOTPSecret(account.ID)
-			assert.NoError(t, checkErr)
-			assert.NotNil(t, cachedSecret)
+			authenticators, err := accountStore.GetTOTPAuthenticators(context.Background(), account.ID)
+			require.NoError(t, err)
+			require.Len(t, authenticators, 2)
+			assert.Equal(t, "phone", authenticators[0].Name)
+			assert.Equal(t, "Authenticator", authenticators[1].Name)
 
-			assert.NoError(t, totpCache.RemoveTOTPSecret(account.ID))
+			found, err := accountStore.Find(context.Background(), account.ID)
+			require.NoError(t, err)
+			assert.True(t, found.TOTPEnabled())
 		})
 	})
 }
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/devices"
+	"github.com/pkg/errors"
+)
+
+// maxDeviceNameLength keeps user agents from filling the store
+const maxDeviceNameLength = 255
+
+// TrustedDeviceCreator trusts a browser to skip the second factor for the account, and returns a
+// token that the browser must present to do so.
+func TrustedDeviceCreator(ctx context.Context, store data.TrustedDeviceStore, cfg *app.Config, accountID int, name string) (string, error) {
+	if len(name) > maxDeviceNameLength {
+		name = name[:maxDeviceNameLength]
+	}
+
+	device, err := store.Create(ctx, accountID, name)
+	if err != nil {
+		return "", errors.Wrap(err, "Create")
+	}
+
+	token, err := devices.New(cfg, device).Sign(cfg.TrustedDeviceSigningKey)
+	if err != nil {
+		return "", errors.Wrap(err, "Sign")
+	}
+	return token, nil
+}
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+func TrustedDeviceRevoker(ctx context.Context, store data.TrustedDeviceStore, accountID int, deviceID string) error {
+	revoked, err := store.Revoke(ctx, accountID, deviceID)
+	if err != nil {
+		return errors.Wrap(err, "Revoke")
+	}
+	if !revoked {
+		return FieldErrors{{"device", ErrNotFound}}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedDeviceRevoker(t *testing.T) {
+	store := mock.NewTrustedDeviceStore(time.Hour)
+
+	t.Run("trusted device", func(t *testing.T) {
+		device, err := store.Create(context.Background(), 123, "Firefox")
+		require.NoError(t, err)
+
+		errs := services.TrustedDeviceRevoker(context.Background(), store, 123, device.ID)
+		assert.Empty(t, errs)
+
+		found, err := store.Find(context.Background(), device.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("device of another account", func(t *testing.T) {
+		device, err := store.Create(context.Background(), 123, "Firefox")
+		require.NoError(t, err)
+
+		errs := services.TrustedDeviceRevoker(context.Background(), store, 456, device.ID)
+		assert.Equal(t, services.FieldErrors{{"device", services.ErrNotFound}}, errs)
+	})
+
+	t.Run("unknown device", func(t *testing.T) {
+		errs := services.TrustedDeviceRevoker(context.Background(), store, 123, "a1b2c3")
+		assert.Equal(t, services.FieldErrors{{"device", services.ErrNotFound}}, errs)
+	})
+}
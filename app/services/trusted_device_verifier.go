@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/devices"
+	"github.com/pkg/errors"
+)
+
+// TrustedDeviceVerifier checks that the token belongs to a browser that is still trusted by the
+// account. Tokens that are invalid, expired, revoked, or for another account are not trusted.
+func TrustedDeviceVerifier(ctx context.Context, store data.TrustedDeviceStore, cfg *app.Config, token string, accountID int) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	claims, err := devices.Parse(token, cfg)
+	if err != nil {
+		return false, nil
+	}
+	if claims.Subject != strconv.Itoa(accountID) {
+		return false, nil
+	}
+
+	device, err := store.Find(ctx, claims.ID)
+	if err != nil {
+		return false, errors.Wrap(err, "Find")
+	}
+	return device != nil && device.AccountID == accountID, nil
+}
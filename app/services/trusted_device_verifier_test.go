@@ -0,0 +1,64 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedDeviceVerifier(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:                &url.URL{Scheme: "https", Host: "authn.example.com"},
+		TrustedDeviceSigningKey: []byte("key-a-reno"),
+	}
+	store := mock.NewTrustedDeviceStore(time.Hour)
+
+	t.Run("trusted device", func(t *testing.T) {
+		token, err := services.TrustedDeviceCreator(context.Background(), store, cfg, 123, "Firefox")
+		require.NoError(t, err)
+
+		trusted, err := services.TrustedDeviceVerifier(context.Background(), store, cfg, token, 123)
+		require.NoError(t, err)
+		assert.True(t, trusted)
+
+		devices, err := store.FindAll(context.Background(), 123)
+		require.NoError(t, err)
+		require.Len(t, devices, 1)
+		assert.Equal(t, "Firefox", devices[0].Name)
+	})
+
+	t.Run("another account", func(t *testing.T) {
+		token, err := services.TrustedDeviceCreator(context.Background(), store, cfg, 123, "Firefox")
+		require.NoError(t, err)
+
+		trusted, err := services.TrustedDeviceVerifier(context.Background(), store, cfg, token, 456)
+		require.NoError(t, err)
+		assert.False(t, trusted)
+	})
+
+	t.Run("revoked device", func(t *testing.T) {
+		token, err := services.TrustedDeviceCreator(context.Background(), store, cfg, 789, "Firefox")
+		require.NoError(t, err)
+		err = store.RevokeAll(context.Background(), 789)
+		require.NoError(t, err)
+
+		trusted, err := services.TrustedDeviceVerifier(context.Background(), store, cfg, token, 789)
+		require.NoError(t, err)
+		assert.False(t, trusted)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		for _, token := range []string{"", "not.a.token"} {
+			trusted, err := services.TrustedDeviceVerifier(context.Background(), store, cfg, token, 123)
+			require.NoError(t, err)
+			assert.False(t, trusted)
+		}
+	})
+}
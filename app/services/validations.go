@@ -17,6 +17,11 @@ var (
 	ErrExpired          = "EXPIRED"
 	ErrNotFound         = "NOT_FOUND"
 	ErrInvalidOrExpired = "INVALID_OR_EXPIRED"
+	ErrRequired         = "REQUIRED"
+	ErrReauthRequired   = "REAUTH_REQUIRED"
+	ErrDenied           = "DENIED"
+	ErrPending          = "PENDING"
+	ErrSlowDown         = "SLOW_DOWN"
 )
 
 type FieldError struct {
@@ -42,7 +47,8 @@ func (es FieldErrors) Error() string {
 	return strings.Join(buf, ", ")
 }
 
-func PasswordValidator(cfg *app.Config, username, password string) *FieldError {
+// PasswordValidator checks a password against the policy of the account's tenant.
+func PasswordValidator(cfg *app.Config, tenant, username, password string) *FieldError {
 	if password == "" {
 		return &FieldError{"password", ErrMissing}
 	}
@@ -53,7 +59,7 @@ func PasswordValidator(cfg *app.Config, username, password string) *FieldError {
 
 	score := CalculatePasswordScore(password)
 
-	if score < cfg.PasswordMinComplexity {
+	if score < cfg.PasswordMinComplexityFor(tenant) {
 		return &FieldError{"password", ErrInsecure}
 	}
 
@@ -0,0 +1,26 @@
+// Package tenants scopes accounts to a tenant (or realm). Each tenant is a separate pool of users
+// with its own application domains, so that the same username may exist in more than one tenant.
+package tenants
+
+import "context"
+
+type tenantKey struct{}
+
+// WithContext scopes account lookups with the context to the named tenant. The default tenant has an
+// empty name.
+func WithContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, name)
+}
+
+// FromContext returns the tenant of the context, and whether one was set. Requests that were not
+// made from an application domain, like those to the private API, are not scoped to a tenant.
+func FromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(tenantKey{}).(string)
+	return name, ok
+}
+
+// Name returns the tenant of the context, or the default tenant when none was set.
+func Name(ctx context.Context) string {
+	name, _ := FromContext(ctx)
+	return name
+}
@@ -0,0 +1,25 @@
+package tenants_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	name, ok := tenants.FromContext(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, "", name)
+
+	ctx := tenants.WithContext(context.Background(), "acme")
+	name, ok = tenants.FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", name)
+	assert.Equal(t, "acme", tenants.Name(ctx))
+
+	ctx = tenants.WithContext(context.Background(), "")
+	_, ok = tenants.FromContext(ctx)
+	assert.True(t, ok)
+}
@@ -0,0 +1,52 @@
+package access
+
+import (
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/google/uuid"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+// Claims describe an access token that was issued to a client, rather than to an account. The
+// format follows RFC 9068.
+type Claims struct {
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope,omitempty"`
+	jwt.Claims
+}
+
+func (c *Claims) Sign(key *private.Key) (string, error) {
+	jwk := jose.JSONWebKey{
+		Key:   key.PrivateKey,
+		KeyID: key.JWK.KeyID,
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: key.Algorithm(), Key: jwk},
+		(&jose.SignerOptions{}).WithType("at+jwt"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewSigner")
+	}
+	return jwt.Signed(signer).Claims(c).CompactSerialize()
+}
+
+func New(cfg *app.Config, client *models.Client, scopes models.NameList, audiences models.NameList) *Claims {
+	return &Claims{
+		ClientID: client.ID,
+		Scope:    scopes.String(),
+		Claims: jwt.Claims{
+			Issuer:   cfg.AuthNURL.String(),
+			Subject:  client.ID,
+			Audience: jwt.Audience(audiences),
+			Expiry:   jwt.NewNumericDate(time.Now().Add(cfg.AccessTokenTTL)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+			ID:       uuid.NewString(),
+		},
+	}
+}
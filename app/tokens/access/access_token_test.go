@@ -0,0 +1,44 @@
+package access_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTokenClaims(t *testing.T) {
+	cfg := app.Config{
+		AuthNURL:       &url.URL{Scheme: "http", Host: "authn.example.com"},
+		AccessTokenTTL: time.Hour,
+	}
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	client := &models.Client{ID: "a1b2c3"}
+
+	token := access.New(&cfg, client, models.NameList{"read", "write"}, models.NameList{"https://api.example.com"})
+	assert.Equal(t, "a1b2c3", token.Subject)
+	assert.Equal(t, "a1b2c3", token.ClientID)
+	assert.Equal(t, "read write", token.Scope)
+	assert.Equal(t, jwt.Audience{"https://api.example.com"}, token.Audience)
+	assert.Equal(t, "http://authn.example.com", token.Issuer)
+	assert.NotEmpty(t, token.ID)
+
+	tokenStr, err := token.Sign(key)
+	require.NoError(t, err)
+
+	parsed, err := jose.ParseSigned(tokenStr)
+	require.NoError(t, err)
+	assert.Equal(t, key.JWK.KeyID, parsed.Signatures[0].Header.KeyID)
+	assert.Equal(t, "at+jwt", parsed.Signatures[0].Header.ExtraHeaders[jose.HeaderType])
+	_, err = parsed.Verify(key.Public())
+	assert.NoError(t, err)
+}
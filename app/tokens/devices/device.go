@@ -0,0 +1,75 @@
+package devices
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v3"
+	jwt "github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+const scope = "device"
+
+// Claims identify a browser that has been trusted to skip the second factor for an account. The
+// ID refers to a TrustedDevice, so that trust may be revoked.
+type Claims struct {
+	Scope string `json:"scope"`
+	jwt.Claims
+}
+
+func (c *Claims) Sign(hmacKey []byte) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.HS256, Key: hmacKey},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewSigner")
+	}
+	return jwt.Signed(signer).Claims(c).CompactSerialize()
+}
+
+func Parse(tokenStr string, cfg *app.Config) (*Claims, error) {
+	token, err := jwt.ParseSigned(tokenStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseSigned")
+	}
+
+	claims := Claims{}
+	err = token.Claims(cfg.TrustedDeviceSigningKey, &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Claims")
+	}
+
+	err = claims.Claims.Validate(jwt.Expected{
+		Audience: jwt.Audience{cfg.AuthNURL.String()},
+		Issuer:   cfg.AuthNURL.String(),
+		Time:     time.Now(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Validate")
+	}
+	if claims.Scope != scope {
+		return nil, fmt.Errorf("token scope not valid")
+	}
+
+	return &claims, nil
+}
+
+// New creates a token for a trusted device that expires along with it.
+func New(cfg *app.Config, device *models.TrustedDevice) *Claims {
+	return &Claims{
+		Scope: scope,
+		Claims: jwt.Claims{
+			Issuer:   cfg.AuthNURL.String(),
+			Subject:  strconv.Itoa(device.AccountID),
+			Audience: jwt.Audience{cfg.AuthNURL.String()},
+			Expiry:   jwt.NewNumericDate(device.ExpiresAt),
+			IssuedAt: jwt.NewNumericDate(device.CreatedAt),
+			ID:       device.ID,
+		},
+	}
+}
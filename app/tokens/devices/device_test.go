@@ -0,0 +1,61 @@
+package devices_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/devices"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevice(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:                &url.URL{Scheme: "https", Host: "authn.example.com"},
+		TrustedDeviceSigningKey: []byte("key-a-reno"),
+	}
+
+	device := &models.TrustedDevice{
+		ID:        "a1b2c3",
+		AccountID: 52168,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	t.Run("creating signing and parsing", func(t *testing.T) {
+		token := devices.New(cfg, device)
+		assert.Equal(t, "device", token.Scope)
+		assert.Equal(t, "https://authn.example.com", token.Issuer)
+		assert.Equal(t, "52168", token.Subject)
+		assert.True(t, token.Audience.Contains("https://authn.example.com"))
+		assert.Equal(t, "a1b2c3", token.ID)
+		assert.NotEmpty(t, token.Expiry)
+		assert.NotEmpty(t, token.IssuedAt)
+
+		tokenStr, err := token.Sign(cfg.TrustedDeviceSigningKey)
+		require.NoError(t, err)
+
+		claims, err := devices.Parse(tokenStr, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "a1b2c3", claims.ID)
+	})
+
+	t.Run("parsing with a different key", func(t *testing.T) {
+		tokenStr, err := devices.New(cfg, device).Sign([]byte("old-a-reno"))
+		require.NoError(t, err)
+		_, err = devices.Parse(tokenStr, cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("parsing an expired device", func(t *testing.T) {
+		expired := *device
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+		tokenStr, err := devices.New(cfg, &expired).Sign(cfg.TrustedDeviceSigningKey)
+		require.NoError(t, err)
+		_, err = devices.Parse(tokenStr, cfg)
+		assert.Error(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package identities
 
 import (
+	"fmt"
 	"strconv"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/go-jose/go-jose/v3"
 	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/tokens/sessions"
 	"github.com/pkg/errors"
 )
@@ -20,9 +22,25 @@ type Claims struct {
 	AuthTime            *jwt.NumericDate `json:"auth_time"`
 	SessionID           string           `json:"sid"`
 	AuthMethodReference []string         `json:"amr"`
+	Scope               string           `json:"scope,omitempty"`
+	Actor               *sessions.Actor  `json:"act,omitempty"`
+	Tenant              string           `json:"tenant,omitempty"`
+	OrganizationID      string           `json:"org_id,omitempty"`
+	Roles               []string         `json:"roles,omitempty"`
+	// PersonalAccessTokenID identifies the personal access token that was exchanged, if any.
+	PersonalAccessTokenID int `json:"pat_id,omitempty"`
+	// ExtraClaims were added by the app's pre-login hook. They may not replace any other claims.
+	ExtraClaims map[string]interface{} `json:"-"`
 	jwt.Claims
 }
 
+// reservedClaims may not be set by ExtraClaims, even when they would otherwise be omitted.
+var reservedClaims = map[string]bool{
+	"iss": true, "sub": true, "aud": true, "exp": true, "nbf": true, "iat": true, "jti": true,
+	"auth_time": true, "sid": true, "amr": true, "scope": true, "act": true, "tenant": true,
+	"org_id": true, "roles": true, "azp": true, "client_id": true, "pat_id": true,
+}
+
 func (c *Claims) Sign(key *private.Key) (string, error) {
 	jwk := jose.JSONWebKey{
 		Key:   key.PrivateKey,
@@ -30,22 +48,36 @@ func (c *Claims) Sign(key *private.Key) (string, error) {
 	}
 
 	signer, err := jose.NewSigner(
-		jose.SigningKey{Algorithm: jose.RS256, Key: jwk},
+		jose.SigningKey{Algorithm: key.Algorithm(), Key: jwk},
 		(&jose.SignerOptions{}).WithType("JWT"),
 	)
 	if err != nil {
 		return "", errors.Wrap(err, "NewSigner")
 	}
-	return jwt.Signed(signer).Claims(c).CompactSerialize()
+	builder := jwt.Signed(signer)
+	if len(c.ExtraClaims) > 0 {
+		extra := map[string]interface{}{}
+		for name, value := range c.ExtraClaims {
+			if !reservedClaims[name] {
+				extra[name] = value
+			}
+		}
+		builder = builder.Claims(extra)
+	}
+	return builder.Claims(c).CompactSerialize()
 }
 
 func New(cfg *app.Config, session *sessions.Claims, accountID int, audience string) *Claims {
+	tenant := cfg.TenantFor(session.Azp)
 	return &Claims{
-		AuthTime:            session.IssuedAt,
+		AuthTime:            session.AuthenticatedAt(),
 		SessionID:           session.SessionID,
 		AuthMethodReference: session.AuthMethodReference,
+		Actor:               session.Actor,
+		Tenant:              tenant,
+		ExtraClaims:         session.ExtraClaims,
 		Claims: jwt.Claims{
-			Issuer:   session.Issuer,
+			Issuer:   cfg.IssuerFor(tenant),
 			Subject:  strconv.Itoa(accountID),
 			Audience: jwt.Audience{audience},
 			Expiry:   jwt.NewNumericDate(time.Now().Add(cfg.AccessTokenTTL)),
@@ -53,3 +85,62 @@ func New(cfg *app.Config, session *sessions.Claims, accountID int, audience stri
 		},
 	}
 }
+
+// NewForPersonalAccessToken builds identity claims for a personal access token. There is no session,
+// so the authentication time is when the token was created and the audience and scopes are those
+// the token was given.
+func NewForPersonalAccessToken(cfg *app.Config, token *models.PersonalAccessToken) *Claims {
+	tenant := cfg.TenantFor(token.Audience)
+	return &Claims{
+		AuthTime:              jwt.NewNumericDate(token.CreatedAt),
+		AuthMethodReference:   []string{"pat"},
+		Scope:                 token.Scopes.String(),
+		PersonalAccessTokenID: token.ID,
+		Tenant:                tenant,
+		Claims: jwt.Claims{
+			Issuer:   cfg.IssuerFor(tenant),
+			Subject:  strconv.Itoa(token.AccountID),
+			Audience: jwt.Audience{token.Audience},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(cfg.AccessTokenTTL)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}
+}
+
+// Parse verifies an identity token against the published keys and the issuer of its tenant. The
+// audience is not checked, since identity tokens are issued for every application domain.
+func Parse(tokenStr string, cfg *app.Config, keys []*private.Key) (*Claims, error) {
+	token, err := jwt.ParseSigned(tokenStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseSigned")
+	}
+	if len(token.Headers) == 0 {
+		return nil, fmt.Errorf("token header missing")
+	}
+
+	var key *private.Key
+	for _, k := range keys {
+		if k.JWK.KeyID == token.Headers[0].KeyID {
+			key = k
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("token key not found")
+	}
+
+	claims := Claims{}
+	err = token.Claims(key.Public(), &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Claims")
+	}
+
+	err = claims.Claims.Validate(jwt.Expected{
+		Issuer: cfg.IssuerFor(claims.Tenant),
+		Time:   time.Now(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Validate")
+	}
+
+	return &claims, nil
+}
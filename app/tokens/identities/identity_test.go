@@ -1,15 +1,19 @@
 package identities_test
 
 import (
+	"context"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app/data/private"
 
 	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/tokens/identities"
 	"github.com/keratin/authn-server/app/tokens/sessions"
 	"github.com/stretchr/testify/assert"
@@ -24,7 +28,7 @@ func TestIdentityClaims(t *testing.T) {
 	}
 	key, err := private.GenerateKey(512)
 	require.NoError(t, err)
-	session, err := sessions.New(store, &cfg, 1, "example.com", []string{"pwd"})
+	session, err := sessions.New(context.Background(), store, &cfg, 1, "example.com", []string{"pwd"})
 	require.NoError(t, err)
 
 	t.Run("includes KID", func(t *testing.T) {
@@ -38,4 +42,159 @@ func TestIdentityClaims(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, key.JWK.KeyID, parsed.Signatures[0].Header.KeyID)
 	})
+
+	t.Run("uses the latest authentication time", func(t *testing.T) {
+		identity := identities.New(&cfg, session, 1, "example.com")
+		assert.Equal(t, session.IssuedAt, identity.AuthTime)
+
+		reauthenticated := session.Reauthenticated([]string{"pwd"})
+		reauthenticated.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		identity = identities.New(&cfg, reauthenticated, 1, "example.com")
+		assert.Equal(t, reauthenticated.AuthTime, identity.AuthTime)
+	})
+
+	t.Run("includes the tenant of the session domain", func(t *testing.T) {
+		identity := identities.New(&cfg, session, 1, "example.com")
+		assert.Empty(t, identity.Tenant)
+
+		tenantCfg := cfg
+		tenantCfg.TenantDomains = map[string]string{"example.com": "acme"}
+		identity = identities.New(&tenantCfg, session, 1, "example.com")
+		assert.Equal(t, "acme", identity.Tenant)
+	})
+
+	for _, alg := range []string{private.ES256, private.EdDSA} {
+		t.Run("signs with "+alg, func(t *testing.T) {
+			key, err := private.GenerateKeyFor(alg, 0)
+			require.NoError(t, err)
+
+			identity := identities.New(&cfg, session, 1, "example.com")
+			identityStr, err := identity.Sign(key)
+			require.NoError(t, err)
+
+			parsed, err := jose.ParseSigned(identityStr)
+			require.NoError(t, err)
+			assert.Equal(t, alg, parsed.Signatures[0].Header.Algorithm)
+			_, err = parsed.Verify(key.Public())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParseIdentity(t *testing.T) {
+	store := mock.NewRefreshTokenStore()
+	cfg := app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+		AccessTokenTTL:    time.Hour,
+	}
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	otherKey, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	session, err := sessions.New(context.Background(), store, &cfg, 1, "example.com", []string{"pwd"})
+	require.NoError(t, err)
+
+	t.Run("valid token", func(t *testing.T) {
+		identityStr, err := identities.New(&cfg, session, 1, "example.com").Sign(key)
+		require.NoError(t, err)
+
+		claims, err := identities.Parse(identityStr, &cfg, []*private.Key{otherKey, key})
+		require.NoError(t, err)
+		assert.Equal(t, "1", claims.Subject)
+		assert.Equal(t, session.SessionID, claims.SessionID)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		identityStr, err := identities.New(&cfg, session, 1, "example.com").Sign(key)
+		require.NoError(t, err)
+
+		_, err = identities.Parse(identityStr, &cfg, []*private.Key{otherKey})
+		assert.Error(t, err)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		identity := identities.New(&cfg, session, 1, "example.com")
+		identity.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		identityStr, err := identity.Sign(key)
+		require.NoError(t, err)
+
+		_, err = identities.Parse(identityStr, &cfg, []*private.Key{key})
+		assert.Error(t, err)
+	})
+
+	t.Run("tenant issuer", func(t *testing.T) {
+		tenantCfg := cfg
+		tenantCfg.TenantDomains = map[string]string{"example.com": "acme"}
+		tenantCfg.Tenants = map[string]*app.TenantConfig{
+			"acme": {Issuer: &url.URL{Scheme: "https", Host: "auth.acme.example.com"}},
+		}
+		identity := identities.New(&tenantCfg, session, 1, "example.com")
+		assert.Equal(t, "https://auth.acme.example.com", identity.Issuer)
+		identityStr, err := identity.Sign(key)
+		require.NoError(t, err)
+
+		claims, err := identities.Parse(identityStr, &tenantCfg, []*private.Key{key})
+		require.NoError(t, err)
+		assert.Equal(t, "acme", claims.Tenant)
+
+		_, err = identities.Parse(identityStr, &cfg, []*private.Key{key})
+		assert.Error(t, err)
+	})
+
+	t.Run("other issuer", func(t *testing.T) {
+		identity := identities.New(&cfg, session, 1, "example.com")
+		identity.Issuer = "https://evil.example.com"
+		identityStr, err := identity.Sign(key)
+		require.NoError(t, err)
+
+		_, err = identities.Parse(identityStr, &cfg, []*private.Key{key})
+		assert.Error(t, err)
+	})
+}
+
+func TestPersonalAccessTokenIdentityClaims(t *testing.T) {
+	cfg := app.Config{
+		AuthNURL:       &url.URL{Scheme: "http", Host: "authn.example.com"},
+		AccessTokenTTL: time.Hour,
+	}
+	token := &models.PersonalAccessToken{
+		AccountID: 42,
+		Scopes:    models.NameList{"read", "write"},
+		Audience:  "https://app.example.com",
+		CreatedAt: time.Now().Add(-24 * time.Hour),
+	}
+
+	identity := identities.NewForPersonalAccessToken(&cfg, token)
+	assert.Equal(t, "42", identity.Subject)
+	assert.Equal(t, jwt.Audience{"https://app.example.com"}, identity.Audience)
+	assert.Equal(t, "http://authn.example.com", identity.Issuer)
+	assert.Equal(t, []string{"pat"}, identity.AuthMethodReference)
+	assert.Equal(t, "read write", identity.Scope)
+	assert.Equal(t, jwt.NewNumericDate(token.CreatedAt), identity.AuthTime)
+	assert.Empty(t, identity.SessionID)
+}
+
+func TestIdentityExtraClaims(t *testing.T) {
+	store := mock.NewRefreshTokenStore()
+	cfg := app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+	}
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	session, err := sessions.New(context.Background(), store, &cfg, 1, "example.com", []string{"pwd"})
+	require.NoError(t, err)
+	session.ExtraClaims = map[string]interface{}{"plan": "pro", "sub": "2", "tenant": "acme"}
+
+	identityStr, err := identities.New(&cfg, session, 1, "example.com").Sign(key)
+	require.NoError(t, err)
+
+	token, err := jwt.ParseSigned(identityStr)
+	require.NoError(t, err)
+	claims := map[string]interface{}{}
+	require.NoError(t, token.Claims(key.Public(), &claims))
+	assert.Equal(t, "pro", claims["plan"])
+	assert.Equal(t, "1", claims["sub"])
+	assert.NotContains(t, claims, "tenant")
 }
@@ -0,0 +1,84 @@
+package invitations
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v3"
+	jwt "github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/lib"
+	"github.com/pkg/errors"
+)
+
+const scope = "invitation"
+
+// Claims is a JWT that invites a username to sign up, even when signup is disabled. The ID is
+// recorded when the invitation is accepted, so that it may only be used once.
+type Claims struct {
+	Scope  string `json:"scope"`
+	Tenant string `json:"tenant,omitempty"`
+	jwt.Claims
+}
+
+func (c *Claims) Sign(hmacKey []byte) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.HS256, Key: hmacKey},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewSigner")
+	}
+	return jwt.Signed(signer).Claims(c).CompactSerialize()
+}
+
+func Parse(tokenStr string, cfg *app.Config) (*Claims, error) {
+	token, err := jwt.ParseSigned(tokenStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseSigned")
+	}
+
+	claims := Claims{}
+	err = token.Claims(cfg.InvitationTokenSigningKey, &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Claims")
+	}
+
+	err = claims.Claims.Validate(jwt.Expected{
+		Audience: jwt.Audience{cfg.AuthNURL.String()},
+		Issuer:   cfg.AuthNURL.String(),
+		Time:     time.Now(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Validate")
+	}
+	if claims.Scope != scope {
+		return nil, fmt.Errorf("token scope not valid")
+	}
+	if claims.ID == "" || claims.Subject == "" {
+		return nil, fmt.Errorf("token is incomplete")
+	}
+
+	return &claims, nil
+}
+
+func New(cfg *app.Config, username string, tenant string) (*Claims, error) {
+	binID, err := lib.GenerateToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "GenerateToken")
+	}
+
+	return &Claims{
+		Scope:  scope,
+		Tenant: tenant,
+		Claims: jwt.Claims{
+			ID:       hex.EncodeToString(binID),
+			Issuer:   cfg.AuthNURL.String(),
+			Subject:  username,
+			Audience: jwt.Audience{cfg.AuthNURL.String()},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(cfg.InvitationTokenTTL)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}, nil
+}
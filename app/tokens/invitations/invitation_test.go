@@ -0,0 +1,70 @@
+package invitations_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationToken(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:                  &url.URL{Scheme: "https", Host: "authn.example.com"},
+		InvitationTokenSigningKey: []byte("key-a-reno"),
+		InvitationTokenTTL:        time.Hour,
+	}
+
+	t.Run("creating signing and parsing", func(t *testing.T) {
+		token, err := invitations.New(cfg, "user@example.com", "acme")
+		require.NoError(t, err)
+		assert.Equal(t, "invitation", token.Scope)
+		assert.Equal(t, "acme", token.Tenant)
+		assert.Equal(t, "https://authn.example.com", token.Issuer)
+		assert.Equal(t, "user@example.com", token.Subject)
+		assert.True(t, token.Audience.Contains("https://authn.example.com"))
+		assert.NotEmpty(t, token.ID)
+		assert.NotEmpty(t, token.Expiry)
+
+		tokenStr, err := token.Sign(cfg.InvitationTokenSigningKey)
+		require.NoError(t, err)
+
+		claims, err := invitations.Parse(tokenStr, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, token.ID, claims.ID)
+		assert.Equal(t, "user@example.com", claims.Subject)
+	})
+
+	t.Run("unique IDs", func(t *testing.T) {
+		token1, err := invitations.New(cfg, "user@example.com", "")
+		require.NoError(t, err)
+		token2, err := invitations.New(cfg, "user@example.com", "")
+		require.NoError(t, err)
+		assert.NotEqual(t, token1.ID, token2.ID)
+	})
+
+	t.Run("parsing with a different key", func(t *testing.T) {
+		oldCfg := *cfg
+		oldCfg.InvitationTokenSigningKey = []byte("old-a-reno")
+		token, err := invitations.New(&oldCfg, "user@example.com", "")
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(oldCfg.InvitationTokenSigningKey)
+		require.NoError(t, err)
+		_, err = invitations.Parse(tokenStr, cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("parsing an expired token", func(t *testing.T) {
+		expiredCfg := *cfg
+		expiredCfg.InvitationTokenTTL = -time.Hour
+		token, err := invitations.New(&expiredCfg, "user@example.com", "")
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(cfg.InvitationTokenSigningKey)
+		require.NoError(t, err)
+		_, err = invitations.Parse(tokenStr, cfg)
+		assert.Error(t, err)
+	})
+}
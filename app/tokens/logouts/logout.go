@@ -0,0 +1,64 @@
+package logouts
+
+import (
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v3"
+	jwt "github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/lib"
+	"github.com/pkg/errors"
+)
+
+// Event identifies a logout token, as specified by OpenID Connect Back-Channel Logout.
+const Event = "http://schemas.openid.net/event/backchannel-logout"
+
+// ttl is only long enough for the app to receive the token, allowing for some clock skew.
+const ttl = 2 * time.Minute
+
+// Claims is a JWT that tells an app that sessions have ended. A logout token with a session ID ends
+// that session, and a logout token without one ends every session of the account.
+type Claims struct {
+	SessionID string                 `json:"sid,omitempty"`
+	Events    map[string]interface{} `json:"events"`
+	jwt.Claims
+}
+
+func (c *Claims) Sign(key *private.Key) (string, error) {
+	jwk := jose.JSONWebKey{
+		Key:   key.PrivateKey,
+		KeyID: key.JWK.KeyID,
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: key.Algorithm(), Key: jwk},
+		(&jose.SignerOptions{}).WithType("logout+jwt"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewSigner")
+	}
+	return jwt.Signed(signer).Claims(c).CompactSerialize()
+}
+
+func New(cfg *app.Config, accountID int, sessionID string, audience string) (*Claims, error) {
+	binID, err := lib.GenerateToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "GenerateToken")
+	}
+
+	return &Claims{
+		SessionID: sessionID,
+		Events:    map[string]interface{}{Event: map[string]interface{}{}},
+		Claims: jwt.Claims{
+			ID:       hex.EncodeToString(binID),
+			Issuer:   cfg.IssuerFor(cfg.TenantFor(audience)),
+			Subject:  strconv.Itoa(accountID),
+			Audience: jwt.Audience{audience},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}, nil
+}
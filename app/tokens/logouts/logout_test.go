@@ -0,0 +1,63 @@
+package logouts_test
+
+import (
+	"net/url"
+	"testing"
+
+	jose "github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/tokens/logouts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogoutClaims(t *testing.T) {
+	cfg := app.Config{
+		AuthNURL: &url.URL{Scheme: "http", Host: "authn.example.com"},
+	}
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+
+	t.Run("for a session", func(t *testing.T) {
+		logout, err := logouts.New(&cfg, 1, "session-a", "app.example.com")
+		require.NoError(t, err)
+		tokenStr, err := logout.Sign(key)
+		require.NoError(t, err)
+
+		parsed, err := jose.ParseSigned(tokenStr)
+		require.NoError(t, err)
+		assert.Equal(t, key.JWK.KeyID, parsed.Signatures[0].Header.KeyID)
+		assert.Equal(t, "logout+jwt", parsed.Signatures[0].Header.ExtraHeaders[jose.HeaderType])
+
+		token, err := jwt.ParseSigned(tokenStr)
+		require.NoError(t, err)
+		claims := map[string]interface{}{}
+		err = token.Claims(key.Public(), &claims)
+		require.NoError(t, err)
+		assert.Equal(t, "http://authn.example.com", claims["iss"])
+		assert.Equal(t, "1", claims["sub"])
+		assert.Equal(t, "app.example.com", claims["aud"])
+		assert.Equal(t, "session-a", claims["sid"])
+		assert.NotEmpty(t, claims["jti"])
+		assert.NotEmpty(t, claims["exp"])
+		assert.Equal(t, map[string]interface{}{logouts.Event: map[string]interface{}{}}, claims["events"])
+		assert.NotContains(t, claims, "nonce")
+	})
+
+	t.Run("for an account", func(t *testing.T) {
+		logout, err := logouts.New(&cfg, 1, "", "app.example.com")
+		require.NoError(t, err)
+		tokenStr, err := logout.Sign(key)
+		require.NoError(t, err)
+
+		token, err := jwt.ParseSigned(tokenStr)
+		require.NoError(t, err)
+		claims := map[string]interface{}{}
+		err = token.Claims(key.Public(), &claims)
+		require.NoError(t, err)
+		assert.Equal(t, "1", claims["sub"])
+		assert.NotContains(t, claims, "sid")
+	})
+}
@@ -0,0 +1,87 @@
+package mfa
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v3"
+	jwt "github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/lib"
+	"github.com/pkg/errors"
+)
+
+const scope = "mfa"
+
+// Claims describe a login that has passed its first factor and must be completed with another.
+type Claims struct {
+	Scope               string   `json:"scope"`
+	AuthMethodReference []string `json:"amr"`
+	jwt.Claims
+}
+
+func (c *Claims) Sign(hmacKey []byte) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.HS256, Key: hmacKey},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewSigner")
+	}
+	return jwt.Signed(signer).Claims(c).CompactSerialize()
+}
+
+// Parse verifies a challenge that was issued for the audience (the application domain).
+func Parse(tokenStr string, cfg *app.Config, audience string) (*Claims, error) {
+	token, err := jwt.ParseSigned(tokenStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseSigned")
+	}
+
+	claims := Claims{}
+	err = token.Claims(cfg.MFAChallengeSigningKey, &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Claims")
+	}
+
+	err = claims.Claims.Validate(jwt.Expected{
+		Audience: jwt.Audience{audience},
+		Issuer:   cfg.AuthNURL.String(),
+		Time:     time.Now(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Validate")
+	}
+	if claims.Scope != scope {
+		return nil, fmt.Errorf("token scope not valid")
+	}
+	if claims.ID == "" {
+		return nil, fmt.Errorf("token id missing")
+	}
+
+	return &claims, nil
+}
+
+// New creates a challenge for the account on the audience (the application domain). The amr lists
+// the factors that have already been verified. The ID (jti) allows the challenge to be used once.
+func New(cfg *app.Config, accountID int, audience string, amr []string) (*Claims, error) {
+	id, err := lib.GenerateToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "GenerateToken")
+	}
+
+	return &Claims{
+		Scope:               scope,
+		AuthMethodReference: amr,
+		Claims: jwt.Claims{
+			Issuer:   cfg.AuthNURL.String(),
+			Subject:  strconv.Itoa(accountID),
+			Audience: jwt.Audience{audience},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(cfg.MFAChallengeTTL)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+			ID:       hex.EncodeToString(id),
+		},
+	}, nil
+}
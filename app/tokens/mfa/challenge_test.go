@@ -0,0 +1,71 @@
+package mfa_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tokens/mfa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallenge(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:               &url.URL{Scheme: "https", Host: "authn.example.com"},
+		MFAChallengeSigningKey: []byte("key-a-reno"),
+		MFAChallengeTTL:        time.Minute,
+	}
+
+	accountID := 52168
+
+	t.Run("creating signing and parsing", func(t *testing.T) {
+		token, err := mfa.New(cfg, accountID, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+		assert.Equal(t, "mfa", token.Scope)
+		assert.Equal(t, "https://authn.example.com", token.Issuer)
+		assert.Equal(t, "52168", token.Subject)
+		assert.True(t, token.Audience.Contains("example.com"))
+		assert.Equal(t, []string{"pwd"}, token.AuthMethodReference)
+		assert.NotEmpty(t, token.Expiry)
+		assert.NotEmpty(t, token.IssuedAt)
+		assert.NotEmpty(t, token.ID)
+
+		tokenStr, err := token.Sign(cfg.MFAChallengeSigningKey)
+		require.NoError(t, err)
+
+		claims, err := mfa.Parse(tokenStr, cfg, "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pwd"}, claims.AuthMethodReference)
+	})
+
+	t.Run("parsing on a different domain", func(t *testing.T) {
+		token, err := mfa.New(cfg, accountID, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(cfg.MFAChallengeSigningKey)
+		require.NoError(t, err)
+		_, err = mfa.Parse(tokenStr, cfg, "other.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("parsing with a different key", func(t *testing.T) {
+		token, err := mfa.New(cfg, accountID, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+		tokenStr, err := token.Sign([]byte("old-a-reno"))
+		require.NoError(t, err)
+		_, err = mfa.Parse(tokenStr, cfg, "example.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("parsing an expired challenge", func(t *testing.T) {
+		expiredCfg := *cfg
+		expiredCfg.MFAChallengeTTL = -time.Minute
+		token, err := mfa.New(&expiredCfg, accountID, "example.com", []string{"pwd"})
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(cfg.MFAChallengeSigningKey)
+		require.NoError(t, err)
+		_, err = mfa.Parse(tokenStr, cfg, "example.com")
+		assert.Error(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package sessions
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -15,13 +16,25 @@ import (
 const scope = "refresh"
 
 type Claims struct {
-	Scope               string   `json:"scope"`
-	Azp                 string   `json:"azp"`
-	SessionID           string   `json:"sid"`
-	AuthMethodReference []string `json:"amr"`
+	Scope               string                 `json:"scope"`
+	Azp                 string                 `json:"azp"`
+	SessionID           string                 `json:"sid"`
+	AuthMethodReference []string               `json:"amr"`
+	RefreshedAt         *jwt.NumericDate       `json:"rat,omitempty"`
+	AuthTime            *jwt.NumericDate       `json:"auth_time,omitempty"`
+	MFAEnrollment       bool                   `json:"mfa_enroll,omitempty"`
+	Actor               *Actor                 `json:"act,omitempty"`
+	OrganizationID      int                    `json:"org_id,omitempty"`
+	ExtraClaims         map[string]interface{} `json:"ext,omitempty"`
 	jwt.Claims
 }
 
+// Actor identifies someone acting on behalf of the session's account, like an admin who is
+// impersonating the user. It is the "act" claim of RFC 8693.
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
 func (c *Claims) Sign(hmacKey []byte) (string, error) {
 	signer, err := jose.NewSigner(
 		jose.SigningKey{Algorithm: jose.HS256, Key: hmacKey},
@@ -33,6 +46,81 @@ func (c *Claims) Sign(hmacKey []byte) (string, error) {
 	return jwt.Signed(signer).Claims(c).CompactSerialize()
 }
 
+// Refreshed returns a copy of the session that records the time of the refresh, for the idle timeout.
+func (c *Claims) Refreshed() *Claims {
+	refreshed := *c
+	refreshed.RefreshedAt = jwt.NewNumericDate(time.Now())
+	return &refreshed
+}
+
+// Reauthenticated returns a copy of the session that records a new authentication, so that it may
+// be used for sensitive actions. The session's lifetime is not extended.
+func (c *Claims) Reauthenticated(amr []string) *Claims {
+	reauthenticated := *c
+	reauthenticated.AuthTime = jwt.NewNumericDate(time.Now())
+	reauthenticated.AuthMethodReference = amr
+	return &reauthenticated
+}
+
+// WithOrganization returns a copy of the session with a different active organization. A zero ID
+// clears the selection.
+func (c *Claims) WithOrganization(organizationID int) *Claims {
+	selected := *c
+	selected.OrganizationID = organizationID
+	return &selected
+}
+
+// AuthenticatedAt is when the user last provided credentials for the session, either when it was
+// created or when they reauthenticated.
+func (c *Claims) AuthenticatedAt() *jwt.NumericDate {
+	if c.AuthTime != nil {
+		return c.AuthTime
+	}
+	return c.IssuedAt
+}
+
+// Fresh is true when the user provided credentials for the session recently enough for a sensitive
+// action. A zero maxAge is unlimited. Impersonated sessions are never fresh, since the user did not
+// provide credentials at all.
+func (c *Claims) Fresh(maxAge time.Duration, now time.Time) bool {
+	if c.Actor != nil {
+		return false
+	}
+	if maxAge == 0 {
+		return true
+	}
+	authTime := c.AuthenticatedAt()
+	return authTime != nil && now.Sub(authTime.Time()) <= maxAge
+}
+
+// Expired is true when the session has outlived the maximum age or idle timeout of the application
+// domain where it was created. Impersonated sessions are also limited by IMPERSONATION_MAX_AGE.
+func (c *Claims) Expired(cfg *app.Config, now time.Time) bool {
+	if c.Actor != nil && cfg.ImpersonationMaxAge > 0 {
+		if c.IssuedAt == nil || now.Sub(c.IssuedAt.Time()) > cfg.ImpersonationMaxAge {
+			return true
+		}
+	}
+
+	if maxAge := cfg.SessionMaxAge.For(c.Azp); maxAge > 0 {
+		if c.IssuedAt == nil || now.Sub(c.IssuedAt.Time()) > maxAge {
+			return true
+		}
+	}
+
+	if idleTimeout := cfg.SessionIdleTimeout.For(c.Azp); idleTimeout > 0 {
+		lastActive := c.IssuedAt
+		if c.RefreshedAt != nil {
+			lastActive = c.RefreshedAt
+		}
+		if lastActive == nil || now.Sub(lastActive.Time()) > idleTimeout {
+			return true
+		}
+	}
+
+	return false
+}
+
 func Parse(tokenStr string, cfg *app.Config) (*Claims, error) {
 	token, err := jwt.ParseSigned(tokenStr)
 	if err != nil {
@@ -59,16 +147,22 @@ func Parse(tokenStr string, cfg *app.Config) (*Claims, error) {
 	return &claims, nil
 }
 
-func New(store data.RefreshTokenStore, cfg *app.Config, accountID int, authorizedAudience string, amr []string) (*Claims, error) {
-	refreshToken, err := store.Create(accountID)
+func New(ctx context.Context, store data.RefreshTokenStore, cfg *app.Config, accountID int, authorizedAudience string, amr []string) (*Claims, error) {
+	refreshToken, err := store.Create(ctx, accountID)
 	if err != nil {
 		return nil, errors.Wrap(err, "Create")
 	}
 
+	sessionID := uuid.NewString()
+	err = store.SetSessionID(ctx, refreshToken, sessionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "SetSessionID")
+	}
+
 	return &Claims{
 		Scope:               scope,
 		Azp:                 authorizedAudience,
-		SessionID:           uuid.NewString(),
+		SessionID:           sessionID,
 		AuthMethodReference: amr,
 		Claims: jwt.Claims{
 			Issuer:   cfg.AuthNURL.String(),
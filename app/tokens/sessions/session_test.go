@@ -1,8 +1,10 @@
 package sessions_test
 
 import (
+	"context"
 	"net/url"
 	"testing"
+	"time"
 
 	jwt "github.com/go-jose/go-jose/v3/jwt"
 
@@ -20,7 +22,7 @@ func TestNewAndParseAndSign(t *testing.T) {
 		SessionSigningKey: []byte("key-a-reno"),
 	}
 
-	token, err := sessions.New(store, &cfg, 658908, "example.com", []string{"pwd"})
+	token, err := sessions.New(context.Background(), store, &cfg, 658908, "example.com", []string{"pwd"})
 	require.NoError(t, err)
 	assert.Equal(t, "refresh", token.Scope)
 	assert.Equal(t, "http://authn.example.com", token.Issuer)
@@ -51,7 +53,7 @@ func TestParseInvalidSessionJWT(t *testing.T) {
 	cfg := app.Config{AuthNURL: &authn, SessionSigningKey: key}
 
 	t.Run("old key", func(t *testing.T) {
-		token, err := sessions.New(store, &app.Config{AuthNURL: &authn}, 1, mainApp.Host, []string{"pwd"})
+		token, err := sessions.New(context.Background(), store, &app.Config{AuthNURL: &authn}, 1, mainApp.Host, []string{"pwd"})
 		require.NoError(t, err)
 		tokenStr, err := token.Sign([]byte("old key"))
 		require.NoError(t, err)
@@ -61,7 +63,7 @@ func TestParseInvalidSessionJWT(t *testing.T) {
 	})
 
 	t.Run("different audience", func(t *testing.T) {
-		token, err := sessions.New(store, &app.Config{AuthNURL: &authn}, 2, mainApp.Host, []string{"pwd"})
+		token, err := sessions.New(context.Background(), store, &app.Config{AuthNURL: &authn}, 2, mainApp.Host, []string{"pwd"})
 		require.NoError(t, err)
 		token.Audience = jwt.Audience{mainApp.String()}
 		tokenStr, err := token.Sign(key)
@@ -71,3 +73,92 @@ func TestParseInvalidSessionJWT(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestExpired(t *testing.T) {
+	store := mock.NewRefreshTokenStore()
+	cfg := app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+		SessionMaxAge: app.DomainDurations{
+			Default: 24 * time.Hour,
+			Domains: map[string]time.Duration{"admin.example.com": time.Hour},
+		},
+		SessionIdleTimeout: app.DomainDurations{
+			Domains: map[string]time.Duration{"admin.example.com": 10 * time.Minute},
+		},
+	}
+
+	session, err := sessions.New(context.Background(), store, &cfg, 1, "example.com", []string{"pwd"})
+	require.NoError(t, err)
+	assert.False(t, session.Expired(&cfg, time.Now()))
+	assert.False(t, session.Expired(&cfg, time.Now().Add(2*time.Hour)))
+	assert.True(t, session.Expired(&cfg, time.Now().Add(25*time.Hour)))
+
+	admin, err := sessions.New(context.Background(), store, &cfg, 1, "admin.example.com", []string{"pwd"})
+	require.NoError(t, err)
+	assert.False(t, admin.Expired(&cfg, time.Now()))
+	assert.True(t, admin.Expired(&cfg, time.Now().Add(15*time.Minute)))
+
+	// refreshing resets the idle timeout but not the maximum age
+	admin.IssuedAt = jwt.NewNumericDate(time.Now().Add(-30 * time.Minute))
+	assert.True(t, admin.Expired(&cfg, time.Now()))
+	refreshed := admin.Refreshed()
+	assert.False(t, refreshed.Expired(&cfg, time.Now()))
+	assert.True(t, refreshed.Expired(&cfg, time.Now().Add(31*time.Minute)))
+}
+
+func TestReauthenticated(t *testing.T) {
+	store := mock.NewRefreshTokenStore()
+	cfg := app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+		SessionMaxAge:     app.DomainDurations{Default: 24 * time.Hour},
+	}
+
+	session, err := sessions.New(context.Background(), store, &cfg, 1, "example.com", []string{"pwd"})
+	require.NoError(t, err)
+	assert.Equal(t, session.IssuedAt, session.AuthenticatedAt())
+	assert.True(t, session.Fresh(0, time.Now().Add(48*time.Hour)))
+	assert.True(t, session.Fresh(5*time.Minute, time.Now()))
+	assert.False(t, session.Fresh(5*time.Minute, time.Now().Add(10*time.Minute)))
+
+	// reauthenticating makes the session fresh but does not extend the maximum age
+	session.IssuedAt = jwt.NewNumericDate(time.Now().Add(-23 * time.Hour))
+	assert.False(t, session.Fresh(5*time.Minute, time.Now()))
+	reauthenticated := session.Reauthenticated([]string{"pwd", "otp"})
+	assert.True(t, reauthenticated.Fresh(5*time.Minute, time.Now()))
+	assert.Equal(t, []string{"pwd", "otp"}, reauthenticated.AuthMethodReference)
+	assert.True(t, reauthenticated.Expired(&cfg, time.Now().Add(2*time.Hour)))
+
+	sessionString, err := reauthenticated.Sign(cfg.SessionSigningKey)
+	require.NoError(t, err)
+	claims, err := sessions.Parse(sessionString, &cfg)
+	require.NoError(t, err)
+	assert.Equal(t, reauthenticated.AuthTime, claims.AuthTime)
+}
+
+func TestImpersonated(t *testing.T) {
+	store := mock.NewRefreshTokenStore()
+	cfg := app.Config{
+		AuthNURL:            &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey:   []byte("key-a-reno"),
+		SessionMaxAge:       app.DomainDurations{Default: 24 * time.Hour},
+		ImpersonationMaxAge: time.Hour,
+	}
+
+	session, err := sessions.New(context.Background(), store, &cfg, 1, "example.com", []string{"imp"})
+	require.NoError(t, err)
+	session.Actor = &sessions.Actor{Subject: "admin@example.com"}
+
+	// impersonated sessions have a shorter lifetime and may not perform sensitive actions
+	assert.False(t, session.Expired(&cfg, time.Now()))
+	assert.True(t, session.Expired(&cfg, time.Now().Add(2*time.Hour)))
+	assert.False(t, session.Fresh(0, time.Now()))
+	assert.False(t, session.Reauthenticated([]string{"pwd"}).Fresh(5*time.Minute, time.Now()))
+
+	token, err := session.Sign(cfg.SessionSigningKey)
+	require.NoError(t, err)
+	parsed, err := sessions.Parse(token, &cfg)
+	require.NoError(t, err)
+	assert.Equal(t, session.Actor, parsed.Actor)
+}
@@ -0,0 +1,78 @@
+package compat
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// keyIDSeparator divides the key ID from the encrypted message. It does not appear in base64.
+const keyIDSeparator = "$"
+
+// Keyring holds the current encryption key and any previous keys that may still be needed to read
+// older messages. Messages are always encrypted with the current key and are prefixed with the ID
+// of that key, so that they can be decrypted after the next rotation.
+type Keyring struct {
+	keys [][]byte
+	ids  []string
+}
+
+// NewKeyring creates a Keyring from the current key and any previous keys.
+func NewKeyring(current []byte, previous ...[]byte) *Keyring {
+	k := &Keyring{}
+	for _, key := range append([][]byte{current}, previous...) {
+		k.keys = append(k.keys, key)
+		k.ids = append(k.ids, KeyID(key))
+	}
+	return k
+}
+
+// KeyID is a short fingerprint of a key. It identifies the key without revealing it.
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// Encrypt encrypts the value with the current key.
+func (k *Keyring) Encrypt(value []byte) ([]byte, error) {
+	message, err := Encrypt(value, k.keys[0])
+	if err != nil {
+		return nil, err
+	}
+	return []byte(k.ids[0] + keyIDSeparator + string(message)), nil
+}
+
+// Decrypt decrypts a message with the key that encrypted it. Messages from before key IDs were
+// embedded are tried against each known key in turn.
+func (k *Keyring) Decrypt(message []byte) (string, error) {
+	if i := bytes.Index(message, []byte(keyIDSeparator)); i >= 0 {
+		id := string(message[:i])
+		for idx, key := range k.keys {
+			if k.ids[idx] == id {
+				return Decrypt(message[i+1:], key)
+			}
+		}
+		return "", fmt.Errorf("unknown encryption key: %v", id)
+	}
+
+	if bytes.Count(message, []byte("--")) != 2 {
+		return "", fmt.Errorf("unexpected encrypted message format")
+	}
+	var err error
+	for _, key := range k.keys {
+		var val string
+		val, err = Decrypt(message, key)
+		if err == nil {
+			return val, nil
+		}
+	}
+	return "", errors.Wrap(err, "Decrypt")
+}
+
+// IsCurrent reports whether a message was encrypted with the current key.
+func (k *Keyring) IsCurrent(message []byte) bool {
+	return bytes.HasPrefix(message, []byte(k.ids[0]+keyIDSeparator))
+}
@@ -0,0 +1,60 @@
+package compat_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	oldKey := []byte("AES256Key-32Characters1234567890")
+	newKey := []byte("AES256Key-32Characters0987654321")
+
+	t.Run("encrypts with the current key", func(t *testing.T) {
+		keyring := compat.NewKeyring(newKey, oldKey)
+		msg, err := keyring.Encrypt([]byte("plaintext"))
+		require.NoError(t, err)
+		assert.True(t, keyring.IsCurrent(msg))
+
+		val, err := keyring.Decrypt(msg)
+		require.NoError(t, err)
+		assert.Equal(t, "plaintext", val)
+	})
+
+	t.Run("decrypts with a previous key", func(t *testing.T) {
+		msg, err := compat.NewKeyring(oldKey).Encrypt([]byte("plaintext"))
+		require.NoError(t, err)
+
+		keyring := compat.NewKeyring(newKey, oldKey)
+		assert.False(t, keyring.IsCurrent(msg))
+		val, err := keyring.Decrypt(msg)
+		require.NoError(t, err)
+		assert.Equal(t, "plaintext", val)
+	})
+
+	t.Run("decrypts messages without a key id", func(t *testing.T) {
+		msg, err := compat.Encrypt([]byte("plaintext"), oldKey)
+		require.NoError(t, err)
+
+		keyring := compat.NewKeyring(newKey, oldKey)
+		assert.False(t, keyring.IsCurrent(msg))
+		val, err := keyring.Decrypt(msg)
+		require.NoError(t, err)
+		assert.Equal(t, "plaintext", val)
+	})
+
+	t.Run("rejects unknown keys", func(t *testing.T) {
+		msg, err := compat.NewKeyring(oldKey).Encrypt([]byte("plaintext"))
+		require.NoError(t, err)
+
+		_, err = compat.NewKeyring(newKey).Decrypt(msg)
+		assert.Error(t, err)
+
+		msg, err = compat.Encrypt([]byte("plaintext"), oldKey)
+		require.NoError(t, err)
+		_, err = compat.NewKeyring(newKey).Decrypt(msg)
+		assert.Error(t, err)
+	})
+}
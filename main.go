@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
@@ -33,6 +34,10 @@ func main() {
 		serve(cfg)
 	} else if cmd == "migrate" {
 		migrate(cfg)
+	} else if cmd == "reencrypt" {
+		reencrypt(cfg)
+	} else if cmd == "rotate-keys" {
+		rotateKeys(cfg, len(os.Args) > 2 && os.Args[2] == "--revoke")
 	} else {
 		os.Stderr.WriteString("unexpected invocation\n")
 		usage()
@@ -74,12 +79,64 @@ func migrate(cfg *app.Config) {
 	}
 }
 
+func reencrypt(cfg *app.Config) {
+	fmt.Println("Re-encrypting data with the current DB_ENCRYPTION_KEY.")
+	logger := logrus.New()
+	app, err := app.NewApp(cfg, logger)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	keyring := cfg.DBEncryptionKeyring()
+	authenticators, err := data.ReencryptTOTPSecrets(context.Background(), app.DB, keyring)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("Re-encrypted %d TOTP secrets.\n", authenticators)
+
+	blobs, err := app.BlobStore.Reencrypt(context.Background())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("Re-encrypted %d blobs.\n", blobs)
+}
+
+func rotateKeys(cfg *app.Config, revoke bool) {
+	logger := logrus.New()
+	app, err := app.NewApp(cfg, logger)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if app.KeyRotater == nil {
+		fmt.Println("Signing keys are static and must be rotated by changing the configuration.")
+		os.Exit(1)
+	}
+
+	key, replaced, err := app.KeyRotater.ForceRotation(context.Background(), revoke)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("Signing with key %s.\n", key.JWK.KeyID)
+	if revoke {
+		fmt.Printf("Revoked key %s.\n", replaced.JWK.KeyID)
+	} else {
+		fmt.Printf("Replaced key %s will be published until it expires.\n", replaced.JWK.KeyID)
+	}
+}
+
 func usage() {
 	exe := path.Base(os.Args[0])
 	fmt.Printf(`
 Usage:
-%s server  - run the server (default)
-%s migrate - run migrations
+%s server                 - run the server (default)
+%s migrate                - run migrations
+%s reencrypt              - re-encrypt stored secrets with the current DB_ENCRYPTION_KEY
+%s rotate-keys [--revoke] - immediately rotate the signing key, optionally revoking the old key
 
-`, exe, exe)
+`, exe, exe, exe, exe)
 }
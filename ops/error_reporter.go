@@ -1,6 +1,7 @@
 package ops
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -40,7 +41,8 @@ func NewErrorReporter(credentials string, t ErrorReporterType, logger logrus.Fie
 }
 
 // PanicHandler returns a http.Handler that will recover any panics and report them as request
-// errors. If a panic is caught, the handler will return HTTP 500.
+// errors. If a panic is caught, the handler will return HTTP 500. Panics caused by a client that
+// went away before the request finished are not reported.
 func PanicHandler(r ErrorReporter, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		defer func() {
@@ -49,6 +51,10 @@ func PanicHandler(r ErrorReporter, next http.Handler) http.Handler {
 			case nil:
 				return
 			case error:
+				if errors.Is(err, context.Canceled) && req.Context().Err() != nil {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
 				r.ReportRequestError(err, req)
 				w.WriteHeader(http.StatusInternalServerError)
 			default:
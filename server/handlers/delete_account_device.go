@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+)
+
+func DeleteAccountDevice(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, "account")
+			return
+		}
+
+		err = services.TrustedDeviceRevoker(r.Context(), app.TrustedDeviceStore, id, mux.Vars(r)["device_id"])
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, "device")
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteAccountDevice(t *testing.T) {
+	app := test.App()
+	app.Config.EnableTrustedDevices = true
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	account, err := app.AccountStore.Create(context.Background(), "trusting@test.com", []byte("bar"))
+	require.NoError(t, err)
+
+	t.Run("unknown device", func(t *testing.T) {
+		res, err := client.Delete(fmt.Sprintf("/accounts/%v/devices/a1b2c3", account.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("trusted device", func(t *testing.T) {
+		device, err := app.TrustedDeviceStore.Create(context.Background(), account.ID, "Firefox")
+		require.NoError(t, err)
+
+		res, err := client.Delete(fmt.Sprintf("/accounts/%v/devices/%v", account.ID, device.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.TrustedDeviceStore.Find(context.Background(), device.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
@@ -33,7 +33,7 @@ func DeleteAccountOauth(app *app.App) http.HandlerFunc {
 			return
 		}
 
-		err = services.IdentityRemover(app.AccountStore, accountID, []string{provider})
+		err = services.IdentityRemover(r.Context(), app.AccountStore, accountID, []string{provider})
 		if err != nil {
 			app.Logger.WithError(err).Error("IdentityRemover")
 
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -23,10 +24,10 @@ func TestDeleteAccountOauth(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		account, err := app.AccountStore.Create("deleted-social-account@keratin.tech", []byte("password"))
+		account, err := app.AccountStore.Create(context.Background(), "deleted-social-account@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		err = app.AccountStore.AddOauthAccount(account.ID, "test", "DELETEDID", "email", "TOKEN")
+		err = app.AccountStore.AddOauthAccount(context.Background(), account.ID, "test", "DELETEDID", "email", "TOKEN")
 		require.NoError(t, err)
 
 		url := fmt.Sprintf("/accounts/%d/oauth/%s", account.ID, "test")
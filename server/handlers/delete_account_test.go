@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -25,29 +26,29 @@ func TestDeleteAccount(t *testing.T) {
 	})
 
 	t.Run("unarchived account", func(t *testing.T) {
-		account, err := app.AccountStore.Create("unlocked@test.com", []byte("bar"))
+		account, err := app.AccountStore.Create(context.Background(), "unlocked@test.com", []byte("bar"))
 		require.NoError(t, err)
 
 		res, err := client.Delete(fmt.Sprintf("/accounts/%v", account.ID))
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 
-		account, err = app.AccountStore.Find(account.ID)
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.NotEmpty(t, account.DeletedAt)
 	})
 
 	t.Run("archived account", func(t *testing.T) {
-		account, err := app.AccountStore.Create("locked@test.com", []byte("bar"))
+		account, err := app.AccountStore.Create(context.Background(), "locked@test.com", []byte("bar"))
 		require.NoError(t, err)
-		_, err = app.AccountStore.Archive(account.ID)
+		_, err = app.AccountStore.Archive(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		res, err := client.Delete(fmt.Sprintf("/accounts/%v", account.ID))
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 
-		account, err = app.AccountStore.Find(account.ID)
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.NotEmpty(t, account.DeletedAt)
 	})
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+)
+
+func DeleteClient(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := services.ClientDeleter(r.Context(), app.ClientStore, mux.Vars(r)["id"])
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, "client")
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
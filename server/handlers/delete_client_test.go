@@ -0,0 +1,40 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteClient(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("unknown client", func(t *testing.T) {
+		res, err := client.Delete("/clients/abcdef")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("valid client", func(t *testing.T) {
+		err := app.ClientStore.Create(context.Background(), &models.Client{ID: "a1b2c3", Name: "billing"})
+		require.NoError(t, err)
+
+		res, err := client.Delete("/clients/a1b2c3")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.ClientStore.Find(context.Background(), "a1b2c3")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
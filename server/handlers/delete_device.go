@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func DeleteDevice(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		err := services.TrustedDeviceRevoker(r.Context(), app.TrustedDeviceStore, accountID, mux.Vars(r)["id"])
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, "device")
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,56 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteDevice(t *testing.T) {
+	app := test.App()
+	app.Config.EnableTrustedDevices = true
+	server := test.Server(app)
+	defer server.Close()
+
+	account, err := app.AccountStore.Create(context.Background(), "trusting@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+
+	t.Run("trusted device", func(t *testing.T) {
+		device, err := app.TrustedDeviceStore.Create(context.Background(), account.ID, "Firefox")
+		require.NoError(t, err)
+
+		res, err := client.Delete("/devices/" + device.ID)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.TrustedDeviceStore.Find(context.Background(), device.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("device of another account", func(t *testing.T) {
+		device, err := app.TrustedDeviceStore.Create(context.Background(), account.ID+1, "Safari")
+		require.NoError(t, err)
+
+		res, err := client.Delete("/devices/" + device.ID)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+		found, err := app.TrustedDeviceStore.Find(context.Background(), device.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, found)
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).Delete("/devices/a1b2c3")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
@@ -16,7 +16,11 @@ func DeleteOauth(app *app.App, providerName string) http.HandlerFunc {
 			return
 		}
 
-		err := services.IdentityRemover(app.AccountStore, accountID, []string{providerName})
+		if !requireFreshSession(app, w, r) {
+			return
+		}
+
+		err := services.IdentityRemover(r.Context(), app.AccountStore, accountID, []string{providerName})
 		if err != nil {
 			app.Logger.WithError(err).Error("IdentityRemover")
 			WriteErrors(w, err)
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -40,10 +41,10 @@ func TestDeleteOauthAccount(t *testing.T) {
 	})
 
 	t.Run("success", func(t *testing.T) {
-		account, err := app.AccountStore.Create("deleted@keratin.tech", []byte("password"))
+		account, err := app.AccountStore.Create(context.Background(), "deleted@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		err = app.AccountStore.AddOauthAccount(account.ID, "test", "DELETEDID", "email", "TOKEN")
+		err = app.AccountStore.AddOauthAccount(context.Background(), account.ID, "test", "DELETEDID", "email", "TOKEN")
 		require.NoError(t, err)
 
 		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+)
+
+func DeleteOrganization(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, "organization")
+			return
+		}
+
+		err = services.OrganizationDeleter(r.Context(), app.OrganizationStore, id)
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, "organization")
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+)
+
+func DeleteOrganizationMember(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, "organization")
+			return
+		}
+		accountID, err := strconv.Atoi(mux.Vars(r)["account_id"])
+		if err != nil {
+			WriteNotFound(w, "account")
+			return
+		}
+
+		err = services.MembershipDeleter(r.Context(), app.OrganizationStore, id, accountID)
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, "membership")
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
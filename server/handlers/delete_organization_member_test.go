@@ -0,0 +1,45 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteOrganizationMember(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	organization, err := app.OrganizationStore.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	path := "/organizations/" + strconv.Itoa(organization.ID) + "/members/123"
+
+	t.Run("unknown membership", func(t *testing.T) {
+		res, err := client.Delete(path)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("valid membership", func(t *testing.T) {
+		err := app.OrganizationStore.SetMembership(context.Background(), organization.ID, 123, models.NameList{"admin"})
+		require.NoError(t, err)
+
+		res, err := client.Delete(path)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		membership, err := app.OrganizationStore.FindMembership(context.Background(), organization.ID, 123)
+		require.NoError(t, err)
+		assert.Nil(t, membership)
+	})
+}
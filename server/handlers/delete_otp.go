@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func DeleteOTP(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if !requireFreshSession(app, w, r) {
+			return
+		}
+
+		if err := services.OTPChannelDeleter(r.Context(), app.AccountStore, app.TrustedDeviceStore, app.Config, accountID); err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteOTP(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	t.Run("with a session", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "delete@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		_, err = app.AccountStore.SetOTPChannel(context.Background(), account.ID, "sms")
+		require.NoError(t, err)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Delete("/otp")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.False(t, account.OTPChannelEnabled())
+	})
+
+	t.Run("when required by policy", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "required@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		_, err = app.AccountStore.SetOTPChannel(context.Background(), account.ID, "sms")
+		require.NoError(t, err)
+		_, err = app.AccountStore.SetRequireMFA(context.Background(), account.ID, true)
+		require.NoError(t, err)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Delete("/otp")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: services.ErrRequired}})
+	})
+
+	t.Run("without a session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		res, err := client.Delete("/otp")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
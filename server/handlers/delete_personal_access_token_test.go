@@ -0,0 +1,56 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletePersonalAccessToken(t *testing.T) {
+	app := test.App()
+	app.Config.EnablePersonalAccessTokens = true
+	server := test.Server(app)
+	defer server.Close()
+
+	account, err := app.AccountStore.Create(context.Background(), "owner@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	pat, err := app.AccountStore.AddPersonalAccessToken(context.Background(), account.ID, "cli", "hash", models.NameList{}, "test.com", nil)
+	require.NoError(t, err)
+
+	t.Run("token of another account", func(t *testing.T) {
+		stranger, err := app.AccountStore.Create(context.Background(), "stranger@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, stranger.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Delete(fmt.Sprintf("/personal_access_tokens/%d", pat.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("own token", func(t *testing.T) {
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Delete(fmt.Sprintf("/personal_access_tokens/%d", pat.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		tokens, err := app.AccountStore.GetPersonalAccessTokens(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		res, err := client.Delete(fmt.Sprintf("/personal_access_tokens/%d", pat.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
@@ -10,7 +10,11 @@ import (
 
 func DeleteSession(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := services.SessionEnder(app.RefreshTokenStore, sessions.GetRefreshToken(r))
+		var tenant string
+		if session := sessions.Get(r); session != nil {
+			tenant = app.Config.TenantFor(session.Azp)
+		}
+		err := services.SessionEnder(r.Context(), app.RefreshTokenStore, app.KeyStore, app.Config, app.Reporter, tenant, sessions.GetRefreshToken(r))
 		if err != nil {
 			app.Reporter.ReportRequestError(err, r)
 		}
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -24,7 +25,7 @@ func TestDeleteSessionSuccess(t *testing.T) {
 	// token exists
 	claims, err := sessions.Parse(session.Value, testApp.Config)
 	require.NoError(t, err)
-	id, err := testApp.RefreshTokenStore.Find(models.RefreshToken(claims.Subject))
+	id, err := testApp.RefreshTokenStore.Find(context.Background(), models.RefreshToken(claims.Subject))
 	require.NoError(t, err)
 	assert.NotEmpty(t, id)
 
@@ -36,7 +37,7 @@ func TestDeleteSessionSuccess(t *testing.T) {
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 
 	// token no longer exists
-	id, err = testApp.RefreshTokenStore.Find(models.RefreshToken(claims.Subject))
+	id, err = testApp.RefreshTokenStore.Find(context.Background(), models.RefreshToken(claims.Subject))
 	require.NoError(t, err)
 	assert.Empty(t, id)
 }
@@ -17,7 +17,15 @@ func DeleteTOTP(app *app.App) http.HandlerFunc {
 			return
 		}
 
-		if err := services.TOTPDeleter(app.AccountStore, accountID); err != nil {
+		if !requireFreshSession(app, w, r) {
+			return
+		}
+
+		if err := services.TOTPDeleter(r.Context(), app.AccountStore, app.TrustedDeviceStore, app.Config, accountID); err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
 			w.WriteHeader(http.StatusUnprocessableEntity)
 			return
 		}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func DeleteTOTPAuthenticator(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if !requireFreshSession(app, w, r) {
+			return
+		}
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, "authenticator")
+			return
+		}
+
+		err = services.TOTPAuthenticatorDeleter(r.Context(), app.AccountStore, app.TrustedDeviceStore, app.Config, accountID, id)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				if fe[0].Message == services.ErrNotFound {
+					WriteNotFound(w, "authenticator")
+				} else {
+					WriteErrors(w, fe)
+				}
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteTOTPAuthenticator(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	t.Run("one of several authenticators", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "several@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		phone, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("test"), 0)
+		require.NoError(t, err)
+		tablet, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "tablet", []byte("test"), 0)
+		require.NoError(t, err)
+
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Delete(fmt.Sprintf("/totp/%d", phone.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		authenticators, err := app.AccountStore.GetTOTPAuthenticators(context.Background(), account.ID)
+		require.NoError(t, err)
+		if assert.Len(t, authenticators, 1) {
+			assert.Equal(t, tablet.ID, authenticators[0].ID)
+		}
+	})
+
+	t.Run("authenticator of another account", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "other@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		_, err = app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("test"), 0)
+		require.NoError(t, err)
+		stranger, err := app.AccountStore.Create(context.Background(), "stranger@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		foreign, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), stranger.ID, "phone", []byte("test"), 0)
+		require.NoError(t, err)
+
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Delete(fmt.Sprintf("/totp/%d", foreign.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("last authenticator required by policy", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "required@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		phone, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("test"), 0)
+		require.NoError(t, err)
+		_, err = app.AccountStore.SetRequireMFA(context.Background(), account.ID, true)
+		require.NoError(t, err)
+
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Delete(fmt.Sprintf("/totp/%d", phone.ID))
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "totp", Message: services.ErrRequired}})
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		res, err := client.Delete("/totp/1")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
@@ -1,9 +1,11 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
+	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/test"
 	"github.com/stretchr/testify/assert"
@@ -15,10 +17,10 @@ func TestDeleteTOTPSuccess(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	account, _ := app.AccountStore.Create("account@keratin.tech", []byte("password"))
-	set, err := app.AccountStore.SetTOTPSecret(account.ID, []byte("test"))
+	account, _ := app.AccountStore.Create(context.Background(), "account@keratin.tech", []byte("password"))
+	set, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("test"), 0)
 
-	require.True(t, set)
+	require.NotNil(t, set)
 	require.NoError(t, err)
 
 	existingSession := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
@@ -46,3 +48,24 @@ func TestDeleteTOTPUnauthenticated(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
 	assert.Equal(t, []byte{}, body)
 }
+
+func TestDeleteTOTPRequiredByPolicy(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	account, _ := app.AccountStore.Create(context.Background(), "account@keratin.tech", []byte("password"))
+	_, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("test"), 0)
+	require.NoError(t, err)
+	_, err = app.AccountStore.SetRequireMFA(context.Background(), account.ID, true)
+	require.NoError(t, err)
+
+	existingSession := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(existingSession)
+	res, err := client.Delete("/totp")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	test.AssertErrors(t, res, services.FieldErrors{{Field: "totp", Message: services.ErrRequired}})
+}
@@ -0,0 +1,47 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountDevices(t *testing.T) {
+	app := test.App()
+	app.Config.EnableTrustedDevices = true
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.Get("/accounts/999999/devices")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("with trusted devices", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "trusting@test.com", []byte("bar"))
+		require.NoError(t, err)
+		device, err := app.TrustedDeviceStore.Create(context.Background(), account.ID, "Firefox")
+		require.NoError(t, err)
+
+		res, err := client.Get(fmt.Sprintf("/accounts/%v/devices", account.ID))
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		var devices []models.TrustedDevice
+		err = test.ExtractResult(res, &devices)
+		require.NoError(t, err)
+		if assert.Len(t, devices, 1) {
+			assert.Equal(t, device.ID, devices[0].ID)
+		}
+	})
+}
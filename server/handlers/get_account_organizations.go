@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+)
+
+func GetAccountOrganizations(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, "account")
+			return
+		}
+
+		_, err = services.AccountGetter(r.Context(), app.AccountStore, id)
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, "account")
+				return
+			}
+
+			panic(err)
+		}
+
+		memberships, err := app.OrganizationStore.GetMemberships(r.Context(), id)
+		if err != nil {
+			panic(err)
+		}
+
+		WriteData(w, http.StatusOK, memberships)
+	}
+}
@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountOrganizations(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.Get("/accounts/9999/organizations")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("valid account", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "user@example.com", []byte("password"))
+		require.NoError(t, err)
+		organization, err := app.OrganizationStore.Create(context.Background(), "Acme")
+		require.NoError(t, err)
+		err = app.OrganizationStore.SetMembership(context.Background(), organization.ID, account.ID, models.NameList{"admin"})
+		require.NoError(t, err)
+
+		res, err := client.Get("/accounts/" + strconv.Itoa(account.ID) + "/organizations")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result []struct {
+			OrganizationID   int      `json:"organization_id"`
+			OrganizationName string   `json:"organization_name"`
+			Roles            []string `json:"roles"`
+		}
+		require.NoError(t, test.ExtractResult(res, &result))
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, organization.ID, result[0].OrganizationID)
+			assert.Equal(t, "Acme", result[0].OrganizationName)
+			assert.Equal(t, []string{"admin"}, result[0].Roles)
+		}
+	})
+}
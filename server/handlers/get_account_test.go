@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"sort"
@@ -34,16 +35,16 @@ func TestGetAccount(t *testing.T) {
 	})
 
 	t.Run("valid account", func(t *testing.T) {
-		account, err := app.AccountStore.Create("unlocked@test.com", []byte("bar"))
+		account, err := app.AccountStore.Create(context.Background(), "unlocked@test.com", []byte("bar"))
 		require.NoError(t, err)
 
-		err = app.AccountStore.AddOauthAccount(account.ID, "test", "ID1", "email", "TOKEN1")
+		err = app.AccountStore.AddOauthAccount(context.Background(), account.ID, "test", "ID1", "email", "TOKEN1")
 		require.NoError(t, err)
 
-		err = app.AccountStore.AddOauthAccount(account.ID, "trial", "ID2", "email", "TOKEN2")
+		err = app.AccountStore.AddOauthAccount(context.Background(), account.ID, "trial", "ID2", "email", "TOKEN2")
 		require.NoError(t, err)
 
-		oauthAccounts, err := app.AccountStore.GetOauthAccounts(account.ID)
+		oauthAccounts, err := app.AccountStore.GetOauthAccounts(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		res, err := client.Get(fmt.Sprintf("/accounts/%v", account.ID))
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -15,7 +16,7 @@ func TestGetAccountsAvailable(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	account, err := app.AccountStore.Create("existing@test.com", []byte("bar"))
+	account, err := app.AccountStore.Create(context.Background(), "existing@test.com", []byte("bar"))
 	require.NoError(t, err)
 
 	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+)
+
+func GetClient(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, err := app.ClientStore.Find(r.Context(), mux.Vars(r)["id"])
+		if err != nil {
+			panic(err)
+		}
+		if client == nil {
+			WriteNotFound(w, "client")
+			return
+		}
+
+		WriteData(w, http.StatusOK, client)
+	}
+}
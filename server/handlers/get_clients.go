@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+)
+
+func GetClients(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		clients, err := app.ClientStore.FindAll(r.Context())
+		if err != nil {
+			panic(err)
+		}
+
+		WriteData(w, http.StatusOK, clients)
+	}
+}
@@ -0,0 +1,36 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetClients(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	err := app.ClientStore.Create(context.Background(), &models.Client{ID: "a1b2c3", Name: "billing", Secret: []byte("hashed")})
+	require.NoError(t, err)
+
+	res, err := client.Get("/clients")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	var result []map[string]interface{}
+	require.NoError(t, test.ExtractResult(res, &result))
+	if assert.Len(t, result, 1) {
+		assert.Equal(t, "a1b2c3", result[0]["id"])
+		assert.Equal(t, "billing", result[0]["name"])
+		assert.NotContains(t, result[0], "secret")
+	}
+}
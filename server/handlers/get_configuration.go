@@ -4,17 +4,58 @@ import (
 	"net/http"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/private"
 )
 
 func GetConfiguration(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		WriteJSON(w, http.StatusOK, map[string]interface{}{
-			"issuer":                                app.Config.AuthNURL.String(),
+		// tenants with their own issuer are discovered from its host
+		issuer := app.Config.AuthNURL.String()
+		for _, tenant := range app.Config.Tenants {
+			if tenant.Issuer != nil && tenant.Issuer.Host == r.Host {
+				issuer = tenant.Issuer.String()
+			}
+		}
+
+		configuration := map[string]interface{}{
+			"issuer":                                issuer,
 			"response_types_supported":              []string{"id_token"},
 			"subject_types_supported":               []string{"public"},
-			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"id_token_signing_alg_values_supported": signingAlgorithms(app),
 			"claims_supported":                      []string{"iss", "sub", "aud", "exp", "iat", "auth_time"},
-			"jwks_uri":                              app.Config.AuthNURL.String() + "/jwks",
-		})
+			"jwks_uri":                              issuer + "/jwks",
+		}
+		if app.Config.DeviceVerificationURL != nil {
+			configuration["device_authorization_endpoint"] = issuer + "/device/code"
+		}
+		WriteJSON(w, http.StatusOK, configuration)
+	}
+}
+
+// signingAlgorithms lists the algorithms of the published keys, starting with the current key. When
+// AuthN generates its own keys, the configured algorithm is also listed because it will be used for
+// the next generated key.
+func signingAlgorithms(app *app.App) []string {
+	algs := []string{}
+	seen := map[string]bool{}
+	add := func(alg string) {
+		if alg != "" && !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+
+	if app.KeyStore != nil && app.KeyStore.Key() != nil {
+		add(app.KeyStore.Key().JWK.Algorithm)
+		for _, key := range app.KeyStore.Keys() {
+			add(key.JWK.Algorithm)
+		}
+	}
+	if app.KeyRotater != nil || len(algs) == 0 {
+		add(app.Config.IdentitySigningAlgorithm)
+	}
+	if len(algs) == 0 {
+		add(private.RS256)
 	}
+	return algs
 }
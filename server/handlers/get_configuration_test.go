@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/private"
 	"github.com/keratin/authn-server/server/test"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -37,3 +39,81 @@ func TestGetConfiguration(t *testing.T) {
 	require.NoError(t, json.Unmarshal(body, &data))
 	assert.Equal(t, "https://authn.example.com/foo/jwks", data.JWKSURI)
 }
+
+func TestGetConfigurationForTenantIssuer(t *testing.T) {
+	app := &app.App{
+		Config: &app.Config{
+			AuthNURL: &url.URL{Scheme: "https", Host: "authn.example.com"},
+			Tenants: map[string]*app.TenantConfig{
+				"acme": {Issuer: &url.URL{Scheme: "https", Host: "auth.acme.example.com"}},
+			},
+		},
+		Logger: logrus.New(),
+	}
+	server := test.Server(app)
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/configuration", server.URL), nil)
+	require.NoError(t, err)
+	req.Host = "auth.acme.example.com"
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	data := struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+	require.NoError(t, json.Unmarshal(test.ReadBody(res), &data))
+	assert.Equal(t, "https://auth.acme.example.com", data.Issuer)
+	assert.Equal(t, "https://auth.acme.example.com/jwks", data.JWKSURI)
+}
+
+func TestGetConfigurationAlgorithms(t *testing.T) {
+	ecKey, err := private.GenerateKeyFor(private.ES256, 0)
+	require.NoError(t, err)
+	edKey, err := private.GenerateKeyFor(private.EdDSA, 0)
+	require.NoError(t, err)
+	keyStore := data.NewRotatingKeyStore()
+	keyStore.Reset([]*private.Key{edKey, ecKey}, nil)
+	app := &app.App{
+		KeyStore: keyStore,
+		Config: &app.Config{
+			AuthNURL:                 &url.URL{Scheme: "https", Host: "authn.example.com"},
+			IdentitySigningAlgorithm: private.RS256,
+		},
+		Logger: logrus.New(),
+	}
+	server := test.Server(app)
+	defer server.Close()
+
+	res, err := http.Get(fmt.Sprintf("%s/configuration", server.URL))
+	require.NoError(t, err)
+	body := test.ReadBody(res)
+
+	data := struct {
+		Algorithms []string `json:"id_token_signing_alg_values_supported"`
+	}{}
+	require.NoError(t, json.Unmarshal(body, &data))
+	assert.Equal(t, []string{"ES256", "EdDSA"}, data.Algorithms)
+}
+
+func TestGetConfigurationDeviceAuthorization(t *testing.T) {
+	app := &app.App{
+		Config: &app.Config{
+			AuthNURL:              &url.URL{Scheme: "https", Host: "authn.example.com"},
+			DeviceVerificationURL: &url.URL{Scheme: "https", Host: "app.example.com", Path: "/device"},
+		},
+		Logger: logrus.New(),
+	}
+	server := test.Server(app)
+	defer server.Close()
+
+	res, err := http.Get(fmt.Sprintf("%s/configuration", server.URL))
+	require.NoError(t, err)
+
+	data := struct {
+		DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	}{}
+	require.NoError(t, json.Unmarshal(test.ReadBody(res), &data))
+	assert.Equal(t, "https://authn.example.com/device/code", data.DeviceAuthorizationEndpoint)
+}
@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDevices(t *testing.T) {
+	app := test.App()
+	app.Config.EnableTrustedDevices = true
+	server := test.Server(app)
+	defer server.Close()
+
+	t.Run("with trusted devices", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "trusting@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		device, err := app.TrustedDeviceStore.Create(context.Background(), account.ID, "Firefox")
+		require.NoError(t, err)
+		_, err = app.TrustedDeviceStore.Create(context.Background(), account.ID+1, "Safari")
+		require.NoError(t, err)
+
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Get("/devices")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		var devices []models.TrustedDevice
+		err = test.ExtractResult(res, &devices)
+		require.NoError(t, err)
+		if assert.Len(t, devices, 1) {
+			assert.Equal(t, device.ID, devices[0].ID)
+			assert.Equal(t, "Firefox", devices[0].Name)
+		}
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		res, err := client.Get("/devices")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
@@ -1,10 +1,12 @@
 package handlers_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
 
+	"github.com/go-jose/go-jose/v3"
 	"github.com/keratin/authn-server/app/data/private"
 	"github.com/sirupsen/logrus"
 
@@ -36,6 +38,31 @@ func TestGetJWKs(t *testing.T) {
 	assert.NotEmpty(t, body)
 }
 
+func TestGetJWKsWithEllipticKeys(t *testing.T) {
+	for _, alg := range []string{private.ES256, private.EdDSA} {
+		key, err := private.GenerateKeyFor(alg, 0)
+		require.NoError(t, err)
+		app := &app.App{
+			KeyStore: mock.NewKeyStore(key),
+			Config:   &app.Config{},
+			Logger:   logrus.New(),
+		}
+
+		server := test.Server(app)
+		res, err := http.Get(fmt.Sprintf("%s/jwks", server.URL))
+		require.NoError(t, err)
+		body := test.ReadBody(res)
+		server.Close()
+
+		jwks := jose.JSONWebKeySet{}
+		require.NoError(t, json.Unmarshal(body, &jwks))
+		require.Len(t, jwks.Keys, 1)
+		assert.Equal(t, alg, jwks.Keys[0].Algorithm)
+		assert.Equal(t, key.JWK.KeyID, jwks.Keys[0].KeyID)
+		assert.Equal(t, key.Public(), jwks.Keys[0].Key)
+	}
+}
+
 func BenchmarkGetJWKs(b *testing.B) {
 	rsaKey, _ := private.GenerateKey(2048)
 	app := &app.App{
@@ -13,11 +13,10 @@ import (
 
 func GetOauth(app *app.App, providerName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		provider := app.OauthProviders[providerName]
-
 		// require and validate a redirect URI
 		redirectURI := r.FormValue("redirect_uri")
-		if route.FindDomain(redirectURI, app.Config.ApplicationDomains) == nil {
+		domain := route.FindDomain(redirectURI, app.Config.ApplicationDomains)
+		if domain == nil {
 			app.Reporter.ReportRequestError(errors.New("unknown redirect domain"), r)
 			failsafe := app.Config.ApplicationDomains[0].URL()
 			http.Redirect(w, r, failsafe.String(), http.StatusSeeOther)
@@ -30,6 +29,13 @@ func GetOauth(app *app.App, providerName string) http.HandlerFunc {
 			redirectFailure(w, r, redirectURI)
 		}
 
+		// the tenant of the redirect domain may have its own credentials
+		provider, ok := app.OauthProvider(app.Config.TenantFor(domain.String()), providerName)
+		if !ok {
+			fail(errors.New("provider not configured for tenant"))
+			return
+		}
+
 		// set nonce in a secured cookie
 		bytes, err := lib.GenerateToken()
 		if err != nil {
@@ -16,7 +16,7 @@ func GetOauthAccounts(app *app.App) http.HandlerFunc {
 			return
 		}
 
-		account, err := services.AccountGetter(app.AccountStore, accountID)
+		account, err := services.AccountGetter(r.Context(), app.AccountStore, accountID)
 		if err != nil {
 			WriteErrors(w, err)
 			return
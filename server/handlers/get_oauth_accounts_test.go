@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -38,10 +39,10 @@ func TestGetOauthInfo(t *testing.T) {
 			}
 		}
 
-		account, err := app.AccountStore.Create("get-oauth-info@keratin.tech", []byte("password"))
+		account, err := app.AccountStore.Create(context.Background(), "get-oauth-info@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		err = app.AccountStore.AddOauthAccount(account.ID, "test", "ID", "email", "TOKEN")
+		err = app.AccountStore.AddOauthAccount(context.Background(), account.ID, "test", "ID", "email", "TOKEN")
 		require.NoError(t, err)
 
 		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
@@ -9,13 +9,13 @@ import (
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/sessions"
 )
 
 func GetOauthReturn(app *app.App, providerName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		provider := app.OauthProviders[providerName]
-
 		// verify the state and nonce
 		state, err := getState(app.Config, r)
 		if err != nil {
@@ -32,6 +32,20 @@ func GetOauthReturn(app *app.App, providerName string) http.HandlerFunc {
 			redirectFailure(w, r, state.Destination)
 		}
 
+		// sessions from this flow are issued for the domain of the destination, so accounts belong to
+		// its tenant. the destination was checked when the state was signed, but may no longer match
+		// a domain after a configuration change.
+		domain := route.FindDomain(state.Destination, app.Config.ApplicationDomains)
+		if domain == nil {
+			domain = &app.Config.ApplicationDomains[0]
+		}
+		tenant := app.Config.TenantFor(domain.String())
+		provider, ok := app.OauthProvider(tenant, providerName)
+		if !ok {
+			fail(errors.New("provider not configured for tenant"))
+			return
+		}
+
 		// exchange code for tokens and user info
 		returnURL := app.Config.AuthNURL.String() + "/oauth/" + providerName + "/return"
 		config, err := provider.Config(returnURL)
@@ -50,9 +64,11 @@ func GetOauthReturn(app *app.App, providerName string) http.HandlerFunc {
 			return
 		}
 
+		ctx := tenants.WithContext(r.Context(), tenant)
+
 		// attempt to reconcile oauth identity information into an authn account
 		sessionAccountID := sessions.GetAccountID(r)
-		account, err := services.IdentityReconciler(app.AccountStore, app.Config, providerName, providerUser, tok, sessionAccountID)
+		account, err := services.IdentityReconciler(ctx, app.AccountStore, app.Config, providerName, providerUser, tok, sessionAccountID)
 		if err != nil {
 			fail(err)
 			return
@@ -62,8 +78,8 @@ func GetOauthReturn(app *app.App, providerName string) http.HandlerFunc {
 
 		// identityToken is not returned in this flow. it must be imported by the frontend like a SSO session.
 		sessionToken, _, err := services.SessionCreator(
-			app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
-			account.ID, &app.Config.ApplicationDomains[0], sessions.GetRefreshToken(r), amr,
+			ctx, app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+			account.ID, domain, sessions.GetRefreshToken(r), amr,
 		)
 		if err != nil {
 			fail(errors.Wrap(err, "NewSession"))
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -8,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/keratin/authn-server/app/tenants"
 	oauthtoken "github.com/keratin/authn-server/app/tokens/oauth"
 	oauthlib "github.com/keratin/authn-server/lib/oauth"
 	"github.com/keratin/authn-server/lib/route"
@@ -52,14 +54,14 @@ func TestGetOauthReturn(t *testing.T) {
 		test.AssertSession(t, app.Config, res.Cookies(), "oauth:test")
 
 		// creates an account
-		account, err := app.AccountStore.FindByOauthAccount("test", "something")
+		account, err := app.AccountStore.FindByOauthAccount(context.Background(), "test", "something")
 		require.NoError(t, err)
 		assert.NotNil(t, account)
 		assert.Equal(t, "something", account.Username)
 	})
 
 	t.Run("connect new identity with current session", func(t *testing.T) {
-		account, err := app.AccountStore.Create("existing@keratin.tech", []byte("password"))
+		account, err := app.AccountStore.Create(context.Background(), "existing@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
 
@@ -71,9 +73,9 @@ func TestGetOauthReturn(t *testing.T) {
 	})
 
 	t.Run("not connect new identity with current session that is already linked", func(t *testing.T) {
-		account, err := app.AccountStore.Create("linked@keratin.tech", []byte("password"))
+		account, err := app.AccountStore.Create(context.Background(), "linked@keratin.tech", []byte("password"))
 		require.NoError(t, err)
-		err = app.AccountStore.AddOauthAccount(account.ID, "test", "PREVIOUSID", "email", "TOKEN")
+		err = app.AccountStore.AddOauthAccount(context.Background(), account.ID, "test", "PREVIOUSID", "email", "TOKEN")
 		require.NoError(t, err)
 		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
 
@@ -83,13 +85,13 @@ func TestGetOauthReturn(t *testing.T) {
 	})
 
 	t.Run("not connect provider account already linked", func(t *testing.T) {
-		linkedAccount, err := app.AccountStore.Create("linked.account@keratin.tech", []byte("password"))
+		linkedAccount, err := app.AccountStore.Create(context.Background(), "linked.account@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		err = app.AccountStore.AddOauthAccount(linkedAccount.ID, "test", "LINKEDID", "email", "TOKEN")
+		err = app.AccountStore.AddOauthAccount(context.Background(), linkedAccount.ID, "test", "LINKEDID", "email", "TOKEN")
 		require.NoError(t, err)
 
-		account, err := app.AccountStore.Create("registered.account@keratin.tech", []byte("password"))
+		account, err := app.AccountStore.Create(context.Background(), "registered.account@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
 		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
@@ -100,9 +102,9 @@ func TestGetOauthReturn(t *testing.T) {
 	})
 
 	t.Run("log in to existing identity", func(t *testing.T) {
-		account, err := app.AccountStore.Create("registered@keratin.tech", []byte("password"))
+		account, err := app.AccountStore.Create(context.Background(), "registered@keratin.tech", []byte("password"))
 		require.NoError(t, err)
-		err = app.AccountStore.AddOauthAccount(account.ID, "test", "REGISTEREDID", "email", "TOKEN")
+		err = app.AccountStore.AddOauthAccount(context.Background(), account.ID, "test", "REGISTEREDID", "email", "TOKEN")
 		require.NoError(t, err)
 
 		// codes don't normally specify the id, but our test provider is set up to reflect the code
@@ -115,9 +117,9 @@ func TestGetOauthReturn(t *testing.T) {
 	})
 
 	t.Run("log in to locked identity", func(t *testing.T) {
-		account, err := app.AccountStore.Create("locked@keratin.tech", []byte("password"))
+		account, err := app.AccountStore.Create(context.Background(), "locked@keratin.tech", []byte("password"))
 		require.NoError(t, err)
-		_, err = app.AccountStore.Lock(account.ID)
+		_, err = app.AccountStore.Lock(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		res, err := client.Get("/oauth/test/return?code=locked@keratin.tech&state=" + state)
@@ -126,7 +128,7 @@ func TestGetOauthReturn(t *testing.T) {
 	})
 
 	t.Run("email collision", func(t *testing.T) {
-		_, err := app.AccountStore.Create("collision@keratin.tech", []byte("password"))
+		_, err := app.AccountStore.Create(context.Background(), "collision@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
 		res, err := client.Get("/oauth/test/return?code=collision@keratin.tech&state=" + state)
@@ -147,3 +149,57 @@ func TestGetOauthReturn(t *testing.T) {
 		test.AssertRedirect(t, res, "http://test.com")
 	})
 }
+
+func TestGetOauthReturnWithTenants(t *testing.T) {
+	providerServer := httptest.NewServer(test.ProviderApp())
+	defer providerServer.Close()
+
+	// only the acme tenant has credentials for the provider
+	app := test.App()
+	app.Config.ApplicationDomains = []route.Domain{{Hostname: "test.com"}, {Hostname: "acme.com"}}
+	app.Config.TenantDomains = map[string]string{"acme.com": "acme"}
+	app.TenantOauthProviders = map[string]map[string]oauthlib.Provider{
+		"acme": {"test": *oauthlib.NewTestProvider(providerServer)},
+	}
+	server := test.Server(app)
+	defer server.Close()
+
+	nonce := "rand123"
+	client := route.NewClient(server.URL).WithCookie(&http.Cookie{
+		Name:  app.Config.OAuthCookieName,
+		Value: nonce,
+	})
+	http.DefaultClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	t.Run("returning to a domain of the tenant", func(t *testing.T) {
+		token, err := oauthtoken.New(app.Config, nonce, "https://acme.com/return")
+		require.NoError(t, err)
+		state, err := token.Sign(app.Config.OAuthSigningKey)
+		require.NoError(t, err)
+
+		res, err := client.Get("/oauth/test/return?code=acme-user&state=" + state)
+		require.NoError(t, err)
+		if !test.AssertRedirect(t, res, "https://acme.com/return") {
+			return
+		}
+		test.AssertSession(t, app.Config, res.Cookies(), "oauth:test")
+
+		account, err := app.AccountStore.FindByOauthAccount(tenants.WithContext(context.Background(), "acme"), "test", "acme-user")
+		require.NoError(t, err)
+		require.NotNil(t, account)
+		assert.Equal(t, "acme", account.Tenant)
+	})
+
+	t.Run("returning to a domain of another tenant", func(t *testing.T) {
+		token, err := oauthtoken.New(app.Config, nonce, "https://test.com/return")
+		require.NoError(t, err)
+		state, err := token.Sign(app.Config.OAuthSigningKey)
+		require.NoError(t, err)
+
+		res, err := client.Get("/oauth/test/return?code=other-user&state=" + state)
+		require.NoError(t, err)
+		test.AssertRedirect(t, res, "https://test.com/return?status=failed")
+	})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+)
+
+func GetOrganization(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, "organization")
+			return
+		}
+
+		organization, err := app.OrganizationStore.Find(r.Context(), id)
+		if err != nil {
+			panic(err)
+		}
+		if organization == nil {
+			WriteNotFound(w, "organization")
+			return
+		}
+
+		WriteData(w, http.StatusOK, organization)
+	}
+}
@@ -0,0 +1,49 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrganizationMembers(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("unknown organization", func(t *testing.T) {
+		res, err := client.Get("/organizations/9999/members")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("valid organization", func(t *testing.T) {
+		organization, err := app.OrganizationStore.Create(context.Background(), "Acme")
+		require.NoError(t, err)
+		err = app.OrganizationStore.SetMembership(context.Background(), organization.ID, 123, models.NameList{"admin"})
+		require.NoError(t, err)
+
+		res, err := client.Get("/organizations/" + strconv.Itoa(organization.ID) + "/members")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result []struct {
+			AccountID int      `json:"account_id"`
+			Roles     []string `json:"roles"`
+		}
+		require.NoError(t, test.ExtractResult(res, &result))
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, 123, result[0].AccountID)
+			assert.Equal(t, []string{"admin"}, result[0].Roles)
+		}
+	})
+}
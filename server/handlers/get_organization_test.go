@@ -0,0 +1,40 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrganization(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("unknown organization", func(t *testing.T) {
+		res, err := client.Get("/organizations/9999")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("valid organization", func(t *testing.T) {
+		organization, err := app.OrganizationStore.Create(context.Background(), "Acme")
+		require.NoError(t, err)
+
+		res, err := client.Get("/organizations/" + strconv.Itoa(organization.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result map[string]interface{}
+		require.NoError(t, test.ExtractResult(res, &result))
+		assert.Equal(t, "Acme", result["name"])
+	})
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+// GetOrganizations lists the organizations of the current session's user, with their roles.
+func GetOrganizations(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		memberships, err := app.OrganizationStore.GetMemberships(r.Context(), accountID)
+		if err != nil {
+			panic(err)
+		}
+
+		WriteData(w, http.StatusOK, memberships)
+	}
+}
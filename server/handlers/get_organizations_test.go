@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrganizations(t *testing.T) {
+	app := test.App()
+	app.Config.EnableOrganizations = true
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+
+	t.Run("without a session", func(t *testing.T) {
+		res, err := client.Get("/organizations")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("with a session", func(t *testing.T) {
+		accountID := 8642
+		organization, err := app.OrganizationStore.Create(context.Background(), "Acme")
+		require.NoError(t, err)
+		err = app.OrganizationStore.SetMembership(context.Background(), organization.ID, accountID, models.NameList{"admin"})
+		require.NoError(t, err)
+		err = app.OrganizationStore.SetMembership(context.Background(), organization.ID, 1234, models.NameList{"viewer"})
+		require.NoError(t, err)
+
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, accountID)
+		res, err := client.WithCookie(session).Get("/organizations")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result []struct {
+			OrganizationName string   `json:"organization_name"`
+			Roles            []string `json:"roles"`
+		}
+		require.NoError(t, test.ExtractResult(res, &result))
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, "Acme", result[0].OrganizationName)
+			assert.Equal(t, []string{"admin"}, result[0].Roles)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPersonalAccessTokens(t *testing.T) {
+	app := test.App()
+	app.Config.EnablePersonalAccessTokens = true
+	server := test.Server(app)
+	defer server.Close()
+
+	t.Run("with tokens", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "tokens@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		_, err = app.AccountStore.AddPersonalAccessToken(context.Background(), account.ID, "cli", "hash", models.NameList{"read"}, "test.com", nil)
+		require.NoError(t, err)
+
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Get("/personal_access_tokens")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		body := test.ReadBody(res)
+		assert.Contains(t, string(body), `"name":"cli"`)
+		assert.Contains(t, string(body), `"scopes":["read"]`)
+		assert.NotContains(t, string(body), "hash")
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		res, err := client.Get("/personal_access_tokens")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
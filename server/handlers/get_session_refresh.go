@@ -15,13 +15,42 @@ func GetSessionRefresh(app *app.App) http.HandlerFunc {
 		// check for valid session with live token
 		accountID := sessions.GetAccountID(r)
 		if accountID == 0 {
+			if sessions.GetEnrollingAccountID(r) != 0 {
+				WriteErrors(w, services.FieldErrors{{Field: "totp", Message: services.ErrRequired}})
+				return
+			}
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
+		session := sessions.Get(r)
+		if app.Config.RefreshTokenRotation {
+			rotated, sessionToken, err := services.SessionRotator(
+				r.Context(), app.RefreshTokenStore, app.Config, session, accountID,
+			)
+			if err != nil {
+				panic(errors.Wrap(err, "SessionRotator"))
+			}
+			if rotated == nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			sessions.Set(app.Config, w, sessionToken)
+			session = rotated
+		} else if app.Config.SessionIdleTimeout.For(session.Azp) > 0 {
+			// record the activity for the idle timeout
+			session = session.Refreshed()
+			sessionToken, err := session.Sign(app.Config.SessionSigningKey)
+			if err != nil {
+				panic(errors.Wrap(err, "Sign"))
+			}
+			sessions.Set(app.Config, w, sessionToken)
+		}
+
 		identityToken, err := services.SessionRefresher(
-			app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
-			sessions.Get(r), accountID, route.MatchedDomain(r),
+			r.Context(), app.RefreshTokenStore, app.OrganizationStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+			session, accountID, route.MatchedDomain(r),
 		)
 		if err != nil {
 			panic(errors.Wrap(err, "IdentityForSession"))
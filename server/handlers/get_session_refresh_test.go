@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/data/redis"
 	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/keratin/authn-server/app/tokens/sessions"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/ops"
 	"github.com/keratin/authn-server/server/test"
@@ -53,7 +55,7 @@ func BenchmarkGetSessionRefresh(b *testing.B) {
 		testApp := test.App()
 		server := test.Server(testApp)
 		defer server.Close()
-		testApp.RefreshTokenStore = &sqlite3.RefreshTokenStore{Ext: sqliteDB, TTL: time.Hour}
+		testApp.RefreshTokenStore = &sqlite3.RefreshTokenStore{ExtContext: sqliteDB, TTL: time.Hour}
 		client := route.NewClient(server.URL).
 			Referred(&testApp.Config.ApplicationDomains[0]).
 			WithCookie(test.CreateSession(testApp.RefreshTokenStore, testApp.Config, 12345))
@@ -146,3 +148,92 @@ func TestGetSessionRefreshFailure(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
 	}
 }
+
+func TestGetSessionRefreshRotation(t *testing.T) {
+	testApp := test.App()
+	testApp.Config.RefreshTokenRotation = true
+	server := test.Server(testApp)
+	defer server.Close()
+
+	accountID := 82595
+	existingSession := test.CreateSession(testApp.RefreshTokenStore, testApp.Config, accountID)
+	client := route.NewClient(server.URL).Referred(&testApp.Config.ApplicationDomains[0])
+
+	res, err := client.WithCookie(existingSession).Get("/session/refresh")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	test.AssertIDTokenResponse(t, res, testApp.KeyStore, testApp.Config)
+
+	rotatedSession := test.ReadCookie(res.Cookies(), testApp.Config.SessionCookieName)
+	require.NotNil(t, rotatedSession)
+	assert.NotEqual(t, existingSession.Value, rotatedSession.Value)
+
+	t.Run("with the rotated session", func(t *testing.T) {
+		res, err := client.WithCookie(rotatedSession).Get("/session/refresh")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		rotatedSession = test.ReadCookie(res.Cookies(), testApp.Config.SessionCookieName)
+		require.NotNil(t, rotatedSession)
+	})
+
+	t.Run("with a replaced session", func(t *testing.T) {
+		res, err := client.WithCookie(existingSession).Get("/session/refresh")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		// the entire family has been revoked
+		res, err = client.WithCookie(rotatedSession).Get("/session/refresh")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		tokens, err := testApp.RefreshTokenStore.FindAll(context.Background(), accountID)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+}
+
+func TestGetSessionRefreshRotationGrace(t *testing.T) {
+	testApp := test.App()
+	testApp.Config.RefreshTokenRotation = true
+	testApp.Config.RefreshTokenReuseGrace = time.Minute
+	server := test.Server(testApp)
+	defer server.Close()
+
+	accountID := 82597
+	existingSession := test.CreateSession(testApp.RefreshTokenStore, testApp.Config, accountID)
+	client := route.NewClient(server.URL).Referred(&testApp.Config.ApplicationDomains[0])
+
+	res, err := client.WithCookie(existingSession).Get("/session/refresh")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	rotatedSession := test.ReadCookie(res.Cookies(), testApp.Config.SessionCookieName)
+	require.NotNil(t, rotatedSession)
+
+	// a concurrent refresh with the replaced session does not revoke the family
+	res, err = client.WithCookie(existingSession).Get("/session/refresh")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	res, err = client.WithCookie(rotatedSession).Get("/session/refresh")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+}
+
+func TestGetSessionRefreshIdleTimeout(t *testing.T) {
+	testApp := test.App()
+	testApp.Config.SessionIdleTimeout = app.DomainDurations{Default: time.Hour}
+	server := test.Server(testApp)
+	defer server.Close()
+
+	existingSession := test.CreateSession(testApp.RefreshTokenStore, testApp.Config, 82596)
+	client := route.NewClient(server.URL).Referred(&testApp.Config.ApplicationDomains[0]).WithCookie(existingSession)
+	res, err := client.Get("/session/refresh")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	refreshedSession := test.ReadCookie(res.Cookies(), testApp.Config.SessionCookieName)
+	require.NotNil(t, refreshedSession)
+	claims, err := sessions.Parse(refreshedSession.Value, testApp.Config)
+	require.NoError(t, err)
+	assert.NotNil(t, claims.RefreshedAt)
+}
@@ -9,7 +9,7 @@ import (
 
 func GetSessionToken(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		account, err := app.AccountStore.FindByUsername(r.FormValue("username"))
+		account, err := app.AccountStore.FindByUsername(r.Context(), r.FormValue("username"))
 		if err != nil {
 			panic(err)
 		}
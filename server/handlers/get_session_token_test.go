@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -18,7 +19,7 @@ func TestGetSessionToken(t *testing.T) {
 	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
 
 	t.Run("known account", func(t *testing.T) {
-		_, err := app.AccountStore.Create("known@keratin.tech", []byte("pwd"))
+		_, err := app.AccountStore.Create(context.Background(), "known@keratin.tech", []byte("pwd"))
 		require.NoError(t, err)
 
 		res, err := client.Get("/session/token?username=known@keratin.tech")
@@ -8,17 +8,17 @@ import (
 
 func GetStats(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		daily, err := app.Actives.ActivesByDay()
+		daily, err := app.Actives.ActivesByDay(r.Context())
 		if err != nil {
 			panic(err)
 		}
 
-		weekly, err := app.Actives.ActivesByWeek()
+		weekly, err := app.Actives.ActivesByWeek(r.Context())
 		if err != nil {
 			panic(err)
 		}
 
-		monthly, err := app.Actives.ActivesByMonth()
+		monthly, err := app.Actives.ActivesByMonth(r.Context())
 		if err != nil {
 			panic(err)
 		}
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -15,7 +16,7 @@ func TestGetStats(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	require.NoError(t, app.Actives.Track(1))
+	require.NoError(t, app.Actives.Track(context.Background(), 1))
 
 	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
 
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func GetTOTP(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		authenticators, err := app.AccountStore.GetTOTPAuthenticators(r.Context(), accountID)
+		if err != nil {
+			panic(err)
+		}
+
+		WriteData(w, http.StatusOK, authenticators)
+	}
+}
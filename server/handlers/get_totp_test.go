@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTOTP(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	t.Run("with authenticators", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "authenticated@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		authenticator, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("test"), 0)
+		require.NoError(t, err)
+		other, err := app.AccountStore.Create(context.Background(), "other@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		_, err = app.AccountStore.AddTOTPAuthenticator(context.Background(), other.ID, "tablet", []byte("test"), 0)
+		require.NoError(t, err)
+
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Get("/totp")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		var authenticators []models.TOTPAuthenticator
+		err = test.ExtractResult(res, &authenticators)
+		require.NoError(t, err)
+		if assert.Len(t, authenticators, 1) {
+			assert.Equal(t, authenticator.ID, authenticators[0].ID)
+			assert.Equal(t, "phone", authenticators[0].Name)
+			assert.Empty(t, authenticators[0].Secret)
+		}
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		res, err := client.Get("/totp")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
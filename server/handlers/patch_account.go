@@ -23,7 +23,7 @@ func PatchAccount(app *app.App) http.HandlerFunc {
 			return
 		}
 
-		err = services.AccountUpdater(app.AccountStore, app.Config, id, user.Username)
+		err = services.AccountUpdater(r.Context(), app.AccountStore, app.Config, id, user.Username)
 		if err != nil {
 			if fe, ok := err.(services.FieldErrors); ok {
 				if fe[0].Message == services.ErrNotFound {
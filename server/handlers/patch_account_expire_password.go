@@ -17,7 +17,7 @@ func PatchAccountExpirePassword(app *app.App) http.HandlerFunc {
 			return
 		}
 
-		err = services.PasswordExpirer(app.AccountStore, app.RefreshTokenStore, id)
+		err = services.PasswordExpirer(r.Context(), app.AccountStore, app.RefreshTokenStore, app.TrustedDeviceStore, app.KeyStore, app.Config, app.Reporter, id)
 		if err != nil {
 			if _, ok := err.(services.FieldErrors); ok {
 				WriteNotFound(w, "account")
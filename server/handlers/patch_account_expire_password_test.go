@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -26,14 +27,14 @@ func TestPatchAccountExpirePassword(t *testing.T) {
 	})
 
 	t.Run("active account", func(t *testing.T) {
-		account, err := app.AccountStore.Create("active@test.com", []byte("bar"))
+		account, err := app.AccountStore.Create(context.Background(), "active@test.com", []byte("bar"))
 		require.NoError(t, err)
 
 		res, err := client.Patch(fmt.Sprintf("/accounts/%v/expire_password", account.ID), url.Values{})
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 
-		account, err = app.AccountStore.Find(account.ID)
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.True(t, account.RequireNewPassword)
 	})
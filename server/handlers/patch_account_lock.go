@@ -17,7 +17,7 @@ func PatchAccountLock(app *app.App) http.HandlerFunc {
 			return
 		}
 
-		err = services.AccountLocker(app.AccountStore, app.RefreshTokenStore, id)
+		err = services.AccountLocker(r.Context(), app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Config, app.Reporter, id)
 		if err != nil {
 			if _, ok := err.(services.FieldErrors); ok {
 				WriteNotFound(w, "account")
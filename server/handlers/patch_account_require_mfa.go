@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PatchAccountRequireMFA(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, "account")
+			return
+		}
+
+		var params struct {
+			Required string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+		required := true
+		if params.Required != "" {
+			required, err = regexp.MatchString("^(?i:t|true|yes)$", params.Required)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		err = services.AccountMFARequirer(r.Context(), app.AccountStore, id, required)
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, "account")
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,58 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchAccountRequireMFA(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.Patch("/accounts/999999/require_mfa", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("requiring MFA", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "required@test.com", []byte("bar"))
+		require.NoError(t, err)
+
+		res, err := client.Patch(fmt.Sprintf("/accounts/%v/require_mfa", account.ID), url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.True(t, account.RequireMFA)
+	})
+
+	t.Run("no longer requiring MFA", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "optional@test.com", []byte("bar"))
+		require.NoError(t, err)
+		_, err = app.AccountStore.SetRequireMFA(context.Background(), account.ID, true)
+		require.NoError(t, err)
+
+		res, err := client.Patch(fmt.Sprintf("/accounts/%v/require_mfa", account.ID), url.Values{
+			"required": []string{"false"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.False(t, account.RequireMFA)
+	})
+}
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -28,33 +29,33 @@ func TestPatchAccount(t *testing.T) {
 	})
 
 	t.Run("existing account", func(t *testing.T) {
-		account, err := app.AccountStore.Create("one@test.com", []byte("bar"))
+		account, err := app.AccountStore.Create(context.Background(), "one@test.com", []byte("bar"))
 		require.NoError(t, err)
 
 		res, err := client.Patch(fmt.Sprintf("/accounts/%v", account.ID), url.Values{"username": []string{"newname"}})
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 
-		account, err = app.AccountStore.Find(account.ID)
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.Equal(t, "newname", account.Username)
 	})
 
 	t.Run("existing account using JSON", func(t *testing.T) {
-		account, err := app.AccountStore.Create("two@test.com", []byte("bar"))
+		account, err := app.AccountStore.Create(context.Background(), "two@test.com", []byte("bar"))
 		require.NoError(t, err)
 
 		res, err := client.PatchJSON(fmt.Sprintf("/accounts/%v", account.ID), "{\"username\": \"secondUsername\"}")
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 
-		account, err = app.AccountStore.Find(account.ID)
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.Equal(t, "secondUsername", account.Username)
 	})
 
 	t.Run("bad username", func(t *testing.T) {
-		account, err := app.AccountStore.Create("three@test.com", []byte("bar"))
+		account, err := app.AccountStore.Create(context.Background(), "three@test.com", []byte("bar"))
 		require.NoError(t, err)
 
 		res, err := client.Patch(fmt.Sprintf("/accounts/%v", account.ID), url.Values{"username": []string{""}})
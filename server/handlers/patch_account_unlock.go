@@ -17,7 +17,7 @@ func PatchAccountUnlock(app *app.App) http.HandlerFunc {
 			return
 		}
 
-		err = services.AccountUnlocker(app.AccountStore, id)
+		err = services.AccountUnlocker(r.Context(), app.AccountStore, id)
 		if err != nil {
 			if _, ok := err.(services.FieldErrors); ok {
 				WriteNotFound(w, "account")
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -26,29 +27,29 @@ func TestPatchAccountUnlock(t *testing.T) {
 	})
 
 	t.Run("unlocked account", func(t *testing.T) {
-		account, err := app.AccountStore.Create("unlocked@test.com", []byte("bar"))
+		account, err := app.AccountStore.Create(context.Background(), "unlocked@test.com", []byte("bar"))
 		require.NoError(t, err)
 
 		res, err := client.Patch(fmt.Sprintf("/accounts/%v/unlock", account.ID), url.Values{})
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 
-		account, err = app.AccountStore.Find(account.ID)
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.False(t, account.Locked)
 	})
 
 	t.Run("locked account", func(t *testing.T) {
-		account, err := app.AccountStore.Create("locked@test.com", []byte("bar"))
+		account, err := app.AccountStore.Create(context.Background(), "locked@test.com", []byte("bar"))
 		require.NoError(t, err)
-		_, err = app.AccountStore.Lock(account.ID)
+		_, err = app.AccountStore.Lock(context.Background(), account.ID)
 		require.NoError(t, err)
 
 		res, err := client.Patch(fmt.Sprintf("/accounts/%v/unlock", account.ID), url.Values{})
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 
-		account, err = app.AccountStore.Find(account.ID)
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.False(t, account.Locked)
 	})
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PatchClient(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			Name      string
+			Scopes    string
+			Audiences string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		err := services.ClientUpdater(
+			r.Context(), app.ClientStore, mux.Vars(r)["id"],
+			params.Name, models.ParseNameList(params.Scopes), models.ParseNameList(params.Audiences),
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				if fe[0].Message == services.ErrNotFound {
+					WriteNotFound(w, "client")
+				} else {
+					WriteErrors(w, fe)
+				}
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchClient(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	err := app.ClientStore.Create(context.Background(), &models.Client{ID: "a1b2c3", Name: "billing"})
+	require.NoError(t, err)
+
+	t.Run("unknown client", func(t *testing.T) {
+		res, err := client.Patch("/clients/abcdef", url.Values{"name": []string{"billing"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		res, err := client.Patch("/clients/a1b2c3", url.Values{"name": []string{""}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "name", Message: services.ErrMissing}})
+	})
+
+	t.Run("valid client", func(t *testing.T) {
+		res, err := client.Patch("/clients/a1b2c3", url.Values{
+			"name":      []string{"invoicing"},
+			"scopes":    []string{"read"},
+			"audiences": []string{"https://api.example.com"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.ClientStore.Find(context.Background(), "a1b2c3")
+		require.NoError(t, err)
+		assert.Equal(t, "invoicing", found.Name)
+		assert.Equal(t, models.NameList{"read"}, found.Scopes)
+		assert.Equal(t, models.NameList{"https://api.example.com"}, found.Audiences)
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PatchOrganization(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, "organization")
+			return
+		}
+
+		var params struct {
+			Name string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		err = services.OrganizationUpdater(r.Context(), app.OrganizationStore, id, params.Name)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				if fe[0].Message == services.ErrNotFound {
+					WriteNotFound(w, "organization")
+				} else {
+					WriteErrors(w, fe)
+				}
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchOrganization(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	organization, err := app.OrganizationStore.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	path := "/organizations/" + strconv.Itoa(organization.ID)
+
+	t.Run("unknown organization", func(t *testing.T) {
+		res, err := client.Patch("/organizations/9999", url.Values{"name": []string{"Globex"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		res, err := client.Patch(path, url.Values{"name": []string{""}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "name", Message: services.ErrMissing}})
+	})
+
+	t.Run("valid organization", func(t *testing.T) {
+		res, err := client.Patch(path, url.Values{"name": []string{"Globex"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.OrganizationStore.Find(context.Background(), organization.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Globex", found.Name)
+	})
+}
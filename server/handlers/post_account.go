@@ -6,6 +6,7 @@ import (
 	"github.com/keratin/authn-server/lib/parse"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/sessions"
@@ -14,20 +15,35 @@ import (
 func PostAccount(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var credentials struct {
-			Username string
-			Password string
+			Username   string
+			Password   string
+			Invitation string
 		}
 		if err := parse.Payload(r, &credentials); err != nil {
 			WriteErrors(w, err)
 			return
 		}
-		// Create the account
-		account, err := services.AccountCreator(
-			app.AccountStore,
-			app.Config,
-			credentials.Username,
-			credentials.Password,
-		)
+
+		// Create the account, with the username of an invitation if given
+		var account *models.Account
+		var err error
+		if credentials.Invitation != "" {
+			account, err = services.InvitationAcceptor(
+				r.Context(), app.AccountStore, app.InvitationStore,
+				app.Config,
+				credentials.Invitation,
+				credentials.Password,
+			)
+		} else if app.Config.EnableSignup {
+			account, err = services.AccountCreator(
+				r.Context(), app.AccountStore,
+				app.Config,
+				credentials.Username,
+				credentials.Password,
+			)
+		} else {
+			err = services.FieldErrors{{Field: "invitation", Message: services.ErrMissing}}
+		}
 		if err != nil {
 			if fe, ok := err.(services.FieldErrors); ok {
 				WriteErrors(w, fe)
@@ -40,10 +56,15 @@ func PostAccount(app *app.App) http.HandlerFunc {
 		amr := []string{"pwd"}
 
 		sessionToken, identityToken, err := services.SessionCreator(
-			app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+			r.Context(), app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
 			account.ID, route.MatchedDomain(r), sessions.GetRefreshToken(r), amr,
 		)
 		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
 			panic(err)
 		}
 
@@ -51,8 +72,6 @@ func PostAccount(app *app.App) http.HandlerFunc {
 		sessions.Set(app.Config, w, sessionToken)
 
 		// Return the signed identity token in the body
-		WriteData(w, http.StatusCreated, map[string]string{
-			"id_token": identityToken,
-		})
+		writeIdentityToken(w, identityToken)
 	}
 }
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PostAccountImpersonate(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, "account")
+			return
+		}
+
+		var params struct {
+			Actor  string
+			Domain string
+			Reason string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		sessionToken, identityToken, err := services.SessionImpersonator(
+			r.Context(), app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Config, app.Logger,
+			id, params.Domain, params.Actor, params.Reason,
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				if fe[0].Message == services.ErrNotFound {
+					WriteNotFound(w, "account")
+				} else {
+					WriteErrors(w, fe)
+				}
+				return
+			}
+
+			panic(err)
+		}
+
+		WriteData(w, http.StatusCreated, map[string]string{
+			"session":  sessionToken,
+			"id_token": identityToken,
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostAccountImpersonate(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+	domain := app.Config.ApplicationDomains[0]
+
+	t.Run("active account", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "impersonated@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+
+		res, err := client.PostForm(fmt.Sprintf("/accounts/%d/impersonate", account.ID), url.Values{
+			"actor":  []string{"admin@keratin.tech"},
+			"domain": []string{domain.String()},
+			"reason": []string{"ticket 123"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var result struct {
+			Session string `json:"session"`
+			IDToken string `json:"id_token"`
+		}
+		err = test.ExtractResult(res, &result)
+		require.NoError(t, err)
+
+		claims, err := identities.Parse(result.IDToken, app.Config, app.KeyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"imp"}, claims.AuthMethodReference)
+		if assert.NotNil(t, claims.Actor) {
+			assert.Equal(t, "admin@keratin.tech", claims.Actor.Subject)
+		}
+
+		// the session may be refreshed, and keeps the actor
+		session := &http.Cookie{Name: app.Config.SessionCookieName, Value: result.Session}
+		sessionClient := route.NewClient(server.URL).Referred(&domain).WithCookie(session)
+		res, err = sessionClient.Get("/session/refresh")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "imp")
+
+		// but may not be used for sensitive actions
+		res, err = sessionClient.Delete("/totp")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "session", Message: services.ErrReauthRequired}})
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.PostForm("/accounts/9999/impersonate", url.Values{
+			"actor":  []string{"admin@keratin.tech"},
+			"domain": []string{domain.String()},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("missing actor", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "anonymous@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+
+		res, err := client.PostForm(fmt.Sprintf("/accounts/%d/impersonate", account.ID), url.Values{
+			"domain": []string{domain.String()},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "actor", Message: services.ErrMissing}})
+	})
+}
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"testing"
@@ -52,7 +53,7 @@ func TestPostAccountSuccessWithSession(t *testing.T) {
 	session := test.CreateSession(app.RefreshTokenStore, app.Config, accountID)
 
 	// before
-	refreshTokens, err := app.RefreshTokenStore.FindAll(accountID)
+	refreshTokens, err := app.RefreshTokenStore.FindAll(context.Background(), accountID)
 	require.NoError(t, err)
 	refreshToken := refreshTokens[0]
 
@@ -64,7 +65,7 @@ func TestPostAccountSuccessWithSession(t *testing.T) {
 	require.NoError(t, err)
 
 	// after
-	id, err := app.RefreshTokenStore.Find(refreshToken)
+	id, err := app.RefreshTokenStore.Find(context.Background(), refreshToken)
 	require.NoError(t, err)
 	assert.Empty(t, id)
 }
@@ -94,3 +95,58 @@ func TestPostAccountFailure(t *testing.T) {
 		test.AssertErrors(t, res, tc.errors)
 	}
 }
+
+func TestPostAccountWithInvitation(t *testing.T) {
+	app := test.App()
+	app.Config.EnableSignup = false
+	server := test.Server(app)
+	defer server.Close()
+
+	invitation, err := services.InvitationCreator(context.Background(), app.AccountStore, app.Config, "invited")
+	require.NoError(t, err)
+
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+
+	t.Run("without invitation", func(t *testing.T) {
+		res, err := client.PostForm("/accounts", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"0a0b0c0"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "invitation", Message: "MISSING"}})
+	})
+
+	t.Run("with invitation", func(t *testing.T) {
+		res, err := client.PostForm("/accounts", url.Values{
+			"username":   []string{"other"},
+			"password":   []string{"0a0b0c0"},
+			"invitation": []string{invitation},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config, res.Cookies())
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config)
+
+		// the invited username is used
+		account, err := app.AccountStore.FindByUsername(context.Background(), "invited")
+		require.NoError(t, err)
+		assert.NotNil(t, account)
+		account, err = app.AccountStore.FindByUsername(context.Background(), "other")
+		require.NoError(t, err)
+		assert.Nil(t, account)
+	})
+
+	t.Run("with used invitation", func(t *testing.T) {
+		res, err := client.PostForm("/accounts", url.Values{
+			"password":   []string{"0a0b0c0"},
+			"invitation": []string{invitation},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "invitation", Message: "INVALID_OR_EXPIRED"}})
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
 )
 
 func PostAccountsImport(app *app.App) http.HandlerFunc {
@@ -15,6 +16,7 @@ func PostAccountsImport(app *app.App) http.HandlerFunc {
 			Username string
 			Password string
 			Locked string
+			Tenant string
 		}
 		if err := parse.Payload(r, &user); err != nil {
 			WriteErrors(w, err)
@@ -25,8 +27,13 @@ func PostAccountsImport(app *app.App) http.HandlerFunc {
 			panic(err)
 		}
 
+		if !app.Config.TenantExists(user.Tenant) {
+			WriteErrors(w, services.FieldErrors{{Field: "tenant", Message: services.ErrNotFound}})
+			return
+		}
+
 		account, err := services.AccountImporter(
-			app.AccountStore,
+			tenants.WithContext(r.Context(), user.Tenant), app.AccountStore,
 			app.Config,
 			user.Username,
 			user.Password,
@@ -1,11 +1,13 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"testing"
 
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/test"
 	"github.com/stretchr/testify/assert"
@@ -27,7 +29,7 @@ func TestPostAccountsImport(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusCreated, res.StatusCode)
 
-		account, err := app.AccountStore.FindByUsername("someone@app.com")
+		account, err := app.AccountStore.FindByUsername(context.Background(), "someone@app.com")
 		require.NoError(t, err)
 		test.AssertData(t, res, map[string]int{"id": account.ID})
 	})
@@ -41,7 +43,7 @@ func TestPostAccountsImport(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusCreated, res.StatusCode)
 
-		account, err := app.AccountStore.FindByUsername("locked@app.com")
+		account, err := app.AccountStore.FindByUsername(context.Background(), "locked@app.com")
 		require.NoError(t, err)
 		assert.True(t, account.Locked)
 	})
@@ -55,7 +57,7 @@ func TestPostAccountsImport(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusCreated, res.StatusCode)
 
-		account, err := app.AccountStore.FindByUsername("unlocked@app.com")
+		account, err := app.AccountStore.FindByUsername(context.Background(), "unlocked@app.com")
 		require.NoError(t, err)
 		assert.False(t, account.Locked)
 	})
@@ -69,7 +71,7 @@ func TestPostAccountsImport(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusCreated, res.StatusCode)
 
-		account, err := app.AccountStore.FindByUsername("jsonunlocked@app.com")
+		account, err := app.AccountStore.FindByUsername(context.Background(), "jsonunlocked@app.com")
 		require.NoError(t, err)
 		assert.False(t, account.Locked)
 	})
@@ -84,4 +86,32 @@ func TestPostAccountsImport(t *testing.T) {
 		test.AssertErrors(t, res, services.FieldErrors{{Field: "password", Message: "MISSING"}})
 	})
 
+	t.Run("importing into a tenant", func(t *testing.T) {
+		app.Config.TenantDomains = map[string]string{"test.com": "acme"}
+		defer func() { app.Config.TenantDomains = nil }()
+
+		res, err := client.PostForm("/accounts/import", url.Values{
+			"username": []string{"someone@app.com"},
+			"password": []string{"secret"},
+			"tenant":   []string{"acme"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		account, err := app.AccountStore.FindByUsername(tenants.WithContext(context.Background(), "acme"), "someone@app.com")
+		require.NoError(t, err)
+		require.NotNil(t, account)
+		test.AssertData(t, res, map[string]int{"id": account.ID})
+	})
+
+	t.Run("importing into an unknown tenant", func(t *testing.T) {
+		res, err := client.PostForm("/accounts/import", url.Values{
+			"username": []string{"unknown@app.com"},
+			"password": []string{"secret"},
+			"tenant":   []string{"initech"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "tenant", Message: "NOT_FOUND"}})
+	})
 }
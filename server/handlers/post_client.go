@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PostClient(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			Name      string
+			PublicKey string
+			Scopes    string
+			Audiences string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		client, secret, err := services.ClientCreator(
+			r.Context(), app.ClientStore, app.Config,
+			params.Name, params.PublicKey, models.ParseNameList(params.Scopes), models.ParseNameList(params.Audiences),
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		WriteData(w, http.StatusCreated, struct {
+			*models.Client
+			Secret string `json:"secret,omitempty"`
+		}{client, secret})
+	}
+}
@@ -0,0 +1,66 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPostClient(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("valid client", func(t *testing.T) {
+		res, err := client.PostForm("/clients", url.Values{
+			"name":      []string{"billing"},
+			"scopes":    []string{"read write"},
+			"audiences": []string{"https://api.example.com"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var result struct {
+			ID        string
+			Name      string
+			Scopes    []string
+			Audiences []string
+			Secret    string
+		}
+		require.NoError(t, test.ExtractResult(res, &result))
+		assert.Equal(t, "billing", result.Name)
+		assert.Equal(t, []string{"read", "write"}, result.Scopes)
+		assert.Equal(t, []string{"https://api.example.com"}, result.Audiences)
+		assert.NotEmpty(t, result.Secret)
+
+		found, err := app.ClientStore.Find(context.Background(), result.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, models.NameList{"read", "write"}, found.Scopes)
+		assert.NoError(t, bcrypt.CompareHashAndPassword(found.Secret, []byte(result.Secret)))
+	})
+
+	t.Run("invalid client", func(t *testing.T) {
+		res, err := client.PostForm("/clients", url.Values{"name": []string{""}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "name", Message: services.ErrMissing}})
+	})
+
+	t.Run("without authentication", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).PostForm("/clients", url.Values{"name": []string{"billing"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+// PostDeviceApprove lets the current session approve a device that is showing the user code, so that
+// the device may log in to the same account.
+func PostDeviceApprove(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if !requireFreshSession(app, w, r) {
+			return
+		}
+
+		var params struct {
+			UserCode string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		err := services.DeviceCodeApprover(
+			r.Context(), app.DeviceCodeCache,
+			params.UserCode, accountID, route.MatchedDomain(r).String(), sessions.Get(r).AuthMethodReference,
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
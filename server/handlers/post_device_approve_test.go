@@ -0,0 +1,57 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostDeviceApprove(t *testing.T) {
+	app := test.App()
+	app.Config.DeviceVerificationURL = &url.URL{Scheme: "https", Host: "test.com", Path: "/device"}
+	app.Config.DeviceCodeTTL = 10 * time.Minute
+	server := test.Server(app)
+	defer server.Close()
+
+	accountID := 7531
+	session := test.CreateSession(app.RefreshTokenStore, app.Config, accountID)
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+
+	t.Run("without a session", func(t *testing.T) {
+		_, userCode, err := services.DeviceCodeCreator(context.Background(), app.DeviceCodeCache, app.Config, false)
+		require.NoError(t, err)
+
+		res, err := client.PostForm("/device/approve", url.Values{"userCode": []string{userCode}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("unknown user code", func(t *testing.T) {
+		res, err := client.WithCookie(session).PostForm("/device/approve", url.Values{"userCode": []string{"BBBB-BBBB"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "user_code", Message: services.ErrInvalidOrExpired}})
+	})
+
+	t.Run("pending user code", func(t *testing.T) {
+		deviceCode, userCode, err := services.DeviceCodeCreator(context.Background(), app.DeviceCodeCache, app.Config, false)
+		require.NoError(t, err)
+
+		res, err := client.WithCookie(session).PostForm("/device/approve", url.Values{"userCode": []string{userCode}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		authorization, err := app.DeviceCodeCache.LoadDeviceAuthorization(context.Background(), deviceCode)
+		require.NoError(t, err)
+		assert.Equal(t, accountID, authorization.AccountID)
+		assert.Equal(t, "test.com", authorization.Audience)
+	})
+}
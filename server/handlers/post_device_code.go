@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+)
+
+// PostDeviceCode is the device authorization endpoint of RFC 8628. It responds in the format of the
+// RFC rather than the JSON envelope.
+func PostDeviceCode(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		deviceCode, userCode, err := services.DeviceCodeCreator(
+			r.Context(), app.DeviceCodeCache, app.Config, r.PostFormValue("session") == "true",
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		complete := *app.Config.DeviceVerificationURL
+		query := complete.Query()
+		query.Set("user_code", userCode)
+		complete.RawQuery = query.Encode()
+
+		WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"device_code":               deviceCode,
+			"user_code":                 userCode,
+			"verification_uri":          app.Config.DeviceVerificationURL.String(),
+			"verification_uri_complete": complete.String(),
+			"expires_in":                int(app.Config.DeviceCodeTTL.Seconds()),
+			"interval":                  int(services.DeviceCodeInterval.Seconds()),
+		})
+	}
+}
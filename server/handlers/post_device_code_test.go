@@ -0,0 +1,62 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostDeviceCode(t *testing.T) {
+	app := test.App()
+	app.Config.DeviceVerificationURL = &url.URL{Scheme: "https", Host: "test.com", Path: "/device"}
+	app.Config.DeviceCodeTTL = 10 * time.Minute
+	server := test.Server(app)
+	defer server.Close()
+
+	readBody := func(res *http.Response) map[string]interface{} {
+		body := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &body))
+		return body
+	}
+
+	t.Run("requesting codes", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).PostForm("/device/code", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+
+		body := readBody(res)
+		userCode := body["user_code"].(string)
+		assert.NotEmpty(t, body["device_code"])
+		assert.Equal(t, "https://test.com/device", body["verification_uri"])
+		assert.Equal(t, "https://test.com/device?user_code="+userCode, body["verification_uri_complete"])
+		assert.Equal(t, float64(600), body["expires_in"])
+		assert.Equal(t, float64(5), body["interval"])
+
+		authorization, err := app.DeviceCodeCache.LoadDeviceAuthorization(context.Background(), body["device_code"].(string))
+		require.NoError(t, err)
+		require.NotNil(t, authorization)
+		assert.Equal(t, userCode, authorization.UserCode)
+		assert.False(t, authorization.Session)
+	})
+
+	t.Run("requesting a session", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).PostForm("/device/code", url.Values{
+			"session": []string{"true"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		authorization, err := app.DeviceCodeCache.LoadDeviceAuthorization(context.Background(), readBody(res)["device_code"].(string))
+		require.NoError(t, err)
+		assert.True(t, authorization.Session)
+	})
+}
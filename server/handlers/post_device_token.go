@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+)
+
+// DeviceCodeGrantType identifies the device authorization grant of RFC 8628.
+const DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// PostDeviceToken is polled by a device until its code is approved, then returns an identity token
+// for a new session. It responds in the format of RFC 8628 rather than the JSON envelope.
+func PostDeviceToken(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		if r.PostFormValue("grant_type") != DeviceCodeGrantType {
+			WriteJSON(w, http.StatusBadRequest, RequestError{Error: "unsupported_grant_type"})
+			return
+		}
+
+		sessionToken, identityToken, err := services.DeviceTokenCreator(
+			r.Context(), app.DeviceCodeCache, app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+			r.PostFormValue("device_code"),
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteJSON(w, http.StatusBadRequest, RequestError{Error: deviceTokenError(fe[0])})
+				return
+			}
+
+			panic(err)
+		}
+
+		result := map[string]interface{}{
+			"access_token": identityToken,
+			"id_token":     identityToken,
+			"token_type":   "Bearer",
+			"expires_in":   int(app.Config.AccessTokenTTL.Seconds()),
+		}
+		if sessionToken != "" {
+			result["session"] = sessionToken
+		}
+		WriteJSON(w, http.StatusOK, result)
+	}
+}
+
+// deviceTokenError translates a field error into an error code of RFC 8628.
+func deviceTokenError(fe services.FieldError) string {
+	switch fe.Message {
+	case services.ErrPending:
+		return "authorization_pending"
+	case services.ErrSlowDown:
+		return "slow_down"
+	case services.ErrExpired:
+		return "expired_token"
+	case services.ErrNotFound:
+		return "invalid_grant"
+	default:
+		return "access_denied"
+	}
+}
@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/handlers"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostDeviceToken(t *testing.T) {
+	app := test.App()
+	app.Config.DeviceVerificationURL = &url.URL{Scheme: "https", Host: "test.com", Path: "/device"}
+	app.Config.DeviceCodeTTL = 10 * time.Minute
+	server := test.Server(app)
+	defer server.Close()
+
+	account, err := app.AccountStore.Create(context.Background(), "device@test.com", []byte("password"))
+	require.NoError(t, err)
+	client := route.NewClient(server.URL)
+
+	readBody := func(res *http.Response) map[string]interface{} {
+		body := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &body))
+		return body
+	}
+	poll := func(deviceCode string) *http.Response {
+		res, err := client.PostForm("/device/token", url.Values{
+			"grant_type":  []string{handlers.DeviceCodeGrantType},
+			"device_code": []string{deviceCode},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("unsupported grant type", func(t *testing.T) {
+		res, err := client.PostForm("/device/token", url.Values{
+			"grant_type": []string{"client_credentials"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "unsupported_grant_type", readBody(res)["error"])
+	})
+
+	t.Run("unknown device code", func(t *testing.T) {
+		res := poll("unknown")
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "invalid_grant", readBody(res)["error"])
+	})
+
+	t.Run("pending device code", func(t *testing.T) {
+		deviceCode, _, err := services.DeviceCodeCreator(context.Background(), app.DeviceCodeCache, app.Config, false)
+		require.NoError(t, err)
+
+		res := poll(deviceCode)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "authorization_pending", readBody(res)["error"])
+
+		res = poll(deviceCode)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "slow_down", readBody(res)["error"])
+	})
+
+	t.Run("approved device code", func(t *testing.T) {
+		deviceCode, userCode, err := services.DeviceCodeCreator(context.Background(), app.DeviceCodeCache, app.Config, false)
+		require.NoError(t, err)
+		err = services.DeviceCodeApprover(context.Background(), app.DeviceCodeCache, userCode, account.ID, "test.com", []string{"pwd"})
+		require.NoError(t, err)
+
+		res := poll(deviceCode)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+
+		body := readBody(res)
+		assert.Equal(t, "Bearer", body["token_type"])
+		assert.Equal(t, body["id_token"], body["access_token"])
+		assert.NotContains(t, body, "session")
+		claims, err := identities.Parse(body["id_token"].(string), app.Config, app.KeyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, "test.com", claims.Audience[0])
+	})
+
+	t.Run("approved device code with session", func(t *testing.T) {
+		deviceCode, userCode, err := services.DeviceCodeCreator(context.Background(), app.DeviceCodeCache, app.Config, true)
+		require.NoError(t, err)
+		err = services.DeviceCodeApprover(context.Background(), app.DeviceCodeCache, userCode, account.ID, "test.com", []string{"pwd"})
+		require.NoError(t, err)
+
+		res := poll(deviceCode)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.NotEmpty(t, readBody(res)["session"])
+	})
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PostIntrospect(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			Token string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		introspection, err := services.TokenIntrospector(r.Context(), app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Config, params.Token)
+		if err != nil {
+			panic(err)
+		}
+
+		if ttl := app.Config.IntrospectionCacheTTL; ttl > 0 {
+			w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(ttl.Seconds())))
+		} else {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		WriteJSON(w, http.StatusOK, introspection)
+	}
+}
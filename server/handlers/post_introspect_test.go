@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostIntrospect(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	introspect := func(token string) (*http.Response, map[string]interface{}) {
+		res, err := client.PostForm("/introspect", url.Values{"token": []string{token}})
+		require.NoError(t, err)
+		body := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &body))
+		return res, body
+	}
+
+	t.Run("session token", func(t *testing.T) {
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, 123)
+		claims, err := sessions.Parse(session.Value, app.Config)
+		require.NoError(t, err)
+
+		res, body := introspect(session.Value)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+		assert.Equal(t, map[string]interface{}{
+			"active": true,
+			"sub":    "123",
+			"sid":    claims.SessionID,
+		}, body)
+	})
+
+	t.Run("identity token", func(t *testing.T) {
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, 123)
+		claims, err := sessions.Parse(session.Value, app.Config)
+		require.NoError(t, err)
+		identity := identities.New(app.Config, claims, 123, app.Config.ApplicationDomains[0].String())
+		identityToken, err := identity.Sign(app.KeyStore.Key())
+		require.NoError(t, err)
+
+		res, body := introspect(identityToken)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, map[string]interface{}{
+			"active": true,
+			"sub":    "123",
+			"sid":    claims.SessionID,
+			"exp":    float64(*identity.Expiry),
+		}, body)
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, 123)
+		test.RevokeSession(app.RefreshTokenStore, app.Config, session)
+
+		res, body := introspect(session.Value)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, map[string]interface{}{"active": false}, body)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		res, body := introspect("not.a.token")
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, map[string]interface{}{"active": false}, body)
+	})
+
+	t.Run("with cache ttl", func(t *testing.T) {
+		app.Config.IntrospectionCacheTTL = 30 * time.Second
+		defer func() { app.Config.IntrospectionCacheTTL = 0 }()
+
+		res, _ := introspect("not.a.token")
+		assert.Equal(t, "private, max-age=30", res.Header.Get("Cache-Control"))
+	})
+
+	t.Run("without authentication", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).PostForm("/introspect", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
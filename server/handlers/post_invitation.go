@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PostInvitation(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			Username string
+			Tenant   string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		if !app.Config.TenantExists(params.Tenant) {
+			WriteErrors(w, services.FieldErrors{{Field: "tenant", Message: services.ErrNotFound}})
+			return
+		}
+
+		invitation, err := services.InvitationCreator(
+			tenants.WithContext(r.Context(), params.Tenant), app.AccountStore, app.Config, params.Username,
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		// the invitation is only returned when the app does not expect it to be delivered
+		if app.Config.AppInvitationURL != nil {
+			err = services.InvitationSender(app.Config, params.Username, invitation, app.Logger)
+			if err != nil {
+				panic(err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+
+		WriteData(w, http.StatusCreated, map[string]string{
+			"invitation": invitation,
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostInvitation(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("returning the invitation", func(t *testing.T) {
+		res, err := client.PostForm("/invitations", url.Values{"username": []string{"invited"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var result struct {
+			Invitation string `json:"invitation"`
+		}
+		require.NoError(t, test.ExtractResult(res, &result))
+		claims, err := invitations.Parse(result.Invitation, app.Config)
+		require.NoError(t, err)
+		assert.Equal(t, "invited", claims.Subject)
+	})
+
+	t.Run("missing username", func(t *testing.T) {
+		res, err := client.PostForm("/invitations", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "username", Message: "MISSING"}})
+	})
+
+	t.Run("unknown tenant", func(t *testing.T) {
+		res, err := client.PostForm("/invitations", url.Values{
+			"username": []string{"invited"},
+			"tenant":   []string{"initech"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "tenant", Message: "NOT_FOUND"}})
+	})
+}
+
+func TestPostInvitationWithDelivery(t *testing.T) {
+	var delivered url.Values
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		delivered = r.PostForm
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer remoteApp.Close()
+
+	app := test.App()
+	appURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+	app.Config.AppInvitationURL = appURL
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+	res, err := client.PostForm("/invitations", url.Values{"username": []string{"invited"}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, []byte{}, test.ReadBody(res))
+
+	assert.Equal(t, "invited", delivered.Get("username"))
+	_, err = invitations.Parse(delivered.Get("invitation"), app.Config)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PostKeysRotate(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			Revoke string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+		revoke, err := regexp.MatchString("^(?i:t|true|yes)$", params.Revoke)
+		if err != nil {
+			panic(err)
+		}
+
+		key, replaced, err := app.KeyRotater.ForceRotation(r.Context(), revoke)
+		if err != nil {
+			panic(err)
+		}
+
+		WriteData(w, http.StatusCreated, map[string]interface{}{
+			"key_id":          key.JWK.KeyID,
+			"replaced_key_id": replaced.JWK.KeyID,
+			"revoked":         revoke,
+		})
+	}
+}
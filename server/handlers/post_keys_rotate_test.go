@@ -0,0 +1,80 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostKeysRotate(t *testing.T) {
+	app := test.App()
+	blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(time.Hour, time.Second), compat.NewKeyring(app.Config.DBEncryptionKey))
+	keyStore := data.NewRotatingKeyStore()
+	app.KeyStore = keyStore
+	app.KeyRotater = data.NewKeyStoreRotater(blobStore, time.Hour, private.ES256, app.Logger)
+	require.NoError(t, app.KeyRotater.Maintain(keyStore, app.Reporter))
+
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	publishedKeyIDs := func() []string {
+		res, err := client.Get("/jwks")
+		require.NoError(t, err)
+		jwks := jose.JSONWebKeySet{}
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &jwks))
+		ids := []string{}
+		for _, key := range jwks.Keys {
+			ids = append(ids, key.KeyID)
+		}
+		return ids
+	}
+
+	t.Run("rotating", func(t *testing.T) {
+		current := keyStore.Key()
+		next := keyStore.Keys()[len(keyStore.Keys())-1]
+		assert.Contains(t, publishedKeyIDs(), next.JWK.KeyID)
+
+		res, err := client.PostForm("/keys/rotate", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertData(t, res, map[string]interface{}{
+			"key_id":          next.JWK.KeyID,
+			"replaced_key_id": current.JWK.KeyID,
+			"revoked":         false,
+		})
+
+		assert.Equal(t, next.JWK.KeyID, keyStore.Key().JWK.KeyID)
+		assert.Contains(t, publishedKeyIDs(), current.JWK.KeyID)
+	})
+
+	t.Run("rotating and revoking", func(t *testing.T) {
+		current := keyStore.Key()
+
+		res, err := client.PostForm("/keys/rotate", url.Values{"revoke": []string{"true"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		assert.NotEqual(t, current.JWK.KeyID, keyStore.Key().JWK.KeyID)
+		assert.NotContains(t, publishedKeyIDs(), current.JWK.KeyID)
+	})
+
+	t.Run("without authentication", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).PostForm("/keys/rotate", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
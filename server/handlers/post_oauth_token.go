@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+)
+
+// PostOauthToken is the OAuth 2.0 token endpoint. It supports the client_credentials grant, and
+// responds in the format of RFC 6749 rather than the JSON envelope.
+func PostOauthToken(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		if r.PostFormValue("grant_type") != "client_credentials" {
+			WriteJSON(w, http.StatusBadRequest, RequestError{Error: "unsupported_grant_type"})
+			return
+		}
+
+		clientID, secret, basic := r.BasicAuth()
+		if !basic {
+			clientID = r.PostFormValue("client_id")
+			secret = r.PostFormValue("client_secret")
+		}
+		var assertion string
+		if r.PostFormValue("client_assertion_type") == services.ClientAssertionType {
+			assertion = r.PostFormValue("client_assertion")
+		}
+
+		client, err := services.ClientAuthenticator(r.Context(), app.ClientStore, app.ClientAssertions, app.Config, clientID, secret, assertion)
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				if basic {
+					w.Header().Set("WWW-Authenticate", `Basic realm="AuthN"`)
+				}
+				WriteJSON(w, http.StatusUnauthorized, RequestError{Error: "invalid_client"})
+				return
+			}
+
+			panic(err)
+		}
+
+		token, scopes, err := services.ClientTokenCreator(app.KeyStore, app.Config, client, r.PostFormValue("scope"), r.PostFormValue("audience"))
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				if fe[0].Field == "audience" {
+					WriteJSON(w, http.StatusBadRequest, RequestError{Error: "invalid_target"})
+				} else {
+					WriteJSON(w, http.StatusBadRequest, RequestError{Error: "invalid_scope"})
+				}
+				return
+			}
+
+			panic(err)
+		}
+
+		WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_in":   int(app.Config.AccessTokenTTL.Seconds()),
+			"scope":        scopes.String(),
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/access"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostOauthToken(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	serviceClient, secret, err := services.ClientCreator(
+		context.Background(), app.ClientStore, app.Config,
+		"billing", "", models.NameList{"read", "write"}, models.NameList{"https://api.example.com"},
+	)
+	require.NoError(t, err)
+
+	readBody := func(res *http.Response) map[string]interface{} {
+		body := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &body))
+		return body
+	}
+
+	t.Run("with basic authentication", func(t *testing.T) {
+		client := route.NewClient(server.URL).Authenticated(serviceClient.ID, secret)
+		res, err := client.PostForm("/oauth/token", url.Values{
+			"grant_type": []string{"client_credentials"},
+			"scope":      []string{"read"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+
+		body := readBody(res)
+		assert.Equal(t, "Bearer", body["token_type"])
+		assert.Equal(t, "read", body["scope"])
+
+		parsed, err := jwt.ParseSigned(body["access_token"].(string))
+		require.NoError(t, err)
+		claims := access.Claims{}
+		require.NoError(t, parsed.Claims(app.KeyStore.Key().Public(), &claims))
+		assert.Equal(t, serviceClient.ID, claims.Subject)
+		assert.Equal(t, jwt.Audience{"https://api.example.com"}, claims.Audience)
+	})
+
+	t.Run("with credentials in the body", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).PostForm("/oauth/token", url.Values{
+			"grant_type":    []string{"client_credentials"},
+			"client_id":     []string{serviceClient.ID},
+			"client_secret": []string{secret},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "read write", readBody(res)["scope"])
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		client := route.NewClient(server.URL).Authenticated(serviceClient.ID, "wrong")
+		res, err := client.PostForm("/oauth/token", url.Values{"grant_type": []string{"client_credentials"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.Equal(t, "invalid_client", readBody(res)["error"])
+	})
+
+	t.Run("scope not granted", func(t *testing.T) {
+		client := route.NewClient(server.URL).Authenticated(serviceClient.ID, secret)
+		res, err := client.PostForm("/oauth/token", url.Values{
+			"grant_type": []string{"client_credentials"},
+			"scope":      []string{"admin"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "invalid_scope", readBody(res)["error"])
+	})
+
+	t.Run("unsupported grant", func(t *testing.T) {
+		client := route.NewClient(server.URL).Authenticated(serviceClient.ID, secret)
+		res, err := client.PostForm("/oauth/token", url.Values{"grant_type": []string{"password"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "unsupported_grant_type", readBody(res)["error"])
+	})
+}
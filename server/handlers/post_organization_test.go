@@ -0,0 +1,46 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostOrganization(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("missing name", func(t *testing.T) {
+		res, err := client.PostForm("/organizations", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "name", Message: services.ErrMissing}})
+	})
+
+	t.Run("valid organization", func(t *testing.T) {
+		res, err := client.PostForm("/organizations", url.Values{"name": []string{"Acme"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var result struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		require.NoError(t, test.ExtractResult(res, &result))
+		assert.Equal(t, "Acme", result.Name)
+
+		organization, err := app.OrganizationStore.Find(context.Background(), result.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, organization)
+	})
+}
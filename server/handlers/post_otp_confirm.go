@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+// ConfirmOTP finishes setting up email or SMS codes as the second factor
+func ConfirmOTP(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// check for valid session with live token
+		accountID := sessions.GetEnrollingAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		account, err := services.OTPChannelSetter(r.Context(), app.AccountStore, app.TrustedDeviceStore, app.OTPCodeCache, app.Config, accountID, r.FormValue("otp"))
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		// a session that was restricted to setting up MFA is replaced with a full session
+		session := sessions.Get(r)
+		if session.MFAEnrollment {
+			sessionToken, identityToken, err := services.SessionCreator(
+				r.Context(), app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+				accountID, route.MatchedDomain(r), sessions.GetRefreshToken(r), append(session.AuthMethodReference, services.OTPMethod(account)),
+			)
+			if err != nil {
+				if fe, ok := err.(services.FieldErrors); ok {
+					WriteErrors(w, fe)
+					return
+				}
+
+				panic(err)
+			}
+
+			sessions.Set(app.Config, w, sessionToken)
+			writeIdentityToken(w, identityToken)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
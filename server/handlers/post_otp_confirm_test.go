@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostOTPConfirm(t *testing.T) {
+	app := test.App()
+	remoteApp, deliveries := test.OTPDelivery(app)
+	defer remoteApp.Close()
+	server := test.Server(app)
+	defer server.Close()
+
+	t.Run("with the delivered code", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "confirm@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/otp/new", url.Values{"channel": []string{"email"}})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		delivery := <-deliveries
+
+		res, err = client.PostForm("/otp/confirm", url.Values{"otp": []string{delivery.Get("code")}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		account, err = app.AccountStore.Find(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "email", account.OTPChannel.String)
+	})
+
+	t.Run("with a wrong code", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "wrong@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/otp/new", url.Values{"channel": []string{"email"}})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		<-deliveries
+
+		res, err = client.PostForm("/otp/confirm", url.Values{"otp": []string{"abcdef"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: services.ErrInvalidOrExpired}})
+	})
+
+	t.Run("completing MFA enrollment", func(t *testing.T) {
+		app.Config.MFARequired = true
+		defer func() { app.Config.MFARequired = false }()
+
+		_, err := app.AccountStore.Create(context.Background(), "enroll@keratin.tech", []byte("$2a$04$lzQPXlov4RFLxps1uUGq4e4wmVjLYz3WrqQw4bSdfIiJRyo3/fk3C"))
+		require.NoError(t, err)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"enroll@keratin.tech"},
+			"password": []string{"mysecret"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		session := test.ReadCookie(res.Cookies(), app.Config.SessionCookieName)
+
+		client = client.WithCookie(session)
+		res, err = client.PostForm("/otp/new", url.Values{"channel": []string{"sms"}})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		delivery := <-deliveries
+
+		res, err = client.PostForm("/otp/confirm", url.Values{"otp": []string{delivery.Get("code")}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config, res.Cookies(), "pwd", "sms")
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "pwd", "sms")
+	})
+}
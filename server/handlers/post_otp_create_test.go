@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostOTPCreate(t *testing.T) {
+	app := test.App()
+	remoteApp, deliveries := test.OTPDelivery(app)
+	defer remoteApp.Close()
+	server := test.Server(app)
+	defer server.Close()
+
+	t.Run("with a session", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "create@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/otp/new", url.Values{"channel": []string{"sms"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		delivery := <-deliveries
+		assert.Equal(t, "sms", delivery.Get("channel"))
+		assert.Regexp(t, "^[0-9]{6}$", delivery.Get("code"))
+	})
+
+	t.Run("with an unknown channel", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "unknown@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/otp/new", url.Values{"channel": []string{"fax"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "channel", Message: services.ErrFormatInvalid}})
+	})
+
+	t.Run("with TOTP", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "totp@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		_, err = app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", []byte("test"), 0)
+		require.NoError(t, err)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/otp/new", url.Values{"channel": []string{"email"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: services.ErrTaken}})
+	})
+
+	t.Run("without a session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		res, err := client.PostForm("/otp/new", url.Values{"channel": []string{"sms"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
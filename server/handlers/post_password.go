@@ -4,7 +4,9 @@ import (
 	"net/http"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
 	"github.com/keratin/authn-server/lib/parse"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/sessions"
@@ -25,23 +27,38 @@ func PostPassword(app *app.App) http.HandlerFunc {
 
 		var err error
 		var accountID int
+		amr := []string{"pwd"}
 		if credentials.Token != "" {
-			accountID, err = services.PasswordResetter(
-				app.AccountStore,
+			var account *models.Account
+			account, err = services.PasswordResetter(
+				r.Context(), app.AccountStore, app.OTPCodeCache,
 				app.Reporter,
 				app.Config,
 				credentials.Token,
 				credentials.Password,
 				credentials.OTP,
 			)
+			if services.IsMissingOTP(err) {
+				sendOTPCode(app, r, account)
+			}
+			if err == nil {
+				accountID = account.ID
+				// a code was only verified if the account has a second factor
+				if credentials.OTP != "" && account.MFAEnabled() {
+					amr = append(amr, services.OTPMethod(account))
+				}
+			}
 		} else {
 			accountID = sessions.GetAccountID(r)
 			if accountID == 0 {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
+			if !requireFreshSession(app, w, r) {
+				return
+			}
 			err = services.PasswordChanger(
-				app.AccountStore,
+				r.Context(), app.AccountStore,
 				app.Reporter,
 				app.Config,
 				accountID,
@@ -59,23 +76,29 @@ func PostPassword(app *app.App) http.HandlerFunc {
 			panic(err)
 		}
 
+		// a new password resets the trust of every device
+		err = app.TrustedDeviceStore.RevokeAll(r.Context(), accountID)
+		if err != nil {
+			panic(err)
+		}
+
 		if app.Config.PasswordChangeLogout {
-			err = services.SessionBatchEnder(app.RefreshTokenStore, accountID)
+			err = services.SessionBatchEnder(r.Context(), app.RefreshTokenStore, app.KeyStore, app.Config, app.Reporter, tenants.Name(r.Context()), accountID)
 			if err != nil {
 				panic(err)
 			}
 		}
 
-		amr := []string{"pwd"}
-		if credentials.OTP != "" {
-			amr = append(amr, "otp")
-		}
-
 		sessionToken, identityToken, err := services.SessionCreator(
-			app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+			r.Context(), app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
 			accountID, route.MatchedDomain(r), sessions.GetRefreshToken(r), amr,
 		)
 		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
 			panic(err)
 		}
 
@@ -83,8 +106,6 @@ func PostPassword(app *app.App) http.HandlerFunc {
 		sessions.Set(app.Config, w, sessionToken)
 
 		// Return the signed identity token in the body
-		WriteData(w, http.StatusCreated, map[string]string{
-			"id_token": identityToken,
-		})
+		writeIdentityToken(w, identityToken)
 	}
 }
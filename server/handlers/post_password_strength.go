@@ -6,6 +6,7 @@ import (
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
 )
 
 func PostPasswordScore(app *app.App) http.HandlerFunc {
@@ -30,7 +31,7 @@ func PostPasswordScore(app *app.App) http.HandlerFunc {
 
 		WriteData(w, http.StatusOK, map[string]interface{}{
 			"score":         score,
-			"requiredScore": app.Config.PasswordMinComplexity,
+			"requiredScore": app.Config.PasswordMinComplexityFor(tenants.Name(r.Context())),
 		})
 	}
 }
@@ -1,8 +1,10 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -31,7 +33,7 @@ func TestPostPassword(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, res.StatusCode)
 		test.AssertSession(t, app.Config, res.Cookies())
 		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config)
-		found, err := app.AccountStore.Find(account.ID)
+		found, err := app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.NotEqual(t, found.Password, account.Password)
 	}
@@ -42,7 +44,7 @@ func TestPostPassword(t *testing.T) {
 			return nil, errors.Wrap(err, "bcrypt")
 		}
 
-		return app.AccountStore.Create(username, hash)
+		return app.AccountStore.Create(context.Background(), username, hash)
 	}
 
 	t.Run("valid reset token", func(t *testing.T) {
@@ -101,11 +103,35 @@ func TestPostPassword(t *testing.T) {
 		// invalidates old session
 		claims, err := sessions.Parse(session.Value, app.Config)
 		require.NoError(t, err)
-		id, err := app.RefreshTokenStore.Find(models.RefreshToken(claims.Subject))
+		id, err := app.RefreshTokenStore.Find(context.Background(), models.RefreshToken(claims.Subject))
 		require.NoError(t, err)
 		assert.Empty(t, id)
 	})
 
+	t.Run("trusted devices", func(t *testing.T) {
+		// given an account
+		account, err := factory("trusted.devices@authn.tech", "oldpwd")
+		require.NoError(t, err)
+
+		// given a trusted device
+		device, err := app.TrustedDeviceStore.Create(context.Background(), account.ID, "Firefox")
+		require.NoError(t, err)
+
+		// invoking the endpoint
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		res, err := client.WithCookie(session).PostForm("/password", url.Values{
+			"currentPassword": []string{"oldpwd"},
+			"password":        []string{"0a0b0c0d0"},
+		})
+		require.NoError(t, err)
+		assertSuccess(t, res, account)
+
+		// resets trust
+		found, err := app.TrustedDeviceStore.Find(context.Background(), device.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
 	t.Run("valid session and bad password", func(t *testing.T) {
 		// given an account
 		account, err := factory("bad.password@authn.tech", "oldpwd")
@@ -212,7 +238,7 @@ func TestPostPassword(t *testing.T) {
 		// invalidates other session
 		claims, err := sessions.Parse(otherSession.Value, app.Config)
 		require.NoError(t, err)
-		id, err := app.RefreshTokenStore.Find(models.RefreshToken(claims.Subject))
+		id, err := app.RefreshTokenStore.Find(context.Background(), models.RefreshToken(claims.Subject))
 		require.NoError(t, err)
 		assert.Empty(t, id)
 	})
@@ -241,7 +267,7 @@ func TestPostPassword(t *testing.T) {
 		// preserves other session
 		claims, err := sessions.Parse(otherSession.Value, app.Config)
 		require.NoError(t, err)
-		id, err := app.RefreshTokenStore.Find(models.RefreshToken(claims.Subject))
+		id, err := app.RefreshTokenStore.Find(context.Background(), models.RefreshToken(claims.Subject))
 		require.NoError(t, err)
 		assert.Equal(t, account.ID, id)
 	})
@@ -262,7 +288,7 @@ func TestPostPasswordWithOTP(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, res.StatusCode)
 		test.AssertSession(t, app.Config, res.Cookies())
 		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config)
-		found, err := app.AccountStore.Find(account.ID)
+		found, err := app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.NotEqual(t, found.Password, account.Password)
 	}
@@ -273,14 +299,14 @@ func TestPostPasswordWithOTP(t *testing.T) {
 			return nil, errors.Wrap(err, "bcrypt")
 		}
 
-		return app.AccountStore.Create(username, hash)
+		return app.AccountStore.Create(context.Background(), username, hash)
 	}
 
 	t.Run("valid totp code", func(t *testing.T) {
 		// given an account
 		account, err := factory("valid@authn.tech", "oldpwd")
 		require.NoError(t, err)
-		_, err = app.AccountStore.SetTOTPSecret(account.ID, totpSecretEnc)
+		_, err = app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
 		require.NoError(t, err)
 
 		// given a reset token
@@ -303,13 +329,35 @@ func TestPostPasswordWithOTP(t *testing.T) {
 
 		// works
 		assertSuccess(t, res, account)
+		test.AssertSession(t, app.Config, res.Cookies(), "pwd", "otp")
+	})
+
+	t.Run("code without a second factor", func(t *testing.T) {
+		account, err := factory("plain@authn.tech", "oldpwd")
+		require.NoError(t, err)
+
+		token, err := resets.New(app.Config, account.ID, account.PasswordChangedAt)
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(app.Config.ResetSigningKey)
+		require.NoError(t, err)
+
+		res, err := client.PostForm("/password", url.Values{
+			"token":    []string{tokenStr},
+			"password": []string{"0a0b0c0d0"},
+			"otp":      []string{"123456"},
+		})
+		require.NoError(t, err)
+
+		// the code was not verified, so it is not a factor
+		assertSuccess(t, res, account)
+		test.AssertSession(t, app.Config, res.Cookies(), "pwd")
 	})
 
 	t.Run("invalid totp code", func(t *testing.T) {
 		// given an account
 		account, err := factory("invaild@authn.tech", "oldpwd")
 		require.NoError(t, err)
-		_, err = app.AccountStore.SetTOTPSecret(account.ID, totpSecretEnc)
+		_, err = app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
 		require.NoError(t, err)
 
 		// given a reset token
@@ -330,3 +378,47 @@ func TestPostPasswordWithOTP(t *testing.T) {
 		test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: "INVALID_OR_EXPIRED"}})
 	})
 }
+
+func TestPostPasswordWithOTPChannel(t *testing.T) {
+	app := test.App()
+	remoteApp, deliveries := test.OTPDelivery(app)
+	defer remoteApp.Close()
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("oldpwd"), 4)
+	account, err := app.AccountStore.Create(context.Background(), "foo", b)
+	require.NoError(t, err)
+	_, err = app.AccountStore.SetOTPChannel(context.Background(), account.ID, "sms")
+	require.NoError(t, err)
+
+	token, err := resets.New(app.Config, account.ID, account.PasswordChangedAt)
+	require.NoError(t, err)
+	tokenStr, err := token.Sign(app.Config.ResetSigningKey)
+	require.NoError(t, err)
+
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+
+	// a code is sent when it is missing
+	res, err := client.PostForm("/password", url.Values{
+		"token":    []string{tokenStr},
+		"password": []string{"0a0b0c0d0"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: services.ErrMissing}})
+
+	delivery := <-deliveries
+	assert.Equal(t, strconv.Itoa(account.ID), delivery.Get("account_id"))
+	assert.Equal(t, "sms", delivery.Get("channel"))
+
+	res, err = client.PostForm("/password", url.Values{
+		"token":    []string{tokenStr},
+		"password": []string{"0a0b0c0d0"},
+		"otp":      []string{delivery.Get("code")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	test.AssertSession(t, app.Config, res.Cookies(), "pwd", "sms")
+	test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "pwd", "sms")
+}
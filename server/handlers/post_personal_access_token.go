@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func PostPersonalAccessToken(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if !requireFreshSession(app, w, r) {
+			return
+		}
+
+		var params struct {
+			Name      string
+			Scope     string
+			ExpiresIn int
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		pat, token, err := services.PersonalAccessTokenCreator(
+			r.Context(), app.AccountStore, app.Config,
+			accountID, route.MatchedDomain(r).String(), params.Name, params.Scope, params.ExpiresIn,
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		WriteData(w, http.StatusCreated, struct {
+			*models.PersonalAccessToken
+			Token string `json:"token"`
+		}{pat, token})
+	}
+}
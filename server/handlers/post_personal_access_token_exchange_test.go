@@ -0,0 +1,48 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostPersonalAccessTokenExchange(t *testing.T) {
+	app := test.App()
+	app.Config.EnablePersonalAccessTokens = true
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("valid token", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "valid@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		_, token, err := services.PersonalAccessTokenCreator(context.Background(), app.AccountStore, app.Config, account.ID, "test.com", "cli", "", 0)
+		require.NoError(t, err)
+
+		res, err := client.PostForm("/personal_access_tokens/exchange", url.Values{"token": []string{token}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "pat")
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		res, err := client.PostForm("/personal_access_tokens/exchange", url.Values{"token": []string{"pat_unknown"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "token", Message: services.ErrInvalidOrExpired}})
+	})
+
+	t.Run("without authentication", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).PostForm("/personal_access_tokens/exchange", url.Values{"token": []string{"pat_unknown"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostPersonalAccessToken(t *testing.T) {
+	app := test.App()
+	app.Config.EnablePersonalAccessTokens = true
+	app.Config.PersonalAccessTokenScopes = []string{"read", "write"}
+	app.Config.ReauthenticationMaxAge = 5 * time.Minute
+	server := test.Server(app)
+	defer server.Close()
+
+	t.Run("valid token", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "valid@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/personal_access_tokens", url.Values{
+			"name":  []string{"cli"},
+			"scope": []string{"read"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var result struct {
+			ID       int      `json:"id"`
+			Token    string   `json:"token"`
+			Scopes   []string `json:"scopes"`
+			Audience string   `json:"audience"`
+		}
+		err = test.ExtractResult(res, &result)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(result.Token, "pat_"))
+		assert.Equal(t, []string{"read"}, result.Scopes)
+		assert.Equal(t, app.Config.ApplicationDomains[0].String(), result.Audience)
+
+		tokens, err := app.AccountStore.GetPersonalAccessTokens(context.Background(), account.ID)
+		require.NoError(t, err)
+		if assert.Len(t, tokens, 1) {
+			assert.Equal(t, result.ID, tokens[0].ID)
+		}
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "scope@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/personal_access_tokens", url.Values{
+			"name":  []string{"cli"},
+			"scope": []string{"admin"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "scope", Message: services.ErrFormatInvalid}})
+	})
+
+	t.Run("stale session", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "stale@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+
+		session := createStaleSession(t, app, account.ID)
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/personal_access_tokens", url.Values{
+			"name": []string{"cli"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "session", Message: services.ErrReauthRequired}})
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		res, err := client.PostForm("/personal_access_tokens", url.Values{
+			"name": []string{"cli"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
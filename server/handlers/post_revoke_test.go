@@ -0,0 +1,66 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostRevoke(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	t.Run("session token", func(t *testing.T) {
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, 123)
+		claims, err := sessions.Parse(session.Value, app.Config)
+		require.NoError(t, err)
+
+		res, err := client.PostForm("/revoke", url.Values{"token": []string{session.Value}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.RefreshTokenStore.Find(context.Background(), models.RefreshToken(claims.Subject))
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("identity token", func(t *testing.T) {
+		session := test.CreateSession(app.RefreshTokenStore, app.Config, 123)
+		claims, err := sessions.Parse(session.Value, app.Config)
+		require.NoError(t, err)
+		identityToken, err := identities.New(app.Config, claims, 123, app.Config.ApplicationDomains[0].String()).Sign(app.KeyStore.Key())
+		require.NoError(t, err)
+
+		res, err := client.PostForm("/revoke", url.Values{"token": []string{identityToken}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.RefreshTokenStore.Find(context.Background(), models.RefreshToken(claims.Subject))
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		res, err := client.PostForm("/revoke", url.Values{"token": []string{"not.a.token"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("without authentication", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).PostForm("/revoke", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
@@ -13,9 +13,10 @@ import (
 func PostSession(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var credentials struct {
-			Username string
-			Password string
-			OTP      string
+			Username    string
+			Password    string
+			OTP         string
+			TrustDevice string
 		}
 		if err := parse.Payload(r, &credentials); err != nil {
 			WriteErrors(w, err)
@@ -24,13 +25,23 @@ func PostSession(app *app.App) http.HandlerFunc {
 
 		// Check the password
 		account, err := services.CredentialsVerifier(
-			app.AccountStore,
+			r.Context(), app.AccountStore, app.OTPCodeCache,
 			app.Config,
 			credentials.Username,
 			credentials.Password,
 			credentials.OTP,
 		)
+		if services.IsMissingOTP(err) && isTrustedDevice(app, r, account.ID) {
+			err = nil
+		}
+		if services.IsMissingOTP(err) {
+			sendOTPCode(app, r, account)
+		}
 		if err != nil {
+			if app.Config.EnableMFAChallenge && services.IsMissingOTP(err) {
+				writeMFAChallenge(app, w, r, account.ID, []string{"pwd"})
+				return
+			}
 			if fe, ok := err.(services.FieldErrors); ok {
 				WriteErrors(w, fe)
 				return
@@ -39,25 +50,34 @@ func PostSession(app *app.App) http.HandlerFunc {
 			panic(err)
 		}
 
+		// a code was only verified if the account has a second factor
+		verifiedOTP := credentials.OTP != "" && account.MFAEnabled()
 		amr := []string{"pwd"}
-		if credentials.OTP != "" {
-			amr = append(amr, "otp")
+		if verifiedOTP {
+			amr = append(amr, services.OTPMethod(account))
 		}
 
 		sessionToken, identityToken, err := services.SessionCreator(
-			app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+			r.Context(), app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
 			account.ID, route.MatchedDomain(r), sessions.GetRefreshToken(r), amr,
 		)
 		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
 			panic(err)
 		}
 
+		if verifiedOTP {
+			trustDevice(app, w, r, account.ID, credentials.TrustDevice)
+		}
+
 		// Return the signed session in a cookie
 		sessions.Set(app.Config, w, sessionToken)
 
 		// Return the signed identity token in the body
-		WriteData(w, http.StatusCreated, map[string]string{
-			"id_token": identityToken,
-		})
+		writeIdentityToken(w, identityToken)
 	}
 }
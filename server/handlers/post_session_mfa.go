@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func PostSessionMFA(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var credentials struct {
+			MFAToken    string `json:"mfa_token" schema:"mfa_token"`
+			OTP         string
+			TrustDevice string
+		}
+		if err := parse.Payload(r, &credentials); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		accountID, amr, err := services.MFAChallengeVerifier(
+			r.Context(), app.AccountStore, app.OTPCodeCache, app.MFAChallenges, app.Config,
+			route.MatchedDomain(r),
+			credentials.MFAToken,
+			credentials.OTP,
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		sessionToken, identityToken, err := services.SessionCreator(
+			r.Context(), app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+			accountID, route.MatchedDomain(r), sessions.GetRefreshToken(r), amr,
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		trustDevice(app, w, r, accountID, credentials.TrustDevice)
+
+		// Return the signed session in a cookie
+		sessions.Set(app.Config, w, sessionToken)
+
+		// Return the signed identity token in the body
+		writeIdentityToken(w, identityToken)
+	}
+}
+
+// writeMFAChallenge responds with a challenge that may be completed by PostSessionMFA on the same
+// domain.
+func writeMFAChallenge(app *app.App, w http.ResponseWriter, r *http.Request, accountID int, amr []string) {
+	challenge, err := services.MFAChallengeCreator(app.Config, accountID, route.MatchedDomain(r), amr)
+	if err != nil {
+		panic(err)
+	}
+
+	WriteData(w, http.StatusAccepted, map[string]interface{}{
+		"mfa_token": challenge,
+		"factors":   []string{"otp"},
+	})
+}
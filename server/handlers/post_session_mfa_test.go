@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPostSessionMFA(t *testing.T) {
+	// nolint: gosec
+	totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
+	totpSecretEnc := []byte("cli6azfL5i7PAnh8U/w3Zbglsm3XcdaGODy+Ga5QqT02c9hotDAR1Y28--3UihzsJhw/+EU3R6--qUw9L8DwN5XPVfOStshKzA==")
+
+	app := test.App()
+	app.Config.EnableMFAChallenge = true
+	app.Config.MFAChallengeSigningKey = []byte("challenge-a-reno")
+	app.Config.MFAChallengeTTL = time.Minute
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	account, err := app.AccountStore.Create(context.Background(), "foo", b)
+	require.NoError(t, err)
+	ok, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
+	require.NoError(t, err)
+	assert.NotNil(t, ok)
+
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+
+	login := func() string {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusAccepted, res.StatusCode)
+		assert.Empty(t, res.Cookies())
+
+		var challenge struct {
+			MFAToken string   `json:"mfa_token"`
+			Factors  []string `json:"factors"`
+		}
+		err = test.ExtractResult(res, &challenge)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"otp"}, challenge.Factors)
+		return challenge.MFAToken
+	}
+
+	t.Run("completing the challenge", func(t *testing.T) {
+		code, err := totp.GenerateCode(totpSecret, time.Now())
+		require.NoError(t, err)
+
+		res, err := client.PostForm("/session/mfa", url.Values{
+			"mfa_token": []string{login()},
+			"otp":       []string{code},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config, res.Cookies(), "pwd", "otp")
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "pwd", "otp")
+	})
+
+	t.Run("with an invalid code", func(t *testing.T) {
+		res, err := client.PostForm("/session/mfa", url.Values{
+			"mfa_token": []string{login()},
+			"otp":       []string{"12345"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: services.ErrInvalidOrExpired}})
+	})
+
+	t.Run("with an invalid password", func(t *testing.T) {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"wrong"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "credentials", Message: services.ErrFailed}})
+	})
+}
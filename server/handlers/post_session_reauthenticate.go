@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/sessions"
+	"github.com/pkg/errors"
+)
+
+// PostSessionReauthenticate confirms the credentials of the current session's user, and records the
+// new authentication on the session so that it may be used for sensitive actions.
+func PostSessionReauthenticate(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var credentials struct {
+			Password string
+			OTP      string
+		}
+		if err := parse.Payload(r, &credentials); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		account, err := services.Reauthenticator(
+			r.Context(), app.AccountStore, app.OTPCodeCache, app.Config,
+			accountID, credentials.Password, credentials.OTP,
+		)
+		if services.IsMissingOTP(err) {
+			sendOTPCode(app, r, account)
+		}
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		// a code was only verified if the account has a second factor
+		amr := []string{"pwd"}
+		if credentials.OTP != "" && account.MFAEnabled() {
+			amr = append(amr, services.OTPMethod(account))
+		}
+
+		session := sessions.Get(r).Reauthenticated(amr)
+		sessionToken, err := session.Sign(app.Config.SessionSigningKey)
+		if err != nil {
+			panic(errors.Wrap(err, "Sign"))
+		}
+		sessions.Set(app.Config, w, sessionToken)
+
+		identityToken, err := services.SessionRefresher(
+			r.Context(), app.RefreshTokenStore, app.OrganizationStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+			session, accountID, route.MatchedDomain(r),
+		)
+		if err != nil {
+			panic(errors.Wrap(err, "SessionRefresher"))
+		}
+
+		writeIdentityToken(w, identityToken)
+	}
+}
@@ -0,0 +1,188 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	jwt "github.com/go-jose/go-jose/v3/jwt"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	oauthtoken "github.com/keratin/authn-server/app/tokens/oauth"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	oauthlib "github.com/keratin/authn-server/lib/oauth"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// createStaleSession creates a session where the user last provided credentials an hour ago.
+func createStaleSession(t *testing.T, app *app.App, accountID int) *http.Cookie {
+	session := test.CreateSession(app.RefreshTokenStore, app.Config, accountID)
+	claims, err := sessions.Parse(session.Value, app.Config)
+	require.NoError(t, err)
+	claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	session.Value, err = claims.Sign(app.Config.SessionSigningKey)
+	require.NoError(t, err)
+	return session
+}
+
+func TestPostSessionReauthenticate(t *testing.T) {
+	app := test.App()
+	app.Config.ReauthenticationMaxAge = 5 * time.Minute
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+
+	t.Run("with password", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "password@keratin.tech", b)
+		require.NoError(t, err)
+		session := createStaleSession(t, app, account.ID)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/password", url.Values{
+			"currentPassword": []string{"bar"},
+			"password":        []string{"0a0b0c0d0e0f"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "session", Message: services.ErrReauthRequired}})
+
+		res, err = client.PostForm("/session/reauthenticate", url.Values{
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config, res.Cookies(), "pwd")
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "pwd")
+
+		// the refreshed session is accepted for sensitive actions
+		refreshed := test.ReadCookie(res.Cookies(), app.Config.SessionCookieName)
+		claims, err := sessions.Parse(refreshed.Value, app.Config)
+		require.NoError(t, err)
+		assert.True(t, claims.AuthTime.Time().After(claims.IssuedAt.Time()))
+
+		client = route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(refreshed)
+		res, err = client.PostForm("/password", url.Values{
+			"currentPassword": []string{"bar"},
+			"password":        []string{"0a0b0c0d0e0f"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+	})
+
+	t.Run("with wrong password", func(t *testing.T) {
+		account, err := app.AccountStore.Create(context.Background(), "wrong@keratin.tech", b)
+		require.NoError(t, err)
+		session := createStaleSession(t, app, account.ID)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/session/reauthenticate", url.Values{
+			"password": []string{"wrong"},
+		})
+		require.NoError(t, err)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "credentials", Message: services.ErrFailed}})
+		assert.Empty(t, test.ReadCookie(res.Cookies(), app.Config.SessionCookieName))
+	})
+
+	t.Run("with TOTP", func(t *testing.T) {
+		// nolint: gosec
+		totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
+		totpSecretEnc := []byte("cli6azfL5i7PAnh8U/w3Zbglsm3XcdaGODy+Ga5QqT02c9hotDAR1Y28--3UihzsJhw/+EU3R6--qUw9L8DwN5XPVfOStshKzA==")
+		account, err := app.AccountStore.Create(context.Background(), "totp@keratin.tech", b)
+		require.NoError(t, err)
+		_, err = app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
+		require.NoError(t, err)
+		session := createStaleSession(t, app, account.ID)
+
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+		res, err := client.PostForm("/session/reauthenticate", url.Values{
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: services.ErrMissing}})
+
+		code, err := totp.GenerateCode(totpSecret, time.Now())
+		require.NoError(t, err)
+		res, err = client.PostForm("/session/reauthenticate", url.Values{
+			"password": []string{"bar"},
+			"otp":      []string{code},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config, res.Cookies(), "pwd", "otp")
+
+		refreshed := test.ReadCookie(res.Cookies(), app.Config.SessionCookieName)
+		client = route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(refreshed)
+		res, err = client.Delete("/totp")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		res, err := client.PostForm("/session/reauthenticate", url.Values{
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
+
+func TestReauthenticateWithOauth(t *testing.T) {
+	providerServer := httptest.NewServer(test.ProviderApp())
+	defer providerServer.Close()
+
+	app := test.App()
+	app.Config.ReauthenticationMaxAge = 5 * time.Minute
+	app.OauthProviders["test"] = *oauthlib.NewTestProvider(providerServer)
+	server := test.Server(app)
+	defer server.Close()
+
+	http.DefaultClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	// an account that was created by OAuth has no password that the user knows
+	nonce := "rand123"
+	destination := app.Config.ApplicationDomains[0].URL()
+	token, err := oauthtoken.New(app.Config, nonce, destination.String()+"/return")
+	require.NoError(t, err)
+	state, err := token.Sign(app.Config.OAuthSigningKey)
+	require.NoError(t, err)
+	nonceCookie := &http.Cookie{Name: app.Config.OAuthCookieName, Value: nonce}
+
+	res, err := route.NewClient(server.URL).WithCookie(nonceCookie).Get("/oauth/test/return?code=oauth-only&state=" + state)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSeeOther, res.StatusCode)
+	account, err := app.AccountStore.FindByOauthAccount(context.Background(), "test", "oauth-only")
+	require.NoError(t, err)
+	require.NotNil(t, account)
+
+	session := createStaleSession(t, app, account.ID)
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+
+	res, err = client.WithCookie(session).Delete("/oauth/test")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	test.AssertErrors(t, res, services.FieldErrors{{Field: "session", Message: services.ErrReauthRequired}})
+
+	// logging in again through the provider reauthenticates
+	res, err = route.NewClient(server.URL).WithCookie(nonceCookie).WithCookie(session).Get("/oauth/test/return?code=oauth-only&state=" + state)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSeeOther, res.StatusCode)
+	test.AssertSession(t, app.Config, res.Cookies(), "oauth:test")
+	refreshed := test.ReadCookie(res.Cookies(), app.Config.SessionCookieName)
+	require.NotNil(t, refreshed)
+
+	res, err = client.WithCookie(refreshed).Delete("/oauth/test")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
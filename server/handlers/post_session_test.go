@@ -1,12 +1,18 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/keratin/authn-server/app/tokens/identities"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/test"
 	"github.com/pquerna/otp/totp"
@@ -21,7 +27,7 @@ func TestPostSessionSuccess(t *testing.T) {
 	defer server.Close()
 
 	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
-	_, err := app.AccountStore.Create("foo", b)
+	_, err := app.AccountStore.Create(context.Background(), "foo", b)
 	require.NoError(t, err)
 
 	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
@@ -42,13 +48,13 @@ func TestPostSessionSuccessWithSession(t *testing.T) {
 	defer server.Close()
 
 	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
-	_, _ = app.AccountStore.Create("foo", b)
+	_, _ = app.AccountStore.Create(context.Background(), "foo", b)
 
 	accountID := 8642
 	session := test.CreateSession(app.RefreshTokenStore, app.Config, accountID)
 
 	// before
-	refreshTokens, err := app.RefreshTokenStore.FindAll(accountID)
+	refreshTokens, err := app.RefreshTokenStore.FindAll(context.Background(), accountID)
 	require.NoError(t, err)
 	refreshToken := refreshTokens[0]
 
@@ -60,7 +66,7 @@ func TestPostSessionSuccessWithSession(t *testing.T) {
 	require.NoError(t, err)
 
 	// after
-	id, err := app.RefreshTokenStore.Find(refreshToken)
+	id, err := app.RefreshTokenStore.Find(context.Background(), refreshToken)
 	require.NoError(t, err)
 	assert.Empty(t, id)
 }
@@ -101,10 +107,10 @@ func TestPostSessionSuccessWithOTP(t *testing.T) {
 	defer server.Close()
 
 	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
-	account, _ := app.AccountStore.Create("foo", b)
+	account, _ := app.AccountStore.Create(context.Background(), "foo", b)
 
-	ok, err := app.AccountStore.SetTOTPSecret(account.ID, totpSecretEnc)
-	assert.True(t, ok)
+	ok, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
+	assert.NotNil(t, ok)
 	require.NoError(t, err)
 
 	code, err := totp.GenerateCode(totpSecret, time.Now())
@@ -133,21 +139,21 @@ func TestPostSessionSuccessWithSessionAndTOTP(t *testing.T) {
 	defer server.Close()
 
 	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
-	account, _ := app.AccountStore.Create("foo", b)
+	account, _ := app.AccountStore.Create(context.Background(), "foo", b)
 
 	accountID := 8642
 	session := test.CreateSession(app.RefreshTokenStore, app.Config, accountID)
 
 	//Generate OTP code
-	ok, err := app.AccountStore.SetTOTPSecret(account.ID, totpSecretEnc)
-	assert.True(t, ok)
+	ok, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
+	assert.NotNil(t, ok)
 	require.NoError(t, err)
 
 	code, err := totp.GenerateCode(totpSecret, time.Now())
 	require.NoError(t, err)
 
 	// before
-	refreshTokens, err := app.RefreshTokenStore.FindAll(accountID)
+	refreshTokens, err := app.RefreshTokenStore.FindAll(context.Background(), accountID)
 	require.NoError(t, err)
 	refreshToken := refreshTokens[0]
 
@@ -160,7 +166,7 @@ func TestPostSessionSuccessWithSessionAndTOTP(t *testing.T) {
 	require.NoError(t, err)
 
 	// after
-	id, err := app.RefreshTokenStore.Find(refreshToken)
+	id, err := app.RefreshTokenStore.Find(context.Background(), refreshToken)
 	require.NoError(t, err)
 	assert.Empty(t, id)
 }
@@ -173,10 +179,10 @@ func TestPostSessionFailureWithOTP(t *testing.T) {
 	defer server.Close()
 
 	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
-	account, _ := app.AccountStore.Create("foo", b)
+	account, _ := app.AccountStore.Create(context.Background(), "foo", b)
 
-	ok, err := app.AccountStore.SetTOTPSecret(account.ID, totpSecretEnc)
-	assert.True(t, ok)
+	ok, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
+	assert.NotNil(t, ok)
 	require.NoError(t, err)
 
 	var testCases = []struct {
@@ -201,3 +207,280 @@ func TestPostSessionFailureWithOTP(t *testing.T) {
 		test.AssertErrors(t, res, tc.errors)
 	}
 }
+
+func TestPostSessionTrustedDevice(t *testing.T) {
+	// nolint: gosec
+	totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
+	totpSecretEnc := []byte("cli6azfL5i7PAnh8U/w3Zbglsm3XcdaGODy+Ga5QqT02c9hotDAR1Y28--3UihzsJhw/+EU3R6--qUw9L8DwN5XPVfOStshKzA==")
+
+	app := test.App()
+	app.Config.EnableTrustedDevices = true
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	account, _ := app.AccountStore.Create(context.Background(), "foo", b)
+	_, err := app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
+	require.NoError(t, err)
+
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+
+	code, err := totp.GenerateCode(totpSecret, time.Now())
+	require.NoError(t, err)
+	res, err := client.PostForm("/session", url.Values{
+		"username":    []string{"foo"},
+		"password":    []string{"bar"},
+		"otp":         []string{code},
+		"trustDevice": []string{"true"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	device := test.ReadCookie(res.Cookies(), app.Config.TrustedDeviceCookieName)
+	require.NotNil(t, device)
+
+	t.Run("trusted device", func(t *testing.T) {
+		res, err := client.WithCookie(device).PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config, res.Cookies(), "pwd")
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "pwd")
+	})
+
+	t.Run("trusted device with wrong password", func(t *testing.T) {
+		res, err := client.WithCookie(device).PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"wrong"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "credentials", Message: services.ErrFailed}})
+	})
+
+	t.Run("untrusted device", func(t *testing.T) {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: services.ErrMissing}})
+	})
+
+	t.Run("without a second factor", func(t *testing.T) {
+		plain, err := app.AccountStore.Create(context.Background(), "plain", b)
+		require.NoError(t, err)
+
+		res, err := client.PostForm("/session", url.Values{
+			"username":    []string{"plain"},
+			"password":    []string{"bar"},
+			"otp":         []string{"123456"},
+			"trustDevice": []string{"true"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Nil(t, test.ReadCookie(res.Cookies(), app.Config.TrustedDeviceCookieName))
+		test.AssertSession(t, app.Config, res.Cookies(), "pwd")
+		devices, err := app.TrustedDeviceStore.FindAll(context.Background(), plain.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+	})
+
+	t.Run("revoked device", func(t *testing.T) {
+		err := app.TrustedDeviceStore.RevokeAll(context.Background(), account.ID)
+		require.NoError(t, err)
+
+		res, err := client.WithCookie(device).PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: services.ErrMissing}})
+	})
+}
+
+func TestPostSessionOTPChannel(t *testing.T) {
+	app := test.App()
+	remoteApp, deliveries := test.OTPDelivery(app)
+	defer remoteApp.Close()
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	account, _ := app.AccountStore.Create(context.Background(), "foo", b)
+	_, err := app.AccountStore.SetOTPChannel(context.Background(), account.ID, "email")
+	require.NoError(t, err)
+
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+
+	res, err := client.PostForm("/session", url.Values{
+		"username": []string{"foo"},
+		"password": []string{"bar"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: services.ErrMissing}})
+
+	delivery := <-deliveries
+	assert.Equal(t, strconv.Itoa(account.ID), delivery.Get("account_id"))
+	assert.Equal(t, "email", delivery.Get("channel"))
+
+	res, err = client.PostForm("/session", url.Values{
+		"username": []string{"foo"},
+		"password": []string{"bar"},
+		"otp":      []string{delivery.Get("code")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	test.AssertSession(t, app.Config, res.Cookies(), "pwd", "mfa")
+	test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "pwd", "mfa")
+
+	// the code may only be used once
+	res, err = client.PostForm("/session", url.Values{
+		"username": []string{"foo"},
+		"password": []string{"bar"},
+		"otp":      []string{delivery.Get("code")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	test.AssertErrors(t, res, services.FieldErrors{{Field: "otp", Message: services.ErrInvalidOrExpired}})
+}
+
+func TestPostSessionWithTenants(t *testing.T) {
+	app := test.App()
+	app.Config.ApplicationDomains = []route.Domain{{Hostname: "acme.com"}, {Hostname: "globex.com"}}
+	app.Config.TenantDomains = map[string]string{"acme.com": "acme", "globex.com": "globex"}
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	_, err := app.AccountStore.Create(tenants.WithContext(context.Background(), "acme"), "foo", b)
+	require.NoError(t, err)
+
+	acme := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+	globex := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[1])
+
+	t.Run("from a domain of another tenant", func(t *testing.T) {
+		res, err := globex.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "credentials", Message: "FAILED"}})
+	})
+
+	t.Run("from a domain of the tenant", func(t *testing.T) {
+		res, err := acme.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var result struct {
+			IDToken string `json:"id_token"`
+		}
+		require.NoError(t, test.ExtractResult(res, &result))
+		claims, err := identities.Parse(result.IDToken, app.Config, app.KeyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, "acme", claims.Tenant)
+
+		session := test.ReadCookie(res.Cookies(), app.Config.SessionCookieName)
+		res, err = acme.WithCookie(session).Get("/session/refresh")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		res, err = globex.WithCookie(session).Get("/session/refresh")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
+
+func TestPostSessionWithPreLoginHook(t *testing.T) {
+	var received url.Values
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		received = r.PostForm
+		if strings.HasPrefix(received.Get("username"), "overdue") {
+			w.Write([]byte(`{"allow": false, "reason": "BILLING_PAST_DUE"}`))
+		} else {
+			w.Write([]byte(`{"allow": true}`))
+		}
+	}))
+	defer hook.Close()
+
+	app := test.App()
+	hookURL, err := url.Parse(hook.URL)
+	require.NoError(t, err)
+	app.Config.AppPreLoginURL = hookURL
+	app.Config.HookTimeout = time.Second
+	app.Config.EnableTrustedDevices = true
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	_, err = app.AccountStore.Create(context.Background(), "foo", b)
+	require.NoError(t, err)
+	_, err = app.AccountStore.Create(context.Background(), "overdue", b)
+	require.NoError(t, err)
+	// nolint: gosec
+	totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
+	totpSecretEnc := []byte("cli6azfL5i7PAnh8U/w3Zbglsm3XcdaGODy+Ga5QqT02c9hotDAR1Y28--3UihzsJhw/+EU3R6--qUw9L8DwN5XPVfOStshKzA==")
+	overdueMFA, err := app.AccountStore.Create(context.Background(), "overdue-mfa", b)
+	require.NoError(t, err)
+	_, err = app.AccountStore.AddTOTPAuthenticator(context.Background(), overdueMFA.ID, "phone", totpSecretEnc, 0)
+	require.NoError(t, err)
+
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+
+	t.Run("allowed", func(t *testing.T) {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Equal(t, "127.0.0.1", received.Get("ip"))
+		assert.Equal(t, "test.com", received.Get("domain"))
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"overdue"},
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "account", Message: "BILLING_PAST_DUE"}})
+		assert.Empty(t, res.Cookies())
+	})
+
+	t.Run("denied after a second factor", func(t *testing.T) {
+		code, err := totp.GenerateCode(totpSecret, time.Now())
+		require.NoError(t, err)
+		res, err := client.PostForm("/session", url.Values{
+			"username":    []string{"overdue-mfa"},
+			"password":    []string{"bar"},
+			"otp":         []string{code},
+			"trustDevice": []string{"true"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		assert.Empty(t, res.Cookies())
+		devices, err := app.TrustedDeviceStore.FindAll(context.Background(), overdueMFA.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+	})
+}
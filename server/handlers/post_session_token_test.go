@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"testing"
@@ -31,7 +32,7 @@ func TestPostSessionToken(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, res.StatusCode)
 		test.AssertSession(t, app.Config, res.Cookies(), "link")
 		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "link")
-		found, err := app.AccountStore.Find(account.ID)
+		found, err := app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.Equal(t, found.Password, account.Password)
 	}
@@ -42,7 +43,7 @@ func TestPostSessionToken(t *testing.T) {
 			return nil, errors.Wrap(err, "bcrypt")
 		}
 
-		return app.AccountStore.Create(username, hash)
+		return app.AccountStore.Create(context.Background(), username, hash)
 	}
 
 	t.Run("valid passwordless token", func(t *testing.T) {
@@ -104,7 +105,7 @@ func TestPostSessionToken(t *testing.T) {
 		// invalidates old session
 		claims, err := sessions.Parse(session.Value, app.Config)
 		require.NoError(t, err)
-		id, err := app.RefreshTokenStore.Find(models.RefreshToken(claims.Subject))
+		id, err := app.RefreshTokenStore.Find(context.Background(), models.RefreshToken(claims.Subject))
 		require.NoError(t, err)
 		assert.Empty(t, id)
 	})
@@ -126,7 +127,7 @@ func TestPostSessionTokenWithOTP(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, res.StatusCode)
 		test.AssertSession(t, app.Config, res.Cookies(), "link", "otp")
 		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "link", "otp")
-		found, err := app.AccountStore.Find(account.ID)
+		found, err := app.AccountStore.Find(context.Background(), account.ID)
 		require.NoError(t, err)
 		assert.Equal(t, found.Password, account.Password)
 	}
@@ -137,14 +138,14 @@ func TestPostSessionTokenWithOTP(t *testing.T) {
 			return nil, errors.Wrap(err, "bcrypt")
 		}
 
-		return app.AccountStore.Create(username, hash)
+		return app.AccountStore.Create(context.Background(), username, hash)
 	}
 
 	t.Run("valid totp code", func(t *testing.T) {
 		// given an account
 		account, err := factory("first@authn.tech", "oldpwd")
 		require.NoError(t, err)
-		_, err = app.AccountStore.SetTOTPSecret(account.ID, totpSecretEnc)
+		_, err = app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
 		require.NoError(t, err)
 
 		// given a passwordless token
@@ -172,7 +173,7 @@ func TestPostSessionTokenWithOTP(t *testing.T) {
 		// given an account
 		account, err := factory("second@authn.tech", "oldpwd")
 		require.NoError(t, err)
-		_, err = app.AccountStore.SetTOTPSecret(account.ID, totpSecretEnc)
+		_, err = app.AccountStore.AddTOTPAuthenticator(context.Background(), account.ID, "phone", totpSecretEnc, 0)
 		require.NoError(t, err)
 
 		// given a passwordless token
@@ -5,6 +5,7 @@ import (
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/sessions"
 )
 
@@ -12,13 +13,13 @@ import (
 func ConfirmTOTP(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// check for valid session with live token
-		accountID := sessions.GetAccountID(r)
+		accountID := sessions.GetEnrollingAccountID(r)
 		if accountID == 0 {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
-		if err := services.TOTPSetter(app.AccountStore, app.TOTPCache, app.Config, accountID, r.FormValue("otp")); err != nil {
+		if err := services.TOTPSetter(r.Context(), app.AccountStore, app.TrustedDeviceStore, app.TOTPCache, app.Config, accountID, r.FormValue("otp"), r.FormValue("name")); err != nil {
 			w.WriteHeader(http.StatusUnprocessableEntity)
 			if fe, ok := err.(services.FieldErrors); ok {
 				WriteErrors(w, fe)
@@ -28,6 +29,27 @@ func ConfirmTOTP(app *app.App) http.HandlerFunc {
 			panic(err)
 		}
 
+		// a session that was restricted to setting up MFA is replaced with a full session
+		session := sessions.Get(r)
+		if session.MFAEnrollment {
+			sessionToken, identityToken, err := services.SessionCreator(
+				r.Context(), app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+				accountID, route.MatchedDomain(r), sessions.GetRefreshToken(r), append(session.AuthMethodReference, "otp"),
+			)
+			if err != nil {
+				if fe, ok := err.(services.FieldErrors); ok {
+					WriteErrors(w, fe)
+					return
+				}
+
+				panic(err)
+			}
+
+			sessions.Set(app.Config, w, sessionToken)
+			writeIdentityToken(w, identityToken)
+			return
+		}
+
 		w.WriteHeader(http.StatusOK)
 	}
 }
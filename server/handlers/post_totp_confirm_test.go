@@ -1,17 +1,20 @@
 package handlers_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"testing"
 	"time"
 
+	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/test"
 	"github.com/pquerna/otp/totp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestPostTOTPConfirmSuccess(t *testing.T) {
@@ -21,9 +24,9 @@ func TestPostTOTPConfirmSuccess(t *testing.T) {
 
 	// nolint: gosec
 	totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
-	account, _ := app.AccountStore.Create("account@keratin.tech", []byte("password"))
+	account, _ := app.AccountStore.Create(context.Background(), "account@keratin.tech", []byte("password"))
 	existingSession := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
-	err := app.TOTPCache.CacheTOTPSecret(account.ID, []byte(totpSecret))
+	err := app.TOTPCache.CacheTOTPSecret(context.Background(), account.ID, []byte(totpSecret))
 	require.NoError(t, err)
 
 	code, err := totp.GenerateCode(totpSecret, time.Now())
@@ -40,10 +43,15 @@ func TestPostTOTPConfirmSuccess(t *testing.T) {
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 	assert.Equal(t, "", string(body))
 
-	// ensure that after confirmation a new secret cannot be requested
+	authenticators, err := app.AccountStore.GetTOTPAuthenticators(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, authenticators, 1)
+	assert.Equal(t, "Authenticator", authenticators[0].Name)
+
+	// ensure that after confirmation another authenticator may be added
 	res, err = client.PostForm("/totp/new", url.Values{})
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
 }
 
 func TestPostTOTPConfirmFailure(t *testing.T) {
@@ -53,9 +61,9 @@ func TestPostTOTPConfirmFailure(t *testing.T) {
 
 	// nolint: gosec
 	totpSecret := "JKK5AG4NDAWSZSR4ZFKZBWZ7OJGLB2JM"
-	account, _ := app.AccountStore.Create("account@keratin.tech", []byte("password"))
+	account, _ := app.AccountStore.Create(context.Background(), "account@keratin.tech", []byte("password"))
 	existingSession := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
-	err := app.TOTPCache.CacheTOTPSecret(account.ID, []byte(totpSecret))
+	err := app.TOTPCache.CacheTOTPSecret(context.Background(), account.ID, []byte(totpSecret))
 	require.NoError(t, err)
 
 	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(existingSession)
@@ -68,3 +76,60 @@ func TestPostTOTPConfirmFailure(t *testing.T) {
 	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
 	assert.Equal(t, "{\"errors\":[{\"field\":\"otp\",\"message\":\"INVALID_OR_EXPIRED\"}]}", string(body))
 }
+
+func TestPostTOTPConfirmMFAEnrollment(t *testing.T) {
+	app := test.App()
+	app.Config.MFARequired = true
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	_, err := app.AccountStore.Create(context.Background(), "enrolling@keratin.tech", b)
+	require.NoError(t, err)
+
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+	res, err := client.PostForm("/session", url.Values{
+		"username": []string{"enrolling@keratin.tech"},
+		"password": []string{"bar"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	test.AssertData(t, res, map[string]bool{"mfa_enrollment_required": true})
+	restricted := test.ReadCookie(res.Cookies(), app.Config.SessionCookieName)
+	require.NotNil(t, restricted)
+
+	// the restricted session cannot be refreshed
+	client = client.WithCookie(restricted)
+	res, err = client.Get("/session/refresh")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	test.AssertErrors(t, res, services.FieldErrors{{Field: "totp", Message: services.ErrRequired}})
+
+	// but it can set up MFA
+	res, err = client.PostForm("/totp/new", url.Values{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	responseData := struct {
+		Secret string `json:"secret"`
+	}{}
+	err = test.ExtractResult(res, &responseData)
+	require.NoError(t, err)
+
+	code, err := totp.GenerateCode(responseData.Secret, time.Now())
+	require.NoError(t, err)
+	res, err = client.PostForm("/totp/confirm", url.Values{
+		"otp": []string{code},
+	})
+	require.NoError(t, err)
+
+	// and is upgraded once it has
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	test.AssertSession(t, app.Config, res.Cookies(), "pwd", "otp")
+	test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config, "pwd", "otp")
+
+	res, err = route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).
+		WithCookie(test.ReadCookie(res.Cookies(), app.Config.SessionCookieName)).
+		Get("/session/refresh")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+}
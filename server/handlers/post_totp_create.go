@@ -1,26 +1,34 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/base64"
 	"errors"
+	"image/png"
 	"net/http"
+	"strconv"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/sessions"
+	"github.com/pquerna/otp"
 )
 
+// totpQRSize is the width and height of the QR code image, in pixels
+const totpQRSize = 256
+
 // CreateTOTP begins the OTP onboarding process
 func CreateTOTP(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// check for valid session with live token
-		accountID := sessions.GetAccountID(r)
+		accountID := sessions.GetEnrollingAccountID(r)
 		if accountID == 0 {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
-		totpKey, err := services.TOTPCreator(app.AccountStore, app.TOTPCache, accountID, route.MatchedDomain(r))
+		totpKey, err := services.TOTPCreator(r.Context(), app.AccountStore, app.TOTPCache, app.Config, accountID, route.MatchedDomain(r))
 		if err != nil {
 			if errors.Is(err, services.ErrExistingTOTPSecret) {
 				w.WriteHeader(http.StatusUnprocessableEntity)
@@ -28,9 +36,30 @@ func CreateTOTP(app *app.App) http.HandlerFunc {
 			panic(err)
 		}
 
-		WriteData(w, http.StatusOK, map[string]string{
+		result := map[string]string{
 			"secret": totpKey.Secret(),
 			"url":    totpKey.URL(),
-		})
+		}
+		if qr, _ := strconv.ParseBool(r.FormValue("qr")); qr {
+			result["qr"], err = totpQRCode(totpKey)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		WriteData(w, http.StatusOK, result)
+	}
+}
+
+// totpQRCode renders the key's URL as a PNG data URI that may be used directly as an image source.
+func totpQRCode(key *otp.Key) (string, error) {
+	img, err := key.Image(totpQRSize, totpQRSize)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
 	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
 }
@@ -1,8 +1,13 @@
 package handlers_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image/png"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/keratin/authn-server/lib/route"
@@ -16,7 +21,7 @@ func TestPostTOTPCreateSuccess(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	account, _ := app.AccountStore.Create("account@keratin.tech", []byte("password"))
+	account, _ := app.AccountStore.Create(context.Background(), "account@keratin.tech", []byte("password"))
 	existingSession := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
 
 	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(existingSession)
@@ -37,6 +42,35 @@ func TestPostTOTPCreateSuccess(t *testing.T) {
 	assert.Contains(t, responseData.Url, responseData.Secret)
 }
 
+func TestPostTOTPCreateWithQRCode(t *testing.T) {
+	app := test.App()
+	app.Config.TOTPIssuer = "Example"
+	server := test.Server(app)
+	defer server.Close()
+
+	account, _ := app.AccountStore.Create(context.Background(), "account@keratin.tech", []byte("password"))
+	existingSession := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(existingSession)
+	res, err := client.PostForm("/totp/new", url.Values{"qr": []string{"true"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	responseData := struct {
+		Url string `json:"url"`
+		QR  string `json:"qr"`
+	}{}
+	err = test.ExtractResult(res, &responseData)
+	require.NoError(t, err)
+
+	assert.Contains(t, responseData.Url, "otpauth://totp/Example:account@keratin.tech")
+	require.True(t, strings.HasPrefix(responseData.QR, "data:image/png;base64,"))
+	img, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(responseData.QR, "data:image/png;base64,"))
+	require.NoError(t, err)
+	_, err = png.Decode(bytes.NewReader(img))
+	assert.NoError(t, err)
+}
+
 func TestPostTOTPCreateUnauthenticated(t *testing.T) {
 	app := test.App()
 	server := test.Server(app)
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PutOrganizationMember(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, "organization")
+			return
+		}
+		accountID, err := strconv.Atoi(mux.Vars(r)["account_id"])
+		if err != nil {
+			WriteNotFound(w, "account")
+			return
+		}
+
+		var params struct {
+			Roles string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		err = services.MembershipSetter(r.Context(), app.OrganizationStore, app.AccountStore, id, accountID, params.Roles)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, fe[0].Field)
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutOrganizationMember(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+
+	organization, err := app.OrganizationStore.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	account, err := app.AccountStore.Create(context.Background(), "user@example.com", []byte("password"))
+	require.NoError(t, err)
+	path := "/organizations/" + strconv.Itoa(organization.ID) + "/members/" + strconv.Itoa(account.ID)
+
+	t.Run("unknown organization", func(t *testing.T) {
+		res, err := client.Put("/organizations/9999/members/"+strconv.Itoa(account.ID), url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.Put("/organizations/"+strconv.Itoa(organization.ID)+"/members/9999", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("valid member", func(t *testing.T) {
+		res, err := client.Put(path, url.Values{"roles": []string{"admin billing"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		membership, err := app.OrganizationStore.FindMembership(context.Background(), organization.ID, account.ID)
+		require.NoError(t, err)
+		require.NotNil(t, membership)
+		assert.Equal(t, models.NameList{"admin", "billing"}, membership.Roles)
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/sessions"
+	"github.com/pkg/errors"
+)
+
+// PutSessionOrganization selects the active organization of the current session, so that refreshed
+// identity tokens describe the user's membership in it.
+func PutSessionOrganization(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var params struct {
+			OrganizationID int `json:"organization_id" schema:"organization_id"`
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, err)
+			return
+		}
+
+		err := services.OrganizationSelector(r.Context(), app.OrganizationStore, accountID, params.OrganizationID)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		session := sessions.Get(r).WithOrganization(params.OrganizationID)
+		sessionToken, err := session.Sign(app.Config.SessionSigningKey)
+		if err != nil {
+			panic(errors.Wrap(err, "Sign"))
+		}
+		sessions.Set(app.Config, w, sessionToken)
+
+		identityToken, err := services.SessionRefresher(
+			r.Context(), app.RefreshTokenStore, app.OrganizationStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
+			session, accountID, route.MatchedDomain(r),
+		)
+		if err != nil {
+			panic(errors.Wrap(err, "SessionRefresher"))
+		}
+
+		writeIdentityToken(w, identityToken)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutSessionOrganization(t *testing.T) {
+	app := test.App()
+	app.Config.EnableOrganizations = true
+	server := test.Server(app)
+	defer server.Close()
+
+	accountID := 8642
+	organization, err := app.OrganizationStore.Create(context.Background(), "Acme")
+	require.NoError(t, err)
+	err = app.OrganizationStore.SetMembership(context.Background(), organization.ID, accountID, models.NameList{"admin", "billing"})
+	require.NoError(t, err)
+	other, err := app.OrganizationStore.Create(context.Background(), "Globex")
+	require.NoError(t, err)
+
+	session := test.CreateSession(app.RefreshTokenStore, app.Config, accountID)
+	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+
+	t.Run("without a session", func(t *testing.T) {
+		res, err := client.Put("/session/organization", url.Values{"organization_id": []string{strconv.Itoa(organization.ID)}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("organization of another account", func(t *testing.T) {
+		res, err := client.WithCookie(session).Put("/session/organization", url.Values{"organization_id": []string{strconv.Itoa(other.ID)}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{Field: "organization_id", Message: services.ErrNotFound}})
+	})
+
+	t.Run("organization of the account", func(t *testing.T) {
+		res, err := client.WithCookie(session).Put("/session/organization", url.Values{"organization_id": []string{strconv.Itoa(organization.ID)}})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var result struct {
+			IDToken string `json:"id_token"`
+		}
+		require.NoError(t, test.ExtractResult(res, &result))
+		claims, err := identities.Parse(result.IDToken, app.Config, app.KeyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(organization.ID), claims.OrganizationID)
+		assert.Equal(t, []string{"admin", "billing"}, claims.Roles)
+
+		// the selection is remembered by the session
+		cookie := test.ReadCookie(res.Cookies(), app.Config.SessionCookieName)
+		require.NotNil(t, cookie)
+		updated, err := sessions.Parse(cookie.Value, app.Config)
+		require.NoError(t, err)
+		assert.Equal(t, organization.ID, updated.OrganizationID)
+
+		res, err = client.WithCookie(cookie).Get("/session/refresh")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.NoError(t, test.ExtractResult(res, &result))
+		claims, err = identities.Parse(result.IDToken, app.Config, app.KeyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(organization.ID), claims.OrganizationID)
+	})
+}
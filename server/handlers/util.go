@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"net/url"
+	"regexp"
 	"time"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tenants"
 	"github.com/keratin/authn-server/app/tokens/oauth"
+	"github.com/keratin/authn-server/server/sessions"
 	"github.com/pkg/errors"
 )
 
@@ -51,3 +57,95 @@ func redirectFailure(w http.ResponseWriter, r *http.Request, destination string)
 	url.RawQuery = query.Encode()
 	http.Redirect(w, r, url.String(), http.StatusSeeOther)
 }
+
+// writeIdentityToken responds with a new identity token. A session that is restricted to setting up
+// MFA will not have one.
+func writeIdentityToken(w http.ResponseWriter, identityToken string) {
+	if identityToken == "" {
+		WriteData(w, http.StatusCreated, map[string]bool{
+			"mfa_enrollment_required": true,
+		})
+		return
+	}
+
+	WriteData(w, http.StatusCreated, map[string]string{
+		"id_token": identityToken,
+	})
+}
+
+// isTrustedDevice checks whether the request comes from a browser that the account has trusted to
+// skip the second factor.
+func isTrustedDevice(app *app.App, r *http.Request, accountID int) bool {
+	if !app.Config.EnableTrustedDevices {
+		return false
+	}
+	cookie, err := r.Cookie(app.Config.TrustedDeviceCookieName)
+	if err != nil {
+		return false
+	}
+
+	trusted, err := services.TrustedDeviceVerifier(r.Context(), app.TrustedDeviceStore, app.Config, cookie.Value, accountID)
+	if err != nil {
+		panic(err)
+	}
+	return trusted
+}
+
+// trustDevice remembers the browser for the account if the user asked for it, so that later logins
+// may skip the second factor.
+func trustDevice(app *app.App, w http.ResponseWriter, r *http.Request, accountID int, requested string) {
+	if !app.Config.EnableTrustedDevices {
+		return
+	}
+	trust, err := regexp.MatchString("^(?i:t|true|yes)$", requested)
+	if err != nil {
+		panic(err)
+	}
+	if !trust {
+		return
+	}
+
+	token, err := services.TrustedDeviceCreator(r.Context(), app.TrustedDeviceStore, app.Config, accountID, r.UserAgent())
+	if err != nil {
+		panic(err)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     app.Config.TrustedDeviceCookieName,
+		Value:    token,
+		Path:     app.Config.MountedPath,
+		Secure:   app.Config.ForceSSL,
+		HttpOnly: true,
+		MaxAge:   int(app.Config.TrustedDeviceTTL.Seconds()),
+		SameSite: app.Config.SameSiteComputed(),
+	})
+}
+
+// requireFreshSession checks that the user provided credentials for the session recently enough for
+// a sensitive action. Otherwise it responds with an error that asks for reauthentication. Accounts
+// without a password reauthenticate by logging in with OAuth again, which creates a fresh session.
+func requireFreshSession(app *app.App, w http.ResponseWriter, r *http.Request) bool {
+	session := sessions.Get(r)
+	if session == nil || !session.Fresh(app.Config.ReauthenticationMaxAge, time.Now()) {
+		WriteErrors(w, services.FieldErrors{{Field: "session", Message: services.ErrReauthRequired}})
+		return false
+	}
+	return true
+}
+
+// sendOTPCode delivers a one-time code when the account's second factor is a channel like email
+// or SMS. It runs in the background because delivery may be retried.
+func sendOTPCode(app *app.App, r *http.Request, account *models.Account) {
+	if !account.OTPChannelEnabled() {
+		return
+	}
+
+	// the request may be finished before the code is delivered
+	ctx := tenants.WithContext(context.Background(), tenants.Name(r.Context()))
+	go func() {
+		err := services.OTPCodeSender(ctx, app.OTPCodeCache, app.Config, account, account.OTPChannel.String, app.Logger)
+		if err != nil {
+			app.Reporter.ReportError(err)
+		}
+	}()
+}
@@ -32,6 +32,10 @@ func PrivateRoutes(app *app.App) []*route.HandledRoute {
 			SecuredWith(authentication).
 			Handle(handlers.PostAccountsImport(app)),
 
+		route.Post("/invitations").
+			SecuredWith(authentication).
+			Handle(handlers.PostInvitation(app)),
+
 		route.Get("/accounts/{id:[0-9]+}").
 			SecuredWith(authentication).
 			Handle(handlers.GetAccount(app)),
@@ -52,6 +56,10 @@ func PrivateRoutes(app *app.App) []*route.HandledRoute {
 			SecuredWith(authentication).
 			Handle(handlers.PatchAccountExpirePassword(app)),
 
+		route.Patch("/accounts/{id:[0-9]+}/require_mfa").
+			SecuredWith(authentication).
+			Handle(handlers.PatchAccountRequireMFA(app)),
+
 		route.Put("/accounts/{id:[0-9]+}").
 			SecuredWith(authentication).
 			Handle(handlers.PatchAccount(app)),
@@ -68,6 +76,10 @@ func PrivateRoutes(app *app.App) []*route.HandledRoute {
 			SecuredWith(authentication).
 			Handle(handlers.PatchAccountExpirePassword(app)),
 
+		route.Put("/accounts/{id:[0-9]+}/require_mfa").
+			SecuredWith(authentication).
+			Handle(handlers.PatchAccountRequireMFA(app)),
+
 		route.Delete("/accounts/{id:[0-9]+}").
 			SecuredWith(authentication).
 			Handle(handlers.DeleteAccount(app)),
@@ -75,8 +87,108 @@ func PrivateRoutes(app *app.App) []*route.HandledRoute {
 		route.Delete("/accounts/{id:[0-9]+}/oauth/{name}").
 			SecuredWith(authentication).
 			Handle(handlers.DeleteAccountOauth(app)),
+
+		route.Post("/accounts/{id:[0-9]+}/impersonate").
+			SecuredWith(authentication).
+			Handle(handlers.PostAccountImpersonate(app)),
+
+		route.Post("/clients").
+			SecuredWith(authentication).
+			Handle(handlers.PostClient(app)),
+
+		route.Get("/clients").
+			SecuredWith(authentication).
+			Handle(handlers.GetClients(app)),
+
+		route.Get("/clients/{id:[0-9a-f]+}").
+			SecuredWith(authentication).
+			Handle(handlers.GetClient(app)),
+
+		route.Patch("/clients/{id:[0-9a-f]+}").
+			SecuredWith(authentication).
+			Handle(handlers.PatchClient(app)),
+
+		route.Put("/clients/{id:[0-9a-f]+}").
+			SecuredWith(authentication).
+			Handle(handlers.PatchClient(app)),
+
+		route.Delete("/clients/{id:[0-9a-f]+}").
+			SecuredWith(authentication).
+			Handle(handlers.DeleteClient(app)),
+
+		route.Post("/organizations").
+			SecuredWith(authentication).
+			Handle(handlers.PostOrganization(app)),
+
+		route.Get("/organizations/{id:[0-9]+}").
+			SecuredWith(authentication).
+			Handle(handlers.GetOrganization(app)),
+
+		route.Patch("/organizations/{id:[0-9]+}").
+			SecuredWith(authentication).
+			Handle(handlers.PatchOrganization(app)),
+
+		route.Put("/organizations/{id:[0-9]+}").
+			SecuredWith(authentication).
+			Handle(handlers.PatchOrganization(app)),
+
+		route.Delete("/organizations/{id:[0-9]+}").
+			SecuredWith(authentication).
+			Handle(handlers.DeleteOrganization(app)),
+
+		route.Get("/organizations/{id:[0-9]+}/members").
+			SecuredWith(authentication).
+			Handle(handlers.GetOrganizationMembers(app)),
+
+		route.Put("/organizations/{id:[0-9]+}/members/{account_id:[0-9]+}").
+			SecuredWith(authentication).
+			Handle(handlers.PutOrganizationMember(app)),
+
+		route.Delete("/organizations/{id:[0-9]+}/members/{account_id:[0-9]+}").
+			SecuredWith(authentication).
+			Handle(handlers.DeleteOrganizationMember(app)),
+
+		route.Get("/accounts/{id:[0-9]+}/organizations").
+			SecuredWith(authentication).
+			Handle(handlers.GetAccountOrganizations(app)),
+
+		route.Post("/introspect").
+			SecuredWith(authentication).
+			Handle(handlers.PostIntrospect(app)),
+
+		route.Post("/revoke").
+			SecuredWith(authentication).
+			Handle(handlers.PostRevoke(app)),
 	)
 
+	if app.KeyRotater != nil {
+		routes = append(routes,
+			route.Post("/keys/rotate").
+				SecuredWith(authentication).
+				Handle(handlers.PostKeysRotate(app)),
+		)
+	}
+
+	if app.Config.EnableTrustedDevices {
+		routes = append(routes,
+			route.Get("/accounts/{id:[0-9]+}/devices").
+				SecuredWith(authentication).
+				Handle(handlers.GetAccountDevices(app)),
+
+			route.Delete("/accounts/{id:[0-9]+}/devices/{device_id:[0-9a-f]+}").
+				SecuredWith(authentication).
+				Handle(handlers.DeleteAccountDevice(app)),
+		)
+	}
+
+	if app.Config.EnablePersonalAccessTokens {
+		routes = append(routes,
+			route.Post("/personal_access_tokens/exchange").
+				SecuredWith(authentication).
+				Handle(handlers.PostPersonalAccessTokenExchange(app)),
+		)
+	}
+
 	if app.Actives != nil {
 		routes = append(routes,
 			route.Get("/stats").
@@ -4,13 +4,15 @@ import (
 	"net/http"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tenants"
+	"github.com/keratin/authn-server/lib/oauth"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/handlers"
 )
 
 func PublicRoutes(app *app.App) []*route.HandledRoute {
 	var routes []*route.HandledRoute
-	originSecurity := route.OriginSecurity(app.Config.ApplicationDomains, app.Logger)
+	originSecurity := tenantSecurity(app.Config, route.OriginSecurity(app.Config.ApplicationDomains, app.Logger))
 
 	routes = append(routes,
 		route.Get("/health").
@@ -45,6 +47,10 @@ func PublicRoutes(app *app.App) []*route.HandledRoute {
 			SecuredWith(originSecurity).
 			Handle(handlers.GetSessionRefresh(app)),
 
+		route.Post("/session/reauthenticate").
+			SecuredWith(originSecurity).
+			Handle(handlers.PostSessionReauthenticate(app)),
+
 		route.Post("/totp/new").
 			SecuredWith(originSecurity).
 			Handle(handlers.CreateTOTP(app)),
@@ -57,16 +63,112 @@ func PublicRoutes(app *app.App) []*route.HandledRoute {
 			SecuredWith(originSecurity).
 			Handle(handlers.DeleteTOTP(app)),
 
+		route.Get("/totp").
+			SecuredWith(originSecurity).
+			Handle(handlers.GetTOTP(app)),
+
+		route.Delete("/totp/{id:[0-9]+}").
+			SecuredWith(originSecurity).
+			Handle(handlers.DeleteTOTPAuthenticator(app)),
+
 		route.Get("/oauth/accounts").
 			SecuredWith(originSecurity).
 			Handle(handlers.GetOauthAccounts(app)),
+
+		route.Post("/oauth/token").
+			SecuredWith(route.Unsecured()).
+			Handle(handlers.PostOauthToken(app)),
 	)
 
-	if app.Config.EnableSignup {
+	if app.Config.EnableMFAChallenge {
 		routes = append(routes,
-			route.Post("/accounts").
+			route.Post("/session/mfa").
 				SecuredWith(originSecurity).
-				Handle(handlers.PostAccount(app)),
+				Handle(handlers.PostSessionMFA(app)),
+		)
+	}
+
+	if app.Config.EnableTrustedDevices {
+		routes = append(routes,
+			route.Get("/devices").
+				SecuredWith(originSecurity).
+				Handle(handlers.GetDevices(app)),
+
+			route.Delete("/devices/{id:[0-9a-f]+}").
+				SecuredWith(originSecurity).
+				Handle(handlers.DeleteDevice(app)),
+		)
+	}
+
+	if app.Config.EnablePersonalAccessTokens {
+		routes = append(routes,
+			route.Get("/personal_access_tokens").
+				SecuredWith(originSecurity).
+				Handle(handlers.GetPersonalAccessTokens(app)),
+
+			route.Post("/personal_access_tokens").
+				SecuredWith(originSecurity).
+				Handle(handlers.PostPersonalAccessToken(app)),
+
+			route.Delete("/personal_access_tokens/{id:[0-9]+}").
+				SecuredWith(originSecurity).
+				Handle(handlers.DeletePersonalAccessToken(app)),
+		)
+	}
+
+	if app.Config.EnableOrganizations {
+		routes = append(routes,
+			route.Get("/organizations").
+				SecuredWith(originSecurity).
+				Handle(handlers.GetOrganizations(app)),
+
+			route.Put("/session/organization").
+				SecuredWith(originSecurity).
+				Handle(handlers.PutSessionOrganization(app)),
+		)
+	}
+
+	if app.Config.DeviceVerificationURL != nil {
+		routes = append(routes,
+			route.Post("/device/code").
+				SecuredWith(route.Unsecured()).
+				Handle(handlers.PostDeviceCode(app)),
+
+			route.Post("/device/token").
+				SecuredWith(route.Unsecured()).
+				Handle(handlers.PostDeviceToken(app)),
+
+			route.Post("/device/approve").
+				SecuredWith(originSecurity).
+				Handle(handlers.PostDeviceApprove(app)),
+		)
+	}
+
+	if app.Config.AppOTPDeliveryURL != nil {
+		routes = append(routes,
+			route.Post("/otp/new").
+				SecuredWith(originSecurity).
+				Handle(handlers.CreateOTP(app)),
+
+			route.Post("/otp/confirm").
+				SecuredWith(originSecurity).
+				Handle(handlers.ConfirmOTP(app)),
+
+			route.Delete("/otp").
+				SecuredWith(originSecurity).
+				Handle(handlers.DeleteOTP(app)),
+		)
+	}
+
+	// signup is always possible with an invitation
+	routes = append(routes,
+		route.Post("/accounts").
+			SecuredWith(originSecurity).
+			Handle(handlers.PostAccount(app)),
+	)
+
+	if app.Config.EnableSignup {
+		routes = append(routes,
 			route.Get("/accounts/available").
 				SecuredWith(originSecurity).
 				Handle(handlers.GetAccountsAvailable(app)),
@@ -93,7 +195,17 @@ func PublicRoutes(app *app.App) []*route.HandledRoute {
 		)
 	}
 
+	// a provider has routes when the server or any tenant has credentials for it
+	oauthProviders := map[string]oauth.Provider{}
+	for _, tenantProviders := range app.TenantOauthProviders {
+		for providerName, provider := range tenantProviders {
+			oauthProviders[providerName] = provider
+		}
+	}
 	for providerName, provider := range app.OauthProviders {
+		oauthProviders[providerName] = provider
+	}
+	for providerName, provider := range oauthProviders {
 		var returnRoute *route.Route
 		if provider.ReturnMethod() == http.MethodPost {
 			returnRoute = route.Post("/oauth/" + providerName + "/return")
@@ -115,3 +227,14 @@ func PublicRoutes(app *app.App) []*route.HandledRoute {
 
 	return routes
 }
+
+// tenantSecurity extends OriginSecurity so that accounts are found and created in the tenant of the
+// matched domain.
+func tenantSecurity(cfg *app.Config, originSecurity route.SecurityHandler) route.SecurityHandler {
+	return func(h http.Handler) http.Handler {
+		return originSecurity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := tenants.WithContext(r.Context(), cfg.TenantFor(route.MatchedDomain(r).String()))
+			h.ServeHTTP(w, r.WithContext(ctx))
+		}))
+	}
+}
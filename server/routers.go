@@ -1,12 +1,15 @@
 package server
 
 import (
+	"net"
 	"net/http"
 	"os"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/hooks"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/ops"
 	"github.com/keratin/authn-server/server/cors"
@@ -32,6 +35,8 @@ func wrapRouter(r *mux.Router, app *app.App) http.Handler {
 	stack := handlers.CombinedLoggingHandler(os.Stdout, r)
 	stack = sessions.Middleware(app)(stack)
 	stack = cors.Middleware(app)(stack)
+	stack = readYourWrites(stack)
+	stack = hookClient(stack)
 
 	if app.Config.Proxied {
 		stack = handlers.ProxyHeaders(stack)
@@ -39,3 +44,24 @@ func wrapRouter(r *mux.Router, app *app.App) http.Handler {
 
 	return ops.PanicHandler(app.Reporter, stack)
 }
+
+// readYourWrites ensures that once a request writes to the primary database, its later account
+// lookups will not be sent to a replica that may lag behind.
+func readYourWrites(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(data.WithReadYourWrites(r.Context())))
+	})
+}
+
+// hookClient remembers who made the request, so that it may be described to the app's hooks. When
+// AuthN is proxied, the address has already been taken from the forwarding headers.
+func hookClient(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		ctx := hooks.WithClient(r.Context(), hooks.Client{IP: ip, UserAgent: r.UserAgent()})
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
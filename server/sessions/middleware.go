@@ -2,12 +2,16 @@ package sessions
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
 	"github.com/pkg/errors"
 )
 
@@ -48,9 +52,40 @@ func Middleware(app *app.App) func(http.Handler) http.Handler {
 						return
 					}
 
-					accountID, err = app.RefreshTokenStore.Find(models.RefreshToken(session.Subject))
+					// a session can not be used on a domain of another tenant
+					domain := route.FindDomain(route.InferOrigin(r), app.Config.ApplicationDomains)
+					if domain != nil && app.Config.TenantFor(domain.String()) != app.Config.TenantFor(session.Azp) {
+						return
+					}
+
+					// an expired session is discarded even if the refresh token is still active
+					tenant := app.Config.TenantFor(session.Azp)
+					if session.Expired(app.Config, time.Now()) {
+						token := models.RefreshToken(session.Subject)
+						err = services.SessionEnder(r.Context(), app.RefreshTokenStore, app.KeyStore, app.Config, app.Reporter, tenant, &token)
+						if err != nil {
+							app.Reporter.ReportRequestError(errors.Wrap(err, "SessionEnder"), r)
+						}
+						return
+					}
+
+					accountID, err = app.RefreshTokenStore.Find(r.Context(), models.RefreshToken(session.Subject))
 					if err != nil {
 						app.Reporter.ReportRequestError(errors.Wrap(err, "Find"), r)
+						return
+					}
+
+					// a replaced refresh token should never be seen again
+					if accountID == 0 && app.Config.RefreshTokenRotation {
+						reusedBy, err := app.RefreshTokenStore.RevokeFamily(r.Context(), models.RefreshToken(session.Subject), app.Config.RefreshTokenReuseGrace)
+						if err != nil {
+							app.Reporter.ReportRequestError(errors.Wrap(err, "RevokeFamily"), r)
+						} else if reusedBy != 0 {
+							app.Reporter.ReportRequestError(fmt.Errorf("refresh token reused for account %d, session revoked", reusedBy), r)
+							if session.SessionID != "" {
+								services.BackchannelLogout(app.Config, app.KeyStore, app.Reporter, tenant, reusedBy, session.SessionID)
+							}
+						}
 					}
 				})
 
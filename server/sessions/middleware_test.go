@@ -1,13 +1,21 @@
 package sessions_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/logouts"
+	sessiontokens "github.com/keratin/authn-server/app/tokens/sessions"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/ops"
 	"github.com/keratin/authn-server/server/sessions"
@@ -93,6 +101,32 @@ func TestSession(t *testing.T) {
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
 
+	t.Run("expired session", func(t *testing.T) {
+		testApp.Config.SessionMaxAge = app.DomainDurations{Default: time.Nanosecond}
+		defer func() { testApp.Config.SessionMaxAge = app.DomainDurations{} }()
+
+		accountID := 10002
+		session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config, accountID)
+
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.NotEmpty(t, sessions.Get(r))
+			assert.Empty(t, sessions.GetAccountID(r))
+
+			w.WriteHeader(http.StatusOK)
+		}
+		server := httptest.NewServer(sessions.Middleware(testApp)(http.HandlerFunc(handler)))
+		defer server.Close()
+
+		client := route.NewClient(server.URL).WithCookie(session)
+		res, err := client.Get("/")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		tokens, err := testApp.RefreshTokenStore.FindAll(context.Background(), accountID)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
 	t.Run("missing session", func(t *testing.T) {
 		handler := func(w http.ResponseWriter, r *http.Request) {
 			assert.Empty(t, sessions.Get(r))
@@ -109,3 +143,95 @@ func TestSession(t *testing.T) {
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
 }
+
+func TestSessionBackchannelLogout(t *testing.T) {
+	received := make(chan string, 2)
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.FormValue("logout_token")
+	}))
+	defer remoteApp.Close()
+	otherTenantApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- "other tenant"
+	}))
+	defer otherTenantApp.Close()
+	remoteURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+	otherTenantURL, err := url.Parse(otherTenantApp.URL)
+	require.NoError(t, err)
+
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	testApp := &app.App{
+		Config: &app.Config{
+			SessionCookieName:  "authn-test",
+			SessionSigningKey:  []byte("drinkme"),
+			AuthNURL:           &url.URL{Scheme: "http", Host: "authn.example.com"},
+			ApplicationDomains: []route.Domain{{Hostname: "example.com"}, {Hostname: "other.com"}},
+			TenantDomains:      map[string]string{"other.com": "other"},
+			BackchannelLogoutURIs: map[string]*url.URL{
+				"example.com": remoteURL,
+				"other.com":   otherTenantURL,
+			},
+		},
+		KeyStore:          mock.NewKeyStore(key),
+		RefreshTokenStore: mock.NewRefreshTokenStore(),
+		Reporter:          &ops.LogReporter{FieldLogger: logrus.New()},
+	}
+	server := httptest.NewServer(sessions.Middleware(testApp)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, sessions.GetAccountID(r))
+		w.WriteHeader(http.StatusOK)
+	})))
+	defer server.Close()
+
+	assertLogout := func(t *testing.T, session *http.Cookie, accountID int) {
+		claims, err := sessiontokens.Parse(session.Value, testApp.Config)
+		require.NoError(t, err)
+
+		select {
+		case logoutToken := <-received:
+			parsed, err := jwt.ParseSigned(logoutToken)
+			require.NoError(t, err)
+			logout := logouts.Claims{}
+			require.NoError(t, parsed.Claims(key.Public(), &logout))
+			assert.Equal(t, strconv.Itoa(accountID), logout.Subject)
+			assert.Equal(t, claims.SessionID, logout.SessionID)
+		case <-time.After(time.Second):
+			assert.Fail(t, "logout token was not sent")
+		}
+		select {
+		case <-received:
+			assert.Fail(t, "logout token was sent to another tenant")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	t.Run("expired session", func(t *testing.T) {
+		testApp.Config.SessionMaxAge = app.DomainDurations{Default: time.Nanosecond}
+		defer func() { testApp.Config.SessionMaxAge = app.DomainDurations{} }()
+
+		accountID := 10003
+		session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config, accountID)
+
+		res, err := route.NewClient(server.URL).WithCookie(session).Get("/")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assertLogout(t, session, accountID)
+	})
+
+	t.Run("reused session", func(t *testing.T) {
+		testApp.Config.RefreshTokenRotation = true
+		defer func() { testApp.Config.RefreshTokenRotation = false }()
+
+		accountID := 10004
+		session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config, accountID)
+		claims, err := sessiontokens.Parse(session.Value, testApp.Config)
+		require.NoError(t, err)
+		_, err = testApp.RefreshTokenStore.Rotate(context.Background(), models.RefreshToken(claims.Subject), accountID)
+		require.NoError(t, err)
+
+		res, err := route.NewClient(server.URL).WithCookie(session).Get("/")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assertLogout(t, session, accountID)
+	})
+}
@@ -17,7 +17,18 @@ func Get(r *http.Request) *sessions.Claims {
 	return nil
 }
 
+// GetAccountID returns the account of a live session. Sessions that are restricted to setting up
+// MFA are ignored.
 func GetAccountID(r *http.Request) int {
+	if session := Get(r); session != nil && session.MFAEnrollment {
+		return 0
+	}
+	return GetEnrollingAccountID(r)
+}
+
+// GetEnrollingAccountID returns the account of a live session, including a session that is
+// restricted to setting up MFA.
+func GetEnrollingAccountID(r *http.Request) int {
 	fn, ok := r.Context().Value(accountIDKey(0)).(func() int)
 	if ok {
 		return fn()
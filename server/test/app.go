@@ -27,35 +27,51 @@ func App() *app.App {
 	}
 
 	cfg := app.Config{
-		BcryptCost:              4,
-		SessionSigningKey:       []byte("TestKey"),
-		AuthNURL:                authnURL,
-		SessionCookieName:       "authn",
-		OAuthCookieName:         "authn-oauth-nonce",
-		DBEncryptionKey:         []byte("DLz2TNDRdWWA5w8YNeCJ7uzcS4WDzQmB"),
-		ApplicationDomains:      []route.Domain{{Hostname: "test.com"}},
-		PasswordMinComplexity:   2,
-		AppPasswordResetURL:     &url.URL{Scheme: "https", Host: "app.example.com"},
-		AppPasswordlessTokenURL: &url.URL{Scheme: "https", Host: "app.example.com"},
-		EnableSignup:            true,
-		SameSite:                http.SameSiteDefaultMode,
-		PasswordChangeLogout:    false,
+		BcryptCost:                4,
+		SessionSigningKey:         []byte("TestKey"),
+		AuthNURL:                  authnURL,
+		SessionCookieName:         "authn",
+		OAuthCookieName:           "authn-oauth-nonce",
+		TrustedDeviceCookieName:   "authn-device",
+		TrustedDeviceSigningKey:   []byte("TestKey"),
+		TrustedDeviceTTL:          time.Hour,
+		OTPCodeSigningKey:         []byte("TestKey"),
+		OTPCodeTTL:                time.Minute,
+		InvitationTokenSigningKey: []byte("TestKey"),
+		InvitationTokenTTL:        time.Hour,
+		DBEncryptionKey:           []byte("DLz2TNDRdWWA5w8YNeCJ7uzcS4WDzQmB"),
+		ApplicationDomains:        []route.Domain{{Hostname: "test.com"}},
+		PasswordMinComplexity:     2,
+		AppPasswordResetURL:       &url.URL{Scheme: "https", Host: "app.example.com"},
+		AppPasswordlessTokenURL:   &url.URL{Scheme: "https", Host: "app.example.com"},
+		AppOTPDeliveryURL:         &url.URL{Scheme: "https", Host: "app.example.com"},
+		EnableSignup:              true,
+		SameSite:                  http.SameSiteDefaultMode,
+		PasswordChangeLogout:      false,
 	}
 
 	//Create mock blob stores for the totp cache object (TODO: Create an interface?)
 	bs := mock.NewBlobStore(time.Minute, time.Minute)
-	ebs := data.NewEncryptedBlobStore(bs, cfg.DBEncryptionKey)
+	ebs := data.NewEncryptedBlobStore(bs, cfg.DBEncryptionKeyring())
 
 	logger := logrus.New()
 	return &app.App{
-		Config:            &cfg,
-		KeyStore:          mock.NewKeyStore(weakKey),
-		AccountStore:      mock.NewAccountStore(),
-		RefreshTokenStore: mock.NewRefreshTokenStore(),
-		TOTPCache:         data.NewTOTPCache(ebs),
-		Actives:           mock.NewActives(),
-		Reporter:          &ops.LogReporter{FieldLogger: logger},
-		OauthProviders:    map[string]oauth.Provider{},
-		Logger:            logger,
+		Config:             &cfg,
+		KeyStore:           mock.NewKeyStore(weakKey),
+		AccountStore:       mock.NewAccountStore(),
+		RefreshTokenStore:  mock.NewRefreshTokenStore(),
+		TrustedDeviceStore: mock.NewTrustedDeviceStore(cfg.TrustedDeviceTTL),
+		ClientStore:        mock.NewClientStore(),
+		OrganizationStore:  mock.NewOrganizationStore(),
+		InvitationStore:    mock.NewInvitationStore(),
+		TOTPCache:          data.NewTOTPCache(ebs),
+		OTPCodeCache:       data.NewOTPCodeCache(ebs),
+		DeviceCodeCache:    data.NewDeviceCodeCache(ebs),
+		ClientAssertions:   data.NewClientAssertionCache(ebs),
+		MFAChallenges:      data.NewMFAChallengeCache(ebs),
+		Actives:            mock.NewActives(),
+		Reporter:           &ops.LogReporter{FieldLogger: logger},
+		OauthProviders:     map[string]oauth.Provider{},
+		Logger:             logger,
 	}
 }
@@ -0,0 +1,26 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/keratin/authn-server/app"
+)
+
+// OTPDelivery serves a fake remote app that receives one-time codes, and configures the app to
+// deliver codes to it. Each delivery is sent on the returned channel.
+func OTPDelivery(app *app.App) (*httptest.Server, <-chan url.Values) {
+	deliveries := make(chan url.Values, 10)
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		deliveries <- r.PostForm
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	app.Config.AppOTPDeliveryURL, _ = url.Parse(remoteApp.URL)
+	return remoteApp, deliveries
+}
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"net/http"
 
 	jose "github.com/go-jose/go-jose/v3"
@@ -12,7 +13,7 @@ import (
 )
 
 func CreateSession(tokenStore data.RefreshTokenStore, cfg *app.Config, accountID int) *http.Cookie {
-	sessionToken, err := sessions.New(tokenStore, cfg, accountID, cfg.ApplicationDomains[0].String(), []string{"pwd"})
+	sessionToken, err := sessions.New(context.Background(), tokenStore, cfg, accountID, cfg.ApplicationDomains[0].String(), []string{"pwd"})
 	if err != nil {
 		panic(err)
 	}
@@ -40,7 +41,7 @@ func RevokeSession(store data.RefreshTokenStore, cfg *app.Config, session *http.
 	if err != nil {
 		panic(err)
 	}
-	err = store.Revoke(models.RefreshToken(claims.Subject))
+	err = store.Revoke(context.Background(), models.RefreshToken(claims.Subject))
 	if err != nil {
 		panic(err)
 	}